@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"http_server/auth-service/internal/config"
+)
+
+const configUsage = "usage: auth-service config check [path]"
+
+// runConfigCommand handles "auth-service config ...". It returns the exit
+// code: 0 for a valid file, 1 for an invalid one and 2 for bad usage.
+func runConfigCommand(args []string, defaultPath string) int {
+	if len(args) == 0 || args[0] != "check" || len(args) > 2 {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+	path := defaultPath
+	if len(args) == 2 {
+		path = args[1]
+	}
+
+	if _, err := config.LoadConfig(path); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+	fmt.Printf("%s: OK\n", path)
+	return 0
+}
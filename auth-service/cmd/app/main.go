@@ -5,128 +5,435 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 	"time"
 
 	"http_server/auth-service/internal/config"
 	"http_server/auth-service/internal/domain/models"
 	"http_server/auth-service/internal/domain/repository"
+	"http_server/auth-service/internal/events"
+	"http_server/auth-service/internal/grpcserver"
 	"http_server/auth-service/internal/handler"
+	"http_server/auth-service/internal/mail"
+	"http_server/auth-service/internal/passhash"
 	"http_server/auth-service/internal/server"
 	"http_server/auth-service/internal/service"
-	"http_server/auth-service/pkg/logging"
+	"http_server/auth-service/internal/validator"
 	"http_server/auth-service/pkg/middleware"
 	"http_server/auth-service/pkg/monitoring"
+	sharedconfig "http_server/shared/config"
+	"http_server/shared/database"
+	"http_server/shared/jwtkeys"
+	"http_server/shared/logging"
+	"http_server/shared/metrics"
+	"http_server/shared/secrets"
+	"http_server/shared/tracing"
 
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"go.uber.org/zap"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
 )
 
 func main() {
 	// Load configuration
-	cfg, err := config.LoadConfig("config.yaml")
+	configPath := os.Getenv("CONFIG_PATH")
+	if configPath == "" {
+		configPath = "config.yaml"
+	}
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:], configPath))
+	}
+	cfg, err := config.LoadConfig(configPath)
 	if err != nil {
-		log.Println("Failed to load config: %v\n", err)
+		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	// Initialize logger
-	logConfig := &logging.Config{
-		Environment: os.Getenv("APP_ENV"),
-		LogLevel:    cfg.Logging.Level,
-		FilePath:    cfg.Logging.Output,
-		MaxSize:     10, // 10MB
-		MaxBackups:  5,
-		MaxAge:      30, // 30 days
-		Compress:    true,
+	// Read secrets, refusing placeholder values outside development. This
+	// happens before the logger exists, since the logger needs the PII key.
+	secretProvider, err := secrets.NewProvider(cfg.Secrets)
+	if err != nil {
+		log.Fatalf("Failed to initialize secrets provider: %v", err)
+	}
+	if err := config.ResolveSecrets(context.Background(), cfg, secretProvider); err != nil {
+		log.Fatalf("Failed to read secrets: %v", err)
+	}
+	if !secrets.IsDevelopment(os.Getenv("APP_ENV")) {
+		if err := config.CheckSecrets(cfg); err != nil {
+			log.Fatalf("Refusing to start with default secrets: %v", err)
+		}
 	}
 
+	// Initialize logger
+	logConfig := newLogConfig(cfg.Logging)
 	logger, err := logging.NewLogger(logConfig)
 	if err != nil {
 		fmt.Printf("Failed to initialize logger: %v\n", err)
 		os.Exit(1)
 	}
-	defer func(logger *logging.Logger) {
-		err := logger.Sync()
-		if err != nil {
-			fmt.Errorf("Failed to sync logger: %v\n", err)
+	defer logger.Sync()
+
+	if cfg.Logging.PIIKey == "" {
+		logger.Warn("No PII key configured; hashed personal data in the logs cannot be correlated across restarts")
+	}
+
+	// Initialize tracing
+	tracingConfig := cfg.Telemetry.Tracing
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Enabled:     cfg.Telemetry.Enabled && tracingConfig.Enabled,
+		Provider:    tracingConfig.Provider,
+		Endpoint:    tracingConfig.Endpoint,
+		Insecure:    tracingConfig.Insecure,
+		ServiceName: tracingConfig.ServiceName,
+		Environment: os.Getenv("APP_ENV"),
+		SampleRate:  tracingConfig.SampleRate,
+	})
+	if err != nil {
+		logger.Fatal("Failed to initialize tracing", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("Failed to flush traces", err)
 		}
-	}(logger)
+	}()
 
 	// Initialize metrics
-	metrics := monitoring.NewMetrics(cfg.Metrics.ServiceName)
+	metricsConfig := cfg.Telemetry.Metrics
+	registry := metrics.NewRegistry()
+	authMetrics := monitoring.NewMetrics(metricsConfig.ServiceName, registry)
+	httpMetrics := metrics.NewHTTPMetrics(metricsConfig.ServiceName, registry)
 
 	// Initialize database connection
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.DBName,
-		cfg.Database.SSLMode,
-	)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := database.Open(cfg.Database)
 	if err != nil {
 		logger.Fatal("Failed to connect to database", err)
 	}
 
-	// Configure connection pool
+	if err := db.Use(repository.NewTracingPlugin()); err != nil {
+		logger.Fatal("Failed to instrument database", err)
+	}
+
+	// Export connection pool statistics
 	sqlDB, err := db.DB()
 	if err != nil {
 		logger.Fatal("Failed to get database instance", err)
 	}
-
-	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
-	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
-	sqlDB.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	registry.MustRegister(collectors.NewDBStatsCollector(sqlDB, cfg.Database.DBName))
 
 	// Auto-migrate database schemas
-	if err := db.AutoMigrate(&models.User{}, &models.Role{}, &models.UserRole{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.OutboxEvent{},
+		&models.MagicLink{}, &models.WebAuthnCredential{}, &models.WebAuthnSession{}); err != nil {
 		logger.Fatal("Failed to auto-migrate database", err)
 	}
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	roleRepo := repository.NewRoleRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	magicLinkRepo := repository.NewMagicLinkRepository(db)
+	webauthnRepo := repository.NewWebAuthnRepository(db)
+	transactor := repository.NewTransactor(db)
+
+	// Initialize password policy
+	passwordValidator, err := newPasswordValidator(cfg.Security.Password)
+	if err != nil {
+		logger.Fatal("Failed to initialize password policy", err)
+	}
+
+	passwordHasher, err := newPasswordHasher(cfg.Security.Hashing)
+	if err != nil {
+		logger.Fatal("Failed to initialize password hasher", err)
+	}
+
+	mailer, err := mail.NewSender(cfg.Mail, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize mail sender", err)
+	}
+
+	webAuthn, err := newWebAuthn(cfg.Passwordless.WebAuthn)
+	if err != nil {
+		logger.Fatal("Failed to initialize WebAuthn", err)
+	}
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo, roleRepo, []byte(cfg.JWT.SecretKey), logger)
+	signingKeys := jwtkeys.New([]byte(cfg.JWT.SecretKey))
+	authService := service.NewAuthService(userRepo, roleRepo, outboxRepo, transactor, cfg.Account, passwordValidator, passwordHasher, signingKeys, logger)
+	accountService := service.NewAccountService(userRepo, roleRepo, outboxRepo, transactor, cfg.Account, passwordValidator, passwordHasher, logger)
+	passwordlessService := service.NewPasswordlessService(userRepo, roleRepo, magicLinkRepo, webauthnRepo, transactor, mailer, webAuthn, cfg.Passwordless, signingKeys, logger)
+
+	// Initialize event publishing
+	publisher, err := events.NewPublisher(cfg.Events)
+	if err != nil {
+		logger.Fatal("Failed to create event publisher", err)
+	}
+	defer publisher.Close()
+	relay := events.NewRelay(outboxRepo, transactor, publisher, cfg.Events, logger)
 
 	// Initialize handlers and middleware
-	authHandler := handler.NewAuthHandler(authService, logger, metrics)
-	authMiddleware := middleware.NewAuthMiddleware(authService, logger, metrics)
+	authHandler := handler.NewAuthHandler(authService, logger, authMetrics)
+	accountHandler := handler.NewAccountHandler(accountService, logger)
+	passwordlessHandler := handler.NewPasswordlessHandler(passwordlessService, cfg.Passwordless.MagicLink.TTL, logger)
+	mw := middleware.NewMiddleware(newMiddlewareConfig(cfg), authService, logger, authMetrics)
 
-	// Initialize server
-	srv := server.NewServer(cfg, authHandler, authMiddleware)
+	// Initialize health checks and server
+	healthRegistry := server.NewHealth(cfg, sqlDB, outboxRepo, signingKeys)
+	srv := server.NewServer(cfg, authHandler, accountHandler, passwordlessHandler, healthRegistry, mw, httpMetrics, logger)
 
-	// Setup graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	var grpcServer *grpcserver.Server
+	if cfg.GRPC.Enabled {
+		grpcServer, err = grpcserver.NewServer(cfg, server.NewTLSConfig(cfg.Server.TLS), authService, mw, healthRegistry, logger)
+		if err != nil {
+			logger.Fatal("Failed to initialize gRPC server", err)
+		}
+	}
 
-	// Start server
+	// Start background workers
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	// Purge accounts whose deletion grace period has ended
+	go accountService.RunPurger(workerCtx)
+	// Serve metrics and the health status on their own port
+	if cfg.Telemetry.Enabled && metricsConfig.Enabled {
+		metricsServer := server.NewMetricsServer(metricsConfig, registry, healthRegistry, logger)
+		go func() {
+			if err := metricsServer.Run(workerCtx); err != nil {
+				logger.Error("Metrics server stopped with error", err)
+			}
+		}()
+	}
+	// Serve the health report and log level endpoint to local operators
+	if cfg.Admin.Enabled {
+		adminServer := server.NewAdminServer(cfg.Admin, healthRegistry, logger)
+		go func() {
+			if err := adminServer.Run(workerCtx); err != nil {
+				logger.Error("Admin server stopped with error", err)
+			}
+		}()
+	}
+	// Serve the gRPC API for internal callers
+	if grpcServer != nil {
+		go func() {
+			if err := grpcServer.Run(workerCtx); err != nil {
+				logger.Error("gRPC server stopped with error", err)
+			}
+		}()
+	}
+	// Relay domain events recorded in the outbox
+	go relay.Run(workerCtx)
+	// Remove expired magic links and passkey ceremonies
+	go passwordlessService.RunCleanup(workerCtx)
+	// Issue and mail the magic links queued by requests
+	mailerDone := make(chan struct{})
 	go func() {
-		if err := srv.Run(); err != nil {
-			logger.Fatal("Failed to start server", err)
-		}
+		passwordlessService.RunMailer(workerCtx)
+		close(mailerDone)
 	}()
+	// Pick up rotations of the JWT signing key
+	if cfg.Secrets.Provider != "env" {
+		go secrets.Watch(workerCtx, secretProvider, config.SecretJWTKey, cfg.JWT.SecretKey, cfg.Secrets.RefreshInterval,
+			signingKeys.Rotator(os.Getenv("APP_ENV"), logger), logger)
+	}
+	// Apply configuration changes on SIGHUP or when the file changes
+	if cfg.ConfigReload.Enabled {
+		current := cfg
+		go sharedconfig.Watch(workerCtx, configPath, cfg.ConfigReload.Interval, func() {
+			current = reloadConfig(configPath, current, mw, logger)
+		})
+	}
 
-	logger.Info("Server started successfully")
+	// Serve until SIGINT or SIGTERM, then drain in-flight requests
+	if err := srv.Run(context.Background()); err != nil {
+		logger.Fatal("Server stopped with error", err)
+	}
 
-	// Wait for interrupt signal
-	sig := <-sigChan
-	logger.Info("Received shutdown signal", zap.String("signal", sig.String()))
+	// Mail the magic links still queued; their requests were answered.
+	stopWorkers()
+	select {
+	case <-mailerDone:
+	case <-time.After(cfg.Server.ShutdownTimeout):
+		logger.Warn("Timed out mailing queued magic links")
+	}
 
-	// Create shutdown context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	logger.Info("Server shutdown completed")
+}
 
-	// Shutdown server gracefully
-	if err := srv.Shutdown(ctx); err != nil {
-		logger.Error("Server shutdown failed", err)
-		os.Exit(1)
+// newLogConfig maps the logging settings. Without sinks, output names a
+// file, or "stdout" or "stderr", as before sinks existed.
+func newLogConfig(cfg config.LoggingConfig) *logging.Config {
+	logConfig := &logging.Config{
+		ServiceName: "auth-service",
+		Environment: os.Getenv("APP_ENV"),
+		LogLevel:    cfg.Level,
+		FilePath:    cfg.Output,
+		MaxSize:     10, // 10MB
+		MaxBackups:  5,
+		MaxAge:      30, // 30 days
+		Compress:    true,
+		Format:      cfg.Format,
+		TimeFormat:  cfg.TimeFormat,
+		PIIFields:   cfg.PIIFields,
+		PIIKey:      []byte(cfg.PIIKey),
 	}
+	for _, sink := range cfg.Sinks {
+		logConfig.Sinks = append(logConfig.Sinks, logging.SinkConfig{
+			Type:       sink.Type,
+			Format:     sink.Format,
+			Path:       sink.Path,
+			MaxSize:    sink.MaxSize,
+			MaxBackups: sink.MaxBackups,
+			MaxAge:     sink.MaxAge,
+			Compress:   sink.Compress,
+			Network:    sink.Network,
+			Address:    sink.Address,
+			Endpoint:   sink.Endpoint,
+		})
+	}
+	for _, override := range cfg.PackageLevels {
+		logConfig.PackageLevels = append(logConfig.PackageLevels, logging.PackageLevel{
+			Package: override.Package,
+			Level:   override.Level,
+		})
+	}
+	return logConfig
+}
 
-	logger.Info("Server shutdown completed")
+// reloadConfig loads the file again and applies the settings that can change
+// while running. It returns the configuration now in effect, which keeps
+// the current values of everything else.
+func reloadConfig(path string, current *config.Config, mw *middleware.Middleware, logger *logging.Logger) *config.Config {
+	next, err := config.LoadConfig(path)
+	if err != nil {
+		logger.Error("Configuration reload rejected, keeping the current configuration", err)
+		return current
+	}
+
+	applied, restart := config.Reload(current, next)
+	if len(restart) > 0 {
+		logger.Warn("Configuration changes that need a restart were not applied", zap.Strings("sections", restart))
+	}
+	if err := logger.SetLevel(applied.Logging.Level); err != nil {
+		logger.Error("Failed to change the log level", err)
+	}
+	mw.Reload(newMiddlewareConfig(applied))
+	logger.Info("Configuration reloaded", zap.String("path", path))
+	return applied
+}
+
+// newPasswordValidator builds the password policy, loading the reject list
+// file and opening the breached-password dataset when configured.
+func newPasswordValidator(cfg config.PasswordConfig) (*validator.PasswordValidator, error) {
+	rejectList := append([]string(nil), cfg.RejectList...)
+	if cfg.RejectListFile != "" {
+		passwords, err := validator.LoadRejectList(cfg.RejectListFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load password reject list: %w", err)
+		}
+		rejectList = append(rejectList, passwords...)
+	}
+
+	var breaches validator.BreachChecker
+	if cfg.BreachDatasetDir != "" {
+		checker, err := validator.NewHashPrefixBreachChecker(cfg.BreachDatasetDir, cfg.BreachThreshold)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open breached password dataset: %w", err)
+		}
+		breaches = checker
+	}
+
+	return validator.NewPasswordValidator(validator.PasswordConfig{
+		MinLength:      cfg.MinLength,
+		MaxLength:      cfg.MaxLength,
+		RequireUpper:   cfg.RequireUpper,
+		RequireLower:   cfg.RequireLower,
+		RequireNumber:  cfg.RequireNumber,
+		RequireSpecial: cfg.RequireSpecial,
+		MinStrength:    cfg.MinStrength,
+		RejectList:     rejectList,
+	}, breaches), nil
+}
+
+// newMiddlewareConfig maps the security settings onto the middleware
+// components. Auth and RBAC are always built since routes depend on them;
+// client certificate auth is built when mutual TLS is configured.
+func newMiddlewareConfig(cfg *config.Config) *middleware.Config {
+	var mwConfig middleware.Config
+	mwConfig.Auth.Enabled = true
+	mwConfig.RBAC.Enabled = true
+
+	rateLimit := cfg.Security.RateLimit
+	mwConfig.RateLimit.Enabled = rateLimit.Enabled
+	mwConfig.RateLimit.RequestsPerMinute = rateLimit.RequestsPerMinute
+	mwConfig.RateLimit.Burst = rateLimit.BurstSize
+
+	if tls := cfg.Server.TLS; tls.Enabled && tls.ClientCAFile != "" {
+		mwConfig.MachineAuth.Enabled = true
+		mwConfig.MachineAuth.Principals = make(map[string]string, len(tls.Principals))
+		for _, principal := range tls.Principals {
+			mwConfig.MachineAuth.Principals[principal.Identity] = principal.Principal
+		}
+	}
+
+	headers := cfg.Security.Headers
+	mwConfig.Security.Enabled = true
+	mwConfig.Security.Headers = middleware.HeadersConfig{
+		EnableCSP:     headers.EnableCSP,
+		CSPDirectives: headers.CSPDirectives,
+		EnableHSTS:    headers.EnableHSTS,
+		HSTSMaxAge:    headers.HSTSMaxAge,
+	}
+	mwConfig.CORS = middleware.CORSConfig{
+		Enabled:          len(headers.AllowedOrigins) > 0,
+		AllowedOrigins:   headers.AllowedOrigins,
+		AllowedMethods:   headers.AllowedMethods,
+		AllowedHeaders:   headers.AllowedHeaders,
+		ExposedHeaders:   headers.ExposedHeaders,
+		AllowCredentials: headers.AllowCredentials,
+	}
+	return &mwConfig
+}
+
+// newWebAuthn configures the passkey relying party, or returns nil when
+// passkeys are disabled.
+func newWebAuthn(cfg config.WebAuthnConfig) (*webauthn.WebAuthn, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	timeout := webauthn.TimeoutConfig{Enforce: true, Timeout: cfg.Timeout}
+	return webauthn.New(&webauthn.Config{
+		RPID:          cfg.RPID,
+		RPDisplayName: cfg.RPDisplayName,
+		RPOrigins:     cfg.RPOrigins,
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        timeout,
+			Registration: timeout,
+		},
+	})
+}
+
+// newPasswordHasher builds the password hasher, decoding the "keyid=secret"
+// pepper entries.
+func newPasswordHasher(cfg config.HashingConfig) (passhash.Hasher, error) {
+	peppers := make(map[string][]byte, len(cfg.Peppers))
+	for _, entry := range cfg.Peppers {
+		keyID, secret, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || keyID == "" || secret == "" {
+			return nil, fmt.Errorf("pepper entries must be keyid=secret")
+		}
+		peppers[keyID] = []byte(secret)
+	}
+
+	return passhash.NewHasher(passhash.Config{
+		Algorithm:  cfg.Algorithm,
+		BcryptCost: cfg.BcryptCost,
+		Argon2: passhash.Argon2Params{
+			Memory:      cfg.Argon2.Memory,
+			Iterations:  cfg.Argon2.Iterations,
+			Parallelism: cfg.Argon2.Parallelism,
+			SaltLength:  cfg.Argon2.SaltLength,
+			KeyLength:   cfg.Argon2.KeyLength,
+		},
+		Peppers:     peppers,
+		PepperKeyID: cfg.PepperKeyID,
+	})
 }
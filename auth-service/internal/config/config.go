@@ -1,127 +1,804 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"strings"
+	"net"
+	"reflect"
 	"time"
 
-	"github.com/spf13/viper"
+	sharedconfig "http_server/shared/config"
+	"http_server/shared/database"
 )
 
 type Config struct {
-	Server   ServerConfig
-	JWT      JWTConfig
-	Database DatabaseConfig
-	Security SecurityConfig
-	Logging  LoggingConfig
-	Metrics  MetricsConfig
+	Server    ServerConfig    `mapstructure:"server"`
+	JWT       JWTConfig       `mapstructure:"jwt"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	Security  SecurityConfig  `mapstructure:"security"`
+	Logging   LoggingConfig   `mapstructure:"logging"`
+	Telemetry TelemetryConfig `mapstructure:"telemetry"`
+	Account   AccountConfig   `mapstructure:"account"`
+	Events    EventsConfig    `mapstructure:"events"`
+	Redis     RedisConfig     `mapstructure:"redis"`
+	Health    HealthConfig    `mapstructure:"health"`
+	GRPC      GRPCConfig      `mapstructure:"grpc"`
+	Secrets   SecretsConfig   `mapstructure:"secrets"`
+	Admin     AdminConfig     `mapstructure:"admin"`
+
+	ConfigReload ReloadConfig `mapstructure:"config_reload"`
+
+	Passwordless PasswordlessConfig `mapstructure:"passwordless"`
+	Mail         MailConfig         `mapstructure:"mail"`
+}
+
+// PasswordlessConfig controls login without a password.
+type PasswordlessConfig struct {
+	MagicLink MagicLinkConfig `mapstructure:"magic_link"`
+	WebAuthn  WebAuthnConfig  `mapstructure:"webauthn"`
+	// CleanupInterval is how often expired links and ceremonies are removed.
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+}
+
+// MagicLinkConfig controls emailed login links. URL is the frontend page
+// that receives the token as the "token" query parameter and posts it back.
+// Links are issued and mailed by Workers goroutines from a queue holding up
+// to QueueSize requests; requests beyond that are refused.
+type MagicLinkConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	URL       string        `mapstructure:"url"`
+	TTL       time.Duration `mapstructure:"ttl"`
+	Workers   int           `mapstructure:"workers"`
+	QueueSize int           `mapstructure:"queue_size"`
+}
+
+// WebAuthnConfig identifies this service as a WebAuthn relying party.
+// RPID is the effective domain, e.g. "example.com"; RPOrigins are the full
+// origins allowed to run ceremonies.
+type WebAuthnConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	RPID          string        `mapstructure:"rp_id"`
+	RPDisplayName string        `mapstructure:"rp_display_name"`
+	RPOrigins     []string      `mapstructure:"rp_origins"`
+	Timeout       time.Duration `mapstructure:"timeout"`
+}
+
+// MailConfig selects how email is sent. Sender is "smtp" or "log".
+type MailConfig struct {
+	Sender   string `mapstructure:"sender"`
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+}
+
+// AccountConfig controls the account lifecycle. Deleted accounts stay
+// recoverable for DeletionGracePeriod before the purger removes them.
+type AccountConfig struct {
+	DeletionGracePeriod time.Duration `mapstructure:"deletion_grace_period"`
+	PurgeInterval       time.Duration `mapstructure:"purge_interval"`
+	PurgeBatchSize      int           `mapstructure:"purge_batch_size"`
+	// LockoutThreshold consecutive failed logins lock the account for
+	// LockoutDuration.
+	LockoutThreshold int           `mapstructure:"lockout_threshold"`
+	LockoutDuration  time.Duration `mapstructure:"lockout_duration"`
+}
+
+// EventsConfig controls how domain events recorded in the outbox are relayed
+// to the broker. Publisher is "kafka" or "memory".
+type EventsConfig struct {
+	Publisher      string        `mapstructure:"publisher"`
+	Brokers        []string      `mapstructure:"brokers"`
+	Topic          string        `mapstructure:"topic"`
+	PublishTimeout time.Duration `mapstructure:"publish_timeout"`
+	RelayInterval  time.Duration `mapstructure:"relay_interval"`
+	BatchSize      int           `mapstructure:"batch_size"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`
+	Retention      time.Duration `mapstructure:"retention"`
+}
+
+// GRPCConfig controls the gRPC API for internal callers. It uses the
+// server's TLS settings. Reflection lets tools such as grpcurl discover the
+// API.
+type GRPCConfig struct {
+	Enabled    bool `mapstructure:"enabled"`
+	Port       int  `mapstructure:"port"`
+	Reflection bool `mapstructure:"reflection"`
+}
+
+// ReloadConfig controls reloading the file while running. The file is
+// checked for changes every Interval; SIGHUP forces a reload either way.
+// Only the log level, rate limits and CORS policy take effect without a
+// restart.
+type ReloadConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// RedisConfig locates the Redis instance. An empty Host means Redis is not
+// used and its health check is skipped.
+type RedisConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Password string `mapstructure:"password"`
+}
+
+// HealthConfig controls the dependency checks behind the readiness probe.
+// Results are cached for CacheTTL; each check is bounded by Timeout.
+// OutboxMaxLag is how old the oldest unpublished event may get before the
+// service reports itself degraded.
+type HealthConfig struct {
+	CacheTTL     time.Duration `mapstructure:"cache_ttl"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	OutboxMaxLag time.Duration `mapstructure:"outbox_max_lag"`
+}
+
+// AdminConfig controls the operator listener serving the detailed health
+// report and the log level endpoint. It has no authentication, so Host must
+// be a loopback address; reach it with kubectl port-forward or from inside
+// the container.
+type AdminConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Host    string `mapstructure:"host"`
+	Port    int    `mapstructure:"port"`
 }
 
+// LoggingConfig controls the service logs. Entries go to every sink in
+// Sinks; without sinks, Output names a file, or "stdout" or "stderr".
+// PackageLevels override Level for entries logged from some packages.
+// PIIFields names the fields holding personal data, which are logged as an
+// HMAC-SHA256 keyed with PIIKey; it defaults to "email". Secrets such as
+// tokens and passwords are always redacted.
 type LoggingConfig struct {
-	Level      string
-	Format     string
-	Output     string
-	TimeFormat string
+	Level         string               `mapstructure:"level"`
+	Format        string               `mapstructure:"format"`
+	Output        string               `mapstructure:"output"`
+	TimeFormat    string               `mapstructure:"time_format"`
+	Sinks         []LogSinkConfig      `mapstructure:"sinks"`
+	PackageLevels []PackageLevelConfig `mapstructure:"package_levels"`
+	PIIFields     []string             `mapstructure:"pii_fields"`
+	PIIKey        string               `mapstructure:"pii_key"`
 }
 
+// LogSinkConfig is one log destination. Type is "stdout", "stderr", "file",
+// "syslog" or "otlp"; Format is "json" or "console". Files are rotated at
+// MaxSize megabytes. Syslog is reached over Network ("udp" or "tcp") at
+// Address; OTLP logs are posted to the OTLP/HTTP Endpoint.
+type LogSinkConfig struct {
+	Type       string `mapstructure:"type"`
+	Format     string `mapstructure:"format"`
+	Path       string `mapstructure:"path"`
+	MaxSize    int    `mapstructure:"max_size"`
+	MaxBackups int    `mapstructure:"max_backups"`
+	MaxAge     int    `mapstructure:"max_age"`
+	Compress   bool   `mapstructure:"compress"`
+	Network    string `mapstructure:"network"`
+	Address    string `mapstructure:"address"`
+	Endpoint   string `mapstructure:"endpoint"`
+}
+
+// PackageLevelConfig sets the level for a Go package, such as
+// "http_server/auth-service/internal/grpcserver", and those below it.
+type PackageLevelConfig struct {
+	Package string `mapstructure:"package"`
+	Level   string `mapstructure:"level"`
+}
+
+// MetricsConfig controls the Prometheus listener, served on its own port
+// so metrics stay reachable to scrapers but off the public API. ServiceName
+// is the metric namespace.
 type MetricsConfig struct {
-	Enabled     bool
-	Endpoint    string
-	ServiceName string
+	Enabled     bool   `mapstructure:"enabled"`
+	Port        int    `mapstructure:"port"`
+	Path        string `mapstructure:"path"`
+	ServiceName string `mapstructure:"service_name"`
+}
+
+// TelemetryConfig controls trace export. Enabled switches all telemetry
+// export off when false, whatever the nested settings say.
+type TelemetryConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Metrics MetricsConfig `mapstructure:"metrics"`
+	Tracing TracingConfig `mapstructure:"tracing"`
+}
+
+// TracingConfig configures the OTLP trace exporter. Provider is "otlp"
+// (gRPC) or "otlp-http"; an empty Endpoint falls back to the standard
+// OTEL_EXPORTER_OTLP_* environment variables.
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Provider    string  `mapstructure:"provider"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	ServiceName string  `mapstructure:"service_name"`
+	SampleRate  float64 `mapstructure:"sample_rate"`
 }
 
 type ServerConfig struct {
-	Port         int
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
-	TLSEnabled   bool
-	TLSCertFile  string
-	TLSKeyFile   string
+	Port            int           `mapstructure:"port"`
+	ReadTimeout     time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout    time.Duration `mapstructure:"write_timeout"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// DrainDelay is how long readiness fails before the listener closes on
+	// shutdown, giving load balancers time to stop routing here.
+	DrainDelay time.Duration    `mapstructure:"drain_delay"`
+	Middleware MiddlewareConfig `mapstructure:"middleware"`
+	TLS        TLSConfig        `mapstructure:"tls"`
 }
 
-type JWTConfig struct {
-	SecretKey           string
-	Expiration          time.Duration
-	RefreshTokenSecret  string
-	RefreshTokenExpiry  time.Duration
-	TokenRotationEnable bool
+// TLSConfig enables HTTPS and HTTP/2. MinVersion is "1.2" or "1.3";
+// CipherSuites restricts TLS 1.2 suites by Go name. Setting ClientCAFile
+// enables mutual TLS, with ClientAuth "verify_if_given" (the default, so
+// public clients need no certificate) or "require". Certificates are
+// reloaded when the files change, checked every ReloadInterval.
+type TLSConfig struct {
+	Enabled        bool                    `mapstructure:"enabled"`
+	CertFile       string                  `mapstructure:"cert_file"`
+	KeyFile        string                  `mapstructure:"key_file"`
+	MinVersion     string                  `mapstructure:"min_version"`
+	CipherSuites   []string                `mapstructure:"cipher_suites"`
+	ClientCAFile   string                  `mapstructure:"client_ca_file"`
+	ClientAuth     string                  `mapstructure:"client_auth"`
+	ReloadInterval time.Duration           `mapstructure:"reload_interval"`
+	Principals     []ClientPrincipalConfig `mapstructure:"principals"`
+}
+
+// ClientPrincipalConfig maps a client certificate identity to the machine
+// principal it authenticates as. Identity is matched against the
+// certificate's URI SANs (e.g. a SPIFFE ID), DNS SANs and common name.
+type ClientPrincipalConfig struct {
+	Identity  string `mapstructure:"identity"`
+	Principal string `mapstructure:"principal"`
+}
+
+// Names of the global HTTP middleware, as used in MiddlewareConfig.
+const (
+	MiddlewareRequestID       = "request_id"
+	MiddlewareTracing         = "tracing"
+	MiddlewareLogging         = "logging"
+	MiddlewareMetrics         = "metrics"
+	MiddlewareRecovery        = "recovery"
+	MiddlewareCORS            = "cors"
+	MiddlewareSecurityHeaders = "security_headers"
+	MiddlewareRateLimit       = "rate_limit"
+)
+
+// DefaultMiddlewareOrder is used when no order is configured.
+var DefaultMiddlewareOrder = []string{
+	MiddlewareRequestID,
+	MiddlewareTracing,
+	MiddlewareLogging,
+	MiddlewareMetrics,
+	MiddlewareRecovery,
+	MiddlewareCORS,
+	MiddlewareSecurityHeaders,
+	MiddlewareRateLimit,
+}
+
+// MiddlewareConfig assembles the middleware applied to every route. Order
+// lists middleware names, outermost first; a middleware left out, or
+// disabled in its own settings, is not applied. Routes adjusts the chain
+// for individual routes.
+type MiddlewareConfig struct {
+	Order     []string                `mapstructure:"order"`
+	Routes    []RouteMiddlewareConfig `mapstructure:"routes"`
+	AccessLog AccessLogConfig         `mapstructure:"access_log"`
+}
+
+// AccessLogConfig samples the access log entries of successful requests:
+// the first SampleFirst each second are written, then every
+// SampleThereafter-th. Zero SampleFirst logs every request.
+type AccessLogConfig struct {
+	SampleFirst      int `mapstructure:"sample_first"`
+	SampleThereafter int `mapstructure:"sample_thereafter"`
 }
 
-type DatabaseConfig struct {
-	Host            string
-	Port            int
-	User            string
-	Password        string
-	DBName          string
-	SSLMode         string
-	MaxOpenConns    int
-	MaxIdleConns    int
-	ConnMaxLifetime time.Duration
+// RouteMiddlewareConfig overrides the middleware for the route whose
+// template is Path, e.g. "/api/v1/auth/login". Skip names middleware not
+// applied to it; a non-zero RateLimit replaces the global limits with a
+// separate bucket for this route.
+type RouteMiddlewareConfig struct {
+	Path      string               `mapstructure:"path"`
+	Skip      []string             `mapstructure:"skip"`
+	RateLimit RouteRateLimitConfig `mapstructure:"rate_limit"`
+}
+
+type RouteRateLimitConfig struct {
+	RequestsPerMinute int `mapstructure:"requests_per_minute"`
+	BurstSize         int `mapstructure:"burst_size"`
+}
+
+type JWTConfig struct {
+	SecretKey           string        `mapstructure:"secret_key"`
+	Expiration          time.Duration `mapstructure:"expiration"`
+	RefreshTokenSecret  string        `mapstructure:"refresh_token_secret"`
+	RefreshTokenExpiry  time.Duration `mapstructure:"refresh_token_expiry"`
+	TokenRotationEnable bool          `mapstructure:"token_rotation_enable"`
 }
 
+// DatabaseConfig holds the connection and pool settings.
+type DatabaseConfig = database.Config
+
 type SecurityConfig struct {
-	RateLimit RateLimitConfig
-	Password  PasswordConfig
-	Headers   HeadersConfig
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	Password  PasswordConfig  `mapstructure:"password"`
+	Hashing   HashingConfig   `mapstructure:"hashing"`
+	Headers   HeadersConfig   `mapstructure:"headers"`
+}
+
+// HashingConfig selects how passwords are stored. Algorithm is "argon2id" or
+// "bcrypt". Peppers are "keyid=secret" entries; PepperKeyID picks the one
+// used for new hashes and may be empty to store hashes unpeppered. Stored
+// hashes using another algorithm, cost or pepper are upgraded at login.
+type HashingConfig struct {
+	Algorithm   string       `mapstructure:"algorithm"`
+	BcryptCost  int          `mapstructure:"bcrypt_cost"`
+	Argon2      Argon2Config `mapstructure:"argon2"`
+	PepperKeyID string       `mapstructure:"pepper_key_id"`
+	Peppers     []string     `mapstructure:"peppers"`
+}
+
+// Argon2Config holds the argon2id cost. Memory is in KiB.
+type Argon2Config struct {
+	Memory      uint32 `mapstructure:"memory"`
+	Iterations  uint32 `mapstructure:"iterations"`
+	Parallelism uint8  `mapstructure:"parallelism"`
+	SaltLength  uint32 `mapstructure:"salt_length"`
+	KeyLength   uint32 `mapstructure:"key_length"`
 }
 
 type RateLimitConfig struct {
-	Enabled           bool
-	RequestsPerMinute int
-	BurstSize         int
+	Enabled           bool `mapstructure:"enabled"`
+	RequestsPerMinute int  `mapstructure:"requests_per_minute"`
+	BurstSize         int  `mapstructure:"burst_size"`
 }
 
+// PasswordConfig is the policy applied wherever a password is set.
+// MinStrength is a zxcvbn score from 0 to 4. BreachDatasetDir points to a
+// local Pwned Passwords range dataset; leave it empty to skip the check.
 type PasswordConfig struct {
-	MinLength      int
-	RequireUpper   bool
-	RequireLower   bool
-	RequireNumber  bool
-	RequireSpecial bool
-	MaxAttempts    int
+	MinLength        int      `mapstructure:"min_length"`
+	MaxLength        int      `mapstructure:"max_length"`
+	RequireUpper     bool     `mapstructure:"require_upper"`
+	RequireLower     bool     `mapstructure:"require_lower"`
+	RequireNumber    bool     `mapstructure:"require_number"`
+	RequireSpecial   bool     `mapstructure:"require_special"`
+	MaxAttempts      int      `mapstructure:"max_attempts"`
+	MinStrength      int      `mapstructure:"min_strength"`
+	RejectList       []string `mapstructure:"reject_list"`
+	RejectListFile   string   `mapstructure:"reject_list_file"`
+	BreachDatasetDir string   `mapstructure:"breach_dataset_dir"`
+	BreachThreshold  int      `mapstructure:"breach_threshold"`
 }
 
 type HeadersConfig struct {
-	AllowedOrigins   []string
-	AllowedMethods   []string
-	AllowedHeaders   []string
-	ExposedHeaders   []string
-	AllowCredentials bool
-	EnableCSP        bool
-	CSPDirectives    string
-	EnableHSTS       bool
-	HSTSMaxAge       time.Duration
+	AllowedOrigins   []string      `mapstructure:"allowed_origins"`
+	AllowedMethods   []string      `mapstructure:"allowed_methods"`
+	AllowedHeaders   []string      `mapstructure:"allowed_headers"`
+	ExposedHeaders   []string      `mapstructure:"exposed_headers"`
+	AllowCredentials bool          `mapstructure:"allow_credentials"`
+	EnableCSP        bool          `mapstructure:"enable_csp"`
+	CSPDirectives    string        `mapstructure:"csp_directives"`
+	EnableHSTS       bool          `mapstructure:"enable_hsts"`
+	HSTSMaxAge       time.Duration `mapstructure:"hsts_max_age"`
 }
 
+// LoadConfig reads and validates the file at path. Unknown keys are errors.
+// All validation problems are returned together, one per line.
 func LoadConfig(path string) (*Config, error) {
-	viper.SetConfigFile(path)
-	viper.AutomaticEnv()
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-
-	if err := viper.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
-
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	if err := sharedconfig.Load(path, &config, sharedconfig.Strict()); err != nil {
+		return nil, err
 	}
 
+	applyDefaults(&config)
+
 	if err := validateConfig(&config); err != nil {
-		return nil, fmt.Errorf("config validation failed: %w", err)
+		return nil, fmt.Errorf("config validation failed:\n%w", err)
 	}
 
 	return &config, nil
 }
 
+// Reload merges the settings that can change while running, the log level,
+// rate limits and CORS policy, from next into a copy of current. It also
+// returns the top-level sections where next differs in settings that only
+// take effect on restart. Secrets are left out of the comparison and keep
+// their current values, since they come from the secrets provider rather
+// than the file.
+func Reload(current, next *Config) (*Config, []string) {
+	applied := *current
+	applied.Logging.Level = next.Logging.Level
+	applied.Security.RateLimit.RequestsPerMinute = next.Security.RateLimit.RequestsPerMinute
+	applied.Security.RateLimit.BurstSize = next.Security.RateLimit.BurstSize
+	headers := &applied.Security.Headers
+	headers.AllowedOrigins = next.Security.Headers.AllowedOrigins
+	headers.AllowedMethods = next.Security.Headers.AllowedMethods
+	headers.AllowedHeaders = next.Security.Headers.AllowedHeaders
+	headers.ExposedHeaders = next.Security.Headers.ExposedHeaders
+	headers.AllowCredentials = next.Security.Headers.AllowCredentials
+
+	compared, incoming := applied, *next
+	for _, config := range []*Config{&compared, &incoming} {
+		for _, secret := range secretFields(config) {
+			*secret.Value = ""
+		}
+	}
+
+	var restart []string
+	va, vb := reflect.ValueOf(compared), reflect.ValueOf(incoming)
+	for i := 0; i < va.NumField(); i++ {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			restart = append(restart, va.Type().Field(i).Tag.Get("mapstructure"))
+		}
+	}
+	return &applied, restart
+}
+
+func applyDefaults(config *Config) {
+	config.Secrets.ApplyDefaults()
+	if config.ConfigReload.Interval == 0 {
+		config.ConfigReload.Interval = 10 * time.Second
+	}
+	if config.Server.ShutdownTimeout == 0 {
+		config.Server.ShutdownTimeout = 30 * time.Second
+	}
+	if config.GRPC.Port == 0 {
+		config.GRPC.Port = 50051
+	}
+	if len(config.Server.Middleware.Order) == 0 {
+		config.Server.Middleware.Order = DefaultMiddlewareOrder
+	}
+	if config.Health.CacheTTL == 0 {
+		config.Health.CacheTTL = 2 * time.Second
+	}
+	if config.Health.Timeout == 0 {
+		config.Health.Timeout = 2 * time.Second
+	}
+	if config.Health.OutboxMaxLag == 0 {
+		config.Health.OutboxMaxLag = 5 * time.Minute
+	}
+	if config.Telemetry.Metrics.ServiceName == "" {
+		config.Telemetry.Metrics.ServiceName = "auth_service"
+	}
+	if config.Telemetry.Metrics.Port == 0 {
+		config.Telemetry.Metrics.Port = 9090
+	}
+	if config.Admin.Host == "" {
+		config.Admin.Host = "127.0.0.1"
+	}
+	if config.Admin.Port == 0 {
+		config.Admin.Port = 9091
+	}
+	if config.Telemetry.Metrics.Path == "" {
+		config.Telemetry.Metrics.Path = "/metrics"
+	}
+	if config.Telemetry.Tracing.Provider == "" {
+		config.Telemetry.Tracing.Provider = "otlp"
+	}
+	if config.Telemetry.Tracing.ServiceName == "" {
+		config.Telemetry.Tracing.ServiceName = "auth-service"
+	}
+	if config.Account.DeletionGracePeriod == 0 {
+		config.Account.DeletionGracePeriod = 30 * 24 * time.Hour
+	}
+	if config.Account.PurgeInterval == 0 {
+		config.Account.PurgeInterval = time.Hour
+	}
+	if config.Account.PurgeBatchSize == 0 {
+		config.Account.PurgeBatchSize = 100
+	}
+	if config.Account.LockoutThreshold == 0 {
+		config.Account.LockoutThreshold = 5
+	}
+	if config.Account.LockoutDuration == 0 {
+		config.Account.LockoutDuration = 15 * time.Minute
+	}
+	if config.Security.Password.MinLength == 0 {
+		config.Security.Password.MinLength = 12
+	}
+	if config.Security.Password.MaxLength == 0 {
+		// bcrypt rejects passwords over 72 bytes unless they are peppered
+		config.Security.Password.MaxLength = 72
+	}
+	if config.Security.Password.BreachThreshold == 0 {
+		config.Security.Password.BreachThreshold = 1
+	}
+	hashing := &config.Security.Hashing
+	if hashing.Algorithm == "" {
+		hashing.Algorithm = "argon2id"
+	}
+	if hashing.BcryptCost == 0 {
+		hashing.BcryptCost = 12
+	}
+	if hashing.Argon2.Memory == 0 {
+		hashing.Argon2.Memory = 64 * 1024
+	}
+	if hashing.Argon2.Iterations == 0 {
+		hashing.Argon2.Iterations = 3
+	}
+	if hashing.Argon2.Parallelism == 0 {
+		hashing.Argon2.Parallelism = 2
+	}
+	if hashing.Argon2.SaltLength == 0 {
+		hashing.Argon2.SaltLength = 16
+	}
+	if hashing.Argon2.KeyLength == 0 {
+		hashing.Argon2.KeyLength = 32
+	}
+	if config.Passwordless.CleanupInterval == 0 {
+		config.Passwordless.CleanupInterval = time.Hour
+	}
+	if config.Passwordless.MagicLink.TTL == 0 {
+		config.Passwordless.MagicLink.TTL = 15 * time.Minute
+	}
+	if config.Passwordless.MagicLink.Workers == 0 {
+		config.Passwordless.MagicLink.Workers = 4
+	}
+	if config.Passwordless.MagicLink.QueueSize == 0 {
+		config.Passwordless.MagicLink.QueueSize = 100
+	}
+	if config.Passwordless.WebAuthn.RPDisplayName == "" {
+		config.Passwordless.WebAuthn.RPDisplayName = "Auth Service"
+	}
+	if config.Passwordless.WebAuthn.Timeout == 0 {
+		config.Passwordless.WebAuthn.Timeout = 5 * time.Minute
+	}
+	if config.Mail.Sender == "" {
+		config.Mail.Sender = "smtp"
+	}
+	if config.Mail.Port == 0 {
+		config.Mail.Port = 587
+	}
+	if config.Events.Publisher == "" {
+		config.Events.Publisher = "kafka"
+	}
+	if config.Events.Topic == "" {
+		config.Events.Topic = "auth.user-events"
+	}
+	if config.Events.PublishTimeout == 0 {
+		config.Events.PublishTimeout = 10 * time.Second
+	}
+	if config.Events.RelayInterval == 0 {
+		config.Events.RelayInterval = time.Second
+	}
+	if config.Events.BatchSize == 0 {
+		config.Events.BatchSize = 100
+	}
+	if config.Events.MaxBackoff == 0 {
+		config.Events.MaxBackoff = time.Minute
+	}
+	if config.Events.Retention == 0 {
+		config.Events.Retention = 7 * 24 * time.Hour
+	}
+}
+
+// validateConfig reports every problem it finds rather than stopping at the
+// first, so a broken file can be fixed in one go.
 func validateConfig(config *Config) error {
+	var errs []error
 	if config.Server.Port == 0 {
-		return fmt.Errorf("server port is required")
+		errs = append(errs, fmt.Errorf("server port is required"))
 	}
 	if config.JWT.SecretKey == "" {
-		return fmt.Errorf("JWT secret key is required")
+		errs = append(errs, fmt.Errorf("JWT secret key is required"))
 	}
 	if config.Database.Host == "" || config.Database.DBName == "" {
-		return fmt.Errorf("database host and name are required")
+		errs = append(errs, fmt.Errorf("database host and name are required"))
+	}
+	errs = append(errs, validateTLS(config.Server.TLS), validateMiddleware(config), config.Secrets.Validate())
+	errs = append(errs, validateLogging(config.Logging))
+	password := config.Security.Password
+	if password.MinStrength < 0 || password.MinStrength > 4 {
+		errs = append(errs, fmt.Errorf("password min_strength must be between 0 and 4"))
+	}
+	if password.MaxLength < password.MinLength {
+		errs = append(errs, fmt.Errorf("password max_length must not be less than min_length"))
+	}
+	switch config.Security.Hashing.Algorithm {
+	case "argon2id", "bcrypt":
+	default:
+		errs = append(errs, fmt.Errorf("unknown password hashing algorithm %q", config.Security.Hashing.Algorithm))
+	}
+	if metrics := config.Telemetry.Metrics; metrics.Enabled && metrics.Port == config.Server.Port {
+		errs = append(errs, fmt.Errorf("metrics port must differ from the server port"))
+	}
+	if grpc := config.GRPC; grpc.Enabled && (grpc.Port == config.Server.Port || grpc.Port == config.Telemetry.Metrics.Port) {
+		errs = append(errs, fmt.Errorf("gRPC port must differ from the server and metrics ports"))
+	}
+	if admin := config.Admin; admin.Enabled {
+		if !isLoopback(admin.Host) {
+			errs = append(errs, fmt.Errorf("admin host must be a loopback address"))
+		}
+		if admin.Port == config.Server.Port || admin.Port == config.Telemetry.Metrics.Port || (config.GRPC.Enabled && admin.Port == config.GRPC.Port) {
+			errs = append(errs, fmt.Errorf("admin port must differ from the server, metrics and gRPC ports"))
+		}
+	}
+	if tracing := config.Telemetry.Tracing; tracing.Enabled {
+		switch tracing.Provider {
+		case "otlp", "otlp-http":
+		default:
+			errs = append(errs, fmt.Errorf("unknown tracing provider %q", tracing.Provider))
+		}
+		if tracing.SampleRate < 0 || tracing.SampleRate > 1 {
+			errs = append(errs, fmt.Errorf("tracing sample_rate must be between 0 and 1"))
+		}
+	}
+	if config.Passwordless.MagicLink.Enabled {
+		if config.Passwordless.MagicLink.URL == "" {
+			errs = append(errs, fmt.Errorf("passwordless magic_link url is required"))
+		}
+		if config.Passwordless.MagicLink.Workers < 0 || config.Passwordless.MagicLink.QueueSize < 0 {
+			errs = append(errs, fmt.Errorf("passwordless magic_link workers and queue_size must not be negative"))
+		}
+		switch config.Mail.Sender {
+		case "log":
+		case "smtp":
+			if config.Mail.Host == "" || config.Mail.From == "" {
+				errs = append(errs, fmt.Errorf("mail host and from are required for the smtp sender"))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("unknown mail sender %q", config.Mail.Sender))
+		}
+	}
+	if config.Passwordless.WebAuthn.Enabled {
+		if config.Passwordless.WebAuthn.RPID == "" || len(config.Passwordless.WebAuthn.RPOrigins) == 0 {
+			errs = append(errs, fmt.Errorf("webauthn rp_id and rp_origins are required"))
+		}
+	}
+	if config.ConfigReload.Interval < 0 {
+		errs = append(errs, fmt.Errorf("config_reload interval must be positive"))
+	}
+	switch config.Events.Publisher {
+	case "memory":
+	case "kafka":
+		if len(config.Events.Brokers) == 0 {
+			errs = append(errs, fmt.Errorf("events brokers are required for the kafka publisher"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown events publisher %q", config.Events.Publisher))
+	}
+	return errors.Join(errs...)
+}
+
+func validateLogging(logging LoggingConfig) error {
+	var errs []error
+	if !validLogLevel(logging.Level) {
+		errs = append(errs, fmt.Errorf("unknown logging level %q", logging.Level))
+	}
+	for _, override := range logging.PackageLevels {
+		if override.Package == "" || override.Level == "" || !validLogLevel(override.Level) {
+			errs = append(errs, fmt.Errorf("logging package_levels need a package and a known level"))
+		}
+	}
+	for _, sink := range logging.Sinks {
+		switch sink.Format {
+		case "", "json", "console":
+		default:
+			errs = append(errs, fmt.Errorf("unknown log sink format %q", sink.Format))
+		}
+		switch sink.Type {
+		case "stdout", "stderr":
+		case "file":
+			if sink.Path == "" {
+				errs = append(errs, fmt.Errorf("file log sink needs a path"))
+			}
+		case "syslog":
+			if sink.Address == "" {
+				errs = append(errs, fmt.Errorf("syslog log sink needs an address"))
+			}
+			switch sink.Network {
+			case "", "udp", "tcp":
+			default:
+				errs = append(errs, fmt.Errorf("syslog network must be udp or tcp"))
+			}
+		case "otlp":
+			if sink.Endpoint == "" {
+				errs = append(errs, fmt.Errorf("otlp log sink needs an endpoint"))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("unknown log sink %q", sink.Type))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func validLogLevel(level string) bool {
+	switch level {
+	case "", "debug", "info", "warn", "error":
+		return true
+	default:
+		return false
+	}
+}
+
+func validateMiddleware(config *Config) error {
+	var errs []error
+	known := make(map[string]bool, len(DefaultMiddlewareOrder))
+	for _, name := range DefaultMiddlewareOrder {
+		known[name] = true
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range config.Server.Middleware.Order {
+		if !known[name] {
+			errs = append(errs, fmt.Errorf("unknown middleware %q", name))
+		}
+		if seen[name] {
+			errs = append(errs, fmt.Errorf("middleware %q is listed twice", name))
+		}
+		seen[name] = true
+	}
+
+	for _, route := range config.Server.Middleware.Routes {
+		if route.Path == "" {
+			errs = append(errs, fmt.Errorf("middleware route path is required"))
+		}
+		for _, name := range route.Skip {
+			if !known[name] {
+				errs = append(errs, fmt.Errorf("unknown middleware %q skipped on route %s", name, route.Path))
+			}
+		}
+		if limit := route.RateLimit; limit.RequestsPerMinute < 0 || limit.BurstSize < 0 ||
+			(limit.RequestsPerMinute > 0) != (limit.BurstSize > 0) {
+			errs = append(errs, fmt.Errorf("route %s rate limit needs both requests_per_minute and burst_size", route.Path))
+		}
+	}
+
+	if accessLog := config.Server.Middleware.AccessLog; accessLog.SampleFirst < 0 || accessLog.SampleThereafter < 0 {
+		errs = append(errs, fmt.Errorf("access_log sample_first and sample_thereafter must not be negative"))
+	}
+
+	if limit := config.Security.RateLimit; limit.Enabled && (limit.RequestsPerMinute <= 0 || limit.BurstSize <= 0) {
+		errs = append(errs, fmt.Errorf("rate limit requests_per_minute and burst_size must be positive"))
+	}
+
+	headers := config.Security.Headers
+	if headers.AllowCredentials {
+		for _, origin := range headers.AllowedOrigins {
+			if origin == "*" {
+				errs = append(errs, fmt.Errorf("CORS allow_credentials cannot be combined with the \"*\" origin"))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateTLS(tls TLSConfig) error {
+	if !tls.Enabled {
+		return nil
+	}
+	var errs []error
+	if tls.CertFile == "" || tls.KeyFile == "" {
+		errs = append(errs, fmt.Errorf("TLS cert_file and key_file are required"))
+	}
+	switch tls.MinVersion {
+	case "", "1.2", "1.3":
+	default:
+		errs = append(errs, fmt.Errorf("TLS min_version must be 1.2 or 1.3"))
+	}
+	switch tls.ClientAuth {
+	case "", "none":
+	case "verify_if_given", "require":
+		if tls.ClientCAFile == "" {
+			errs = append(errs, fmt.Errorf("TLS client_auth %q needs client_ca_file", tls.ClientAuth))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown TLS client_auth %q", tls.ClientAuth))
+	}
+	for _, principal := range tls.Principals {
+		if principal.Identity == "" || principal.Principal == "" {
+			errs = append(errs, fmt.Errorf("TLS principals need an identity and a principal"))
+		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
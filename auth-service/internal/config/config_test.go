@@ -0,0 +1,68 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReloadKeepsResolvedSecrets(t *testing.T) {
+	current := &Config{}
+	current.JWT.SecretKey = "from-provider"
+	current.Database.Password = "db-from-provider"
+	current.Logging.Level = "info"
+
+	next := &Config{}
+	next.JWT.SecretKey = "from-file"
+	next.Logging.Level = "debug"
+
+	applied, restart := Reload(current, next)
+	if len(restart) != 0 {
+		t.Errorf("restart = %v, want none for secret-only differences", restart)
+	}
+	if applied.JWT.SecretKey != "from-provider" || applied.Database.Password != "db-from-provider" {
+		t.Errorf("secrets = %q, %q, want the provider values", applied.JWT.SecretKey, applied.Database.Password)
+	}
+	if applied.Logging.Level != "debug" {
+		t.Errorf("logging level = %q, want debug", applied.Logging.Level)
+	}
+	if current.JWT.SecretKey != "from-provider" || next.JWT.SecretKey != "from-file" {
+		t.Error("Reload modified its arguments")
+	}
+}
+
+func TestReloadReportsRestartSections(t *testing.T) {
+	current := &Config{}
+	next := &Config{}
+	next.Server.Port = 9000
+	next.Security.RateLimit.RequestsPerMinute = 100
+	next.Security.RateLimit.Enabled = true
+
+	applied, restart := Reload(current, next)
+	if want := []string{"server", "security"}; !reflect.DeepEqual(restart, want) {
+		t.Errorf("restart = %v, want %v", restart, want)
+	}
+	if applied.Server.Port != 0 || applied.Security.RateLimit.Enabled {
+		t.Error("settings that need a restart were applied")
+	}
+	if applied.Security.RateLimit.RequestsPerMinute != 100 {
+		t.Errorf("requests per minute = %d, want 100", applied.Security.RateLimit.RequestsPerMinute)
+	}
+}
+
+func TestValidateAdminHost(t *testing.T) {
+	for host, ok := range map[string]bool{
+		"127.0.0.1": true,
+		"::1":       true,
+		"localhost": true,
+		"0.0.0.0":   false,
+		"10.0.0.5":  false,
+	} {
+		config := &Config{}
+		config.Admin = AdminConfig{Enabled: true, Host: host, Port: 9091}
+		err := validateConfig(config)
+		if got := err == nil || !strings.Contains(err.Error(), "admin host"); got != ok {
+			t.Errorf("admin host %q accepted = %v, want %v", host, got, ok)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"context"
+
+	"http_server/shared/secrets"
+)
+
+// Names of the secrets read through the secrets provider.
+const (
+	SecretJWTKey           = "jwt_secret_key"
+	SecretJWTRefreshKey    = "jwt_refresh_token_secret"
+	SecretDatabasePassword = "db_password"
+	SecretMailPassword     = "smtp_password"
+	SecretLogPIIKey        = "log_pii_key"
+)
+
+// SecretsConfig selects where secrets are read from. The JWT signing key is
+// looked up again every refresh interval, so rotating it needs no restart.
+type SecretsConfig = secrets.Config
+
+// secretFields lists each secret with the setting it overrides.
+func secretFields(config *Config) []secrets.Field {
+	return []secrets.Field{
+		{Name: SecretJWTKey, Value: &config.JWT.SecretKey},
+		{Name: SecretJWTRefreshKey, Value: &config.JWT.RefreshTokenSecret},
+		{Name: SecretDatabasePassword, Value: &config.Database.Password},
+		{Name: SecretMailPassword, Value: &config.Mail.Password},
+		{Name: SecretLogPIIKey, Value: &config.Logging.PIIKey},
+	}
+}
+
+// ResolveSecrets replaces the secret settings with the provider's values.
+func ResolveSecrets(ctx context.Context, config *Config, provider secrets.SecretProvider) error {
+	return secrets.Resolve(ctx, provider, secretFields(config))
+}
+
+// CheckSecrets rejects secrets left at a known placeholder value.
+func CheckSecrets(config *Config) error {
+	return secrets.CheckDefaults(secretFields(config))
+}
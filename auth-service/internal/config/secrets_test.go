@@ -0,0 +1,77 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"http_server/shared/secrets"
+)
+
+// mapProvider serves secrets from a map, failing for names in errs.
+type mapProvider struct {
+	values map[string]string
+	errs   map[string]error
+}
+
+func (p mapProvider) Get(ctx context.Context, name string) (string, error) {
+	if err, ok := p.errs[name]; ok {
+		return "", err
+	}
+	value, ok := p.values[name]
+	if !ok {
+		return "", secrets.ErrNotFound
+	}
+	return value, nil
+}
+
+func TestResolveSecrets(t *testing.T) {
+	config := &Config{}
+	config.JWT.SecretKey = "from-file"
+	config.Database.Password = "db-from-file"
+
+	provider := mapProvider{
+		values: map[string]string{SecretJWTKey: "from-provider"},
+		errs:   map[string]error{SecretMailPassword: errors.New("vault unavailable")},
+	}
+	err := ResolveSecrets(context.Background(), config, provider)
+	if err == nil || !strings.Contains(err.Error(), SecretMailPassword) {
+		t.Errorf("ResolveSecrets = %v, want the mail password failure", err)
+	}
+	if config.JWT.SecretKey != "from-provider" {
+		t.Errorf("JWT key = %q, want the provider value", config.JWT.SecretKey)
+	}
+	if config.Database.Password != "db-from-file" {
+		t.Errorf("database password = %q, want the file value kept", config.Database.Password)
+	}
+}
+
+func TestCheckSecretsRejectsDefaults(t *testing.T) {
+	config := &Config{}
+	config.JWT.SecretKey = "your-secret-key"
+	config.JWT.RefreshTokenSecret = "a-real-refresh-secret"
+	config.Database.Password = "postgres"
+	config.Mail.Password = "a-real-mail-password"
+
+	err := CheckSecrets(config)
+	if err == nil {
+		t.Fatal("CheckSecrets accepted placeholder secrets")
+	}
+	for _, name := range []string{SecretJWTKey, SecretDatabasePassword} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("CheckSecrets = %v, missing %s", err, name)
+		}
+	}
+	for _, name := range []string{SecretJWTRefreshKey, SecretMailPassword} {
+		if strings.Contains(err.Error(), name) {
+			t.Errorf("CheckSecrets = %v, real secret %s reported", err, name)
+		}
+	}
+
+	config.JWT.SecretKey = "a-real-signing-key"
+	config.Database.Password = "a-real-db-password"
+	if err := CheckSecrets(config); err != nil {
+		t.Errorf("CheckSecrets = %v, want nil for real secrets", err)
+	}
+}
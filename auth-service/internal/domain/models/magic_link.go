@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MagicLink is a single-use passwordless login token sent by email. Only
+// hashes are stored: TokenHash of the token in the link and DeviceHash of
+// the nonce given to the browser that asked for it, so the link only works
+// on that device.
+type MagicLink struct {
+	ID         uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	TokenHash  []byte     `json:"-" gorm:"not null;uniqueIndex"`
+	DeviceHash []byte     `json:"-" gorm:"not null"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null;index"`
+	UsedAt     *time.Time `json:"used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
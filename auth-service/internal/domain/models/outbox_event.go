@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is a domain event waiting to be relayed to the message broker.
+// It is written in the same transaction as the change it describes. ID doubles
+// as the idempotency key consumers use to discard redeliveries. TraceParent
+// and TraceState hold the W3C trace context of the request that caused the
+// event, so consumers can continue its trace.
+type OutboxEvent struct {
+	ID            uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid"`
+	Sequence      int64      `json:"sequence" gorm:"autoIncrement;uniqueIndex"`
+	AggregateType string     `json:"aggregate_type" gorm:"size:50;not null"`
+	AggregateID   uuid.UUID  `json:"aggregate_id" gorm:"type:uuid;not null;index"`
+	EventType     string     `json:"event_type" gorm:"size:100;not null"`
+	Payload       []byte     `json:"payload" gorm:"type:jsonb;not null"`
+	OccurredAt    time.Time  `json:"occurred_at" gorm:"not null"`
+	TraceParent   string     `json:"trace_parent,omitempty" gorm:"size:55"`
+	TraceState    string     `json:"trace_state,omitempty" gorm:"size:512"`
+	Attempts      int        `json:"attempts" gorm:"default:0"`
+	LastError     string     `json:"last_error,omitempty"`
+	PublishedAt   *time.Time `json:"published_at,omitempty" gorm:"index"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
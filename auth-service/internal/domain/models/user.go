@@ -30,10 +30,16 @@ type User struct {
 	StatusChangedAt    time.Time  `json:"status_changed_at,omitempty"`
 	DeactivatedAt      *time.Time `json:"deactivated_at,omitempty"`
 	DeactivationReason string     `json:"deactivation_reason,omitempty"`
+	// PurgeAfter is set when the user asks for deletion; the account is
+	// removed for good once it has passed unless it is reactivated first.
+	PurgeAfter *time.Time `json:"purge_after,omitempty" gorm:"index"`
 
 	// Security and verification
 	TwoFactorEnabled    bool       `json:"two_factor_enabled" gorm:"default:false"`
 	VerifiedAt          *time.Time `json:"verified_at,omitempty"`
 	FailedLoginAttempts int        `json:"failed_login_attempts,omitempty" gorm:"default:0"`
 	LockedUntil         *time.Time `json:"locked_until,omitempty"`
+	// SessionVersion is carried by every access token issued to the user.
+	// Raising it revokes all of them.
+	SessionVersion int `json:"-" gorm:"not null;default:0"`
 }
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebAuthnCredential is a passkey registered by a user. SignCount is the
+// last authenticator signature counter seen; a counter that does not
+// increase suggests a cloned authenticator.
+type WebAuthnCredential struct {
+	ID              uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid"`
+	UserID          uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	CredentialID    []byte     `json:"-" gorm:"not null;uniqueIndex"`
+	PublicKey       []byte     `json:"-" gorm:"not null"`
+	AttestationType string     `json:"attestation_type"`
+	AAGUID          []byte     `json:"-"`
+	SignCount       uint32     `json:"sign_count" gorm:"type:bigint;not null;default:0"`
+	Transports      string     `json:"transports,omitempty"`
+	BackupEligible  bool       `json:"backup_eligible"`
+	BackupState     bool       `json:"backup_state"`
+	Name            string     `json:"name" gorm:"size:100"`
+	CreatedAt       time.Time  `json:"created_at"`
+	LastUsedAt      *time.Time `json:"last_used_at,omitempty"`
+}
+
+// WebAuthnSession holds the challenge of a registration or login ceremony
+// between its begin and finish steps. UserID is nil for discoverable logins.
+type WebAuthnSession struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid"`
+	UserID    *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid"`
+	Ceremony  string     `json:"ceremony" gorm:"size:20;not null"`
+	Data      []byte     `json:"-" gorm:"type:jsonb;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time  `json:"created_at"`
+}
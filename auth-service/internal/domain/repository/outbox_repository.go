@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"http_server/auth-service/internal/domain/models"
+)
+
+type OutboxRepository interface {
+	Add(ctx context.Context, events ...*models.OutboxEvent) error
+
+	// ClaimPending returns the oldest unpublished events in the order they
+	// were recorded. It must run inside Transactor.WithinTransaction: it takes
+	// a transaction-scoped lock so only one relay publishes at a time, and
+	// returns nothing if another relay holds it.
+	ClaimPending(ctx context.Context, limit int) ([]models.OutboxEvent, error)
+	MarkPublished(ctx context.Context, ids []uuid.UUID, at time.Time) error
+	// MarkFailed records a failed delivery attempt for the given events.
+	MarkFailed(ctx context.Context, ids []uuid.UUID, reason string) error
+	// DeletePublished removes events published before the given time and
+	// returns how many were removed.
+	DeletePublished(ctx context.Context, before time.Time) (int64, error)
+	// OldestPending returns when the oldest unpublished event occurred, or
+	// nil if every event has been published.
+	OldestPending(ctx context.Context) (*time.Time, error)
+}
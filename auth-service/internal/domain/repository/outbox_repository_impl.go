@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"http_server/auth-service/internal/domain/models"
+)
+
+// outboxLockKey identifies the advisory lock held by the active relay.
+const outboxLockKey = 0x6f7574626f78
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Add(ctx context.Context, events ...*models.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return conn(ctx, r.db).Create(events).Error
+}
+
+func (r *outboxRepository) ClaimPending(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	db := conn(ctx, r.db)
+
+	var locked bool
+	if err := db.Raw("SELECT pg_try_advisory_xact_lock(?)", outboxLockKey).Scan(&locked).Error; err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, nil
+	}
+
+	var events []models.OutboxEvent
+	err := db.Where("published_at IS NULL").
+		Order("sequence").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+func (r *outboxRepository) MarkPublished(ctx context.Context, ids []uuid.UUID, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return conn(ctx, r.db).Model(&models.OutboxEvent{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"published_at": at,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"last_error":   "",
+		}).Error
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, ids []uuid.UUID, reason string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return conn(ctx, r.db).Model(&models.OutboxEvent{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": reason,
+		}).Error
+}
+
+func (r *outboxRepository) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("published_at IS NOT NULL AND published_at < ?", before).
+		Delete(&models.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *outboxRepository) OldestPending(ctx context.Context) (*time.Time, error) {
+	var oldest *time.Time
+	err := conn(ctx, r.db).Model(&models.OutboxEvent{}).
+		Where("published_at IS NULL").
+		Select("MIN(occurred_at)").
+		Scan(&oldest).Error
+	return oldest, err
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"http_server/auth-service/internal/domain/models"
+)
+
+type MagicLinkRepository interface {
+	Create(ctx context.Context, link *models.MagicLink) error
+	// DeletePending removes the user's unused links, so only the latest one
+	// works.
+	DeletePending(ctx context.Context, userID uuid.UUID) error
+	// Consume marks the link with the given token hash used and returns it.
+	// It returns ErrNotFound if there is no such link or it was already used,
+	// so a link can be consumed only once even under concurrent requests.
+	Consume(ctx context.Context, tokenHash []byte, at time.Time) (*models.MagicLink, error)
+	// DeleteExpired removes links that expired before the given time.
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type WebAuthnRepository interface {
+	CreateCredential(ctx context.Context, credential *models.WebAuthnCredential) error
+	ListCredentials(ctx context.Context, userID uuid.UUID) ([]models.WebAuthnCredential, error)
+	FindCredential(ctx context.Context, credentialID []byte) (*models.WebAuthnCredential, error)
+	// UpdateCredentialUsage stores the new sign count and backup state. It
+	// only applies if the stored count is still previousCount, and returns
+	// ErrNotFound otherwise, so two assertions cannot both advance it.
+	UpdateCredentialUsage(ctx context.Context, id uuid.UUID, previousCount, signCount uint32, backupState bool, at time.Time) error
+	DeleteCredential(ctx context.Context, userID, id uuid.UUID) error
+
+	CreateSession(ctx context.Context, session *models.WebAuthnSession) error
+	// TakeSession deletes and returns the session for the given ceremony,
+	// so each challenge can be answered once.
+	TakeSession(ctx context.Context, id uuid.UUID, ceremony string) (*models.WebAuthnSession, error)
+	DeleteExpiredSessions(ctx context.Context, before time.Time) (int64, error)
+}
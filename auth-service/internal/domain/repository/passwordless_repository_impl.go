@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"http_server/auth-service/internal/domain/models"
+)
+
+type magicLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewMagicLinkRepository(db *gorm.DB) MagicLinkRepository {
+	return &magicLinkRepository{db: db}
+}
+
+func (r *magicLinkRepository) Create(ctx context.Context, link *models.MagicLink) error {
+	return conn(ctx, r.db).Create(link).Error
+}
+
+func (r *magicLinkRepository) DeletePending(ctx context.Context, userID uuid.UUID) error {
+	return conn(ctx, r.db).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Delete(&models.MagicLink{}).Error
+}
+
+func (r *magicLinkRepository) Consume(ctx context.Context, tokenHash []byte, at time.Time) (*models.MagicLink, error) {
+	var links []models.MagicLink
+	result := conn(ctx, r.db).Model(&links).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND used_at IS NULL", tokenHash).
+		Update("used_at", at)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(links) == 0 {
+		return nil, ErrNotFound
+	}
+	return &links[0], nil
+}
+
+func (r *magicLinkRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("expires_at < ?", before).
+		Delete(&models.MagicLink{})
+	return result.RowsAffected, result.Error
+}
+
+type webAuthnRepository struct {
+	db *gorm.DB
+}
+
+func NewWebAuthnRepository(db *gorm.DB) WebAuthnRepository {
+	return &webAuthnRepository{db: db}
+}
+
+func (r *webAuthnRepository) CreateCredential(ctx context.Context, credential *models.WebAuthnCredential) error {
+	err := conn(ctx, r.db).Create(credential).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicateKey
+	}
+	return err
+}
+
+func (r *webAuthnRepository) ListCredentials(ctx context.Context, userID uuid.UUID) ([]models.WebAuthnCredential, error) {
+	var credentials []models.WebAuthnCredential
+	err := conn(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(&credentials).Error
+	return credentials, err
+}
+
+func (r *webAuthnRepository) FindCredential(ctx context.Context, credentialID []byte) (*models.WebAuthnCredential, error) {
+	var credential models.WebAuthnCredential
+	err := conn(ctx, r.db).Where("credential_id = ?", credentialID).First(&credential).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (r *webAuthnRepository) UpdateCredentialUsage(ctx context.Context, id uuid.UUID, previousCount, signCount uint32, backupState bool, at time.Time) error {
+	result := conn(ctx, r.db).Model(&models.WebAuthnCredential{}).
+		Where("id = ? AND sign_count = ?", id, previousCount).
+		Updates(map[string]interface{}{
+			"sign_count":   signCount,
+			"backup_state": backupState,
+			"last_used_at": at,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *webAuthnRepository) DeleteCredential(ctx context.Context, userID, id uuid.UUID) error {
+	result := conn(ctx, r.db).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&models.WebAuthnCredential{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *webAuthnRepository) CreateSession(ctx context.Context, session *models.WebAuthnSession) error {
+	return conn(ctx, r.db).Create(session).Error
+}
+
+func (r *webAuthnRepository) TakeSession(ctx context.Context, id uuid.UUID, ceremony string) (*models.WebAuthnSession, error) {
+	var sessions []models.WebAuthnSession
+	result := conn(ctx, r.db).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND ceremony = ?", id, ceremony).
+		Delete(&sessions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(sessions) == 0 {
+		return nil, ErrNotFound
+	}
+	return &sessions[0], nil
+}
+
+func (r *webAuthnRepository) DeleteExpiredSessions(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("expires_at < ?", before).
+		Delete(&models.WebAuthnSession{})
+	return result.RowsAffected, result.Error
+}
@@ -19,7 +19,7 @@ func NewRoleRepository(db *gorm.DB) RoleRepository {
 }
 
 func (r *roleRepository) Create(ctx context.Context, role *models.Role) error {
-	result := r.db.WithContext(ctx).Create(role)
+	result := conn(ctx, r.db).Create(role)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
 			return ErrDuplicateKey
@@ -31,7 +31,7 @@ func (r *roleRepository) Create(ctx context.Context, role *models.Role) error {
 
 func (r *roleRepository) FindByName(ctx context.Context, name string) (*models.Role, error) {
 	var role models.Role
-	result := r.db.WithContext(ctx).Where("name = ?", name).First(&role)
+	result := conn(ctx, r.db).Where("name = ?", name).First(&role)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, ErrNotFound
@@ -43,7 +43,7 @@ func (r *roleRepository) FindByName(ctx context.Context, name string) (*models.R
 
 func (r *roleRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Role, error) {
 	var role models.Role
-	result := r.db.WithContext(ctx).First(&role, "id = ?", id)
+	result := conn(ctx, r.db).First(&role, "id = ?", id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, ErrNotFound
@@ -54,7 +54,7 @@ func (r *roleRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Ro
 }
 
 func (r *roleRepository) AssignRoleToUser(ctx context.Context, userRole *models.UserRole) error {
-	result := r.db.WithContext(ctx).Create(userRole)
+	result := conn(ctx, r.db).Create(userRole)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
 			return ErrDuplicateKey
@@ -66,7 +66,7 @@ func (r *roleRepository) AssignRoleToUser(ctx context.Context, userRole *models.
 
 func (r *roleRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]models.Role, error) {
 	var roles []models.Role
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Joins("JOIN user_roles ON roles.id = user_roles.role_id").
 		Where("user_roles.user_id = ?", userID).
 		Find(&roles)
@@ -77,17 +77,20 @@ func (r *roleRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]
 }
 
 func (r *roleRepository) RemoveRoleFromUser(ctx context.Context, userID, roleID uuid.UUID) error {
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("user_id = ? AND role_id = ?", userID, roleID).
 		Delete(&models.UserRole{})
 	if result.Error != nil {
 		return result.Error
 	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
 	return nil
 }
 
 func (r *roleRepository) AddParentRole(ctx context.Context, roleID, parentRoleID uuid.UUID) error {
-	result := r.db.WithContext(ctx).Create(&models.RoleHierarchy{
+	result := conn(ctx, r.db).Create(&models.RoleHierarchy{
 		RoleID:       roleID,
 		ParentRoleID: parentRoleID,
 	})
@@ -101,7 +104,7 @@ func (r *roleRepository) AddParentRole(ctx context.Context, roleID, parentRoleID
 }
 
 func (r *roleRepository) RemoveParentRole(ctx context.Context, roleID, parentRoleID uuid.UUID) error {
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("role_id = ? AND parent_role_id = ?", roleID, parentRoleID).
 		Delete(&models.RoleHierarchy{})
 	if result.Error != nil {
@@ -112,7 +115,7 @@ func (r *roleRepository) RemoveParentRole(ctx context.Context, roleID, parentRol
 
 func (r *roleRepository) GetParentRoles(ctx context.Context, roleID uuid.UUID) ([]models.Role, error) {
 	var roles []models.Role
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Joins("JOIN role_hierarchies ON roles.id = role_hierarchies.parent_role_id").
 		Where("role_hierarchies.role_id = ?", roleID).
 		Find(&roles)
@@ -124,7 +127,7 @@ func (r *roleRepository) GetParentRoles(ctx context.Context, roleID uuid.UUID) (
 
 func (r *roleRepository) GetChildRoles(ctx context.Context, roleID uuid.UUID) ([]models.Role, error) {
 	var roles []models.Role
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Joins("JOIN role_hierarchies ON roles.id = role_hierarchies.role_id").
 		Where("role_hierarchies.parent_role_id = ?", roleID).
 		Find(&roles)
@@ -139,42 +142,34 @@ func (r *roleRepository) BatchAssignRolesToUser(ctx context.Context, userID uuid
 		return nil
 	}
 
-	tx := r.db.WithContext(ctx).Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	userRoles := make([]models.UserRole, len(roleIDs))
-	for i, roleID := range roleIDs {
-		// Verify role exists
-		var exists bool
-		if err := tx.Model(&models.Role{}).Select("count(*) > 0").Where("id = ?", roleID).Scan(&exists).Error; err != nil {
-			tx.Rollback()
-			return err
-		}
-		if !exists {
-			tx.Rollback()
-			return ErrNotFound
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		userRoles := make([]models.UserRole, len(roleIDs))
+		for i, roleID := range roleIDs {
+			// Verify role exists
+			var exists bool
+			if err := tx.Model(&models.Role{}).Select("count(*) > 0").Where("id = ?", roleID).Scan(&exists).Error; err != nil {
+				return err
+			}
+			if !exists {
+				return ErrNotFound
+			}
+
+			userRoles[i] = models.UserRole{
+				ID:     uuid.New(),
+				UserID: userID,
+				RoleID: roleID,
+			}
 		}
 
-		userRoles[i] = models.UserRole{
-			UserID: userID,
-			RoleID: roleID,
+		result := tx.Create(&userRoles)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+				return ErrDuplicateKey
+			}
+			return result.Error
 		}
-	}
-
-	result := tx.Create(&userRoles)
-	if result.Error != nil {
-		tx.Rollback()
-		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
-			return ErrDuplicateKey
-		}
-		return result.Error
-	}
-
-	return tx.Commit().Error
+		return nil
+	})
 }
 
 func (r *roleRepository) BatchRemoveRolesFromUser(ctx context.Context, userID uuid.UUID, roleIDs []uuid.UUID) error {
@@ -182,35 +177,22 @@ func (r *roleRepository) BatchRemoveRolesFromUser(ctx context.Context, userID uu
 		return nil
 	}
 
-	tx := r.db.WithContext(ctx).Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Verify user exists
+		var exists bool
+		if err := tx.Model(&models.User{}).Select("count(*) > 0").Where("id = ?", userID).Scan(&exists).Error; err != nil {
+			return err
+		}
+		if !exists {
+			return ErrNotFound
 		}
-	}()
-
-	// Verify user exists
-	var exists bool
-	if err := tx.Model(&models.User{}).Select("count(*) > 0").Where("id = ?", userID).Scan(&exists).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-	if !exists {
-		tx.Rollback()
-		return ErrNotFound
-	}
-
-	result := tx.Where("user_id = ? AND role_id IN ?", userID, roleIDs).Delete(&models.UserRole{})
-	if result.Error != nil {
-		tx.Rollback()
-		return result.Error
-	}
 
-	return tx.Commit().Error
+		return tx.Where("user_id = ? AND role_id IN ?", userID, roleIDs).Delete(&models.UserRole{}).Error
+	})
 }
 
 func (r *roleRepository) AddPermissionToRole(ctx context.Context, roleID uuid.UUID, permission string) error {
-	result := r.db.WithContext(ctx).Create(&models.RolePermission{
+	result := conn(ctx, r.db).Create(&models.RolePermission{
 		RoleID:     roleID,
 		Permission: permission,
 	})
@@ -224,7 +206,7 @@ func (r *roleRepository) AddPermissionToRole(ctx context.Context, roleID uuid.UU
 }
 
 func (r *roleRepository) RemovePermissionFromRole(ctx context.Context, roleID uuid.UUID, permission string) error {
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("role_id = ? AND permission = ?", roleID, permission).
 		Delete(&models.RolePermission{})
 	if result.Error != nil {
@@ -235,7 +217,7 @@ func (r *roleRepository) RemovePermissionFromRole(ctx context.Context, roleID uu
 
 func (r *roleRepository) GetRolePermissions(ctx context.Context, roleID uuid.UUID) ([]string, error) {
 	var permissions []string
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Model(&models.RolePermission{}).
 		Where("role_id = ?", roleID).
 		Pluck("permission", &permissions)
@@ -247,7 +229,7 @@ func (r *roleRepository) GetRolePermissions(ctx context.Context, roleID uuid.UUI
 
 func (r *roleRepository) HasPermission(ctx context.Context, roleID uuid.UUID, permission string) (bool, error) {
 	var count int64
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Model(&models.RolePermission{}).
 		Where("role_id = ? AND permission = ?", roleID, permission).
 		Count(&count)
@@ -2,13 +2,35 @@ package repository
 
 import (
 	"context"
+	"time"
 
-	"http_server/auth-service/internal/domain/models"
 	"github.com/google/uuid"
+	"http_server/auth-service/internal/domain/models"
 )
 
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	FindByEmail(ctx context.Context, email string) (*models.User, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*models.User, error)
-}
\ No newline at end of file
+	Save(ctx context.Context, user *models.User) error
+
+	// RecordFailedLogin increments the user's failed login counter and returns
+	// the new value.
+	RecordFailedLogin(ctx context.Context, id uuid.UUID) (int, error)
+	// Lock blocks logins until the given time and resets the failure counter.
+	Lock(ctx context.Context, id uuid.UUID, until time.Time) error
+	// ResetFailedLogins clears the failure counter and any expired lock.
+	ResetFailedLogins(ctx context.Context, id uuid.UUID) error
+	// ReplacePasswordHash swaps the stored hash for newHash only if it still
+	// equals oldHash, so a concurrent password change is never overwritten.
+	// It returns ErrNotFound when the hash no longer matches.
+	ReplacePasswordHash(ctx context.Context, id uuid.UUID, oldHash, newHash string) error
+
+	// ListPurgeable returns users whose deletion grace period ended before
+	// the given time.
+	ListPurgeable(ctx context.Context, before time.Time, limit int) ([]models.User, error)
+	// Purge permanently deletes a user scheduled for deletion together with
+	// their role assignments. It returns ErrNotFound if the user no longer
+	// exists or is no longer due, e.g. because it was reactivated.
+	Purge(ctx context.Context, id uuid.UUID, before time.Time) error
+}
@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
-	"http_server/auth-service/internal/domain/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"http_server/auth-service/internal/domain/models"
 )
 
 type userRepository struct {
@@ -19,13 +21,20 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 }
 
 func (r *userRepository) Create(ctx context.Context, user *models.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	err := conn(ctx, r.db).Create(user).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicateKey
+	}
+	return err
 }
 
 func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	err := conn(ctx, r.db).Where("email = ?", email).First(&user).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
 		return nil, err
 	}
 	return &user, nil
@@ -33,9 +42,90 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models
 
 func (r *userRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	var user models.User
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error
+	err := conn(ctx, r.db).Where("id = ?", id).First(&user).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
 		return nil, err
 	}
 	return &user, nil
 }
+
+func (r *userRepository) Save(ctx context.Context, user *models.User) error {
+	return conn(ctx, r.db).Save(user).Error
+}
+
+func (r *userRepository) RecordFailedLogin(ctx context.Context, id uuid.UUID) (int, error) {
+	var attempts int
+	result := conn(ctx, r.db).
+		Raw("UPDATE users SET failed_login_attempts = failed_login_attempts + 1 WHERE id = ? RETURNING failed_login_attempts", id).
+		Scan(&attempts)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, ErrNotFound
+	}
+	return attempts, nil
+}
+
+func (r *userRepository) Lock(ctx context.Context, id uuid.UUID, until time.Time) error {
+	return conn(ctx, r.db).Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"locked_until":          until,
+			"failed_login_attempts": 0,
+		}).Error
+}
+
+func (r *userRepository) ResetFailedLogins(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"locked_until":          nil,
+			"failed_login_attempts": 0,
+		}).Error
+}
+
+func (r *userRepository) ReplacePasswordHash(ctx context.Context, id uuid.UUID, oldHash, newHash string) error {
+	result := conn(ctx, r.db).Model(&models.User{}).
+		Where("id = ? AND password = ?", id, oldHash).
+		Update("password", newHash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) ListPurgeable(ctx context.Context, before time.Time, limit int) ([]models.User, error) {
+	var users []models.User
+	err := conn(ctx, r.db).
+		Where("purge_after IS NOT NULL AND purge_after <= ?", before).
+		Order("purge_after").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) Purge(ctx context.Context, id uuid.UUID, before time.Time) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND purge_after IS NOT NULL AND purge_after <= ?", id, before).
+			Delete(&models.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		for _, owned := range []interface{}{&models.UserRole{}, &models.MagicLink{}, &models.WebAuthnCredential{}, &models.WebAuthnSession{}} {
+			if err := tx.Where("user_id = ?", id).Delete(owned).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
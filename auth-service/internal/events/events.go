@@ -0,0 +1,132 @@
+// Package events defines the domain events auth-service emits and relays them
+// from the transactional outbox to the message broker.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"http_server/auth-service/internal/domain/models"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const aggregateUser = "user"
+
+const (
+	TypeUserRegistered        = "user.registered"
+	TypeUserLocked            = "user.locked"
+	TypeUserPasswordChanged   = "user.password_changed"
+	TypeUserDeactivated       = "user.deactivated"
+	TypeUserReactivated       = "user.reactivated"
+	TypeUserDeletionRequested = "user.deletion_requested"
+	TypeUserDeleted           = "user.deleted"
+	TypeRoleAssigned          = "user.role_assigned"
+	TypeRoleRemoved           = "user.role_removed"
+)
+
+// Event is a change to a user that other services may react to.
+type Event interface {
+	EventType() string
+	UserID() uuid.UUID
+}
+
+type UserRegistered struct {
+	ID    uuid.UUID `json:"user_id"`
+	Email string    `json:"email"`
+	Name  string    `json:"name"`
+	Roles []string  `json:"roles"`
+}
+
+type UserLocked struct {
+	ID          uuid.UUID `json:"user_id"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+type UserPasswordChanged struct {
+	ID uuid.UUID `json:"user_id"`
+}
+
+type UserDeactivated struct {
+	ID     uuid.UUID `json:"user_id"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+type UserReactivated struct {
+	ID uuid.UUID `json:"user_id"`
+}
+
+type UserDeletionRequested struct {
+	ID         uuid.UUID `json:"user_id"`
+	PurgeAfter time.Time `json:"purge_after"`
+}
+
+// UserDeleted is emitted when an account is permanently purged.
+type UserDeleted struct {
+	ID uuid.UUID `json:"user_id"`
+}
+
+type RoleAssigned struct {
+	ID   uuid.UUID `json:"user_id"`
+	Role string    `json:"role"`
+}
+
+type RoleRemoved struct {
+	ID   uuid.UUID `json:"user_id"`
+	Role string    `json:"role"`
+}
+
+func (UserRegistered) EventType() string        { return TypeUserRegistered }
+func (UserLocked) EventType() string            { return TypeUserLocked }
+func (UserPasswordChanged) EventType() string   { return TypeUserPasswordChanged }
+func (UserDeactivated) EventType() string       { return TypeUserDeactivated }
+func (UserReactivated) EventType() string       { return TypeUserReactivated }
+func (UserDeletionRequested) EventType() string { return TypeUserDeletionRequested }
+func (UserDeleted) EventType() string           { return TypeUserDeleted }
+func (RoleAssigned) EventType() string          { return TypeRoleAssigned }
+func (RoleRemoved) EventType() string           { return TypeRoleRemoved }
+
+func (e UserRegistered) UserID() uuid.UUID        { return e.ID }
+func (e UserLocked) UserID() uuid.UUID            { return e.ID }
+func (e UserPasswordChanged) UserID() uuid.UUID   { return e.ID }
+func (e UserDeactivated) UserID() uuid.UUID       { return e.ID }
+func (e UserReactivated) UserID() uuid.UUID       { return e.ID }
+func (e UserDeletionRequested) UserID() uuid.UUID { return e.ID }
+func (e UserDeleted) UserID() uuid.UUID           { return e.ID }
+func (e RoleAssigned) UserID() uuid.UUID          { return e.ID }
+func (e RoleRemoved) UserID() uuid.UUID           { return e.ID }
+
+// NewOutboxEvent wraps e in an outbox record with a fresh idempotency key and
+// the trace context of ctx.
+func NewOutboxEvent(ctx context.Context, e Event) (*models.OutboxEvent, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", e.EventType(), err)
+	}
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return &models.OutboxEvent{
+		ID:            uuid.New(),
+		AggregateType: aggregateUser,
+		AggregateID:   e.UserID(),
+		EventType:     e.EventType(),
+		Payload:       payload,
+		OccurredAt:    time.Now().UTC().Truncate(time.Microsecond),
+		TraceParent:   carrier.Get(headerTraceParent),
+		TraceState:    carrier.Get(headerTraceState),
+	}, nil
+}
+
+// Envelope is the message body published for every event.
+type Envelope struct {
+	ID            uuid.UUID       `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type kafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher returns a Publisher that writes to the given brokers and
+// waits for all in-sync replicas to acknowledge. Messages with the same key
+// go to the same partition, so each user's events stay in order.
+func NewKafkaPublisher(brokers []string) Publisher {
+	return &kafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, messages ...Message) error {
+	records := make([]kafka.Message, len(messages))
+	for i, msg := range messages {
+		headers := make([]kafka.Header, 0, len(msg.Headers))
+		for key, value := range msg.Headers {
+			headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+		}
+		records[i] = kafka.Message{
+			Topic:   msg.Topic,
+			Key:     msg.Key,
+			Value:   msg.Value,
+			Headers: headers,
+		}
+	}
+	return p.writer.WriteMessages(ctx, records...)
+}
+
+func (p *kafkaPublisher) Close() error {
+	return p.writer.Close()
+}
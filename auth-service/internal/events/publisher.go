@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"http_server/auth-service/internal/config"
+)
+
+// Header names set on every published message.
+const (
+	HeaderIdempotencyKey = "idempotency-key"
+	HeaderEventType      = "event-type"
+
+	// W3C trace context of the request that caused the event.
+	headerTraceParent = "traceparent"
+	headerTraceState  = "tracestate"
+)
+
+// Message is a single record handed to a Publisher.
+type Message struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+}
+
+// Publisher delivers messages to a broker. Publish returns only once every
+// message has been acknowledged; on error any of them may or may not have
+// been delivered.
+type Publisher interface {
+	Publish(ctx context.Context, messages ...Message) error
+	Close() error
+}
+
+// NewPublisher creates the publisher selected by cfg.Publisher.
+func NewPublisher(cfg config.EventsConfig) (Publisher, error) {
+	switch cfg.Publisher {
+	case "kafka":
+		return NewKafkaPublisher(cfg.Brokers), nil
+	case "memory":
+		return NewMemoryPublisher(), nil
+	default:
+		return nil, fmt.Errorf("unknown events publisher %q", cfg.Publisher)
+	}
+}
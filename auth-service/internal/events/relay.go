@@ -0,0 +1,204 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"http_server/auth-service/internal/config"
+	"http_server/auth-service/internal/domain/models"
+	"http_server/auth-service/internal/domain/repository"
+	"http_server/shared/logging"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+var tracer = otel.Tracer("http_server/auth-service/internal/events")
+
+// Relay moves events from the outbox to the publisher. An event is marked
+// published only after the publisher acknowledged it, so delivery is
+// at-least-once: consumers deduplicate on the idempotency key.
+type Relay struct {
+	outbox    repository.OutboxRepository
+	tx        repository.Transactor
+	publisher Publisher
+	config    config.EventsConfig
+	logger    *logging.Logger
+}
+
+func NewRelay(outbox repository.OutboxRepository, tx repository.Transactor, publisher Publisher, cfg config.EventsConfig, logger *logging.Logger) *Relay {
+	return &Relay{
+		outbox:    outbox,
+		tx:        tx,
+		publisher: publisher,
+		config:    cfg,
+		logger:    logger,
+	}
+}
+
+// RelayOnce publishes one batch of pending events and returns how many were
+// published. Events are claimed in the order they were recorded and stay
+// claimed until the publisher answers, so a failed batch is retried as a
+// whole before anything newer is sent.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	var published int
+	var publishErr error
+
+	err := r.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		pending, err := r.outbox.ClaimPending(ctx, r.config.BatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to claim outbox events: %w", err)
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		ctx, span := r.startPublishSpan(ctx, pending)
+		defer span.End()
+
+		messages := make([]Message, len(pending))
+		ids := make([]uuid.UUID, len(pending))
+		for i, event := range pending {
+			if messages[i], err = r.message(event); err != nil {
+				return err
+			}
+			ids[i] = event.ID
+		}
+
+		publishCtx, cancel := context.WithTimeout(ctx, r.config.PublishTimeout)
+		publishErr = r.publisher.Publish(publishCtx, messages...)
+		cancel()
+		if publishErr != nil {
+			span.RecordError(publishErr)
+			span.SetStatus(codes.Error, publishErr.Error())
+			return r.outbox.MarkFailed(ctx, ids, publishErr.Error())
+		}
+
+		if err := r.outbox.MarkPublished(ctx, ids, time.Now().UTC()); err != nil {
+			return fmt.Errorf("failed to mark outbox events published: %w", err)
+		}
+		published = len(pending)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if publishErr != nil {
+		return 0, fmt.Errorf("failed to publish events: %w", publishErr)
+	}
+	return published, nil
+}
+
+// Run relays events until ctx is cancelled. It drains full batches
+// back to back, waits RelayInterval when idle and backs off exponentially up
+// to MaxBackoff while publishing fails. Published events older than
+// Retention are removed along the way.
+func (r *Relay) Run(ctx context.Context) {
+	logger := r.logger.WithContext(ctx)
+	delay := r.config.RelayInterval
+	backoff := r.config.RelayInterval
+	lastCleanup := time.Time{}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		published, err := r.RelayOnce(ctx)
+		switch {
+		case err != nil:
+			logger.Error("Failed to relay outbox events", err, zap.Duration("retry_in", backoff))
+			delay = backoff
+			backoff = min(backoff*2, r.config.MaxBackoff)
+		case published == r.config.BatchSize:
+			delay = 0
+			backoff = r.config.RelayInterval
+		default:
+			delay = r.config.RelayInterval
+			backoff = r.config.RelayInterval
+		}
+		if published > 0 {
+			logger.Debug("Relayed outbox events", zap.Int("count", published))
+		}
+
+		if time.Since(lastCleanup) >= time.Hour {
+			lastCleanup = time.Now()
+			removed, err := r.outbox.DeletePublished(ctx, time.Now().UTC().Add(-r.config.Retention))
+			if err != nil {
+				logger.Error("Failed to remove published outbox events", err)
+			} else if removed > 0 {
+				logger.Info("Removed published outbox events", zap.Int64("count", removed))
+			}
+		}
+
+		timer.Reset(delay)
+	}
+}
+
+// startPublishSpan starts a producer span for a batch. Its parent is ctx;
+// the traces that recorded the events are attached as links.
+func (r *Relay) startPublishSpan(ctx context.Context, pending []models.OutboxEvent) (context.Context, trace.Span) {
+	links := make([]trace.Link, 0, len(pending))
+	for _, event := range pending {
+		carrier := traceCarrier(event)
+		if linked := trace.SpanContextFromContext(otel.GetTextMapPropagator().Extract(ctx, carrier)); linked.IsValid() {
+			links = append(links, trace.Link{SpanContext: linked})
+		}
+	}
+	return tracer.Start(ctx, r.config.Topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithLinks(links...),
+		trace.WithAttributes(
+			attribute.String("messaging.destination.name", r.config.Topic),
+			attribute.Int("messaging.batch.message_count", len(pending)),
+		),
+	)
+}
+
+func (r *Relay) message(event models.OutboxEvent) (Message, error) {
+	value, err := json.Marshal(Envelope{
+		ID:            event.ID,
+		Type:          event.EventType,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		OccurredAt:    event.OccurredAt,
+		Payload:       event.Payload,
+	})
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to encode outbox event %s: %w", event.ID, err)
+	}
+	headers := traceCarrier(event)
+	headers[HeaderIdempotencyKey] = event.ID.String()
+	headers[HeaderEventType] = event.EventType
+	return Message{
+		Topic:   r.config.Topic,
+		Key:     []byte(event.AggregateID.String()),
+		Value:   value,
+		Headers: headers,
+	}, nil
+}
+
+// traceCarrier returns the trace context recorded with event, which is
+// published as message headers so consumers continue the originating trace.
+func traceCarrier(event models.OutboxEvent) propagation.MapCarrier {
+	carrier := propagation.MapCarrier{}
+	if event.TraceParent != "" {
+		carrier.Set(headerTraceParent, event.TraceParent)
+	}
+	if event.TraceState != "" {
+		carrier.Set(headerTraceState, event.TraceState)
+	}
+	return carrier
+}
@@ -0,0 +1,183 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	authv1 "http_server/auth-service/api/auth/v1"
+	"http_server/auth-service/internal/service"
+	"http_server/shared/logging"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// authServer implements authv1.AuthServiceServer on top of AuthService.
+type authServer struct {
+	authv1.UnimplementedAuthServiceServer
+	authService service.AuthService
+	logger      *logging.Logger
+}
+
+func (s *authServer) ValidateToken(ctx context.Context, req *authv1.ValidateTokenRequest) (*authv1.ValidateTokenResponse, error) {
+	claims, err := s.validate(ctx, req.GetToken())
+	if err != nil {
+		return nil, s.toStatus(ctx, err)
+	}
+
+	return &authv1.ValidateTokenResponse{
+		UserId:    claims.userID.String(),
+		Email:     claims.email,
+		Roles:     claims.roles,
+		ExpiresAt: timestamp(claims.expiresAt),
+		IssuedAt:  timestamp(claims.issuedAt),
+	}, nil
+}
+
+func (s *authServer) Introspect(ctx context.Context, req *authv1.IntrospectRequest) (*authv1.IntrospectResponse, error) {
+	claims, err := s.validate(ctx, req.GetToken())
+	if err != nil {
+		return &authv1.IntrospectResponse{Active: false}, nil
+	}
+
+	return &authv1.IntrospectResponse{
+		Active:    true,
+		UserId:    claims.userID.String(),
+		Email:     claims.email,
+		Roles:     claims.roles,
+		ExpiresAt: timestamp(claims.expiresAt),
+		IssuedAt:  timestamp(claims.issuedAt),
+	}, nil
+}
+
+func (s *authServer) GetUserRoles(ctx context.Context, req *authv1.GetUserRolesRequest) (*authv1.GetUserRolesResponse, error) {
+	userID, err := parseUserID(req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	roles, err := s.authService.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, s.toStatus(ctx, err)
+	}
+	return &authv1.GetUserRolesResponse{Roles: roles}, nil
+}
+
+func (s *authServer) CheckPermission(ctx context.Context, req *authv1.CheckPermissionRequest) (*authv1.CheckPermissionResponse, error) {
+	userID, err := parseUserID(req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetPermission() == "" {
+		return nil, status.Error(codes.InvalidArgument, "permission is required")
+	}
+	if err := authorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.authService.CheckPermission(ctx, userID, req.GetPermission())
+	if err != nil {
+		return nil, s.toStatus(ctx, err)
+	}
+	return &authv1.CheckPermissionResponse{Allowed: allowed}, nil
+}
+
+func (s *authServer) AssignRole(ctx context.Context, req *authv1.AssignRoleRequest) (*authv1.AssignRoleResponse, error) {
+	userID, err := parseUserID(req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetRole() == "" {
+		return nil, status.Error(codes.InvalidArgument, "role is required")
+	}
+
+	if err := s.authService.AssignRole(ctx, userID, req.GetRole()); err != nil {
+		return nil, s.toStatus(ctx, err)
+	}
+	return &authv1.AssignRoleResponse{}, nil
+}
+
+func (s *authServer) RemoveRole(ctx context.Context, req *authv1.RemoveRoleRequest) (*authv1.RemoveRoleResponse, error) {
+	userID, err := parseUserID(req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetRole() == "" {
+		return nil, status.Error(codes.InvalidArgument, "role is required")
+	}
+
+	if err := s.authService.RemoveRole(ctx, userID, req.GetRole()); err != nil {
+		return nil, s.toStatus(ctx, err)
+	}
+	return &authv1.RemoveRoleResponse{}, nil
+}
+
+// tokenClaims are the claims of a validated access token.
+type tokenClaims struct {
+	userID    uuid.UUID
+	email     string
+	roles     []string
+	expiresAt time.Time
+	issuedAt  time.Time
+}
+
+func (s *authServer) validate(ctx context.Context, token string) (*tokenClaims, error) {
+	if token == "" {
+		return nil, service.ErrInvalidToken
+	}
+	parsed, err := s.authService.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return parseClaims(parsed)
+}
+
+func parseClaims(token *jwt.Token) (*tokenClaims, error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, service.ErrInvalidToken
+	}
+	rawID, _ := claims["user_id"].(string)
+	userID, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, service.ErrInvalidToken
+	}
+
+	result := &tokenClaims{userID: userID}
+	result.email, _ = claims["email"].(string)
+	if roles, ok := claims["roles"].([]interface{}); ok {
+		for _, role := range roles {
+			if name, ok := role.(string); ok {
+				result.roles = append(result.roles, name)
+			}
+		}
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		result.expiresAt = exp.Time
+	}
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		result.issuedAt = iat.Time
+	}
+	return result, nil
+}
+
+func parseUserID(raw string) (uuid.UUID, error) {
+	userID, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "user_id must be a UUID")
+	}
+	return userID, nil
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,36 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"http_server/auth-service/internal/service"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// serviceCodes maps service errors to gRPC status codes, like the REST
+// handlers' serviceErrors table.
+var serviceCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{service.ErrInvalidToken, codes.Unauthenticated},
+	{service.ErrTokenExpired, codes.Unauthenticated},
+	{service.ErrUserNotFound, codes.NotFound},
+	{service.ErrRoleNotFound, codes.NotFound},
+	{service.ErrRoleAlreadyAssigned, codes.AlreadyExists},
+}
+
+// toStatus converts a service error to a gRPC status. Unknown errors are
+// logged and reported as INTERNAL without their message.
+func (s *authServer) toStatus(ctx context.Context, err error) error {
+	for _, mapping := range serviceCodes {
+		if errors.Is(err, mapping.err) {
+			return status.Error(mapping.code, mapping.err.Error())
+		}
+	}
+	s.logger.WithContext(ctx).Error("gRPC request failed", err)
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,161 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	authv1 "http_server/auth-service/api/auth/v1"
+	"http_server/auth-service/internal/domain/models"
+	"http_server/shared/logging"
+	sharedmw "http_server/shared/middleware"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// adminMethods may only be called by services or admins.
+var adminMethods = map[string]bool{
+	authv1.AuthService_AssignRole_FullMethodName: true,
+	authv1.AuthService_RemoveRole_FullMethodName: true,
+}
+
+// caller is who made an authenticated request: a service identified by its
+// client certificate, or a user identified by a bearer token.
+type caller struct {
+	principal string
+	userID    uuid.UUID
+	roles     []string
+}
+
+func (c *caller) isAdmin() bool {
+	if c.principal != "" {
+		return true
+	}
+	for _, role := range c.roles {
+		if role == models.RoleAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+type callerKey struct{}
+
+func callerFromContext(ctx context.Context) (*caller, bool) {
+	c, ok := ctx.Value(callerKey{}).(*caller)
+	return c, ok
+}
+
+// authorizeUser lets users act only on their own account, unless they are
+// admins. Services may act on any user.
+func authorizeUser(ctx context.Context, userID uuid.UUID) error {
+	c, ok := callerFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+	if c.isAdmin() || c.userID == userID {
+		return nil
+	}
+	return status.Error(codes.PermissionDenied, "insufficient permissions")
+}
+
+// recoveryInterceptor turns a panic into an INTERNAL error.
+func recoveryInterceptor(logger *logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logger.WithContext(ctx).Error("Recovered from panic",
+					fmt.Errorf("panic: %v", recovered),
+					zap.String("method", info.FullMethod),
+					zap.Stack("stack"),
+				)
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// loggingInterceptor writes one entry per call once it completes.
+func loggingInterceptor(logger *logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		fields := []zap.Field{
+			zap.String("method", info.FullMethod),
+			zap.String("code", status.Code(err).String()),
+			zap.Duration("duration", time.Since(start)),
+		}
+		if p, ok := peer.FromContext(ctx); ok {
+			fields = append(fields, zap.String("remote_addr", p.Addr.String()))
+		}
+		logger.WithContext(ctx).Info("grpc_request_completed", fields...)
+		return resp, err
+	}
+}
+
+// authInterceptor authenticates calls to the AuthService, leaving the
+// health and reflection services open.
+func authInterceptor(auth *authServer, machineAuth *sharedmw.MachineAuth) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, "/"+authv1.AuthService_ServiceDesc.ServiceName+"/") {
+			return handler(ctx, req)
+		}
+
+		c, err := authenticate(ctx, auth, machineAuth)
+		if err != nil {
+			return nil, err
+		}
+		if adminMethods[info.FullMethod] && !c.isAdmin() {
+			return nil, status.Error(codes.PermissionDenied, "insufficient permissions")
+		}
+
+		ctx = context.WithValue(ctx, callerKey{}, c)
+		if c.principal != "" {
+			ctx = logging.ContextWithFields(ctx, zap.String("machine_principal", c.principal))
+		} else {
+			ctx = logging.ContextWithFields(ctx, zap.String("user_id", c.userID.String()))
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authenticate prefers a verified client certificate mapped to a principal
+// and falls back to the bearer token in the "authorization" metadata.
+func authenticate(ctx context.Context, auth *authServer, machineAuth *sharedmw.MachineAuth) (*caller, error) {
+	if machineAuth != nil {
+		if p, ok := peer.FromContext(ctx); ok {
+			if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.VerifiedChains) > 0 {
+				if principal, ok := machineAuth.Principal(tlsInfo.State.VerifiedChains[0][0]); ok {
+					return &caller{principal: principal}, nil
+				}
+				return nil, status.Error(codes.PermissionDenied, "client certificate not authorized")
+			}
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization required")
+	}
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization format")
+	}
+
+	claims, err := auth.validate(ctx, token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return &caller{userID: claims.userID, roles: claims.roles}, nil
+}
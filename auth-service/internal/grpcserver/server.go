@@ -0,0 +1,127 @@
+// Package grpcserver serves the AuthService gRPC API for internal callers,
+// next to the REST API and on top of the same services.
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	authv1 "http_server/auth-service/api/auth/v1"
+	"http_server/auth-service/internal/config"
+	"http_server/auth-service/internal/service"
+	"http_server/auth-service/pkg/middleware"
+	"http_server/shared/health"
+	"http_server/shared/logging"
+	sharedserver "http_server/shared/server"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+type Server struct {
+	grpcServer      *grpc.Server
+	port            int
+	tls             *sharedserver.TLSReloader
+	shutdownTimeout time.Duration
+	logger          *logging.Logger
+}
+
+// NewServer builds the gRPC server. tlsConfig may be nil to serve without
+// TLS; with a client CA, services authenticate by certificate through
+// machineAuth.
+func NewServer(cfg *config.Config, tlsConfig *sharedserver.TLSConfig, authService service.AuthService, mw *middleware.Middleware, healthRegistry *health.Registry, logger *logging.Logger) (*Server, error) {
+	s := &Server{
+		port:            cfg.GRPC.Port,
+		shutdownTimeout: cfg.Server.ShutdownTimeout,
+		logger:          logger,
+	}
+
+	auth := &authServer{authService: authService, logger: logger}
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			recoveryInterceptor(logger),
+			loggingInterceptor(logger),
+			authInterceptor(auth, mw.GetMachineAuthMiddleware()),
+		),
+	}
+	if tlsConfig != nil {
+		reloader, err := sharedserver.NewTLSReloader(*tlsConfig, logger)
+		if err != nil {
+			return nil, err
+		}
+		s.tls = reloader
+		opts = append(opts, grpc.Creds(credentials.NewTLS(reloader.Config())))
+	}
+
+	s.grpcServer = grpc.NewServer(opts...)
+	authv1.RegisterAuthServiceServer(s.grpcServer, auth)
+	healthpb.RegisterHealthServer(s.grpcServer, &healthServer{registry: healthRegistry})
+	if cfg.GRPC.Reflection {
+		reflection.Register(s.grpcServer)
+	}
+	return s, nil
+}
+
+// Run serves until ctx is cancelled, then stops accepting calls and waits
+// up to the shutdown timeout for in-flight ones.
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return err
+	}
+	if s.tls != nil {
+		go s.tls.Watch(ctx)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.Info("gRPC server listening", zap.String("addr", listener.Addr().String()), zap.Bool("tls", s.tls != nil))
+		errCh <- s.grpcServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.logger.Info("Shutting down gRPC server", zap.Duration("timeout", s.shutdownTimeout))
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(s.shutdownTimeout):
+		s.grpcServer.Stop()
+	}
+	return nil
+}
+
+// healthServer answers the standard gRPC health check from the readiness
+// checks. Watch is not supported.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	registry *health.Registry
+}
+
+func (h *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if req.GetService() != "" && req.GetService() != authv1.AuthService_ServiceDesc.ServiceName {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+
+	switch h.registry.Report(ctx).Status {
+	case health.StatusUp, health.StatusDegraded:
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+	default:
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+	}
+}
@@ -0,0 +1,421 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	authv1 "http_server/auth-service/api/auth/v1"
+	"http_server/auth-service/internal/config"
+	"http_server/auth-service/internal/domain/models"
+	"http_server/auth-service/internal/service"
+	"http_server/auth-service/pkg/middleware"
+	"http_server/shared/health"
+	"http_server/shared/logging"
+	sharedserver "http_server/shared/server"
+	"http_server/shared/server/tlstest"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var testKey = []byte("test-signing-key")
+
+const testPrincipal = "user-service"
+
+// fakeAuthService validates tokens signed with testKey and records role
+// changes.
+type fakeAuthService struct {
+	service.AuthService
+	assigned map[uuid.UUID][]string
+}
+
+func (f *fakeAuthService) ValidateToken(ctx context.Context, token string) (*jwt.Token, error) {
+	parsed, err := jwt.Parse(token, func(*jwt.Token) (interface{}, error) {
+		return testKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, service.ErrTokenExpired
+		}
+		return nil, service.ErrInvalidToken
+	}
+	return parsed, nil
+}
+
+func (f *fakeAuthService) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	roles, ok := f.assigned[userID]
+	if !ok {
+		return nil, service.ErrUserNotFound
+	}
+	return roles, nil
+}
+
+func (f *fakeAuthService) CheckPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
+	return permission == "posts:read", nil
+}
+
+func (f *fakeAuthService) AssignRole(ctx context.Context, userID uuid.UUID, roleName string) error {
+	for _, role := range f.assigned[userID] {
+		if role == roleName {
+			return service.ErrRoleAlreadyAssigned
+		}
+	}
+	f.assigned[userID] = append(f.assigned[userID], roleName)
+	return nil
+}
+
+func (f *fakeAuthService) RemoveRole(ctx context.Context, userID uuid.UUID, roleName string) error {
+	return service.ErrRoleNotFound
+}
+
+func newTestLogger(t *testing.T) *logging.Logger {
+	t.Helper()
+	logger, err := logging.NewLogger(&logging.Config{ServiceName: "test", LogLevel: "error", FilePath: "stderr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+func accessToken(t *testing.T, userID uuid.UUID, roles ...string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID.String(),
+		"email":   "someone@example.com",
+		"roles":   roles,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+		"iat":     time.Now().Unix(),
+	}).SignedString(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+type testServer struct {
+	conn     *grpc.ClientConn
+	auth     *fakeAuthService
+	registry *health.Registry
+}
+
+// startServer serves NewServer over an in-memory listener. With certs set
+// the server requires TLS and maps the client certificate to a principal.
+func startServer(t *testing.T, certs *testCerts, clientCert *tls.Certificate) *testServer {
+	t.Helper()
+	logger := newTestLogger(t)
+
+	cfg := &config.Config{}
+	cfg.GRPC.Reflection = true
+	mwConfig := &middleware.Config{}
+	var tlsConfig *sharedserver.TLSConfig
+	if certs != nil {
+		mwConfig.MachineAuth.Enabled = true
+		mwConfig.MachineAuth.Principals = map[string]string{certs.clientURI: testPrincipal}
+		tlsConfig = &sharedserver.TLSConfig{CertFile: certs.serverCert, KeyFile: certs.serverKey, ClientCAFile: certs.File}
+	}
+
+	ts := &testServer{
+		auth:     &fakeAuthService{assigned: make(map[uuid.UUID][]string)},
+		registry: health.New(health.Config{CacheTTL: time.Nanosecond, Timeout: time.Second}),
+	}
+	mw := middleware.NewMiddleware(mwConfig, ts.auth, logger, nil)
+	server, err := NewServer(cfg, tlsConfig, ts.auth, mw, ts.registry, logger)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	go server.grpcServer.Serve(listener)
+	t.Cleanup(server.grpcServer.Stop)
+
+	transport := insecure.NewCredentials()
+	if certs != nil {
+		clientTLS := &tls.Config{RootCAs: certs.Pool, ServerName: "localhost"}
+		if clientCert != nil {
+			clientTLS.Certificates = []tls.Certificate{*clientCert}
+		}
+		transport = credentials.NewTLS(clientTLS)
+	}
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(transport),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	ts.conn = conn
+	return ts
+}
+
+func TestValidateTokenAndIntrospect(t *testing.T) {
+	ts := startServer(t, nil, nil)
+	client := authv1.NewAuthServiceClient(ts.conn)
+	userID := uuid.New()
+	token := accessToken(t, userID, "user")
+	ctx := withToken(token)
+
+	validated, err := client.ValidateToken(ctx, &authv1.ValidateTokenRequest{Token: token})
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	if validated.GetUserId() != userID.String() || validated.GetEmail() != "someone@example.com" ||
+		len(validated.GetRoles()) != 1 || validated.GetExpiresAt() == nil {
+		t.Errorf("ValidateToken = %v", validated)
+	}
+
+	_, err = client.ValidateToken(ctx, &authv1.ValidateTokenRequest{Token: "not-a-token"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("ValidateToken with a bad token = %v, want UNAUTHENTICATED", err)
+	}
+
+	introspected, err := client.Introspect(ctx, &authv1.IntrospectRequest{Token: "not-a-token"})
+	if err != nil || introspected.GetActive() {
+		t.Errorf("Introspect with a bad token = %v, %v, want inactive", introspected, err)
+	}
+	introspected, err = client.Introspect(ctx, &authv1.IntrospectRequest{Token: token})
+	if err != nil || !introspected.GetActive() || introspected.GetUserId() != userID.String() {
+		t.Errorf("Introspect = %v, %v, want active", introspected, err)
+	}
+}
+
+func TestUserScopedRPCs(t *testing.T) {
+	ts := startServer(t, nil, nil)
+	client := authv1.NewAuthServiceClient(ts.conn)
+	userID, otherID := uuid.New(), uuid.New()
+	ts.auth.assigned[userID] = []string{"user"}
+	ts.auth.assigned[otherID] = []string{"user"}
+	ctx := withToken(accessToken(t, userID, "user"))
+
+	roles, err := client.GetUserRoles(ctx, &authv1.GetUserRolesRequest{UserId: userID.String()})
+	if err != nil || len(roles.GetRoles()) != 1 {
+		t.Errorf("GetUserRoles for self = %v, %v", roles, err)
+	}
+	_, err = client.GetUserRoles(ctx, &authv1.GetUserRolesRequest{UserId: otherID.String()})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("GetUserRoles for another user = %v, want PERMISSION_DENIED", err)
+	}
+	_, err = client.GetUserRoles(ctx, &authv1.GetUserRolesRequest{UserId: "nope"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetUserRoles with a bad ID = %v, want INVALID_ARGUMENT", err)
+	}
+
+	allowed, err := client.CheckPermission(ctx, &authv1.CheckPermissionRequest{UserId: userID.String(), Permission: "posts:read"})
+	if err != nil || !allowed.GetAllowed() {
+		t.Errorf("CheckPermission = %v, %v, want allowed", allowed, err)
+	}
+	_, err = client.CheckPermission(ctx, &authv1.CheckPermissionRequest{UserId: userID.String()})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("CheckPermission without a permission = %v, want INVALID_ARGUMENT", err)
+	}
+
+	adminCtx := withToken(accessToken(t, uuid.New(), models.RoleAdmin))
+	_, err = client.GetUserRoles(adminCtx, &authv1.GetUserRolesRequest{UserId: uuid.New().String()})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetUserRoles for an unknown user = %v, want NOT_FOUND", err)
+	}
+}
+
+func TestRoleManagementRequiresAdmin(t *testing.T) {
+	ts := startServer(t, nil, nil)
+	client := authv1.NewAuthServiceClient(ts.conn)
+	userID := uuid.New()
+	assign := &authv1.AssignRoleRequest{UserId: userID.String(), Role: "editor"}
+
+	_, err := client.AssignRole(withToken(accessToken(t, userID, "user")), assign)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("AssignRole as a user = %v, want PERMISSION_DENIED", err)
+	}
+
+	adminCtx := withToken(accessToken(t, uuid.New(), models.RoleAdmin))
+	if _, err := client.AssignRole(adminCtx, assign); err != nil {
+		t.Fatalf("AssignRole as an admin: %v", err)
+	}
+	if _, err := client.AssignRole(adminCtx, assign); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("repeated AssignRole = %v, want ALREADY_EXISTS", err)
+	}
+	_, err = client.RemoveRole(adminCtx, &authv1.RemoveRoleRequest{UserId: userID.String(), Role: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("RemoveRole of an unknown role = %v, want NOT_FOUND", err)
+	}
+	_, err = client.RemoveRole(adminCtx, &authv1.RemoveRoleRequest{UserId: userID.String()})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("RemoveRole without a role = %v, want INVALID_ARGUMENT", err)
+	}
+}
+
+func TestAuthInterceptorRejectsMissingAndInvalidTokens(t *testing.T) {
+	ts := startServer(t, nil, nil)
+	client := authv1.NewAuthServiceClient(ts.conn)
+	req := &authv1.GetUserRolesRequest{UserId: uuid.New().String()}
+
+	expired, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": uuid.New().String(),
+		"exp":     time.Now().Add(-time.Hour).Unix(),
+	}).SignedString(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{"missing", context.Background()},
+		{"not bearer", metadata.AppendToOutgoingContext(context.Background(), "authorization", "Basic abc")},
+		{"invalid", withToken("not-a-token")},
+		{"expired", withToken(expired)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.GetUserRoles(tt.ctx, req); status.Code(err) != codes.Unauthenticated {
+				t.Errorf("GetUserRoles = %v, want UNAUTHENTICATED", err)
+			}
+		})
+	}
+}
+
+func TestAuthInterceptorMachinePrincipal(t *testing.T) {
+	certs := newTestCerts(t)
+	ts := startServer(t, certs, &certs.client)
+	client := authv1.NewAuthServiceClient(ts.conn)
+	userID := uuid.New()
+
+	// The certificate alone authenticates, and services may manage roles
+	// of any user.
+	_, err := client.AssignRole(context.Background(), &authv1.AssignRoleRequest{UserId: userID.String(), Role: "editor"})
+	if err != nil {
+		t.Fatalf("AssignRole with a client certificate: %v", err)
+	}
+	roles, err := client.GetUserRoles(context.Background(), &authv1.GetUserRolesRequest{UserId: userID.String()})
+	if err != nil || len(roles.GetRoles()) != 1 {
+		t.Errorf("GetUserRoles with a client certificate = %v, %v", roles, err)
+	}
+}
+
+func TestAuthInterceptorUnknownCertificate(t *testing.T) {
+	certs := newTestCerts(t)
+	ts := startServer(t, certs, &certs.unmapped)
+	client := authv1.NewAuthServiceClient(ts.conn)
+
+	_, err := client.GetUserRoles(withToken(accessToken(t, uuid.New(), models.RoleAdmin)), &authv1.GetUserRolesRequest{UserId: uuid.New().String()})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("GetUserRoles with an unmapped certificate = %v, want PERMISSION_DENIED", err)
+	}
+}
+
+func TestAuthInterceptorTLSWithoutCertificate(t *testing.T) {
+	certs := newTestCerts(t)
+	ts := startServer(t, certs, nil)
+	client := authv1.NewAuthServiceClient(ts.conn)
+	userID := uuid.New()
+	ts.auth.assigned[userID] = []string{"user"}
+
+	// Without a client certificate, callers fall back to bearer tokens.
+	if _, err := client.GetUserRoles(withToken(accessToken(t, userID)), &authv1.GetUserRolesRequest{UserId: userID.String()}); err != nil {
+		t.Errorf("GetUserRoles with a token over TLS: %v", err)
+	}
+	if _, err := client.GetUserRoles(context.Background(), &authv1.GetUserRolesRequest{UserId: userID.String()}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("GetUserRoles without credentials = %v, want UNAUTHENTICATED", err)
+	}
+}
+
+func TestHealthService(t *testing.T) {
+	ts := startServer(t, nil, nil)
+	client := healthpb.NewHealthClient(ts.conn)
+	var failing atomic.Bool
+	ts.registry.Register("database", health.CheckerFunc(func(context.Context) error {
+		if failing.Load() {
+			return errors.New("down")
+		}
+		return nil
+	}))
+
+	// The health service is open: no credentials are sent.
+	for _, name := range []string{"", authv1.AuthService_ServiceDesc.ServiceName} {
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: name})
+		if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("Check(%q) = %v, %v, want SERVING", name, resp, err)
+		}
+	}
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "other"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Check of an unknown service = %v, want NOT_FOUND", err)
+	}
+
+	failing.Store(true)
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("Check with a failing dependency = %v, %v, want NOT_SERVING", resp, err)
+	}
+}
+
+func TestReflectionListsServices(t *testing.T) {
+	ts := startServer(t, nil, nil)
+	stream, err := reflectionpb.NewServerReflectionClient(ts.conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("reflection: %v", err)
+	}
+
+	var names []string
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		names = append(names, svc.GetName())
+	}
+	sort.Strings(names)
+	for _, want := range []string{authv1.AuthService_ServiceDesc.ServiceName, healthpb.Health_ServiceDesc.ServiceName} {
+		if i := sort.SearchStrings(names, want); i == len(names) || names[i] != want {
+			t.Errorf("services = %v, missing %s", names, want)
+		}
+	}
+}
+
+// testCerts is a CA with a server certificate for localhost, written to
+// files for the TLS reloader, and two client certificates: one whose URI is
+// mapped to testPrincipal and one that is not mapped.
+type testCerts struct {
+	*tlstest.CA
+	serverCert string
+	serverKey  string
+	clientURI  string
+	client     tls.Certificate
+	unmapped   tls.Certificate
+}
+
+func newTestCerts(t *testing.T) *testCerts {
+	t.Helper()
+	certs := &testCerts{CA: tlstest.NewCA(t), clientURI: "spiffe://test/" + testPrincipal}
+	certs.serverCert, certs.serverKey = certs.ServerFiles(t, "server")
+	certs.client = certs.ClientCert(t, "mapped", certs.clientURI)
+	certs.unmapped = certs.ClientCert(t, "unmapped", "")
+	return certs
+}
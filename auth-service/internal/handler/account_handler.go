@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"http_server/auth-service/internal/service"
+	"http_server/auth-service/internal/validator"
+	"http_server/auth-service/pkg/middleware"
+	"http_server/shared/logging"
+
+	"go.uber.org/zap"
+)
+
+type AccountHandler struct {
+	accountService service.AccountService
+	logger         *logging.Logger
+}
+
+func NewAccountHandler(accountService service.AccountService, logger *logging.Logger) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+		logger:         logger,
+	}
+}
+
+type DeactivateRequest struct {
+	Password string `json:"password"`
+	Reason   string `json:"reason"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type ReactivateRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Status serves GET /account
+func (h *AccountHandler) Status(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	status, err := h.accountService.Status(r.Context(), userID)
+	if err != nil {
+		respondWithAccountError(w, r, logger, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, status)
+}
+
+// ChangePassword serves POST /account/password
+func (h *AccountHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("Failed to decode request payload", zap.Error(err))
+		respondWithProblem(w, r, errInvalidPayload)
+		return
+	}
+
+	if fields := validator.Collect(
+		validator.ValidateRequired("current_password", req.CurrentPassword),
+		validator.ValidateRequired("new_password", req.NewPassword),
+	); len(fields) > 0 {
+		respondWithProblem(w, r, validationError(fields))
+		return
+	}
+
+	if err := h.accountService.ChangePassword(r.Context(), userID, req.CurrentPassword, req.NewPassword); err != nil {
+		// The policy reports on "password"; the request field is new_password.
+		var validationErr *validator.ValidationError
+		if errors.As(err, &validationErr) {
+			renamed := *validationErr
+			renamed.Field = "new_password"
+			err = &renamed
+		}
+		respondWithAccountError(w, r, logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deactivate serves POST /account/deactivate
+func (h *AccountHandler) Deactivate(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	var req DeactivateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("Failed to decode request payload", zap.Error(err))
+		respondWithProblem(w, r, errInvalidPayload)
+		return
+	}
+
+	if err := h.accountService.Deactivate(r.Context(), userID, req.Password, req.Reason); err != nil {
+		respondWithAccountError(w, r, logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Reactivate serves POST /account/reactivate. It is public because
+// deactivated users cannot obtain a token.
+func (h *AccountHandler) Reactivate(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	var req ReactivateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("Failed to decode request payload", zap.Error(err))
+		respondWithProblem(w, r, errInvalidPayload)
+		return
+	}
+
+	if err := h.accountService.Reactivate(r.Context(), req.Email, req.Password); err != nil {
+		respondWithAccountError(w, r, logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Delete serves DELETE /account
+func (h *AccountHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	var req DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("Failed to decode request payload", zap.Error(err))
+		respondWithProblem(w, r, errInvalidPayload)
+		return
+	}
+
+	purgeAfter, err := h.accountService.RequestDeletion(r.Context(), userID, req.Password)
+	if err != nil {
+		respondWithAccountError(w, r, logger, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, map[string]interface{}{
+		"purge_after": purgeAfter,
+	})
+}
+
+// Export serves GET /account/export as a zip download.
+func (h *AccountHandler) Export(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	export, err := h.accountService.Export(r.Context(), userID)
+	if err != nil {
+		respondWithAccountError(w, r, logger, err)
+		return
+	}
+
+	filename := fmt.Sprintf("account-export-%s-%s.zip", userID, export.GeneratedAt.Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Last-Modified", export.GeneratedAt.Format(time.RFC1123))
+	if err := service.WriteExportArchive(w, export); err != nil {
+		// Headers are already sent, so the client sees a truncated archive.
+		logger.Error("Failed to write export archive", err, zap.String("user_id", userID.String()))
+	}
+}
+
+// respondWithAccountError logs unexpected failures before reporting them.
+func respondWithAccountError(w http.ResponseWriter, r *http.Request, logger *logging.Logger, err error) {
+	if toAppError(err).StatusCode() >= http.StatusInternalServerError {
+		logger.Error("Account request failed", err)
+	}
+	respondWithProblem(w, r, err)
+}
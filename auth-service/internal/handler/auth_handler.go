@@ -7,9 +7,13 @@ import (
 
 	"http_server/auth-service/internal/service"
 	"http_server/auth-service/internal/validator"
-	"http_server/auth-service/pkg/logging"
+	"http_server/auth-service/pkg/middleware"
 	"http_server/auth-service/pkg/monitoring"
+	apperrors "http_server/shared/errors"
+	"http_server/shared/logging"
 
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 )
 
@@ -39,8 +43,7 @@ type LoginRequest struct {
 }
 
 type AuthResponse struct {
-	Token string `json:"token,omitempty"`
-	Error string `json:"error,omitempty"`
+	Token string `json:"token"`
 }
 
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
@@ -52,42 +55,35 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		logger.Error("Failed to decode request payload", err)
 		h.metrics.RegisterFailures.WithLabelValues("invalid_payload").Inc()
-		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		respondWithProblem(w, r, errInvalidPayload)
 		return
 	}
 
-	if err := validator.ValidateEmail(req.Email); err != nil {
-		logger.Error("Invalid email format", err, zap.String("email", req.Email))
-		h.metrics.RegisterFailures.WithLabelValues("invalid_email").Inc()
-		respondWithError(w, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	if err := validator.ValidatePassword(req.Password); err != nil {
-		logger.Error("Invalid password format", err)
-		h.metrics.RegisterFailures.WithLabelValues("invalid_password").Inc()
-		respondWithError(w, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	if err := validator.ValidateName(req.Name); err != nil {
-		logger.Error("Invalid name format", err, zap.String("name", req.Name))
-		h.metrics.RegisterFailures.WithLabelValues("invalid_name").Inc()
-		respondWithError(w, http.StatusBadRequest, err.Error())
+	if fields := validator.Collect(
+		validator.ValidateEmail(req.Email),
+		validator.ValidateRequired("password", req.Password),
+		validator.ValidateName(req.Name),
+	); len(fields) > 0 {
+		logger.Warn("Invalid registration request", zap.String("email", req.Email), zap.Any("fields", fields))
+		h.metrics.RegisterFailures.WithLabelValues("invalid_" + fields[0].Field).Inc()
+		respondWithProblem(w, r, validationError(fields))
 		return
 	}
 
 	user, err := h.authService.Register(r.Context(), req.Email, req.Password, req.Name)
 	if err != nil {
-		if err == service.ErrUserExists {
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.Is(err, service.ErrUserExists):
 			logger.Warn("Attempted to register existing user", zap.String("email", req.Email))
 			h.metrics.RegisterFailures.WithLabelValues("user_exists").Inc()
-			respondWithError(w, http.StatusConflict, "User already exists")
-			return
+		case errors.As(err, &validationErr):
+			h.metrics.RegisterFailures.WithLabelValues("invalid_password").Inc()
+		default:
+			logger.Error("Failed to register user", err, zap.String("email", req.Email))
+			h.metrics.RegisterFailures.WithLabelValues("internal_error").Inc()
 		}
-		logger.Error("Failed to register user", err, zap.String("email", req.Email))
-		h.metrics.RegisterFailures.WithLabelValues("internal_error").Inc()
-		respondWithError(w, http.StatusInternalServerError, "Failed to register user")
+		respondWithProblem(w, r, err)
 		return
 	}
 
@@ -109,35 +105,33 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		logger.Error("Failed to decode request payload", err)
 		h.metrics.LoginFailures.WithLabelValues("invalid_payload").Inc()
-		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
-		return
-	}
-
-	if err := validator.ValidateEmail(req.Email); err != nil {
-		logger.Error("Invalid email format", err, zap.String("email", req.Email))
-		h.metrics.LoginFailures.WithLabelValues("invalid_email").Inc()
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		respondWithProblem(w, r, errInvalidPayload)
 		return
 	}
 
-	if err := validator.ValidatePassword(req.Password); err != nil {
-		logger.Error("Invalid password format", err)
-		h.metrics.LoginFailures.WithLabelValues("invalid_password").Inc()
-		respondWithError(w, http.StatusBadRequest, err.Error())
+	if fields := validator.Collect(
+		validator.ValidateEmail(req.Email),
+		validator.ValidateRequired("password", req.Password),
+	); len(fields) > 0 {
+		logger.Warn("Invalid login request", zap.String("email", req.Email), zap.Any("fields", fields))
+		h.metrics.LoginFailures.WithLabelValues("invalid_" + fields[0].Field).Inc()
+		respondWithProblem(w, r, validationError(fields))
 		return
 	}
 
 	token, err := h.authService.Login(r.Context(), req.Email, req.Password)
 	if err != nil {
-		if err == service.ErrInvalidCredentials {
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
 			logger.Warn("Invalid login credentials", zap.String("email", req.Email))
 			h.metrics.LoginFailures.WithLabelValues("invalid_credentials").Inc()
-			respondWithError(w, http.StatusUnauthorized, "Invalid credentials")
-			return
+		case errors.Is(err, service.ErrAccountInactive):
+			h.metrics.LoginFailures.WithLabelValues("account_inactive").Inc()
+		default:
+			logger.Error("Failed to login", err, zap.String("email", req.Email))
+			h.metrics.LoginFailures.WithLabelValues("internal_error").Inc()
 		}
-		logger.Error("Failed to login", err, zap.String("email", req.Email))
-		h.metrics.LoginFailures.WithLabelValues("internal_error").Inc()
-		respondWithError(w, http.StatusInternalServerError, "Failed to login")
+		respondWithProblem(w, r, err)
 		return
 	}
 
@@ -151,30 +145,30 @@ func (h *AuthHandler) ValidateToken(w http.ResponseWriter, r *http.Request) {
 	logger.Info("Handling token validation request")
 	h.metrics.TokenValidationRequests.Inc()
 
-	userID, ok := r.Context().Value("user_id").(string)
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
 	if !ok {
 		err := errors.New("user_id not found in context")
 		logger.Error("Failed to get user_id from context", err)
 		h.metrics.TokenValidationFailures.WithLabelValues("invalid_context").Inc()
-		respondWithError(w, http.StatusInternalServerError, "Internal server error")
+		respondWithProblem(w, r, err)
 		return
 	}
 
-	email, ok := r.Context().Value("email").(string)
+	email, ok := r.Context().Value(middleware.EmailKey).(string)
 	if !ok {
 		err := errors.New("email not found in context")
 		logger.Error("Failed to get email from context", err)
 		h.metrics.TokenValidationFailures.WithLabelValues("invalid_context").Inc()
-		respondWithError(w, http.StatusInternalServerError, "Internal server error")
+		respondWithProblem(w, r, err)
 		return
 	}
 
-	roles, ok := r.Context().Value("roles").([]interface{})
+	roles, ok := r.Context().Value(middleware.RolesKey).([]interface{})
 	if !ok {
 		err := errors.New("roles not found in context")
 		logger.Error("Failed to get roles from context", err)
 		h.metrics.TokenValidationFailures.WithLabelValues("invalid_context").Inc()
-		respondWithError(w, http.StatusInternalServerError, "Internal server error")
+		respondWithProblem(w, r, err)
 		return
 	}
 
@@ -193,8 +187,54 @@ func (h *AuthHandler) ValidateToken(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, response)
 }
 
-func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, AuthResponse{Error: message})
+// UserProfileResponse is the subset of a user exposed to other services.
+type UserProfileResponse struct {
+	ID             uuid.UUID `json:"id"`
+	Name           string    `json:"name"`
+	Bio            string    `json:"bio,omitempty"`
+	ProfilePicture string    `json:"profile_picture,omitempty"`
+	Location       string    `json:"location,omitempty"`
+	Active         bool      `json:"active"`
+	Verified       bool      `json:"verified"`
+	Roles          []string  `json:"roles"`
+}
+
+// GetUserProfile serves the internal profile lookup used by user-service.
+func (h *AuthHandler) GetUserProfile(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithProblem(w, r, apperrors.NewBadRequestError("Invalid user id", err).WithCode(CodeInvalidUserID))
+		return
+	}
+
+	user, err := h.authService.GetUser(r.Context(), userID)
+	if err != nil {
+		if !errors.Is(err, service.ErrUserNotFound) {
+			logger.Error("Failed to get user", err, zap.String("user_id", userID.String()))
+		}
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	roles, err := h.authService.GetUserRoles(r.Context(), userID)
+	if err != nil {
+		logger.Error("Failed to get user roles", err, zap.String("user_id", userID.String()))
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, UserProfileResponse{
+		ID:             user.ID,
+		Name:           user.Name,
+		Bio:            user.Bio,
+		ProfilePicture: user.ProfilePicture,
+		Location:       user.Location,
+		Active:         user.Active,
+		Verified:       user.VerifiedAt != nil,
+		Roles:          roles,
+	})
 }
 
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"http_server/auth-service/internal/service"
+	"http_server/auth-service/internal/validator"
+	apperrors "http_server/shared/errors"
+	"http_server/shared/problem"
+)
+
+// Stable error codes reported in problem responses. Clients may rely on
+// these, so existing values must not change.
+const (
+	CodeInvalidPayload         = "invalid_payload"
+	CodeValidationFailed       = "validation_failed"
+	CodeUnauthorized           = "unauthorized"
+	CodeInvalidCredentials     = "invalid_credentials"
+	CodeUserExists             = "user_exists"
+	CodeUserNotFound           = "user_not_found"
+	CodeAccountInactive        = "account_inactive"
+	CodeAccountLocked          = "account_locked"
+	CodeAccountAlreadyInactive = "account_already_inactive"
+	CodePasswordUnchanged      = "password_unchanged"
+	CodeInvalidUserID          = "invalid_user_id"
+	CodeMethodDisabled         = "login_method_disabled"
+	CodeInvalidMagicLink       = "invalid_magic_link"
+	CodeMagicLinkBusy          = "magic_link_busy"
+	CodeInvalidCeremony        = "invalid_passkey_ceremony"
+	CodePasskeyRejected        = "passkey_rejected"
+	CodePasskeyExists          = "passkey_exists"
+	CodePasskeyNotFound        = "passkey_not_found"
+	CodeInvalidPasskeyID       = "invalid_passkey_id"
+)
+
+var (
+	errInvalidPayload = apperrors.NewBadRequestError("Invalid request payload", nil).WithCode(CodeInvalidPayload)
+	errUnauthorized   = apperrors.NewAuthenticationError("Authentication required", nil).WithCode(CodeUnauthorized)
+)
+
+// serviceErrors maps service sentinel errors onto client-facing errors.
+// Anything not listed is reported as an internal error.
+var serviceErrors = []struct {
+	err    error
+	appErr *apperrors.AppError
+}{
+	{service.ErrUserExists, apperrors.NewConflictError("User already exists", nil).WithCode(CodeUserExists)},
+	{service.ErrInvalidCredentials, apperrors.NewAuthenticationError("Invalid credentials", nil).WithCode(CodeInvalidCredentials)},
+	{service.ErrUserNotFound, apperrors.NewNotFoundError("User not found", nil).WithCode(CodeUserNotFound)},
+	{service.ErrAccountInactive, apperrors.NewAuthorizationError("Account is deactivated", nil).WithCode(CodeAccountInactive)},
+	{service.ErrAccountLocked, apperrors.New(apperrors.ErrorTypeLocked, "Account is temporarily locked", nil).WithCode(CodeAccountLocked)},
+	{service.ErrAccountAlreadyInactive, apperrors.NewConflictError("Account is already deactivated", nil).WithCode(CodeAccountAlreadyInactive)},
+	{service.ErrPasswordUnchanged, apperrors.NewBadRequestError("New password must differ from the current one", nil).WithCode(CodePasswordUnchanged)},
+	{service.ErrPasswordlessDisabled, apperrors.NewNotFoundError("Login method is not enabled", nil).WithCode(CodeMethodDisabled)},
+	{service.ErrMagicLinkBusy, apperrors.New(apperrors.ErrorTypeUnavailable, "Too many sign-in link requests, try again shortly", nil).WithCode(CodeMagicLinkBusy)},
+	{service.ErrInvalidMagicLink, apperrors.NewAuthenticationError("Sign-in link is invalid or expired", nil).WithCode(CodeInvalidMagicLink)},
+	{service.ErrInvalidCeremony, apperrors.NewBadRequestError("Passkey ceremony is invalid or expired", nil).WithCode(CodeInvalidCeremony)},
+	{service.ErrPasskeyVerification, apperrors.NewAuthenticationError("Passkey could not be verified", nil).WithCode(CodePasskeyRejected)},
+	{service.ErrPasskeyExists, apperrors.NewConflictError("Passkey is already registered", nil).WithCode(CodePasskeyExists)},
+	{service.ErrPasskeyNotFound, apperrors.NewNotFoundError("Passkey not found", nil).WithCode(CodePasskeyNotFound)},
+}
+
+// toAppError translates a service error for the client, keeping the original
+// as the cause for logging.
+func toAppError(err error) *apperrors.AppError {
+	if appErr, ok := apperrors.As(err); ok {
+		return appErr
+	}
+	var validationErr *validator.ValidationError
+	if errors.As(err, &validationErr) {
+		return validationError(validator.Collect(validationErr))
+	}
+	for _, mapping := range serviceErrors {
+		if errors.Is(err, mapping.err) {
+			appErr := *mapping.appErr
+			appErr.Err = err
+			return &appErr
+		}
+	}
+	return apperrors.NewInternalError("Internal server error", err)
+}
+
+// validationError reports per-field validation failures.
+func validationError(fields []apperrors.FieldError) *apperrors.AppError {
+	return apperrors.NewValidationError("Request validation failed", nil).
+		WithCode(CodeValidationFailed).
+		WithFields(fields...)
+}
+
+func respondWithProblem(w http.ResponseWriter, r *http.Request, err error) {
+	problem.Write(w, r, toAppError(err))
+}
@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"http_server/auth-service/internal/service"
+	"http_server/auth-service/internal/validator"
+	"http_server/auth-service/pkg/middleware"
+	apperrors "http_server/shared/errors"
+	"http_server/shared/logging"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const (
+	// deviceCookie binds a magic link to the browser that requested it.
+	deviceCookie     = "magic_link_device"
+	deviceCookiePath = "/api/v1/auth/magic-link"
+)
+
+type PasswordlessHandler struct {
+	passwordlessService service.PasswordlessService
+	magicLinkTTL        time.Duration
+	logger              *logging.Logger
+}
+
+func NewPasswordlessHandler(passwordlessService service.PasswordlessService, magicLinkTTL time.Duration, logger *logging.Logger) *PasswordlessHandler {
+	return &PasswordlessHandler{
+		passwordlessService: passwordlessService,
+		magicLinkTTL:        magicLinkTTL,
+		logger:              logger,
+	}
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email"`
+}
+
+type VerifyMagicLinkRequest struct {
+	Token string `json:"token"`
+}
+
+// PasskeyFinishRequest carries the browser's PublicKeyCredential as
+// returned by navigator.credentials.create or .get.
+type PasskeyFinishRequest struct {
+	SessionID  string          `json:"session_id"`
+	Name       string          `json:"name"`
+	Credential json.RawMessage `json:"credential"`
+}
+
+type PasskeyBeginResponse struct {
+	SessionID uuid.UUID   `json:"session_id"`
+	Options   interface{} `json:"options"`
+}
+
+// RequestMagicLink serves POST /auth/magic-link. It answers 202 whether or
+// not the email belongs to an account.
+func (h *PasswordlessHandler) RequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	var req MagicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("Failed to decode request payload", zap.Error(err))
+		respondWithProblem(w, r, errInvalidPayload)
+		return
+	}
+	if fields := validator.Collect(validator.ValidateEmail(req.Email)); len(fields) > 0 {
+		respondWithProblem(w, r, validationError(fields))
+		return
+	}
+
+	deviceNonce, err := h.passwordlessService.RequestMagicLink(r.Context(), req.Email)
+	if err != nil {
+		respondWithAccountError(w, r, logger, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     deviceCookie,
+		Value:    deviceNonce,
+		Path:     deviceCookiePath,
+		MaxAge:   int(h.magicLinkTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// VerifyMagicLink serves POST /auth/magic-link/verify. The link must be
+// opened in the browser that requested it.
+func (h *PasswordlessHandler) VerifyMagicLink(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	var req VerifyMagicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("Failed to decode request payload", zap.Error(err))
+		respondWithProblem(w, r, errInvalidPayload)
+		return
+	}
+	if fields := validator.Collect(validator.ValidateRequired("token", req.Token)); len(fields) > 0 {
+		respondWithProblem(w, r, validationError(fields))
+		return
+	}
+
+	var deviceNonce string
+	if cookie, err := r.Cookie(deviceCookie); err == nil {
+		deviceNonce = cookie.Value
+	}
+
+	token, err := h.passwordlessService.VerifyMagicLink(r.Context(), req.Token, deviceNonce)
+	if err != nil {
+		respondWithAccountError(w, r, logger, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     deviceCookie,
+		Path:     deviceCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	respondWithJSON(w, http.StatusOK, AuthResponse{Token: token})
+}
+
+// BeginPasskeyLogin serves POST /auth/passkeys/login/begin
+func (h *PasswordlessHandler) BeginPasskeyLogin(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	options, sessionID, err := h.passwordlessService.BeginPasskeyLogin(r.Context())
+	if err != nil {
+		respondWithAccountError(w, r, logger, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, PasskeyBeginResponse{SessionID: sessionID, Options: options})
+}
+
+// FinishPasskeyLogin serves POST /auth/passkeys/login/finish
+func (h *PasswordlessHandler) FinishPasskeyLogin(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	req, sessionID, ok := h.decodeFinishRequest(w, r)
+	if !ok {
+		return
+	}
+	response, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(req.Credential))
+	if err != nil {
+		logger.Warn("Failed to parse passkey assertion", zap.Error(err))
+		respondWithProblem(w, r, errInvalidPayload)
+		return
+	}
+
+	token, err := h.passwordlessService.FinishPasskeyLogin(r.Context(), sessionID, response)
+	if err != nil {
+		respondWithAccountError(w, r, logger, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, AuthResponse{Token: token})
+}
+
+// ListPasskeys serves GET /account/passkeys
+func (h *PasswordlessHandler) ListPasskeys(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	passkeys, err := h.passwordlessService.ListPasskeys(r.Context(), userID)
+	if err != nil {
+		respondWithAccountError(w, r, logger, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, passkeys)
+}
+
+// BeginPasskeyRegistration serves POST /account/passkeys/register/begin
+func (h *PasswordlessHandler) BeginPasskeyRegistration(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	options, sessionID, err := h.passwordlessService.BeginPasskeyRegistration(r.Context(), userID)
+	if err != nil {
+		respondWithAccountError(w, r, logger, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, PasskeyBeginResponse{SessionID: sessionID, Options: options})
+}
+
+// FinishPasskeyRegistration serves POST /account/passkeys/register/finish
+func (h *PasswordlessHandler) FinishPasskeyRegistration(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	req, sessionID, ok := h.decodeFinishRequest(w, r)
+	if !ok {
+		return
+	}
+	response, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(req.Credential))
+	if err != nil {
+		logger.Warn("Failed to parse passkey attestation", zap.Error(err))
+		respondWithProblem(w, r, errInvalidPayload)
+		return
+	}
+
+	passkey, err := h.passwordlessService.FinishPasskeyRegistration(r.Context(), userID, sessionID, req.Name, response)
+	if err != nil {
+		respondWithAccountError(w, r, logger, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, passkey)
+}
+
+// DeletePasskey serves DELETE /account/passkeys/{id}
+func (h *PasswordlessHandler) DeletePasskey(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	passkeyID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithProblem(w, r, apperrors.NewBadRequestError("Invalid passkey ID", err).WithCode(CodeInvalidPasskeyID))
+		return
+	}
+
+	if err := h.passwordlessService.DeletePasskey(r.Context(), userID, passkeyID); err != nil {
+		respondWithAccountError(w, r, logger, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeFinishRequest reads a passkey finish request, responding itself on
+// failure.
+func (h *PasswordlessHandler) decodeFinishRequest(w http.ResponseWriter, r *http.Request) (*PasskeyFinishRequest, uuid.UUID, bool) {
+	var req PasskeyFinishRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithContext(r.Context()).Warn("Failed to decode request payload", zap.Error(err))
+		respondWithProblem(w, r, errInvalidPayload)
+		return nil, uuid.Nil, false
+	}
+
+	var fields []apperrors.FieldError
+	sessionID, err := uuid.Parse(req.SessionID)
+	if err != nil {
+		fields = append(fields, apperrors.FieldError{Field: "session_id", Code: validator.CodeInvalidFormat, Message: "session_id must be a UUID"})
+	}
+	if len(req.Credential) == 0 || string(req.Credential) == "null" {
+		fields = append(fields, apperrors.FieldError{Field: "credential", Code: validator.CodeRequired, Message: "credential is required"})
+	}
+	if len(fields) > 0 {
+		respondWithProblem(w, r, validationError(fields))
+		return nil, uuid.Nil, false
+	}
+	return &req, sessionID, true
+}
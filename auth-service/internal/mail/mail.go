@@ -0,0 +1,89 @@
+// Package mail sends transactional email such as magic login links.
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"http_server/auth-service/internal/config"
+	"http_server/shared/logging"
+
+	"go.uber.org/zap"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewSender returns the sender selected by cfg.Sender: "smtp", or "log",
+// which writes messages to the log instead of sending them and is meant for
+// local development only.
+func NewSender(cfg config.MailConfig, logger *logging.Logger) (Sender, error) {
+	switch cfg.Sender {
+	case "smtp":
+		return &smtpSender{config: cfg}, nil
+	case "log":
+		return &logSender{logger: logger}, nil
+	default:
+		return nil, fmt.Errorf("unknown mail sender %q", cfg.Sender)
+	}
+}
+
+type smtpSender struct {
+	config config.MailConfig
+}
+
+func (s *smtpSender) Send(ctx context.Context, msg Message) error {
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, s.config.From, []string{msg.To}, s.compose(msg))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *smtpSender) compose(msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.config.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+type logSender struct {
+	logger *logging.Logger
+}
+
+func (s *logSender) Send(ctx context.Context, msg Message) error {
+	s.logger.WithContext(ctx).Info("Email not sent, logging instead",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body))
+	return nil
+}
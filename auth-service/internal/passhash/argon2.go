@@ -0,0 +1,115 @@
+package passhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2Params are the argon2id cost parameters. Memory is in KiB.
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+func (p Argon2Params) validate() error {
+	if p.Memory < 8*uint32(p.Parallelism) || p.Iterations < 1 || p.Parallelism < 1 {
+		return fmt.Errorf("invalid argon2id parameters m=%d,t=%d,p=%d", p.Memory, p.Iterations, p.Parallelism)
+	}
+	if p.SaltLength < 8 || p.KeyLength < 16 {
+		return fmt.Errorf("argon2id salt must be at least 8 bytes and key at least 16 bytes")
+	}
+	return nil
+}
+
+type argon2idHash struct {
+	params Argon2Params
+	key    string
+	salt   []byte
+	hash   []byte
+}
+
+// hashArgon2id encodes as $argon2id$v=19$m=65536,t=3,p=2[,keyid=k]$salt$hash
+// with unpadded standard base64.
+func hashArgon2id(input []byte, params Argon2Params, keyID string) (string, error) {
+	salt := make([]byte, params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	hash := argon2.IDKey(input, salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	encodedParams := fmt.Sprintf("m=%d,t=%d,p=%d", params.Memory, params.Iterations, params.Parallelism)
+	if keyID != "" {
+		encodedParams += ",keyid=" + keyID
+	}
+	return fmt.Sprintf("$argon2id$v=%d$%s$%s$%s",
+		argon2.Version,
+		encodedParams,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash),
+	), nil
+}
+
+func parseArgon2id(encoded string) (storedHash, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != AlgorithmArgon2id {
+		return nil, ErrUnknownFormat
+	}
+	if parts[2] != fmt.Sprintf("v=%d", argon2.Version) {
+		return nil, fmt.Errorf("%w: unsupported argon2 version %q", ErrUnknownFormat, parts[2])
+	}
+
+	params, err := parseParams(parts[3])
+	if err != nil {
+		return nil, err
+	}
+	memory, errM := strconv.ParseUint(params["m"], 10, 32)
+	iterations, errT := strconv.ParseUint(params["t"], 10, 32)
+	parallelism, errP := strconv.ParseUint(params["p"], 10, 8)
+	if errM != nil || errT != nil || errP != nil {
+		return nil, fmt.Errorf("%w: bad argon2id parameters", ErrUnknownFormat)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad salt", ErrUnknownFormat)
+	}
+	hash, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad hash", ErrUnknownFormat)
+	}
+
+	return &argon2idHash{
+		params: Argon2Params{
+			Memory:      uint32(memory),
+			Iterations:  uint32(iterations),
+			Parallelism: uint8(parallelism),
+			SaltLength:  uint32(len(salt)),
+			KeyLength:   uint32(len(hash)),
+		},
+		key:  params["keyid"],
+		salt: salt,
+		hash: hash,
+	}, nil
+}
+
+func (h *argon2idHash) matches(input []byte) bool {
+	computed := argon2.IDKey(input, h.salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+	return subtle.ConstantTimeCompare(computed, h.hash) == 1
+}
+
+func (h *argon2idHash) keyID() string {
+	return h.key
+}
+
+func (h *argon2idHash) outdated(cfg Config) bool {
+	return cfg.Algorithm != AlgorithmArgon2id || h.params != cfg.Argon2
+}
@@ -0,0 +1,77 @@
+package passhash
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type bcryptHash struct {
+	hash []byte
+	key  string
+}
+
+func validateBcryptCost(cost int) error {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	return nil
+}
+
+// isModularBcrypt matches the classic $2a$/$2b$/$2y$ encoding, used for
+// hashes without a pepper and by every hash created before this package.
+func isModularBcrypt(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+// hashBcrypt returns the classic encoding without a pepper. The classic
+// format has no room for a key ID, so peppered hashes are wrapped as
+// $bcrypt$keyid=k$<base64 of the classic encoding>.
+func hashBcrypt(input []byte, cost int, keyID string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword(input, cost)
+	if err != nil {
+		return "", err
+	}
+	if keyID == "" {
+		return string(hash), nil
+	}
+	return fmt.Sprintf("$bcrypt$keyid=%s$%s", keyID, base64.RawStdEncoding.EncodeToString(hash)), nil
+}
+
+func parseBcrypt(encoded string) (storedHash, error) {
+	if isModularBcrypt(encoded) {
+		return &bcryptHash{hash: []byte(encoded)}, nil
+	}
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[1] != AlgorithmBcrypt {
+		return nil, ErrUnknownFormat
+	}
+	params, err := parseParams(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	hash, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil || !isModularBcrypt(string(hash)) {
+		return nil, fmt.Errorf("%w: bad bcrypt hash", ErrUnknownFormat)
+	}
+	return &bcryptHash{hash: hash, key: params["keyid"]}, nil
+}
+
+func (h *bcryptHash) matches(input []byte) bool {
+	return bcrypt.CompareHashAndPassword(h.hash, input) == nil
+}
+
+func (h *bcryptHash) keyID() string {
+	return h.key
+}
+
+func (h *bcryptHash) outdated(cfg Config) bool {
+	if cfg.Algorithm != AlgorithmBcrypt {
+		return true
+	}
+	cost, err := bcrypt.Cost(h.hash)
+	return err != nil || cost != cfg.BcryptCost
+}
@@ -0,0 +1,165 @@
+// Package passhash hashes and verifies passwords. Hashes are self-describing
+// PHC strings, so parameters can change without invalidating stored hashes:
+// Verify reports when a hash should be recomputed with the current settings.
+package passhash
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unrecognized password hash format")
+	ErrUnknownPepper = errors.New("password hash uses an unknown pepper key")
+)
+
+// Hasher hashes passwords with the current settings and verifies hashes made
+// with any earlier ones.
+type Hasher interface {
+	Hash(password string) (string, error)
+	// Verify reports whether password matches encoded and, if it does,
+	// whether encoded should be replaced by a fresh Hash because it uses an
+	// outdated algorithm, cost or pepper.
+	Verify(password, encoded string) (match, needsRehash bool, err error)
+}
+
+// Config selects the algorithm and its cost. Peppers maps key IDs to
+// secrets; PepperKeyID names the one used for new hashes. Hashes made with
+// an older key stay verifiable as long as its secret is kept.
+type Config struct {
+	Algorithm   string
+	BcryptCost  int
+	Argon2      Argon2Params
+	Peppers     map[string][]byte
+	PepperKeyID string
+}
+
+type hasher struct {
+	config Config
+}
+
+func NewHasher(cfg Config) (Hasher, error) {
+	switch cfg.Algorithm {
+	case AlgorithmArgon2id:
+		if err := cfg.Argon2.validate(); err != nil {
+			return nil, err
+		}
+	case AlgorithmBcrypt:
+		if err := validateBcryptCost(cfg.BcryptCost); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", cfg.Algorithm)
+	}
+	if cfg.PepperKeyID != "" {
+		if _, ok := cfg.Peppers[cfg.PepperKeyID]; !ok {
+			return nil, fmt.Errorf("pepper key %q is not configured", cfg.PepperKeyID)
+		}
+		if !validParamValue(cfg.PepperKeyID) {
+			return nil, fmt.Errorf("pepper key ID %q may only contain letters, digits, '.', '-', '+' and '/'", cfg.PepperKeyID)
+		}
+	}
+	return &hasher{config: cfg}, nil
+}
+
+func (h *hasher) Hash(password string) (string, error) {
+	input, err := h.pepper(password, h.config.PepperKeyID)
+	if err != nil {
+		return "", err
+	}
+	if h.config.Algorithm == AlgorithmBcrypt {
+		return hashBcrypt(input, h.config.BcryptCost, h.config.PepperKeyID)
+	}
+	return hashArgon2id(input, h.config.Argon2, h.config.PepperKeyID)
+}
+
+func (h *hasher) Verify(password, encoded string) (bool, bool, error) {
+	var (
+		stored storedHash
+		err    error
+	)
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		stored, err = parseArgon2id(encoded)
+	case strings.HasPrefix(encoded, "$bcrypt$"), isModularBcrypt(encoded):
+		stored, err = parseBcrypt(encoded)
+	default:
+		return false, false, ErrUnknownFormat
+	}
+	if err != nil {
+		return false, false, err
+	}
+
+	input, err := h.pepper(password, stored.keyID())
+	if err != nil {
+		return false, false, err
+	}
+	if !stored.matches(input) {
+		return false, false, nil
+	}
+	outdated := stored.keyID() != h.config.PepperKeyID || stored.outdated(h.config)
+	return true, outdated, nil
+}
+
+// pepper returns the value actually hashed: the password itself without a
+// key, or base64(HMAC-SHA256(secret, password)) with one. Encoding the MAC
+// keeps the input printable and within bcrypt's 72-byte limit.
+func (h *hasher) pepper(password, keyID string) ([]byte, error) {
+	if keyID == "" {
+		return []byte(password), nil
+	}
+	secret, ok := h.config.Peppers[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPepper, keyID)
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(password))
+	return []byte(base64.RawStdEncoding.EncodeToString(mac.Sum(nil))), nil
+}
+
+// storedHash is a parsed hash.
+type storedHash interface {
+	matches(input []byte) bool
+	keyID() string
+	// outdated reports whether the hash was made with a different algorithm
+	// or parameters than cfg.
+	outdated(cfg Config) bool
+}
+
+// PHC parameter values are restricted to [a-zA-Z0-9/+.-].
+func validParamValue(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, c := range value {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '/', c == '+', c == '.', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseParams parses a PHC parameter list such as "m=65536,t=3,p=2".
+func parseParams(raw string) (map[string]string, error) {
+	params := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" || !validParamValue(value) {
+			return nil, fmt.Errorf("%w: bad parameter %q", ErrUnknownFormat, pair)
+		}
+		params[name] = value
+	}
+	return params, nil
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"http_server/auth-service/internal/config"
+	"http_server/auth-service/internal/domain/repository"
+	"http_server/shared/health"
+	"http_server/shared/jwtkeys"
+)
+
+// NewHealth registers the checks behind the readiness probe. The database
+// and the live signing keys are critical; Redis and outbox lag only degrade the
+// service, since logins keep working without them.
+func NewHealth(cfg *config.Config, sqlDB *sql.DB, outboxRepo repository.OutboxRepository, signingKeys *jwtkeys.Keys) *health.Registry {
+	registry := health.New(health.Config{
+		CacheTTL: cfg.Health.CacheTTL,
+		Timeout:  cfg.Health.Timeout,
+	})
+
+	registry.Register("database", health.PingChecker(sqlDB))
+	registry.Register("signing_key", health.CheckerFunc(func(ctx context.Context) error {
+		return signingKeys.Check()
+	}))
+	if cfg.Redis.Host != "" {
+		addr := net.JoinHostPort(cfg.Redis.Host, strconv.Itoa(cfg.Redis.Port))
+		registry.Register("redis", health.RedisChecker(addr, cfg.Redis.Password), health.NonCritical())
+	}
+	registry.Register("outbox_lag", outboxLagChecker(outboxRepo, cfg.Health.OutboxMaxLag), health.NonCritical())
+
+	return registry
+}
+
+// outboxLagChecker fails when the oldest unpublished event is older than
+// maxLag, which means the relay is stuck or the broker is unreachable.
+func outboxLagChecker(outboxRepo repository.OutboxRepository, maxLag time.Duration) health.Checker {
+	return health.CheckerFunc(func(ctx context.Context) error {
+		oldest, err := outboxRepo.OldestPending(ctx)
+		if err != nil {
+			return err
+		}
+		if oldest == nil {
+			return nil
+		}
+		if lag := time.Since(*oldest); lag > maxLag {
+			return fmt.Errorf("oldest pending event is %s old", lag.Round(time.Second))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"net/http"
+
+	"http_server/auth-service/internal/config"
+	"http_server/auth-service/pkg/middleware"
+	"http_server/shared/logging"
+	"http_server/shared/metrics"
+	sharedmw "http_server/shared/middleware"
+
+	"github.com/gorilla/mux"
+)
+
+// globalMiddleware returns the middleware applied to every route, in the
+// configured order. Middleware disabled in its own settings is left out.
+func globalMiddleware(cfg config.MiddlewareConfig, mw *middleware.Middleware, httpMetrics *metrics.HTTPMetrics, logger *logging.Logger) []mux.MiddlewareFunc {
+	available := map[string]mux.MiddlewareFunc{
+		config.MiddlewareRequestID: sharedmw.RequestID,
+		config.MiddlewareTracing:   sharedmw.Tracing("auth-service"),
+		config.MiddlewareLogging:   sharedmw.Logging(logger, sharedmw.SampleSuccess(cfg.AccessLog.SampleFirst, cfg.AccessLog.SampleThereafter)),
+		config.MiddlewareMetrics:   sharedmw.Metrics(httpMetrics),
+		config.MiddlewareRecovery:  sharedmw.Recovery(logger),
+		config.MiddlewareCORS:      mw.GetCORS(),
+	}
+	if security := mw.GetSecurityMiddleware(); security != nil {
+		available[config.MiddlewareSecurityHeaders] = security.SecureHeaders
+	}
+	if rateLimiter := mw.GetRateLimiter(); rateLimiter != nil {
+		available[config.MiddlewareRateLimit] = routeRateLimit(rateLimiter, cfg.Routes)
+	}
+
+	skipped := make(map[string]map[string]bool)
+	for _, route := range cfg.Routes {
+		for _, name := range route.Skip {
+			if skipped[name] == nil {
+				skipped[name] = make(map[string]bool)
+			}
+			skipped[name][route.Path] = true
+		}
+	}
+
+	var chain []mux.MiddlewareFunc
+	for _, name := range cfg.Order {
+		m, ok := available[name]
+		if !ok {
+			continue
+		}
+		if routes := skipped[name]; routes != nil {
+			m = skipOn(routes, m)
+		}
+		chain = append(chain, m)
+	}
+	return chain
+}
+
+// skipOn bypasses m on the given route templates.
+func skipOn(routes map[string]bool, m mux.MiddlewareFunc) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		wrapped := m(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if routes[sharedmw.RouteTemplate(r)] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			wrapped.ServeHTTP(w, r)
+		})
+	}
+}
+
+// routeRateLimit applies the global limiter, except on routes with limits
+// of their own, which get a separate limiter.
+func routeRateLimit(global *middleware.RateLimiter, routes []config.RouteMiddlewareConfig) mux.MiddlewareFunc {
+	limiters := make(map[string]*middleware.RateLimiter)
+	for _, route := range routes {
+		if limit := route.RateLimit; limit.RequestsPerMinute > 0 {
+			limiters[route.Path] = middleware.NewRateLimiter(limit.RequestsPerMinute, limit.BurstSize)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limiter, ok := limiters[sharedmw.RouteTemplate(r)]
+			if !ok {
+				limiter = global
+			}
+			limiter.Middleware(next).ServeHTTP(w, r)
+		})
+	}
+}
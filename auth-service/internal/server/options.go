@@ -1,19 +0,0 @@
-package server
-
-import "time"
-
-type Options struct {
-	Port         int           `yaml:"port"`
-	ReadTimeout  time.Duration `yaml:"read_timeout"`
-	WriteTimeout time.Duration `yaml:"write_timeout"`
-	IdleTimeout  time.Duration `yaml:"idle_timeout"`
-}
-
-func DefaultOptions() Options {
-	return Options{
-		Port: 8080,
-		ReadTimeout: 15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout: 15 * time.Second,
-	}
-}
@@ -1,39 +1,48 @@
 package server
 
 import (
-	"log"
 	"net/http"
 
+	"http_server/auth-service/internal/config"
 	"http_server/auth-service/internal/handler"
 	"http_server/auth-service/pkg/middleware"
-	handlers "github.com/gorilla/handlers"
+	"http_server/shared/health"
+	"http_server/shared/logging"
+	"http_server/shared/metrics"
+	"http_server/shared/problem"
+
 	"github.com/gorilla/mux"
 )
 
-func NewRouter(authHandler *handler.AuthHandler, authMiddleware *middleware.AuthMiddleware) *mux.Router {
+func NewRouter(cfg config.MiddlewareConfig, authHandler *handler.AuthHandler, accountHandler *handler.AccountHandler, passwordlessHandler *handler.PasswordlessHandler, healthRegistry *health.Registry, mw *middleware.Middleware, httpMetrics *metrics.HTTPMetrics, logger *logging.Logger) *mux.Router {
 	r := mux.NewRouter()
+	r.NotFoundHandler = problem.NotFoundHandler()
+	r.MethodNotAllowedHandler = problem.MethodNotAllowedHandler()
+
+	// Request ID, tracing, access logging, metrics, panic recovery, CORS,
+	// security headers and rate limiting, in the configured order
+	r.Use(globalMiddleware(cfg, mw, httpMetrics, logger)...)
 
-	// Add logging middleware
-	r.Use(func(next http.Handler) http.Handler {
-		return handlers.LoggingHandler(log.Writer(), next)
+	// Let CORS preflight requests match a route so the middleware sees them
+	r.Methods(http.MethodOptions).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
 	})
 
-	// Add CORS middleware
-	corsMiddleware := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-		handlers.ExposedHeaders([]string{"Content-Length"}),
-		handlers.AllowCredentials(),
-	)
-	r.Use(corsMiddleware)
-
-	// Health check
-	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"OK"}`)) // Return JSON response
-	}).Methods("GET")
+	// Health probes; /health is kept as an alias of /readyz for existing
+	// callers. The detailed report is served on the metrics port.
+	r.Handle("/livez", healthRegistry.LiveHandler()).Methods("GET")
+	r.Handle("/readyz", healthRegistry.ReadyHandler()).Methods("GET")
+	r.Handle("/health", healthRegistry.ReadyHandler()).Methods("GET")
+
+	// Internal routes, only for services presenting a known client
+	// certificate. Without mutual TLS they are not served at all.
+	if machineAuth := mw.GetMachineAuthMiddleware(); machineAuth != nil {
+		internal := r.PathPrefix("/internal/v1").Subrouter()
+		internal.Use(machineAuth.RequireClientCert)
+		internal.HandleFunc("/users/{id}", authHandler.GetUserProfile).Methods("GET")
+	} else {
+		logger.Warn("Internal API disabled, it needs mutual TLS (server.tls.client_ca_file)")
+	}
 
 	// API routes
 	api := r.PathPrefix("/api/v1").Subrouter()
@@ -41,11 +50,29 @@ func NewRouter(authHandler *handler.AuthHandler, authMiddleware *middleware.Auth
 	// Public routes
 	api.HandleFunc("/auth/register", authHandler.Register).Methods("POST")
 	api.HandleFunc("/auth/login", authHandler.Login).Methods("POST")
+	api.HandleFunc("/account/reactivate", accountHandler.Reactivate).Methods("POST")
+	api.HandleFunc("/auth/magic-link", passwordlessHandler.RequestMagicLink).Methods("POST")
+	api.HandleFunc("/auth/magic-link/verify", passwordlessHandler.VerifyMagicLink).Methods("POST")
+	api.HandleFunc("/auth/passkeys/login/begin", passwordlessHandler.BeginPasskeyLogin).Methods("POST")
+	api.HandleFunc("/auth/passkeys/login/finish", passwordlessHandler.FinishPasskeyLogin).Methods("POST")
 
 	// Protected routes
 	protected := api.PathPrefix("/auth").Subrouter()
-	protected.Use(authMiddleware.ValidateJWT)
+	protected.Use(mw.GetAuthMiddleware().ValidateJWT)
 	protected.HandleFunc("/validate", authHandler.ValidateToken).Methods("GET")
 
+	// Account lifecycle
+	account := api.PathPrefix("/account").Subrouter()
+	account.Use(mw.GetAuthMiddleware().ValidateJWT)
+	account.HandleFunc("", accountHandler.Status).Methods("GET")
+	account.HandleFunc("", accountHandler.Delete).Methods("DELETE")
+	account.HandleFunc("/password", accountHandler.ChangePassword).Methods("POST")
+	account.HandleFunc("/deactivate", accountHandler.Deactivate).Methods("POST")
+	account.HandleFunc("/export", accountHandler.Export).Methods("GET")
+	account.HandleFunc("/passkeys", passwordlessHandler.ListPasskeys).Methods("GET")
+	account.HandleFunc("/passkeys/register/begin", passwordlessHandler.BeginPasskeyRegistration).Methods("POST")
+	account.HandleFunc("/passkeys/register/finish", passwordlessHandler.FinishPasskeyRegistration).Methods("POST")
+	account.HandleFunc("/passkeys/{id}", passwordlessHandler.DeletePasskey).Methods("DELETE")
+
 	return r
 }
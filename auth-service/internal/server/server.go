@@ -1,36 +1,76 @@
 package server
 
 import (
-	"context"
-	"fmt"
 	"net/http"
+	"time"
 
 	"http_server/auth-service/internal/config"
 	"http_server/auth-service/internal/handler"
 	"http_server/auth-service/pkg/middleware"
+	"http_server/shared/health"
+	"http_server/shared/logging"
+	"http_server/shared/metrics"
+	sharedserver "http_server/shared/server"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-type Server struct {
-	httpServer *http.Server
-}
+func NewServer(cfg *config.Config, authHandler *handler.AuthHandler, accountHandler *handler.AccountHandler, passwordlessHandler *handler.PasswordlessHandler, healthRegistry *health.Registry, mw *middleware.Middleware, httpMetrics *metrics.HTTPMetrics, logger *logging.Logger) *sharedserver.Server {
+	router := NewRouter(cfg.Server.Middleware, authHandler, accountHandler, passwordlessHandler, healthRegistry, mw, httpMetrics, logger)
 
-func NewServer(cfg *config.Config, authHandler *handler.AuthHandler, authMiddleware *middleware.AuthMiddleware) *Server {
-	router := NewRouter(authHandler, authMiddleware)
+	return sharedserver.New(sharedserver.Config{
+		Port:            cfg.Server.Port,
+		ReadTimeout:     cfg.Server.ReadTimeout,
+		WriteTimeout:    cfg.Server.WriteTimeout,
+		ShutdownTimeout: cfg.Server.ShutdownTimeout,
+		DrainDelay:      cfg.Server.DrainDelay,
+		OnDrain:         healthRegistry.SetDraining,
+		TLS:             NewTLSConfig(cfg.Server.TLS),
+	}, router, logger)
+}
 
-	return &Server{
-		httpServer: &http.Server{
-			Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
-			Handler:      router,
-			ReadTimeout:  cfg.Server.ReadTimeout,
-			WriteTimeout: cfg.Server.WriteTimeout,
-		},
+// NewTLSConfig returns nil when TLS is disabled.
+func NewTLSConfig(cfg config.TLSConfig) *sharedserver.TLSConfig {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &sharedserver.TLSConfig{
+		CertFile:       cfg.CertFile,
+		KeyFile:        cfg.KeyFile,
+		MinVersion:     cfg.MinVersion,
+		CipherSuites:   cfg.CipherSuites,
+		ClientCAFile:   cfg.ClientCAFile,
+		ClientAuth:     cfg.ClientAuth,
+		ReloadInterval: cfg.ReloadInterval,
 	}
 }
 
-func (s *Server) Run() error {
-	return s.httpServer.ListenAndServe()
+// NewMetricsServer serves the metrics in gatherer and the overall health
+// status on the metrics port, apart from the API.
+func NewMetricsServer(cfg config.MetricsConfig, gatherer prometheus.Gatherer, healthRegistry *health.Registry, logger *logging.Logger) *sharedserver.Server {
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Path, metrics.Handler(gatherer))
+	mux.Handle("/health", healthRegistry.ReadyHandler())
+
+	return sharedserver.New(sharedserver.Config{
+		Port:         cfg.Port,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}, mux, logger)
 }
 
-func (s *Server) Shutdown(ctx context.Context) error {
-	return s.httpServer.Shutdown(ctx)
+// NewAdminServer serves the operator endpoints, the detailed health report
+// and the log level, on the admin address, which only accepts local
+// connections.
+func NewAdminServer(cfg config.AdminConfig, healthRegistry *health.Registry, logger *logging.Logger) *sharedserver.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/health", healthRegistry.ReportHandler())
+	mux.Handle("/admin/log-level", logger.LevelHandler())
+
+	return sharedserver.New(sharedserver.Config{
+		Host:         cfg.Host,
+		Port:         cfg.Port,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}, mux, logger)
 }
@@ -0,0 +1,431 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"http_server/auth-service/internal/config"
+	"http_server/auth-service/internal/domain/models"
+	"http_server/auth-service/internal/domain/repository"
+	"http_server/auth-service/internal/events"
+	"http_server/auth-service/internal/passhash"
+	"http_server/auth-service/internal/validator"
+	"http_server/shared/logging"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrAccountInactive        = errors.New("account is deactivated")
+	ErrAccountAlreadyInactive = errors.New("account is already deactivated")
+	ErrPasswordUnchanged      = errors.New("new password must differ from the current one")
+)
+
+// maxDeactivationReasonLength bounds the free-text reason users may give.
+const maxDeactivationReasonLength = 500
+
+// AccountStatus describes where an account is in its lifecycle.
+type AccountStatus struct {
+	Active             bool       `json:"active"`
+	DeactivatedAt      *time.Time `json:"deactivated_at,omitempty"`
+	DeactivationReason string     `json:"deactivation_reason,omitempty"`
+	DeletionRequested  bool       `json:"deletion_requested"`
+	PurgeAfter         *time.Time `json:"purge_after,omitempty"`
+}
+
+// AccountExport is everything stored about a user, minus credentials.
+type AccountExport struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	User        ExportedUser   `json:"user"`
+	Roles       []ExportedRole `json:"roles"`
+}
+
+type ExportedUser struct {
+	ID                 uuid.UUID  `json:"id"`
+	Email              string     `json:"email"`
+	Name               string     `json:"name"`
+	EmailVerified      bool       `json:"email_verified"`
+	PhoneNumber        string     `json:"phone_number,omitempty"`
+	ProfilePicture     string     `json:"profile_picture,omitempty"`
+	Bio                string     `json:"bio,omitempty"`
+	DateOfBirth        *time.Time `json:"date_of_birth,omitempty"`
+	Location           string     `json:"location,omitempty"`
+	Active             bool       `json:"active"`
+	TwoFactorEnabled   bool       `json:"two_factor_enabled"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	LastLoginAt        time.Time  `json:"last_login_at"`
+	LastActivityAt     time.Time  `json:"last_activity_at"`
+	VerifiedAt         *time.Time `json:"verified_at,omitempty"`
+	DeactivatedAt      *time.Time `json:"deactivated_at,omitempty"`
+	DeactivationReason string     `json:"deactivation_reason,omitempty"`
+	PurgeAfter         *time.Time `json:"purge_after,omitempty"`
+}
+
+type ExportedRole struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type AccountService interface {
+	Status(ctx context.Context, userID uuid.UUID) (*AccountStatus, error)
+	// ChangePassword replaces the password after checking the current one
+	// and the new one against the password policy.
+	ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error
+	// Deactivate disables login until the user reactivates. The password
+	// confirms the request.
+	Deactivate(ctx context.Context, userID uuid.UUID, password, reason string) error
+	// Reactivate re-enables a deactivated account, cancelling a pending
+	// deletion. It takes credentials because deactivated users cannot log in.
+	Reactivate(ctx context.Context, email, password string) error
+	// RequestDeletion deactivates the account and schedules it for permanent
+	// deletion after the grace period, returning when that will happen.
+	RequestDeletion(ctx context.Context, userID uuid.UUID, password string) (time.Time, error)
+	Export(ctx context.Context, userID uuid.UUID) (*AccountExport, error)
+
+	// PurgeDeleted permanently removes accounts whose grace period is over and
+	// returns how many were removed.
+	PurgeDeleted(ctx context.Context) (int, error)
+	// RunPurger calls PurgeDeleted every interval until ctx is cancelled.
+	RunPurger(ctx context.Context)
+}
+
+type accountService struct {
+	userRepo    repository.UserRepository
+	roleRepo    repository.RoleRepository
+	outbox      repository.OutboxRepository
+	tx          repository.Transactor
+	config      config.AccountConfig
+	passwords   *validator.PasswordValidator
+	hasher      passhash.Hasher
+	credentials *credentialChecker
+	logger      *logging.Logger
+}
+
+func NewAccountService(userRepo repository.UserRepository, roleRepo repository.RoleRepository, outbox repository.OutboxRepository, tx repository.Transactor, cfg config.AccountConfig, passwords *validator.PasswordValidator, hasher passhash.Hasher, logger *logging.Logger) AccountService {
+	return &accountService{
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		outbox:      outbox,
+		tx:          tx,
+		config:      cfg,
+		passwords:   passwords,
+		hasher:      hasher,
+		credentials: newCredentialChecker(userRepo, outbox, tx, cfg, hasher, logger),
+		logger:      logger,
+	}
+}
+
+// save stores user and records e in the same transaction.
+func (s *accountService) save(ctx context.Context, user *models.User, e events.Event) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Save(ctx, user); err != nil {
+			return err
+		}
+		return recordEvent(ctx, s.outbox, e)
+	})
+}
+
+func (s *accountService) Status(ctx context.Context, userID uuid.UUID) (*AccountStatus, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountStatus{
+		Active:             user.Active,
+		DeactivatedAt:      user.DeactivatedAt,
+		DeactivationReason: user.DeactivationReason,
+		DeletionRequested:  user.PurgeAfter != nil,
+		PurgeAfter:         user.PurgeAfter,
+	}, nil
+}
+
+func (s *accountService) ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error {
+	logger := s.logger.WithContext(ctx)
+
+	user, err := s.confirmPassword(ctx, userID, currentPassword)
+	if err != nil {
+		return err
+	}
+	if currentPassword == newPassword {
+		return ErrPasswordUnchanged
+	}
+	if err := s.passwords.Validate(ctx, newPassword, user.Email, user.Name); err != nil {
+		logger.Warn("Password rejected by policy", zap.String("user_id", userID.String()), zap.Error(err))
+		return err
+	}
+
+	hashedPassword, err := hashPassword(ctx, s.hasher, newPassword)
+	if err != nil {
+		logger.Error("Failed to hash password", err, zap.String("user_id", userID.String()))
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user.Password = hashedPassword
+	if err := s.save(ctx, user, events.UserPasswordChanged{ID: user.ID}); err != nil {
+		logger.Error("Failed to change password", err, zap.String("user_id", userID.String()))
+		return fmt.Errorf("failed to change password: %w", err)
+	}
+
+	logger.Info("Password changed", zap.String("user_id", userID.String()))
+	return nil
+}
+
+func (s *accountService) Deactivate(ctx context.Context, userID uuid.UUID, password, reason string) error {
+	logger := s.logger.WithContext(ctx)
+
+	user, err := s.confirmPassword(ctx, userID, password)
+	if err != nil {
+		return err
+	}
+	if !user.Active {
+		return ErrAccountAlreadyInactive
+	}
+
+	reason = strings.TrimSpace(reason)
+	if runes := []rune(reason); len(runes) > maxDeactivationReasonLength {
+		reason = string(runes[:maxDeactivationReasonLength])
+	}
+
+	now := time.Now().UTC()
+	user.Active = false
+	user.DeactivatedAt = &now
+	user.DeactivationReason = reason
+	user.StatusChangedAt = now
+	user.SessionVersion++
+	if err := s.save(ctx, user, events.UserDeactivated{ID: user.ID, Reason: reason}); err != nil {
+		logger.Error("Failed to deactivate user", err, zap.String("user_id", userID.String()))
+		return fmt.Errorf("failed to deactivate user: %w", err)
+	}
+
+	logger.Info("User deactivated", zap.String("user_id", userID.String()))
+	return nil
+}
+
+func (s *accountService) Reactivate(ctx context.Context, email, password string) error {
+	logger := s.logger.WithContext(ctx)
+
+	// Active accounts get the same error as wrong credentials, so this
+	// endpoint tells callers nothing Login would not
+	user, err := s.credentials.check(ctx, email, password)
+	if err != nil {
+		return err
+	}
+	if user.Active {
+		logger.Warn("Reactivation of active account", zap.String("user_id", user.ID.String()))
+		return ErrInvalidCredentials
+	}
+
+	now := time.Now().UTC()
+	user.Active = true
+	user.DeactivatedAt = nil
+	user.DeactivationReason = ""
+	user.DeletedAt = nil
+	user.PurgeAfter = nil
+	user.StatusChangedAt = now
+	if err := s.save(ctx, user, events.UserReactivated{ID: user.ID}); err != nil {
+		logger.Error("Failed to reactivate user", err, zap.String("user_id", user.ID.String()))
+		return fmt.Errorf("failed to reactivate user: %w", err)
+	}
+
+	logger.Info("User reactivated", zap.String("user_id", user.ID.String()))
+	return nil
+}
+
+func (s *accountService) RequestDeletion(ctx context.Context, userID uuid.UUID, password string) (time.Time, error) {
+	logger := s.logger.WithContext(ctx)
+
+	user, err := s.confirmPassword(ctx, userID, password)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	now := time.Now().UTC()
+	purgeAfter := now.Add(s.config.DeletionGracePeriod)
+	user.Active = false
+	if user.DeactivatedAt == nil {
+		user.DeactivatedAt = &now
+	}
+	user.DeactivationReason = "deletion requested"
+	user.DeletedAt = &now
+	user.PurgeAfter = &purgeAfter
+	user.StatusChangedAt = now
+	user.SessionVersion++
+	if err := s.save(ctx, user, events.UserDeletionRequested{ID: user.ID, PurgeAfter: purgeAfter}); err != nil {
+		logger.Error("Failed to schedule user deletion", err, zap.String("user_id", userID.String()))
+		return time.Time{}, fmt.Errorf("failed to schedule deletion: %w", err)
+	}
+
+	logger.Info("User deletion scheduled",
+		zap.String("user_id", userID.String()),
+		zap.Time("purge_after", purgeAfter))
+	return purgeAfter, nil
+}
+
+func (s *accountService) Export(ctx context.Context, userID uuid.UUID) (*AccountExport, error) {
+	logger := s.logger.WithContext(ctx)
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.roleRepo.GetUserRoles(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to get user roles", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	export := &AccountExport{
+		GeneratedAt: time.Now().UTC(),
+		User: ExportedUser{
+			ID:                 user.ID,
+			Email:              user.Email,
+			Name:               user.Name,
+			EmailVerified:      user.EmailVerified,
+			PhoneNumber:        user.PhoneNumber,
+			ProfilePicture:     user.ProfilePicture,
+			Bio:                user.Bio,
+			DateOfBirth:        user.DateOfBirth,
+			Location:           user.Location,
+			Active:             user.Active,
+			TwoFactorEnabled:   user.TwoFactorEnabled,
+			CreatedAt:          user.CreatedAt,
+			UpdatedAt:          user.UpdatedAt,
+			LastLoginAt:        user.LastLoginAt,
+			LastActivityAt:     user.LastActivityAt,
+			VerifiedAt:         user.VerifiedAt,
+			DeactivatedAt:      user.DeactivatedAt,
+			DeactivationReason: user.DeactivationReason,
+			PurgeAfter:         user.PurgeAfter,
+		},
+		Roles: make([]ExportedRole, len(roles)),
+	}
+	for i, role := range roles {
+		export.Roles[i] = ExportedRole{Name: role.Name, Description: role.Description}
+	}
+
+	logger.Info("User data exported", zap.String("user_id", userID.String()))
+	return export, nil
+}
+
+// WriteExportArchive writes export as a zip archive holding account.json and
+// roles.json.
+func WriteExportArchive(w io.Writer, export *AccountExport) error {
+	archive := zip.NewWriter(w)
+
+	files := []struct {
+		name    string
+		payload interface{}
+	}{
+		{"account.json", struct {
+			GeneratedAt time.Time    `json:"generated_at"`
+			User        ExportedUser `json:"user"`
+		}{export.GeneratedAt, export.User}},
+		{"roles.json", export.Roles},
+	}
+	for _, file := range files {
+		f, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.GeneratedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", file.name, err)
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.payload); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+	}
+
+	return archive.Close()
+}
+
+func (s *accountService) PurgeDeleted(ctx context.Context) (int, error) {
+	logger := s.logger.WithContext(ctx)
+	now := time.Now().UTC()
+
+	users, err := s.userRepo.ListPurgeable(ctx, now, s.config.PurgeBatchSize)
+	if err != nil {
+		logger.Error("Failed to list purgeable users", err)
+		return 0, fmt.Errorf("failed to list purgeable users: %w", err)
+	}
+
+	purged := 0
+	for _, user := range users {
+		err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := s.userRepo.Purge(ctx, user.ID, now); err != nil {
+				return err
+			}
+			return recordEvent(ctx, s.outbox, events.UserDeleted{ID: user.ID})
+		})
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			logger.Error("Failed to purge user", err, zap.String("user_id", user.ID.String()))
+			continue
+		}
+		purged++
+		logger.Info("User purged", zap.String("user_id", user.ID.String()))
+	}
+	return purged, nil
+}
+
+func (s *accountService) RunPurger(ctx context.Context) {
+	ticker := time.NewTicker(s.config.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.PurgeDeleted(ctx)
+		}
+	}
+}
+
+// findUser returns the user unless it does not exist or deletion was
+// requested for it.
+func (s *accountService) findUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		s.logger.WithContext(ctx).Error("Failed to find user", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if user.DeletedAt != nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// confirmPassword checks the logged in user's password through the
+// credential checker, so wrong guesses count towards the lockout just as
+// they do at login and a stolen session cannot try passwords freely.
+func (s *accountService) confirmPassword(ctx context.Context, userID uuid.UUID, password string) (*models.User, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	checked, err := s.credentials.check(ctx, user.Email, password)
+	if err != nil {
+		return nil, err
+	}
+	// The email may have moved to another account since the lookup
+	if checked.ID != userID || checked.DeletedAt != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return checked, nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"http_server/auth-service/internal/config"
+	"http_server/auth-service/internal/domain/models"
+	"http_server/auth-service/internal/domain/repository"
+	"http_server/auth-service/internal/passhash"
+	"http_server/shared/jwtkeys"
+	"http_server/shared/logging"
+
+	"github.com/google/uuid"
+)
+
+func (f *fakeUsers) RecordFailedLogin(ctx context.Context, id uuid.UUID) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.users[id].FailedLoginAttempts++
+	return f.users[id].FailedLoginAttempts, nil
+}
+
+func (f *fakeUsers) Lock(ctx context.Context, id uuid.UUID, until time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.users[id].LockedUntil = &until
+	f.users[id].FailedLoginAttempts = 0
+	return nil
+}
+
+func (f *fakeUsers) ResetFailedLogins(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.users[id].FailedLoginAttempts = 0
+	f.users[id].LockedUntil = nil
+	return nil
+}
+
+type fakeOutbox struct {
+	repository.OutboxRepository
+}
+
+func (fakeOutbox) Add(ctx context.Context, events ...*models.OutboxEvent) error {
+	return nil
+}
+
+const testPassword = "correct horse battery staple"
+
+type accountFixture struct {
+	accounts *accountService
+	auth     *authService
+	users    *fakeUsers
+	user     *models.User
+}
+
+func newAccountFixture(t *testing.T) *accountFixture {
+	t.Helper()
+	logger, err := logging.NewLogger(&logging.Config{ServiceName: "test", LogLevel: "error", FilePath: "stderr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hasher, err := passhash.NewHasher(passhash.Config{Algorithm: passhash.AlgorithmBcrypt, BcryptCost: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := hasher.Hash(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.User{ID: uuid.New(), Email: "someone@example.com", Name: "Someone", Password: hash, Active: true}
+	users := &fakeUsers{users: map[uuid.UUID]*models.User{user.ID: user}}
+	account := config.AccountConfig{DeletionGracePeriod: 24 * time.Hour, LockoutThreshold: 3, LockoutDuration: time.Hour}
+	return &accountFixture{
+		accounts: NewAccountService(users, fakeRoles{}, fakeOutbox{}, fakeTransactor{}, account, nil, hasher, logger).(*accountService),
+		auth:     NewAuthService(users, fakeRoles{}, fakeOutbox{}, fakeTransactor{}, account, nil, hasher, jwtkeys.New([]byte("signing-key")), logger).(*authService),
+		users:    users,
+		user:     user,
+	}
+}
+
+func TestAccountOperationsCountTowardsLockout(t *testing.T) {
+	operations := []struct {
+		name string
+		call func(f *accountFixture, password string) error
+	}{
+		{"ChangePassword", func(f *accountFixture, password string) error {
+			return f.accounts.ChangePassword(context.Background(), f.user.ID, password, "a different passphrase")
+		}},
+		{"Deactivate", func(f *accountFixture, password string) error {
+			return f.accounts.Deactivate(context.Background(), f.user.ID, password, "")
+		}},
+		{"RequestDeletion", func(f *accountFixture, password string) error {
+			_, err := f.accounts.RequestDeletion(context.Background(), f.user.ID, password)
+			return err
+		}},
+	}
+	for _, op := range operations {
+		t.Run(op.name, func(t *testing.T) {
+			f := newAccountFixture(t)
+			for i := 0; i < 3; i++ {
+				if err := op.call(f, "wrong password"); !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("attempt %d = %v, want ErrInvalidCredentials", i+1, err)
+				}
+			}
+			// Locked now, so even the right password is refused
+			if err := op.call(f, testPassword); !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("with the right password while locked = %v, want ErrInvalidCredentials", err)
+			}
+			if saved, _ := f.users.FindByID(context.Background(), f.user.ID); saved.LockedUntil == nil {
+				t.Error("account not locked")
+			}
+		})
+	}
+}
+
+func TestDeactivationAndDeletionRevokeTokens(t *testing.T) {
+	revoke := []struct {
+		name string
+		call func(f *accountFixture) error
+	}{
+		{"Deactivate", func(f *accountFixture) error {
+			return f.accounts.Deactivate(context.Background(), f.user.ID, testPassword, "")
+		}},
+		{"RequestDeletion", func(f *accountFixture) error {
+			_, err := f.accounts.RequestDeletion(context.Background(), f.user.ID, testPassword)
+			return err
+		}},
+	}
+	for _, tt := range revoke {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAccountFixture(t)
+			ctx := context.Background()
+			token, err := f.auth.tokens.issue(ctx, f.user)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.auth.ValidateToken(ctx, token); err != nil {
+				t.Fatalf("ValidateToken before revoking: %v", err)
+			}
+
+			if err := tt.call(f); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.auth.ValidateToken(ctx, token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("ValidateToken after %s = %v, want ErrInvalidToken", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestReactivatedUserNeedsNewToken(t *testing.T) {
+	f := newAccountFixture(t)
+	ctx := context.Background()
+	old, err := f.auth.tokens.issue(ctx, f.user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.accounts.Deactivate(ctx, f.user.ID, testPassword, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.accounts.Reactivate(ctx, f.user.Email, testPassword); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.auth.ValidateToken(ctx, old); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ValidateToken with a token from before deactivation = %v, want ErrInvalidToken", err)
+	}
+	fresh, err := f.auth.Login(ctx, f.user.Email, testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.auth.ValidateToken(ctx, fresh); err != nil {
+		t.Errorf("ValidateToken with a new token: %v", err)
+	}
+}
+
+func TestAccountHidesUsersPendingDeletion(t *testing.T) {
+	f := newAccountFixture(t)
+	ctx := context.Background()
+	if _, err := f.accounts.RequestDeletion(ctx, f.user.ID, testPassword); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.accounts.Status(ctx, f.user.ID); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Status = %v, want ErrUserNotFound", err)
+	}
+	if _, err := f.accounts.Export(ctx, f.user.ID); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Export = %v, want ErrUserNotFound", err)
+	}
+	if err := f.accounts.Deactivate(ctx, f.user.ID, testPassword, ""); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Deactivate = %v, want ErrUserNotFound", err)
+	}
+}
@@ -6,14 +6,18 @@ import (
 	"fmt"
 	"time"
 
+	"http_server/auth-service/internal/config"
 	"http_server/auth-service/internal/domain/models"
 	"http_server/auth-service/internal/domain/repository"
-	"http_server/auth-service/pkg/logging"
+	"http_server/auth-service/internal/events"
+	"http_server/auth-service/internal/passhash"
+	"http_server/auth-service/internal/validator"
+	"http_server/shared/jwtkeys"
+	"http_server/shared/logging"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 )
 
 var (
@@ -26,30 +30,49 @@ var (
 	ErrUserNotFound        = errors.New("user not found")
 	ErrInvalidPassword     = errors.New("invalid password format")
 	ErrInvalidEmail        = errors.New("invalid email format")
+	ErrAccountLocked       = errors.New("account is temporarily locked")
 )
 
 type AuthService interface {
 	Register(ctx context.Context, email, password, name string) (*models.User, error)
 	Login(ctx context.Context, email, password string) (string, error)
-	ValidateToken(token string) (*jwt.Token, error)
+	// ValidateToken checks the token's signature and expiry, and that its
+	// session was not revoked by deactivating or deleting the account.
+	ValidateToken(ctx context.Context, token string) (*jwt.Token, error)
 	AssignRole(ctx context.Context, userID uuid.UUID, roleName string) error
 	GetUserRoles(ctx context.Context, userID uuid.UUID) ([]string, error)
 	RemoveRole(ctx context.Context, userID uuid.UUID, roleName string) error
+	// CheckPermission reports whether any of the user's roles grants
+	// permission.
+	CheckPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error)
+	GetUser(ctx context.Context, userID uuid.UUID) (*models.User, error)
 }
 
 type authService struct {
-	userRepo repository.UserRepository
-	roleRepo repository.RoleRepository
-	jwtKey   []byte
-	logger   *logging.Logger
+	userRepo    repository.UserRepository
+	roleRepo    repository.RoleRepository
+	outbox      repository.OutboxRepository
+	tx          repository.Transactor
+	passwords   *validator.PasswordValidator
+	hasher      passhash.Hasher
+	keys        *jwtkeys.Keys
+	tokens      *tokenIssuer
+	credentials *credentialChecker
+	logger      *logging.Logger
 }
 
-func NewAuthService(userRepo repository.UserRepository, roleRepo repository.RoleRepository, jwtKey []byte, logger *logging.Logger) AuthService {
+func NewAuthService(userRepo repository.UserRepository, roleRepo repository.RoleRepository, outbox repository.OutboxRepository, tx repository.Transactor, account config.AccountConfig, passwords *validator.PasswordValidator, hasher passhash.Hasher, keys *jwtkeys.Keys, logger *logging.Logger) AuthService {
 	return &authService{
-		userRepo: userRepo,
-		roleRepo: roleRepo,
-		jwtKey:   jwtKey,
-		logger:   logger,
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		outbox:      outbox,
+		tx:          tx,
+		passwords:   passwords,
+		hasher:      hasher,
+		keys:        keys,
+		tokens:      &tokenIssuer{roleRepo: roleRepo, keys: keys},
+		credentials: newCredentialChecker(userRepo, outbox, tx, account, hasher, logger),
+		logger:      logger,
 	}
 }
 
@@ -57,42 +80,61 @@ func (s *authService) Register(ctx context.Context, email, password, name string
 	logger := s.logger.WithContext(ctx)
 	logger.Info("Starting user registration", zap.String("email", email))
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err := s.passwords.Validate(ctx, password, email, name); err != nil {
+		logger.Warn("Password rejected by policy", zap.String("email", email), zap.Error(err))
+		return nil, err
+	}
+
+	hashedPassword, err := hashPassword(ctx, s.hasher, password)
 	if err != nil {
-		logger.Error("Failed to hash password", err, zap.String("error", err.Error()))
+		logger.Error("Failed to hash password", err)
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
 	user := &models.User{
+		ID:       uuid.New(),
 		Email:    email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		Name:     name,
 	}
 
-	if err := s.userRepo.Create(ctx, user); err != nil {
-		if errors.Is(err, repository.ErrDuplicateKey) {
-			logger.Warn("Attempted to register existing user", zap.String("email", email))
-			return nil, ErrUserExists
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			if errors.Is(err, repository.ErrDuplicateKey) {
+				return ErrUserExists
+			}
+			return fmt.Errorf("failed to create user: %w", err)
 		}
-		logger.Error("Failed to create user", err, zap.String("email", email))
-		return nil, fmt.Errorf("failed to create user: %w", err)
-	}
 
-	// Assign default user role
-	defaultRole, err := s.roleRepo.FindByName(ctx, models.RoleUser)
-	if err != nil {
-		logger.Error("Failed to find default role", err)
-		return nil, fmt.Errorf("failed to find default role: %w", err)
-	}
+		// Assign default user role
+		defaultRole, err := s.roleRepo.FindByName(ctx, models.RoleUser)
+		if err != nil {
+			return fmt.Errorf("failed to find default role: %w", err)
+		}
 
-	userRole := &models.UserRole{
-		UserID: user.ID,
-		RoleID: defaultRole.ID,
-	}
+		userRole := &models.UserRole{
+			ID:     uuid.New(),
+			UserID: user.ID,
+			RoleID: defaultRole.ID,
+		}
+		if err := s.roleRepo.AssignRoleToUser(ctx, userRole); err != nil {
+			return fmt.Errorf("failed to assign default role: %w", err)
+		}
 
-	if err := s.roleRepo.AssignRoleToUser(ctx, userRole); err != nil {
-		logger.Error("Failed to assign default role", err)
-		return nil, fmt.Errorf("failed to assign default role: %w", err)
+		return recordEvent(ctx, s.outbox, events.UserRegistered{
+			ID:    user.ID,
+			Email: user.Email,
+			Name:  user.Name,
+			Roles: []string{defaultRole.Name},
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrUserExists) {
+			logger.Warn("Attempted to register existing user", zap.String("email", email))
+			return nil, err
+		}
+		logger.Error("Failed to register user", err, zap.String("email", email))
+		return nil, err
 	}
 
 	logger.Info("User registered successfully", zap.String("user_id", user.ID.String()), zap.String("email", user.Email))
@@ -103,48 +145,30 @@ func (s *authService) Login(ctx context.Context, email, password string) (string
 	logger := s.logger.WithContext(ctx)
 	logger.Info("Attempting user login", zap.String("email", email))
 
-	user, err := s.userRepo.FindByEmail(ctx, email)
+	user, err := s.credentials.check(ctx, email, password)
 	if err != nil {
-		if errors.Is(err, repository.ErrNotFound) {
-			logger.Warn("User not found during login", zap.String("email", email))
-			return "", ErrInvalidCredentials
-		}
-		logger.Error("Failed to find user", err, zap.String("email", email))
-		return "", fmt.Errorf("failed to find user: %w", err)
+		return "", err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		logger.Warn("Invalid password attempt", zap.String("email", email))
-		return "", ErrInvalidCredentials
+	// Checked after the password so the response does not reveal whether a
+	// deactivated account exists.
+	if !user.Active || user.DeletedAt != nil {
+		logger.Warn("Login attempt on inactive account", zap.String("user_id", user.ID.String()))
+		return "", ErrAccountInactive
 	}
 
-	// Get user roles for token
-	userRoles, err := s.GetUserRoles(ctx, user.ID)
+	tokenString, err := s.tokens.issue(ctx, user)
 	if err != nil {
-		logger.Error("Failed to get user roles", err)
-		return "", fmt.Errorf("failed to get user roles: %w", err)
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID.String(),
-		"email":   user.Email,
-		"roles":   userRoles,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
-		"iat":     time.Now().Unix(),
-	})
-
-	tokenString, err := token.SignedString(s.jwtKey)
-	if err != nil {
-		logger.Error("Failed to generate JWT token", err, zap.String("user_id", user.ID.String()))
-		return "", fmt.Errorf("failed to generate token: %w", err)
+		logger.Error("Failed to issue token", err, zap.String("user_id", user.ID.String()))
+		return "", err
 	}
 
 	logger.Info("User logged in successfully", zap.String("user_id", user.ID.String()), zap.String("email", user.Email))
 	return tokenString, nil
 }
 
-func (s *authService) ValidateToken(tokenString string) (*jwt.Token, error) {
-	logger := s.logger
+func (s *authService) ValidateToken(ctx context.Context, tokenString string) (*jwt.Token, error) {
+	logger := s.logger.WithContext(ctx)
 	logger.Debug("Validating JWT token")
 
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
@@ -152,7 +176,7 @@ func (s *authService) ValidateToken(tokenString string) (*jwt.Token, error) {
 			logger.Warn("Invalid token signing method", zap.String("method", token.Method.Alg()))
 			return nil, ErrInvalidToken
 		}
-		return s.jwtKey, nil
+		return s.keys.VerificationKeys(), nil
 	})
 
 	if err != nil {
@@ -175,9 +199,8 @@ func (s *authService) ValidateToken(tokenString string) (*jwt.Token, error) {
 			return nil, ErrInvalidToken
 		}
 
-		if _, ok := claims["user_id"].(string); !ok {
-			logger.Warn("Invalid user_id claim in token")
-			return nil, ErrInvalidToken
+		if err := s.checkSession(ctx, claims); err != nil {
+			return nil, err
 		}
 	}
 
@@ -185,6 +208,36 @@ func (s *authService) ValidateToken(tokenString string) (*jwt.Token, error) {
 	return token, nil
 }
 
+// checkSession rejects tokens of users who are gone or inactive, and tokens
+// issued before the user's sessions were last revoked. Tokens from before
+// session versions existed carry none and count as version 0.
+func (s *authService) checkSession(ctx context.Context, claims jwt.MapClaims) error {
+	logger := s.logger.WithContext(ctx)
+
+	rawID, _ := claims["user_id"].(string)
+	userID, err := uuid.Parse(rawID)
+	if err != nil {
+		logger.Warn("Invalid user_id claim in token")
+		return ErrInvalidToken
+	}
+	version, _ := claims["sv"].(float64)
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			logger.Warn("Token of unknown user", zap.String("user_id", userID.String()))
+			return ErrInvalidToken
+		}
+		logger.Error("Failed to find user", err, zap.String("user_id", userID.String()))
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if !user.Active || user.DeletedAt != nil || int(version) != user.SessionVersion {
+		logger.Warn("Token of revoked session", zap.String("user_id", userID.String()))
+		return ErrInvalidToken
+	}
+	return nil
+}
+
 func (s *authService) AssignRole(ctx context.Context, userID uuid.UUID, roleName string) error {
 	logger := s.logger.WithContext(ctx)
 	logger.Info("Assigning role to user", zap.String("user_id", userID.String()), zap.String("role", roleName))
@@ -217,11 +270,18 @@ func (s *authService) AssignRole(ctx context.Context, userID uuid.UUID, roleName
 	}
 
 	userRole := &models.UserRole{
+		ID:     uuid.New(),
 		UserID: userID,
 		RoleID: role.ID,
 	}
 
-	if err := s.roleRepo.AssignRoleToUser(ctx, userRole); err != nil {
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.roleRepo.AssignRoleToUser(ctx, userRole); err != nil {
+			return err
+		}
+		return recordEvent(ctx, s.outbox, events.RoleAssigned{ID: userID, Role: role.Name})
+	})
+	if err != nil {
 		if errors.Is(err, repository.ErrDuplicateKey) {
 			logger.Warn("Role already assigned to user",
 				zap.String("user_id", userID.String()),
@@ -268,7 +328,17 @@ func (s *authService) RemoveRole(ctx context.Context, userID uuid.UUID, roleName
 		return fmt.Errorf("failed to find role: %w", err)
 	}
 
-	if err := s.roleRepo.RemoveRoleFromUser(ctx, userID, role.ID); err != nil {
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.roleRepo.RemoveRoleFromUser(ctx, userID, role.ID); err != nil {
+			return err
+		}
+		return recordEvent(ctx, s.outbox, events.RoleRemoved{ID: userID, Role: role.Name})
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Debug("Role was not assigned to user", zap.String("user_id", userID.String()), zap.String("role", roleName))
+		return nil
+	}
+	if err != nil {
 		logger.Error("Failed to remove role", err)
 		return fmt.Errorf("failed to remove role: %w", err)
 	}
@@ -276,3 +346,52 @@ func (s *authService) RemoveRole(ctx context.Context, userID uuid.UUID, roleName
 	logger.Info("Role removed successfully", zap.String("user_id", userID.String()), zap.String("role", roleName))
 	return nil
 }
+
+func (s *authService) CheckPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
+	logger := s.logger.WithContext(ctx)
+	logger.Debug("Checking user permission", zap.String("user_id", userID.String()), zap.String("permission", permission))
+
+	roles, err := s.roleRepo.GetUserRoles(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to get user roles", err)
+		return false, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	for i := range roles {
+		if roles[i].HasPermission(permission) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *authService) GetUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	logger := s.logger.WithContext(ctx)
+	logger.Debug("Getting user", zap.String("user_id", userID.String()))
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		logger.Error("Failed to find user", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if user.DeletedAt != nil {
+		return nil, ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+// recordEvent adds e to the outbox, inside the transaction bound to ctx.
+func recordEvent(ctx context.Context, outbox repository.OutboxRepository, e events.Event) error {
+	record, err := events.NewOutboxEvent(ctx, e)
+	if err != nil {
+		return err
+	}
+	if err := outbox.Add(ctx, record); err != nil {
+		return fmt.Errorf("failed to record %s event: %w", e.EventType(), err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"http_server/auth-service/internal/config"
+	"http_server/auth-service/internal/domain/models"
+	"http_server/auth-service/internal/domain/repository"
+	"http_server/auth-service/internal/events"
+	"http_server/auth-service/internal/passhash"
+	"http_server/shared/logging"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// credentialChecker checks an email and password for callers that are not
+// logged in. Failures count towards the account lockout. Unknown emails,
+// wrong passwords and locked accounts all fail with ErrInvalidCredentials
+// after a password hash comparison, so neither the response nor its timing
+// tells them apart.
+type credentialChecker struct {
+	userRepo repository.UserRepository
+	outbox   repository.OutboxRepository
+	tx       repository.Transactor
+	account  config.AccountConfig
+	hasher   passhash.Hasher
+	logger   *logging.Logger
+
+	dummyOnce sync.Once
+	dummyHash string
+}
+
+func newCredentialChecker(userRepo repository.UserRepository, outbox repository.OutboxRepository, tx repository.Transactor, account config.AccountConfig, hasher passhash.Hasher, logger *logging.Logger) *credentialChecker {
+	return &credentialChecker{
+		userRepo: userRepo,
+		outbox:   outbox,
+		tx:       tx,
+		account:  account,
+		hasher:   hasher,
+		logger:   logger,
+	}
+}
+
+// check returns the user with email when password is theirs. It does not
+// look at whether the account is active.
+func (c *credentialChecker) check(ctx context.Context, email, password string) (*models.User, error) {
+	logger := c.logger.WithContext(ctx)
+
+	user, err := c.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			logger.Warn("Unknown email in credentials", zap.String("email", email))
+			c.compareDummy(password)
+			return nil, ErrInvalidCredentials
+		}
+		logger.Error("Failed to find user", err, zap.String("email", email))
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	// A locked account gets the same answer whatever the password, so
+	// guessing cannot go on while it is locked
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		logger.Warn("Credentials for locked account", zap.String("user_id", user.ID.String()))
+		c.hasher.Verify(password, user.Password)
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := verifyPassword(ctx, c.hasher, c.userRepo, logger, user, password); err != nil {
+		if !errors.Is(err, ErrInvalidCredentials) {
+			return nil, err
+		}
+		logger.Warn("Invalid password attempt", zap.String("user_id", user.ID.String()))
+		c.recordFailure(ctx, user.ID)
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := c.userRepo.ResetFailedLogins(ctx, user.ID); err != nil {
+			logger.Warn("Failed to reset failed login counter", zap.String("user_id", user.ID.String()), zap.Error(err))
+		}
+	}
+	return user, nil
+}
+
+// compareDummy spends the time of a password check on a hash no password
+// matches.
+func (c *credentialChecker) compareDummy(password string) {
+	c.dummyOnce.Do(func() {
+		hash, err := c.hasher.Hash(uuid.NewString())
+		if err != nil {
+			c.logger.Error("Failed to create dummy password hash", err)
+			return
+		}
+		c.dummyHash = hash
+	})
+	if c.dummyHash != "" {
+		c.hasher.Verify(password, c.dummyHash)
+	}
+}
+
+// recordFailure counts a failed attempt and locks the account once the
+// threshold is reached, recording a user.locked event with the lock.
+func (c *credentialChecker) recordFailure(ctx context.Context, userID uuid.UUID) {
+	logger := c.logger.WithContext(ctx)
+
+	var lockedUntil time.Time
+	err := c.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		attempts, err := c.userRepo.RecordFailedLogin(ctx, userID)
+		if err != nil || attempts < c.account.LockoutThreshold {
+			return err
+		}
+		lockedUntil = time.Now().UTC().Add(c.account.LockoutDuration)
+		if err := c.userRepo.Lock(ctx, userID, lockedUntil); err != nil {
+			return err
+		}
+		return recordEvent(ctx, c.outbox, events.UserLocked{ID: userID, LockedUntil: lockedUntil})
+	})
+	if err != nil {
+		logger.Error("Failed to record failed login", err, zap.String("user_id", userID.String()))
+		return
+	}
+	if !lockedUntil.IsZero() {
+		logger.Warn("User locked after repeated failed logins",
+			zap.String("user_id", userID.String()),
+			zap.Time("locked_until", lockedUntil))
+	}
+}
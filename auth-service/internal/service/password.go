@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"http_server/auth-service/internal/domain/models"
+	"http_server/auth-service/internal/domain/repository"
+	"http_server/auth-service/internal/passhash"
+	"http_server/shared/logging"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// verifyPassword checks password against the user's stored hash and returns
+// ErrInvalidCredentials on mismatch. When the hash was made with outdated
+// settings it is replaced with a fresh one, and user.Password is updated so
+// a later Save keeps the new hash. Failing to upgrade does not fail the
+// check.
+func verifyPassword(ctx context.Context, hasher passhash.Hasher, users repository.UserRepository, logger *logging.Logger, user *models.User, password string) error {
+	_, span := tracer.Start(ctx, "password.verify")
+	match, needsRehash, err := hasher.Verify(password, user.Password)
+	span.SetAttributes(attribute.Bool("password.match", match), attribute.Bool("password.needs_rehash", needsRehash))
+	endSpan(span, err)
+	if err != nil {
+		logger.Error("Failed to verify password hash", err, zap.String("user_id", user.ID.String()))
+		return fmt.Errorf("failed to verify password: %w", err)
+	}
+	if !match {
+		return ErrInvalidCredentials
+	}
+	if !needsRehash {
+		return nil
+	}
+
+	newHash, err := hashPassword(ctx, hasher, password)
+	if err != nil {
+		logger.Warn("Failed to rehash password", zap.String("user_id", user.ID.String()), zap.Error(err))
+		return nil
+	}
+	if err := users.ReplacePasswordHash(ctx, user.ID, user.Password, newHash); err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			logger.Warn("Failed to store upgraded password hash", zap.String("user_id", user.ID.String()), zap.Error(err))
+		}
+		return nil
+	}
+	user.Password = newHash
+	logger.Info("Upgraded password hash", zap.String("user_id", user.ID.String()))
+	return nil
+}
+
+// hashPassword hashes password inside a span, since hashing is deliberately
+// slow and shows up in request latency.
+func hashPassword(ctx context.Context, hasher passhash.Hasher, password string) (string, error) {
+	_, span := tracer.Start(ctx, "password.hash")
+	hash, err := hasher.Hash(password)
+	endSpan(span, err)
+	return hash, err
+}
@@ -0,0 +1,680 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"http_server/auth-service/internal/config"
+	"http_server/auth-service/internal/domain/models"
+	"http_server/auth-service/internal/domain/repository"
+	"http_server/auth-service/internal/mail"
+	"http_server/shared/jwtkeys"
+	"http_server/shared/logging"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrPasswordlessDisabled = errors.New("passwordless login method is disabled")
+	ErrMagicLinkBusy        = errors.New("too many magic link requests pending")
+	ErrInvalidMagicLink     = errors.New("magic link is invalid or expired")
+	ErrInvalidCeremony      = errors.New("passkey ceremony is invalid or expired")
+	ErrPasskeyVerification  = errors.New("passkey verification failed")
+	ErrPasskeyExists        = errors.New("passkey is already registered")
+	ErrPasskeyNotFound      = errors.New("passkey not found")
+)
+
+const (
+	ceremonyRegistration = "registration"
+	ceremonyLogin        = "login"
+
+	// secretTokenBytes is the entropy of magic link tokens and device nonces.
+	secretTokenBytes = 32
+	// mailTimeout bounds issuing and sending one magic link.
+	mailTimeout = 30 * time.Second
+	// defaultPasskeyName is used when the user does not name a passkey.
+	defaultPasskeyName = "Passkey"
+)
+
+type PasswordlessService interface {
+	// RequestMagicLink emails a single-use login link to the active user with
+	// this email and returns the device nonce the requesting client must
+	// present along with the link's token. A nonce is returned whether or
+	// not the email is registered, so callers cannot tell the difference.
+	RequestMagicLink(ctx context.Context, email string) (string, error)
+	// VerifyMagicLink consumes the link and returns an access token.
+	VerifyMagicLink(ctx context.Context, token, deviceNonce string) (string, error)
+
+	// BeginPasskeyRegistration starts registering a passkey for the user and
+	// returns the options for navigator.credentials.create together with the
+	// ceremony ID to send back when finishing.
+	BeginPasskeyRegistration(ctx context.Context, userID uuid.UUID) (*protocol.CredentialCreation, uuid.UUID, error)
+	FinishPasskeyRegistration(ctx context.Context, userID, ceremonyID uuid.UUID, name string, response *protocol.ParsedCredentialCreationData) (*models.WebAuthnCredential, error)
+	// BeginPasskeyLogin starts a discoverable (usernameless) login and
+	// returns the options for navigator.credentials.get.
+	BeginPasskeyLogin(ctx context.Context) (*protocol.CredentialAssertion, uuid.UUID, error)
+	// FinishPasskeyLogin verifies the assertion and returns an access token.
+	FinishPasskeyLogin(ctx context.Context, ceremonyID uuid.UUID, response *protocol.ParsedCredentialAssertionData) (string, error)
+	ListPasskeys(ctx context.Context, userID uuid.UUID) ([]models.WebAuthnCredential, error)
+	DeletePasskey(ctx context.Context, userID, passkeyID uuid.UUID) error
+
+	// PurgeExpired removes expired magic links and abandoned ceremonies.
+	PurgeExpired(ctx context.Context) error
+	// RunCleanup calls PurgeExpired every interval until ctx is cancelled.
+	RunCleanup(ctx context.Context)
+	// RunMailer issues the magic links queued by RequestMagicLink until ctx
+	// is cancelled, then issues the ones still queued and returns.
+	RunMailer(ctx context.Context)
+}
+
+// magicLinkRequest is a queued RequestMagicLink call.
+type magicLinkRequest struct {
+	ctx         context.Context
+	email       string
+	deviceNonce string
+}
+
+type passwordlessService struct {
+	userRepo     repository.UserRepository
+	magicLinks   repository.MagicLinkRepository
+	webauthnRepo repository.WebAuthnRepository
+	tx           repository.Transactor
+	mailer       mail.Sender
+	webAuthn     *webauthn.WebAuthn
+	config       config.PasswordlessConfig
+	tokens       *tokenIssuer
+	logger       *logging.Logger
+	queue        chan magicLinkRequest
+}
+
+// NewPasswordlessService builds the service. webAuthn may be nil when
+// passkeys are disabled.
+func NewPasswordlessService(userRepo repository.UserRepository, roleRepo repository.RoleRepository, magicLinks repository.MagicLinkRepository, webauthnRepo repository.WebAuthnRepository, tx repository.Transactor, mailer mail.Sender, webAuthn *webauthn.WebAuthn, cfg config.PasswordlessConfig, keys *jwtkeys.Keys, logger *logging.Logger) PasswordlessService {
+	return &passwordlessService{
+		userRepo:     userRepo,
+		magicLinks:   magicLinks,
+		webauthnRepo: webauthnRepo,
+		tx:           tx,
+		mailer:       mailer,
+		webAuthn:     webAuthn,
+		config:       cfg,
+		tokens:       &tokenIssuer{roleRepo: roleRepo, keys: keys},
+		logger:       logger,
+		queue:        make(chan magicLinkRequest, cfg.MagicLink.QueueSize),
+	}
+}
+
+func (s *passwordlessService) RequestMagicLink(ctx context.Context, email string) (string, error) {
+	if !s.config.MagicLink.Enabled {
+		return "", ErrPasswordlessDisabled
+	}
+
+	deviceNonce, err := newSecretToken()
+	if err != nil {
+		return "", err
+	}
+
+	// Looking the user up, storing the link and mailing it all happen after
+	// the response, so its timing is the same whether or not the email is
+	// registered. A full queue is refused rather than waited on, which also
+	// does not depend on the email.
+	select {
+	case s.queue <- magicLinkRequest{ctx: context.WithoutCancel(ctx), email: email, deviceNonce: deviceNonce}:
+		return deviceNonce, nil
+	default:
+		return "", ErrMagicLinkBusy
+	}
+}
+
+func (s *passwordlessService) RunMailer(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < s.config.MagicLink.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					s.drainQueue()
+					return
+				case request := <-s.queue:
+					s.handleRequest(request)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// drainQueue issues the queued links without waiting for more. It runs
+// once the server stopped accepting requests, so the queue only shrinks.
+func (s *passwordlessService) drainQueue() {
+	for {
+		select {
+		case request := <-s.queue:
+			s.handleRequest(request)
+		default:
+			return
+		}
+	}
+}
+
+func (s *passwordlessService) handleRequest(request magicLinkRequest) {
+	ctx, cancel := context.WithTimeout(request.ctx, mailTimeout)
+	defer cancel()
+	s.issueMagicLink(ctx, request.email, request.deviceNonce)
+}
+
+// issueMagicLink stores a link bound to deviceNonce for the active user with
+// this email and mails it. Failures are only logged since the client already
+// has its response.
+func (s *passwordlessService) issueMagicLink(ctx context.Context, email, deviceNonce string) {
+	logger := s.logger.WithContext(ctx)
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			logger.Info("Magic link requested for unknown email")
+			return
+		}
+		logger.Error("Failed to find user", err)
+		return
+	}
+	if err := checkLoginAllowed(user); err != nil {
+		logger.Info("Magic link requested for account that cannot log in",
+			zap.String("user_id", user.ID.String()), zap.Error(err))
+		return
+	}
+
+	token, err := newSecretToken()
+	if err != nil {
+		logger.Error("Failed to generate magic link token", err)
+		return
+	}
+	link := &models.MagicLink{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		TokenHash:  hashSecret(token),
+		DeviceHash: hashSecret(deviceNonce),
+		ExpiresAt:  time.Now().UTC().Add(s.config.MagicLink.TTL),
+	}
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.magicLinks.DeletePending(ctx, user.ID); err != nil {
+			return err
+		}
+		return s.magicLinks.Create(ctx, link)
+	})
+	if err != nil {
+		logger.Error("Failed to store magic link", err, zap.String("user_id", user.ID.String()))
+		return
+	}
+
+	if err := s.sendMagicLink(ctx, user, token); err != nil {
+		logger.Error("Failed to send magic link", err, zap.String("user_id", user.ID.String()))
+		return
+	}
+	logger.Info("Magic link issued", zap.String("user_id", user.ID.String()), zap.Time("expires_at", link.ExpiresAt))
+}
+
+func (s *passwordlessService) sendMagicLink(ctx context.Context, user *models.User, token string) error {
+	link, err := url.Parse(s.config.MagicLink.URL)
+	if err != nil {
+		return fmt.Errorf("invalid magic link URL: %w", err)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Your sign-in link",
+		Body: fmt.Sprintf("Hi %s,\n\nUse this link to sign in. It works once, on the device you requested it from, for the next %s:\n\n%s\n\nIf you did not ask to sign in, you can ignore this email.\n",
+			user.Name, s.config.MagicLink.TTL, link.String()),
+	})
+}
+
+func (s *passwordlessService) VerifyMagicLink(ctx context.Context, token, deviceNonce string) (string, error) {
+	logger := s.logger.WithContext(ctx)
+	if !s.config.MagicLink.Enabled {
+		return "", ErrPasswordlessDisabled
+	}
+	if token == "" || deviceNonce == "" {
+		return "", ErrInvalidMagicLink
+	}
+
+	now := time.Now().UTC()
+	// Consuming first burns the link even if the checks below fail, so a
+	// leaked link cannot be retried from another device.
+	link, err := s.magicLinks.Consume(ctx, hashSecret(token), now)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			logger.Warn("Unknown or already used magic link")
+			return "", ErrInvalidMagicLink
+		}
+		logger.Error("Failed to consume magic link", err)
+		return "", fmt.Errorf("failed to consume magic link: %w", err)
+	}
+	if now.After(link.ExpiresAt) {
+		logger.Warn("Expired magic link", zap.String("user_id", link.UserID.String()))
+		return "", ErrInvalidMagicLink
+	}
+	if subtle.ConstantTimeCompare(link.DeviceHash, hashSecret(deviceNonce)) != 1 {
+		logger.Warn("Magic link used from another device", zap.String("user_id", link.UserID.String()))
+		return "", ErrInvalidMagicLink
+	}
+
+	user, err := s.findUser(ctx, link.UserID)
+	if err != nil {
+		return "", err
+	}
+	if err := checkLoginAllowed(user); err != nil {
+		return "", err
+	}
+
+	// Following the link proves the user controls the address.
+	if !user.EmailVerified {
+		user.EmailVerified = true
+		user.VerifiedAt = &now
+		if err := s.userRepo.Save(ctx, user); err != nil {
+			logger.Warn("Failed to mark email verified", zap.String("user_id", user.ID.String()), zap.Error(err))
+		}
+	}
+
+	accessToken, err := s.tokens.issue(ctx, user)
+	if err != nil {
+		logger.Error("Failed to issue token", err, zap.String("user_id", user.ID.String()))
+		return "", err
+	}
+	logger.Info("User logged in with magic link", zap.String("user_id", user.ID.String()))
+	return accessToken, nil
+}
+
+func (s *passwordlessService) BeginPasskeyRegistration(ctx context.Context, userID uuid.UUID) (*protocol.CredentialCreation, uuid.UUID, error) {
+	logger := s.logger.WithContext(ctx)
+	if s.webAuthn == nil {
+		return nil, uuid.Nil, ErrPasswordlessDisabled
+	}
+
+	user, credentials, err := s.loadWebAuthnUser(ctx, userID)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(credentials))
+	for _, credential := range user.WebAuthnCredentials() {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+
+	creation, session, err := s.webAuthn.BeginRegistration(user,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		logger.Error("Failed to begin passkey registration", err, zap.String("user_id", userID.String()))
+		return nil, uuid.Nil, fmt.Errorf("failed to begin passkey registration: %w", err)
+	}
+
+	ceremonyID, err := s.saveCeremony(ctx, &userID, ceremonyRegistration, session)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+	return creation, ceremonyID, nil
+}
+
+func (s *passwordlessService) FinishPasskeyRegistration(ctx context.Context, userID, ceremonyID uuid.UUID, name string, response *protocol.ParsedCredentialCreationData) (*models.WebAuthnCredential, error) {
+	logger := s.logger.WithContext(ctx)
+	if s.webAuthn == nil {
+		return nil, ErrPasswordlessDisabled
+	}
+
+	session, err := s.takeCeremony(ctx, ceremonyID, ceremonyRegistration, &userID)
+	if err != nil {
+		return nil, err
+	}
+	user, _, err := s.loadWebAuthnUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := s.webAuthn.CreateCredential(user, *session, response)
+	if err != nil {
+		logger.Warn("Passkey registration rejected", zap.String("user_id", userID.String()), zap.Error(err))
+		return nil, ErrPasskeyVerification
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = defaultPasskeyName
+	}
+	if runes := []rune(name); len(runes) > 100 {
+		name = string(runes[:100])
+	}
+	transports := make([]string, len(credential.Transport))
+	for i, transport := range credential.Transport {
+		transports[i] = string(transport)
+	}
+
+	record := &models.WebAuthnCredential{
+		ID:              uuid.New(),
+		UserID:          userID,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		Transports:      strings.Join(transports, ","),
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+		Name:            name,
+	}
+	if err := s.webauthnRepo.CreateCredential(ctx, record); err != nil {
+		if errors.Is(err, repository.ErrDuplicateKey) {
+			return nil, ErrPasskeyExists
+		}
+		logger.Error("Failed to store passkey", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to store passkey: %w", err)
+	}
+
+	logger.Info("Passkey registered", zap.String("user_id", userID.String()), zap.String("passkey_id", record.ID.String()))
+	return record, nil
+}
+
+func (s *passwordlessService) BeginPasskeyLogin(ctx context.Context) (*protocol.CredentialAssertion, uuid.UUID, error) {
+	logger := s.logger.WithContext(ctx)
+	if s.webAuthn == nil {
+		return nil, uuid.Nil, ErrPasswordlessDisabled
+	}
+
+	assertion, session, err := s.webAuthn.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationRequired),
+	)
+	if err != nil {
+		logger.Error("Failed to begin passkey login", err)
+		return nil, uuid.Nil, fmt.Errorf("failed to begin passkey login: %w", err)
+	}
+
+	ceremonyID, err := s.saveCeremony(ctx, nil, ceremonyLogin, session)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+	return assertion, ceremonyID, nil
+}
+
+func (s *passwordlessService) FinishPasskeyLogin(ctx context.Context, ceremonyID uuid.UUID, response *protocol.ParsedCredentialAssertionData) (string, error) {
+	logger := s.logger.WithContext(ctx)
+	if s.webAuthn == nil {
+		return "", ErrPasswordlessDisabled
+	}
+
+	session, err := s.takeCeremony(ctx, ceremonyID, ceremonyLogin, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		user   *models.User
+		stored *models.WebAuthnCredential
+	)
+	lookup := func(rawID, userHandle []byte) (webauthn.User, error) {
+		credential, err := s.webauthnRepo.FindCredential(ctx, rawID)
+		if err != nil {
+			return nil, err
+		}
+		if subtle.ConstantTimeCompare(credential.UserID[:], userHandle) != 1 {
+			return nil, errors.New("user handle does not own the credential")
+		}
+		found, err := s.userRepo.FindByID(ctx, credential.UserID)
+		if err != nil {
+			return nil, err
+		}
+		user, stored = found, credential
+		return newWebAuthnUser(found, []models.WebAuthnCredential{*credential}), nil
+	}
+
+	credential, err := s.webAuthn.ValidateDiscoverableLogin(lookup, *session, response)
+	if err != nil {
+		logger.Warn("Passkey assertion rejected", zap.Error(err))
+		return "", ErrPasskeyVerification
+	}
+	// A counter that did not increase means two authenticators may hold the
+	// same key.
+	if credential.Authenticator.CloneWarning {
+		logger.Warn("Passkey sign count did not increase, possible cloned authenticator",
+			zap.String("user_id", user.ID.String()),
+			zap.String("passkey_id", stored.ID.String()),
+			zap.Uint32("stored_count", stored.SignCount),
+			zap.Uint32("presented_count", response.Response.AuthenticatorData.Counter))
+		return "", ErrPasskeyVerification
+	}
+	if err := checkLoginAllowed(user); err != nil {
+		return "", err
+	}
+
+	err = s.webauthnRepo.UpdateCredentialUsage(ctx, stored.ID, stored.SignCount, credential.Authenticator.SignCount, credential.Flags.BackupState, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			logger.Warn("Passkey used concurrently", zap.String("passkey_id", stored.ID.String()))
+			return "", ErrPasskeyVerification
+		}
+		logger.Error("Failed to update passkey", err, zap.String("passkey_id", stored.ID.String()))
+		return "", fmt.Errorf("failed to update passkey: %w", err)
+	}
+
+	accessToken, err := s.tokens.issue(ctx, user)
+	if err != nil {
+		logger.Error("Failed to issue token", err, zap.String("user_id", user.ID.String()))
+		return "", err
+	}
+	logger.Info("User logged in with passkey", zap.String("user_id", user.ID.String()), zap.String("passkey_id", stored.ID.String()))
+	return accessToken, nil
+}
+
+func (s *passwordlessService) ListPasskeys(ctx context.Context, userID uuid.UUID) ([]models.WebAuthnCredential, error) {
+	credentials, err := s.webauthnRepo.ListCredentials(ctx, userID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to list passkeys", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+	return credentials, nil
+}
+
+func (s *passwordlessService) DeletePasskey(ctx context.Context, userID, passkeyID uuid.UUID) error {
+	logger := s.logger.WithContext(ctx)
+	if err := s.webauthnRepo.DeleteCredential(ctx, userID, passkeyID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrPasskeyNotFound
+		}
+		logger.Error("Failed to delete passkey", err, zap.String("user_id", userID.String()))
+		return fmt.Errorf("failed to delete passkey: %w", err)
+	}
+	logger.Info("Passkey deleted", zap.String("user_id", userID.String()), zap.String("passkey_id", passkeyID.String()))
+	return nil
+}
+
+func (s *passwordlessService) PurgeExpired(ctx context.Context) error {
+	logger := s.logger.WithContext(ctx)
+	now := time.Now().UTC()
+
+	links, err := s.magicLinks.DeleteExpired(ctx, now)
+	if err != nil {
+		logger.Error("Failed to delete expired magic links", err)
+		return fmt.Errorf("failed to delete expired magic links: %w", err)
+	}
+	ceremonies, err := s.webauthnRepo.DeleteExpiredSessions(ctx, now)
+	if err != nil {
+		logger.Error("Failed to delete expired passkey ceremonies", err)
+		return fmt.Errorf("failed to delete expired passkey ceremonies: %w", err)
+	}
+	if links > 0 || ceremonies > 0 {
+		logger.Info("Expired passwordless state removed",
+			zap.Int64("magic_links", links),
+			zap.Int64("ceremonies", ceremonies))
+	}
+	return nil
+}
+
+func (s *passwordlessService) RunCleanup(ctx context.Context) {
+	ticker := time.NewTicker(s.config.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.PurgeExpired(ctx)
+		}
+	}
+}
+
+func (s *passwordlessService) saveCeremony(ctx context.Context, userID *uuid.UUID, ceremony string, session *webauthn.SessionData) (uuid.UUID, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to encode passkey ceremony: %w", err)
+	}
+	record := &models.WebAuthnSession{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Ceremony:  ceremony,
+		Data:      data,
+		ExpiresAt: time.Now().UTC().Add(s.config.WebAuthn.Timeout),
+	}
+	if err := s.webauthnRepo.CreateSession(ctx, record); err != nil {
+		s.logger.WithContext(ctx).Error("Failed to store passkey ceremony", err)
+		return uuid.Nil, fmt.Errorf("failed to store passkey ceremony: %w", err)
+	}
+	return record.ID, nil
+}
+
+// takeCeremony consumes a stored ceremony, checking that it has not expired
+// and belongs to userID (nil for discoverable logins).
+func (s *passwordlessService) takeCeremony(ctx context.Context, id uuid.UUID, ceremony string, userID *uuid.UUID) (*webauthn.SessionData, error) {
+	record, err := s.webauthnRepo.TakeSession(ctx, id, ceremony)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidCeremony
+		}
+		s.logger.WithContext(ctx).Error("Failed to load passkey ceremony", err)
+		return nil, fmt.Errorf("failed to load passkey ceremony: %w", err)
+	}
+	if time.Now().After(record.ExpiresAt) {
+		return nil, ErrInvalidCeremony
+	}
+	if (userID == nil) != (record.UserID == nil) || (userID != nil && *userID != *record.UserID) {
+		return nil, ErrInvalidCeremony
+	}
+
+	var session webauthn.SessionData
+	if err := json.Unmarshal(record.Data, &session); err != nil {
+		return nil, fmt.Errorf("failed to decode passkey ceremony: %w", err)
+	}
+	return &session, nil
+}
+
+func (s *passwordlessService) loadWebAuthnUser(ctx context.Context, userID uuid.UUID) (*webAuthnUser, []models.WebAuthnCredential, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	credentials, err := s.webauthnRepo.ListCredentials(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+	return newWebAuthnUser(user, credentials), credentials, nil
+}
+
+func (s *passwordlessService) findUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		s.logger.WithContext(ctx).Error("Failed to find user", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	return user, nil
+}
+
+// webAuthnUser adapts a user and their passkeys to webauthn.User. The user
+// handle is the 16-byte user ID.
+type webAuthnUser struct {
+	user        *models.User
+	credentials []webauthn.Credential
+}
+
+func newWebAuthnUser(user *models.User, stored []models.WebAuthnCredential) *webAuthnUser {
+	credentials := make([]webauthn.Credential, len(stored))
+	for i, credential := range stored {
+		var transports []protocol.AuthenticatorTransport
+		for _, transport := range strings.Split(credential.Transports, ",") {
+			if transport != "" {
+				transports = append(transports, protocol.AuthenticatorTransport(transport))
+			}
+		}
+		credentials[i] = webauthn.Credential{
+			ID:              credential.CredentialID,
+			PublicKey:       credential.PublicKey,
+			AttestationType: credential.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				BackupEligible: credential.BackupEligible,
+				BackupState:    credential.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    credential.AAGUID,
+				SignCount: credential.SignCount,
+			},
+		}
+	}
+	return &webAuthnUser{user: user, credentials: credentials}
+}
+
+func (u *webAuthnUser) WebAuthnID() []byte {
+	id := u.user.ID
+	return id[:]
+}
+
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	return u.user.Name
+}
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+func (u *webAuthnUser) WebAuthnIcon() string {
+	return ""
+}
+
+// newSecretToken returns a random URL-safe token.
+func newSecretToken() (string, error) {
+	b := make([]byte, secretTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashSecret is how tokens and nonces are stored: they carry enough entropy
+// that a fast hash is sufficient.
+func hashSecret(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
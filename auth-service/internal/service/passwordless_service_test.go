@@ -0,0 +1,641 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"http_server/auth-service/internal/config"
+	"http_server/auth-service/internal/domain/models"
+	"http_server/auth-service/internal/domain/repository"
+	"http_server/auth-service/internal/mail"
+	"http_server/shared/jwtkeys"
+	"http_server/shared/logging"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+)
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://example.com"
+)
+
+// fakeUsers implements the user lookups the passwordless service needs.
+// When block is set, FindByEmail waits for it to be closed.
+type fakeUsers struct {
+	repository.UserRepository
+	mu    sync.Mutex
+	users map[uuid.UUID]*models.User
+	block chan struct{}
+}
+
+func (f *fakeUsers) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	if f.block != nil {
+		<-f.block
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, user := range f.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (f *fakeUsers) FindByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user, ok := f.users[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (f *fakeUsers) Save(ctx context.Context, user *models.User) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	copied := *user
+	f.users[user.ID] = &copied
+	return nil
+}
+
+type fakeRoles struct {
+	repository.RoleRepository
+}
+
+func (fakeRoles) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]models.Role, error) {
+	return []models.Role{{Name: "user"}}, nil
+}
+
+type fakeMagicLinks struct {
+	mu    sync.Mutex
+	links []*models.MagicLink
+}
+
+func (f *fakeMagicLinks) Create(ctx context.Context, link *models.MagicLink) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.links = append(f.links, link)
+	return nil
+}
+
+func (f *fakeMagicLinks) DeletePending(ctx context.Context, userID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	kept := f.links[:0]
+	for _, link := range f.links {
+		if link.UserID != userID || link.UsedAt != nil {
+			kept = append(kept, link)
+		}
+	}
+	f.links = kept
+	return nil
+}
+
+func (f *fakeMagicLinks) Consume(ctx context.Context, tokenHash []byte, at time.Time) (*models.MagicLink, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, link := range f.links {
+		if bytes.Equal(link.TokenHash, tokenHash) && link.UsedAt == nil {
+			link.UsedAt = &at
+			copied := *link
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (f *fakeMagicLinks) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+type fakeWebAuthn struct {
+	mu          sync.Mutex
+	credentials []*models.WebAuthnCredential
+	sessions    map[uuid.UUID]*models.WebAuthnSession
+}
+
+func (f *fakeWebAuthn) CreateCredential(ctx context.Context, credential *models.WebAuthnCredential) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, existing := range f.credentials {
+		if bytes.Equal(existing.CredentialID, credential.CredentialID) {
+			return repository.ErrDuplicateKey
+		}
+	}
+	f.credentials = append(f.credentials, credential)
+	return nil
+}
+
+func (f *fakeWebAuthn) ListCredentials(ctx context.Context, userID uuid.UUID) ([]models.WebAuthnCredential, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var found []models.WebAuthnCredential
+	for _, credential := range f.credentials {
+		if credential.UserID == userID {
+			found = append(found, *credential)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeWebAuthn) FindCredential(ctx context.Context, credentialID []byte) (*models.WebAuthnCredential, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, credential := range f.credentials {
+		if bytes.Equal(credential.CredentialID, credentialID) {
+			copied := *credential
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (f *fakeWebAuthn) UpdateCredentialUsage(ctx context.Context, id uuid.UUID, previousCount, signCount uint32, backupState bool, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, credential := range f.credentials {
+		if credential.ID == id && credential.SignCount == previousCount {
+			credential.SignCount = signCount
+			credential.BackupState = backupState
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (f *fakeWebAuthn) DeleteCredential(ctx context.Context, userID, id uuid.UUID) error {
+	return repository.ErrNotFound
+}
+
+func (f *fakeWebAuthn) CreateSession(ctx context.Context, session *models.WebAuthnSession) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sessions[session.ID] = session
+	return nil
+}
+
+func (f *fakeWebAuthn) TakeSession(ctx context.Context, id uuid.UUID, ceremony string) (*models.WebAuthnSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	session, ok := f.sessions[id]
+	if !ok || session.Ceremony != ceremony {
+		return nil, repository.ErrNotFound
+	}
+	delete(f.sessions, id)
+	return session, nil
+}
+
+func (f *fakeWebAuthn) DeleteExpiredSessions(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+type fakeTransactor struct{}
+
+func (fakeTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type fakeMailer struct {
+	sent chan mail.Message
+}
+
+func (f *fakeMailer) Send(ctx context.Context, msg mail.Message) error {
+	f.sent <- msg
+	return nil
+}
+
+type passwordlessFixture struct {
+	service  *passwordlessService
+	users    *fakeUsers
+	links    *fakeMagicLinks
+	webauthn *fakeWebAuthn
+	mailer   *fakeMailer
+	user     *models.User
+}
+
+func newPasswordlessFixture(t *testing.T) *passwordlessFixture {
+	t.Helper()
+	logger, err := logging.NewLogger(&logging.Config{ServiceName: "test", LogLevel: "error", FilePath: "stderr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          testRPID,
+		RPDisplayName: "Test",
+		RPOrigins:     []string{testOrigin},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.User{ID: uuid.New(), Email: "someone@example.com", Name: "Someone", Active: true}
+	f := &passwordlessFixture{
+		users:    &fakeUsers{users: map[uuid.UUID]*models.User{user.ID: user}},
+		links:    &fakeMagicLinks{},
+		webauthn: &fakeWebAuthn{sessions: make(map[uuid.UUID]*models.WebAuthnSession)},
+		mailer:   &fakeMailer{sent: make(chan mail.Message, 10)},
+		user:     user,
+	}
+	cfg := config.PasswordlessConfig{
+		MagicLink: config.MagicLinkConfig{Enabled: true, URL: "https://example.com/login", TTL: 15 * time.Minute, Workers: 2, QueueSize: 10},
+		WebAuthn:  config.WebAuthnConfig{Enabled: true, Timeout: 5 * time.Minute},
+	}
+	f.service = NewPasswordlessService(f.users, fakeRoles{}, f.links, f.webauthn, fakeTransactor{}, f.mailer,
+		webAuthn, cfg, jwtkeys.New([]byte("signing-key")), logger).(*passwordlessService)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		f.service.RunMailer(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return f
+}
+
+var linkToken = regexp.MustCompile(`https://example\.com/login\?token=\S+`)
+
+// requestLink requests a magic link for email and returns the device nonce
+// and the token from the mailed link.
+func (f *passwordlessFixture) requestLink(t *testing.T, email string) (string, string) {
+	t.Helper()
+	nonce, err := f.service.RequestMagicLink(context.Background(), email)
+	if err != nil {
+		t.Fatalf("RequestMagicLink: %v", err)
+	}
+	select {
+	case msg := <-f.mailer.sent:
+		link, err := url.Parse(linkToken.FindString(msg.Body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return nonce, link.Query().Get("token")
+	case <-time.After(5 * time.Second):
+		t.Fatal("magic link was not sent")
+		return "", ""
+	}
+}
+
+func TestMagicLinkIsSingleUse(t *testing.T) {
+	f := newPasswordlessFixture(t)
+	ctx := context.Background()
+
+	nonce, token := f.requestLink(t, f.user.Email)
+	if _, err := f.service.VerifyMagicLink(ctx, token, nonce); err != nil {
+		t.Fatalf("first VerifyMagicLink: %v", err)
+	}
+	if _, err := f.service.VerifyMagicLink(ctx, token, nonce); !errors.Is(err, ErrInvalidMagicLink) {
+		t.Errorf("second VerifyMagicLink = %v, want ErrInvalidMagicLink", err)
+	}
+	if saved, _ := f.users.FindByID(ctx, f.user.ID); !saved.EmailVerified {
+		t.Error("email not marked verified")
+	}
+}
+
+func TestMagicLinkFromAnotherDeviceIsBurned(t *testing.T) {
+	f := newPasswordlessFixture(t)
+	ctx := context.Background()
+
+	nonce, token := f.requestLink(t, f.user.Email)
+	if _, err := f.service.VerifyMagicLink(ctx, token, "other-device"); !errors.Is(err, ErrInvalidMagicLink) {
+		t.Fatalf("VerifyMagicLink from another device = %v, want ErrInvalidMagicLink", err)
+	}
+	if _, err := f.service.VerifyMagicLink(ctx, token, nonce); !errors.Is(err, ErrInvalidMagicLink) {
+		t.Errorf("VerifyMagicLink after a failed attempt = %v, want ErrInvalidMagicLink", err)
+	}
+}
+
+func TestMagicLinkReplacesPendingLink(t *testing.T) {
+	f := newPasswordlessFixture(t)
+	ctx := context.Background()
+
+	firstNonce, firstToken := f.requestLink(t, f.user.Email)
+	secondNonce, secondToken := f.requestLink(t, f.user.Email)
+	if _, err := f.service.VerifyMagicLink(ctx, firstToken, firstNonce); !errors.Is(err, ErrInvalidMagicLink) {
+		t.Errorf("VerifyMagicLink with the replaced link = %v, want ErrInvalidMagicLink", err)
+	}
+	if _, err := f.service.VerifyMagicLink(ctx, secondToken, secondNonce); err != nil {
+		t.Errorf("VerifyMagicLink with the latest link: %v", err)
+	}
+}
+
+func TestRequestMagicLinkRespondsBeforeLookup(t *testing.T) {
+	f := newPasswordlessFixture(t)
+	f.users.block = make(chan struct{})
+
+	// The user lookup is blocked, so this only returns if the response
+	// does not wait for it.
+	for _, email := range []string{f.user.Email, "unknown@example.com"} {
+		nonce, err := f.service.RequestMagicLink(context.Background(), email)
+		if err != nil || nonce == "" {
+			t.Errorf("RequestMagicLink(%q) = %q, %v, want a nonce", email, nonce, err)
+		}
+	}
+	close(f.users.block)
+
+	select {
+	case msg := <-f.mailer.sent:
+		if msg.To != f.user.Email {
+			t.Errorf("mail sent to %q, want %q", msg.To, f.user.Email)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("magic link was not sent after the lookup finished")
+	}
+}
+
+func TestRequestMagicLinkRefusedWhenQueueFull(t *testing.T) {
+	f := newPasswordlessFixture(t)
+	// Nothing reads this queue, so it stays full after one request.
+	service := *f.service
+	service.queue = make(chan magicLinkRequest, 1)
+
+	if _, err := service.RequestMagicLink(context.Background(), f.user.Email); err != nil {
+		t.Fatalf("first RequestMagicLink: %v", err)
+	}
+	for _, email := range []string{f.user.Email, "unknown@example.com"} {
+		if _, err := service.RequestMagicLink(context.Background(), email); !errors.Is(err, ErrMagicLinkBusy) {
+			t.Errorf("RequestMagicLink(%q) with a full queue = %v, want ErrMagicLinkBusy", email, err)
+		}
+	}
+}
+
+func TestRunMailerDrainsQueueOnShutdown(t *testing.T) {
+	f := newPasswordlessFixture(t)
+	service := *f.service
+	service.queue = make(chan magicLinkRequest, 1)
+	if _, err := service.RequestMagicLink(context.Background(), f.user.Email); err != nil {
+		t.Fatalf("RequestMagicLink: %v", err)
+	}
+
+	// Already cancelled: the workers stop at once, but still mail the link
+	// that was queued.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	service.RunMailer(ctx)
+
+	select {
+	case msg := <-f.mailer.sent:
+		if msg.To != f.user.Email {
+			t.Errorf("mail sent to %q, want %q", msg.To, f.user.Email)
+		}
+	default:
+		t.Fatal("queued magic link was not sent before RunMailer returned")
+	}
+}
+
+func TestIssueMagicLinkSkipsUnknownAndInactive(t *testing.T) {
+	f := newPasswordlessFixture(t)
+	inactive := &models.User{ID: uuid.New(), Email: "gone@example.com", Active: false}
+	f.users.users[inactive.ID] = inactive
+
+	for _, email := range []string{"unknown@example.com", inactive.Email} {
+		f.service.issueMagicLink(context.Background(), email, "nonce")
+	}
+	if len(f.links.links) != 0 || len(f.mailer.sent) != 0 {
+		t.Errorf("links = %d, mails = %d, want none", len(f.links.links), len(f.mailer.sent))
+	}
+}
+
+// softwareAuthenticator is a platform authenticator holding one P-256
+// passkey, producing the responses a browser would pass on.
+type softwareAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+}
+
+func newSoftwareAuthenticator(t *testing.T) *softwareAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentialID := make([]byte, 32)
+	rand.Read(credentialID)
+	return &softwareAuthenticator{key: key, credentialID: credentialID}
+}
+
+func (a *softwareAuthenticator) clientData(t *testing.T, ceremony protocol.CeremonyType, challenge protocol.URLEncodedBase64) []byte {
+	t.Helper()
+	data, err := json.Marshal(protocol.CollectedClientData{
+		Type:      ceremony,
+		Challenge: challenge.String(),
+		Origin:    testOrigin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// authData builds authenticator data with user presence and verification,
+// including the attested credential when attested is set.
+func (a *softwareAuthenticator) authData(t *testing.T, attested bool) []byte {
+	t.Helper()
+	a.signCount++
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	data := append([]byte{}, rpIDHash[:]...)
+	flags := protocol.FlagUserPresent | protocol.FlagUserVerified
+	if attested {
+		flags |= protocol.FlagAttestedCredentialData
+	}
+	data = append(data, byte(flags))
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if !attested {
+		return data
+	}
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  int64(webauthncose.P256),
+		XCoord: a.key.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(data, make([]byte, 16)...) // AAGUID
+	data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+	data = append(data, a.credentialID...)
+	return append(data, publicKey...)
+}
+
+func (a *softwareAuthenticator) create(t *testing.T, options *protocol.CredentialCreation) *protocol.ParsedCredentialCreationData {
+	t.Helper()
+	a.userHandle = options.Response.User.ID.(protocol.URLEncodedBase64)
+	attestation, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authData(t, true),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"id":    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"rawId": base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(a.clientData(t, protocol.CreateCeremony, options.Response.Challenge)),
+			"attestationObject": base64.RawURLEncoding.EncodeToString(attestation),
+		},
+	})
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("parse creation response: %v", err)
+	}
+	return parsed
+}
+
+func (a *softwareAuthenticator) get(t *testing.T, options *protocol.CredentialAssertion) *protocol.ParsedCredentialAssertionData {
+	t.Helper()
+	authData := a.authData(t, false)
+	clientData := a.clientData(t, protocol.AssertCeremony, options.Response.Challenge)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"id":    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"rawId": base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(clientData),
+			"authenticatorData": base64.RawURLEncoding.EncodeToString(authData),
+			"signature":         base64.RawURLEncoding.EncodeToString(signature),
+			"userHandle":        base64.RawURLEncoding.EncodeToString(a.userHandle),
+		},
+	})
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("parse assertion response: %v", err)
+	}
+	return parsed
+}
+
+// registerPasskey runs the registration ceremony for the fixture's user.
+func (f *passwordlessFixture) registerPasskey(t *testing.T, authenticator *softwareAuthenticator) *models.WebAuthnCredential {
+	t.Helper()
+	ctx := context.Background()
+	options, ceremonyID, err := f.service.BeginPasskeyRegistration(ctx, f.user.ID)
+	if err != nil {
+		t.Fatalf("BeginPasskeyRegistration: %v", err)
+	}
+	credential, err := f.service.FinishPasskeyRegistration(ctx, f.user.ID, ceremonyID, " Laptop ", authenticator.create(t, options))
+	if err != nil {
+		t.Fatalf("FinishPasskeyRegistration: %v", err)
+	}
+	return credential
+}
+
+func TestPasskeyRegistrationAndLogin(t *testing.T) {
+	f := newPasswordlessFixture(t)
+	ctx := context.Background()
+	authenticator := newSoftwareAuthenticator(t)
+
+	credential := f.registerPasskey(t, authenticator)
+	if credential.Name != "Laptop" || !bytes.Equal(credential.CredentialID, authenticator.credentialID) || credential.SignCount != 1 {
+		t.Errorf("stored credential = %+v", credential)
+	}
+
+	options, ceremonyID, err := f.service.BeginPasskeyLogin(ctx)
+	if err != nil {
+		t.Fatalf("BeginPasskeyLogin: %v", err)
+	}
+	assertion := authenticator.get(t, options)
+	token, err := f.service.FinishPasskeyLogin(ctx, ceremonyID, assertion)
+	if err != nil || token == "" {
+		t.Fatalf("FinishPasskeyLogin = %q, %v", token, err)
+	}
+	if stored, _ := f.webauthn.FindCredential(ctx, authenticator.credentialID); stored.SignCount != 2 {
+		t.Errorf("sign count = %d, want 2", stored.SignCount)
+	}
+
+	// The ceremony was taken, so the same assertion cannot be replayed.
+	if _, err := f.service.FinishPasskeyLogin(ctx, ceremonyID, assertion); !errors.Is(err, ErrInvalidCeremony) {
+		t.Errorf("replayed FinishPasskeyLogin = %v, want ErrInvalidCeremony", err)
+	}
+}
+
+func TestPasskeyLoginRejectsRegressedCounter(t *testing.T) {
+	f := newPasswordlessFixture(t)
+	ctx := context.Background()
+	authenticator := newSoftwareAuthenticator(t)
+	f.registerPasskey(t, authenticator)
+
+	// A clone of the authenticator made before registration would present
+	// a counter that did not increase.
+	authenticator.signCount = 0
+	options, ceremonyID, err := f.service.BeginPasskeyLogin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.FinishPasskeyLogin(ctx, ceremonyID, authenticator.get(t, options)); !errors.Is(err, ErrPasskeyVerification) {
+		t.Errorf("FinishPasskeyLogin = %v, want ErrPasskeyVerification", err)
+	}
+}
+
+func TestPasskeyLoginRejectsWrongKey(t *testing.T) {
+	f := newPasswordlessFixture(t)
+	ctx := context.Background()
+	authenticator := newSoftwareAuthenticator(t)
+	f.registerPasskey(t, authenticator)
+
+	impostor := newSoftwareAuthenticator(t)
+	impostor.credentialID = authenticator.credentialID
+	impostor.userHandle = authenticator.userHandle
+	impostor.signCount = 5
+	options, ceremonyID, err := f.service.BeginPasskeyLogin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.FinishPasskeyLogin(ctx, ceremonyID, impostor.get(t, options)); !errors.Is(err, ErrPasskeyVerification) {
+		t.Errorf("FinishPasskeyLogin = %v, want ErrPasskeyVerification", err)
+	}
+}
+
+func TestPasskeyRegistrationRejectsOtherUsersCeremony(t *testing.T) {
+	f := newPasswordlessFixture(t)
+	ctx := context.Background()
+
+	options, ceremonyID, err := f.service.BeginPasskeyRegistration(ctx, f.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := newSoftwareAuthenticator(t).create(t, options)
+	if _, err := f.service.FinishPasskeyRegistration(ctx, uuid.New(), ceremonyID, "", response); !errors.Is(err, ErrInvalidCeremony) {
+		t.Errorf("FinishPasskeyRegistration = %v, want ErrInvalidCeremony", err)
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"http_server/auth-service/internal/domain/models"
+	"http_server/auth-service/internal/domain/repository"
+	"http_server/shared/jwtkeys"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// accessTokenTTL is how long issued access tokens stay valid, which is as
+// long as verifying services accept keys retired by a rotation.
+const accessTokenTTL = jwtkeys.TokenLifetime
+
+// tokenIssuer signs access tokens. Every login method goes through it so
+// tokens carry the same claims however the user authenticated.
+type tokenIssuer struct {
+	roleRepo repository.RoleRepository
+	keys     *jwtkeys.Keys
+}
+
+func (t *tokenIssuer) issue(ctx context.Context, user *models.User) (string, error) {
+	roles, err := t.roleRepo.GetUserRoles(ctx, user.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user roles: %w", err)
+	}
+	roleNames := make([]string, len(roles))
+	for i, role := range roles {
+		roleNames[i] = role.Name
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": user.ID.String(),
+		"email":   user.Email,
+		"roles":   roleNames,
+		"sv":      user.SessionVersion,
+		"exp":     now.Add(accessTokenTTL).Unix(),
+		"iat":     now.Unix(),
+	})
+
+	_, span := tracer.Start(ctx, "jwt.sign", trace.WithAttributes(attribute.String("jwt.alg", jwt.SigningMethodHS256.Alg())))
+	signed, err := token.SignedString(t.keys.SigningKey())
+	endSpan(span, err)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return signed, nil
+}
+
+// checkLoginAllowed rejects users who may not log in by any method.
+func checkLoginAllowed(user *models.User) error {
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		return ErrAccountLocked
+	}
+	if !user.Active || user.DeletedAt != nil {
+		return ErrAccountInactive
+	}
+	return nil
+}
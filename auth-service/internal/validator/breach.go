@@ -0,0 +1,79 @@
+package validator
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// hashPrefixLength is the number of hex characters of the SHA-1 hash used to
+// pick a bucket, as in the Pwned Passwords range API.
+const hashPrefixLength = 5
+
+// BreachChecker reports whether a password is known from a data breach.
+type BreachChecker interface {
+	IsBreached(ctx context.Context, password string) (bool, error)
+}
+
+type hashPrefixBreachChecker struct {
+	dir       string
+	threshold int
+}
+
+// NewHashPrefixBreachChecker checks passwords against a local copy of the
+// Pwned Passwords SHA-1 dataset split into range files: dir/ABCDE.txt holds
+// "SUFFIX:COUNT" lines for every hash starting with ABCDE. Only the bucket
+// for the password's hash prefix is read. A password counts as breached
+// when it was seen at least threshold times.
+func NewHashPrefixBreachChecker(dir string, threshold int) (BreachChecker, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, errors.New(dir + " is not a directory")
+	}
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &hashPrefixBreachChecker{dir: dir, threshold: threshold}, nil
+}
+
+func (c *hashPrefixBreachChecker) IsBreached(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:hashPrefixLength], hash[hashPrefixLength:]
+
+	file, err := os.Open(filepath.Join(c.dir, prefix+".txt"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// No hash in the dataset has this prefix.
+			return false, nil
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		lineSuffix, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || !strings.EqualFold(lineSuffix, suffix) {
+			continue
+		}
+		seen, err := strconv.Atoi(count)
+		if err != nil {
+			return false, err
+		}
+		return seen >= c.threshold, nil
+	}
+	return false, scanner.Err()
+}
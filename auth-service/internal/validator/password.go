@@ -1,29 +1,67 @@
 package validator
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"os"
+	"strings"
 	"unicode"
+	"unicode/utf8"
+
+	"github.com/nbutton23/zxcvbn-go"
 )
 
+// minPersonalTokenLength is the shortest email or name fragment a password
+// may not contain; shorter fragments match too many unrelated passwords.
+const minPersonalTokenLength = 3
+
 type PasswordConfig struct {
 	MinLength      int
+	MaxLength      int
 	RequireUpper   bool
 	RequireLower   bool
 	RequireNumber  bool
 	RequireSpecial bool
+	// MinStrength is the lowest accepted zxcvbn score, from 0 (too
+	// guessable) to 4 (very unguessable). Zero disables the check.
+	MinStrength int
+	// RejectList holds passwords that are refused outright, compared
+	// case-insensitively.
+	RejectList []string
 }
 
+// PasswordValidator enforces the password policy for every path that sets a
+// password: registration, change and reset.
 type PasswordValidator struct {
-	config PasswordConfig
+	config   PasswordConfig
+	rejected map[string]struct{}
+	breaches BreachChecker
 }
 
-func NewPasswordValidator(config PasswordConfig) *PasswordValidator {
-	return &PasswordValidator{config: config}
+// NewPasswordValidator builds the policy. breaches may be nil to skip the
+// breached-password check.
+func NewPasswordValidator(config PasswordConfig, breaches BreachChecker) *PasswordValidator {
+	rejected := make(map[string]struct{}, len(config.RejectList))
+	for _, password := range config.RejectList {
+		rejected[strings.ToLower(password)] = struct{}{}
+	}
+	return &PasswordValidator{config: config, rejected: rejected, breaches: breaches}
 }
 
-func (v *PasswordValidator) Validate(password string) error {
-	if len(password) < v.config.MinLength {
-		return fmt.Errorf("password must be at least %d characters long", v.config.MinLength)
+// Validate checks password against the policy. userInputs are the email,
+// name and similar values the password must not contain. Failures are
+// returned as *ValidationError on the "password" field.
+func (v *PasswordValidator) Validate(ctx context.Context, password string, userInputs ...string) error {
+	if password == "" {
+		return passwordError(CodeRequired, "password is required")
+	}
+	length := utf8.RuneCountInString(password)
+	if length < v.config.MinLength {
+		return passwordError(CodeTooShort, fmt.Sprintf("password must be at least %d characters long", v.config.MinLength))
+	}
+	if v.config.MaxLength > 0 && len(password) > v.config.MaxLength {
+		return passwordError(CodeTooLong, fmt.Sprintf("password must be at most %d bytes long", v.config.MaxLength))
 	}
 
 	var hasUpper, hasLower, hasNumber, hasSpecial bool
@@ -42,20 +80,93 @@ func (v *PasswordValidator) Validate(password string) error {
 	}
 
 	if v.config.RequireUpper && !hasUpper {
-		return fmt.Errorf("password must contain at least one uppercase letter")
+		return passwordError(CodeMissingCharacter, "password must contain at least one uppercase letter")
 	}
 
 	if v.config.RequireLower && !hasLower {
-		return fmt.Errorf("password must contain at least one lowercase letter")
+		return passwordError(CodeMissingCharacter, "password must contain at least one lowercase letter")
 	}
 
 	if v.config.RequireNumber && !hasNumber {
-		return fmt.Errorf("password must contain at least one number")
+		return passwordError(CodeMissingCharacter, "password must contain at least one number")
 	}
 
 	if v.config.RequireSpecial && !hasSpecial {
-		return fmt.Errorf("password must contain at least one special character")
+		return passwordError(CodeMissingCharacter, "password must contain at least one special character")
+	}
+
+	lower := strings.ToLower(password)
+	if _, ok := v.rejected[lower]; ok {
+		return passwordError(CodeRejected, "password is not allowed")
+	}
+	if containsPersonalInfo(lower, userInputs) {
+		return passwordError(CodePersonalInfo, "password must not contain your email address or name")
+	}
+
+	if v.config.MinStrength > 0 {
+		if score := zxcvbn.PasswordStrength(password, userInputs).Score; score < v.config.MinStrength {
+			return passwordError(CodeTooWeak, "password is too easy to guess")
+		}
+	}
+
+	if v.breaches != nil {
+		breached, err := v.breaches.IsBreached(ctx, password)
+		if err != nil {
+			return fmt.Errorf("failed to check breached passwords: %w", err)
+		}
+		if breached {
+			return passwordError(CodeBreached, "password has appeared in a data breach")
+		}
 	}
 
 	return nil
 }
+
+func passwordError(code, message string) *ValidationError {
+	return &ValidationError{Field: "password", Code: code, Message: message}
+}
+
+// containsPersonalInfo reports whether password (lower-cased) contains any
+// user input or a word of one. Only the local part of an email address is
+// considered, since domains such as "gmail" say little about the user.
+func containsPersonalInfo(password string, userInputs []string) bool {
+	for _, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if local, _, ok := strings.Cut(input, "@"); ok {
+			input = local
+		}
+		tokens := strings.FieldsFunc(input, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, token := range append(tokens, input) {
+			if utf8.RuneCountInString(token) >= minPersonalTokenLength && strings.Contains(password, token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LoadRejectList reads one password per line from path, skipping blank
+// lines and lines starting with '#'.
+func LoadRejectList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var passwords []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords = append(passwords, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return passwords, nil
+}
@@ -1,18 +1,34 @@
 package validator
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
+
+	apperrors "http_server/shared/errors"
 )
 
 var (
-	emailRegex        = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-	passwordMinLength = 8
+	emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+)
+
+// Codes reported in ValidationError.Code.
+const (
+	CodeRequired         = "required"
+	CodeInvalidFormat    = "invalid_format"
+	CodeTooShort         = "too_short"
+	CodeTooLong          = "too_long"
+	CodeTooWeak          = "too_weak"
+	CodeMissingCharacter = "missing_character_class"
+	CodeRejected         = "rejected"
+	CodePersonalInfo     = "contains_personal_info"
+	CodeBreached         = "breached"
 )
 
 type ValidationError struct {
 	Field   string
+	Code    string
 	Message string
 }
 
@@ -23,48 +39,53 @@ func (e *ValidationError) Error() string {
 func ValidateEmail(email string) error {
 	email = strings.TrimSpace(email)
 	if email == "" {
-		return &ValidationError{Field: "email", Message: "email is required"}
+		return &ValidationError{Field: "email", Code: CodeRequired, Message: "email is required"}
 	}
 	if !emailRegex.MatchString(email) {
-		return &ValidationError{Field: "email", Message: "invalid email format"}
+		return &ValidationError{Field: "email", Code: CodeInvalidFormat, Message: "invalid email format"}
 	}
 	return nil
 }
 
-func ValidatePassword(password string) error {
-	if len(password) < passwordMinLength {
-		return &ValidationError{Field: "password", Message: fmt.Sprintf("password must be at least %d characters", passwordMinLength)}
-	}
-
-	hasUpper := false
-	hasLower := false
-	hasNumber := false
-
-	for _, char := range password {
-		switch {
-		case 'A' <= char && char <= 'Z':
-			hasUpper = true
-		case 'a' <= char && char <= 'z':
-			hasLower = true
-		case '0' <= char && char <= '9':
-			hasNumber = true
-		}
+// ValidateRequired rejects an empty value. Login uses it for the password,
+// which must not be held to a policy that may have changed since it was set.
+func ValidateRequired(field, value string) error {
+	if value == "" {
+		return &ValidationError{Field: field, Code: CodeRequired, Message: field + " is required"}
 	}
-
-	if !hasUpper || !hasLower || !hasNumber {
-		return &ValidationError{Field: "password", Message: "password must contain at least one uppercase letter, one lowercase letter, and one number"}
-	}
-
 	return nil
 }
 
 func ValidateName(name string) error {
 	name = strings.TrimSpace(name)
 	if name == "" {
-		return &ValidationError{Field: "name", Message: "name is required"}
+		return &ValidationError{Field: "name", Code: CodeRequired, Message: "name is required"}
 	}
 	if len(name) < 2 {
-		return &ValidationError{Field: "name", Message: "name must be at least 2 characters"}
+		return &ValidationError{Field: "name", Code: CodeTooShort, Message: "name must be at least 2 characters"}
 	}
 	return nil
 }
+
+// Collect runs every check and returns the failures as field errors, or nil
+// when all checks pass. Errors that are not ValidationErrors are reported
+// against an empty field.
+func Collect(checks ...error) []apperrors.FieldError {
+	var fields []apperrors.FieldError
+	for _, err := range checks {
+		if err == nil {
+			continue
+		}
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			fields = append(fields, apperrors.FieldError{
+				Field:   validationErr.Field,
+				Code:    validationErr.Code,
+				Message: validationErr.Message,
+			})
+			continue
+		}
+		fields = append(fields, apperrors.FieldError{Code: CodeInvalidFormat, Message: err.Error()})
+	}
+	return fields
+}
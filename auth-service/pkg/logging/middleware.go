@@ -1,66 +0,0 @@
-package logging
-
-import (
-	"net/http"
-	"time"
-
-	"github.com/google/uuid"
-	"go.uber.org/zap"
-)
-
-// LoggingMiddleware creates a middleware that logs HTTP request details
-func LoggingMiddleware(logger *Logger) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			start := time.Now()
-
-			// Generate request ID if not present
-			requestID := r.Header.Get("X-Request-ID")
-			if requestID == "" {
-				requestID = uuid.New().String()
-			}
-
-			// Create a wrapped response writer to capture status code
-			ww := &responseWriter{w: w, status: http.StatusOK}
-
-			// Add request context fields
-			logger.Info("request_started",
-				zap.String("request_id", requestID),
-				zap.String("method", r.Method),
-				zap.String("path", r.URL.Path),
-				zap.String("remote_addr", r.RemoteAddr),
-				zap.String("user_agent", r.UserAgent()),
-			)
-
-			// Call the next handler
-			next.ServeHTTP(ww, r)
-
-			// Log request completion
-			duration := time.Since(start)
-			logger.Info("request_completed",
-				zap.String("request_id", requestID),
-				zap.Int("status", ww.status),
-				zap.Duration("duration", duration),
-			)
-		})
-	}
-}
-
-// responseWriter is a wrapper around http.ResponseWriter that captures the status code
-type responseWriter struct {
-	w      http.ResponseWriter
-	status int
-}
-
-func (rw *responseWriter) Header() http.Header {
-	return rw.w.Header()
-}
-
-func (rw *responseWriter) Write(b []byte) (int, error) {
-	return rw.w.Write(b)
-}
-
-func (rw *responseWriter) WriteHeader(statusCode int) {
-	rw.status = statusCode
-	rw.w.WriteHeader(statusCode)
-}
@@ -2,13 +2,15 @@ package middleware
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"net/http"
 	"strings"
 
 	"http_server/auth-service/internal/service"
-	"http_server/auth-service/pkg/logging"
 	"http_server/auth-service/pkg/monitoring"
+	"http_server/shared/logging"
+	sharedmw "http_server/shared/middleware"
+	"http_server/shared/problem"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -21,10 +23,9 @@ type authContextKey string
 
 // Auth-related context keys
 const (
-	TraceIDKey = authContextKey("trace_id")
-	UserIDKey  = authContextKey("user_id")
-	EmailKey   = authContextKey("email")
-	RolesKey   = authContextKey("roles")
+	UserIDKey = authContextKey("user_id")
+	EmailKey  = authContextKey("email")
+	RolesKey  = authContextKey("roles")
 )
 
 type AuthMiddleware struct {
@@ -43,32 +44,25 @@ func NewAuthMiddleware(authService service.AuthService, logger *logging.Logger,
 
 func (m *AuthMiddleware) ValidateJWT(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		traceID := uuid.New().String()
-		ctx := context.WithValue(r.Context(), TraceIDKey, traceID)
-		logger := m.logger.WithContext(ctx).With(
-			zap.String("trace_id", traceID),
-			zap.String("remote_ip", r.RemoteAddr),
-			zap.String("method", r.Method),
-			zap.String("path", r.URL.Path),
-		)
-
-		logger.Debug("Starting JWT token validation")
+		ctx := r.Context()
+		logger := m.logger.WithContext(ctx)
+
 		m.metrics.AuthRequests.Inc()
 
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
 			logger.Warn("Missing authorization header")
 			m.metrics.AuthFailures.WithLabelValues("missing_header").Inc()
-			http.Error(w, "Authorization header required", http.StatusUnauthorized)
+			problem.Write(w, r, errMissingAuthorization)
 			return
 		}
 
 		bearerToken := strings.Split(authHeader, " ")
 		if len(bearerToken) != 2 || bearerToken[0] != "Bearer" {
-			logger.Warn("Invalid authorization format",
-				zap.String("auth_header", authHeader))
+			// The header may hold a credential, so only its shape is logged
+			logger.Warn("Invalid authorization format", zap.Int("header_length", len(authHeader)))
 			m.metrics.AuthFailures.WithLabelValues("invalid_format").Inc()
-			http.Error(w, "Invalid authorization format", http.StatusUnauthorized)
+			problem.Write(w, r, errInvalidAuthorization)
 			return
 		}
 
@@ -78,76 +72,72 @@ func (m *AuthMiddleware) ValidateJWT(next http.Handler) http.Handler {
 		timer := prometheus.NewTimer(m.metrics.TokenValidationDuration)
 		defer timer.ObserveDuration()
 
-		token, err = m.authService.ValidateToken(bearerToken[1])
+		token, err = m.authService.ValidateToken(ctx, bearerToken[1])
 
 		if err != nil {
-			logger.Warn("Token validation failed",
-				zap.Error(err),
-				zap.String("token_length", fmt.Sprintf("%d", len(bearerToken[1]))),
-				zap.String("validation_error", err.Error()))
+			logger.Warn("Token validation failed", zap.Error(err))
 			m.metrics.AuthFailures.WithLabelValues("invalid_token").Inc()
 
-			var statusCode int
-			var message string
-
-			switch err {
-			case service.ErrTokenExpired:
-				statusCode = http.StatusUnauthorized
-				message = "Token has expired"
-			case service.ErrInvalidToken:
-				statusCode = http.StatusUnauthorized
-				message = "Invalid token"
+			switch {
+			case errors.Is(err, service.ErrTokenExpired):
+				problem.Write(w, r, errTokenExpired)
+			case errors.Is(err, service.ErrInvalidToken):
+				problem.Write(w, r, errInvalidToken)
 			default:
-				statusCode = http.StatusInternalServerError
-				message = "Internal server error"
+				problem.Write(w, r, err)
 			}
-
-			http.Error(w, message, statusCode)
 			return
 		}
 
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok {
-			logger.Error("Failed to parse token claims")
+			logger.Error("Failed to parse token claims", nil)
 			m.metrics.AuthFailures.WithLabelValues("invalid_claims").Inc()
-			http.Error(w, "Invalid token claims", http.StatusUnauthorized)
+			problem.Write(w, r, errInvalidClaims)
 			return
 		}
 
 		userID, ok := claims["user_id"].(string)
 		if !ok {
-			logger.Error("Invalid user_id claim type")
+			logger.Error("Invalid user_id claim type", nil)
 			m.metrics.AuthFailures.WithLabelValues("invalid_user_id").Inc()
-			http.Error(w, "Invalid token claims", http.StatusUnauthorized)
+			problem.Write(w, r, errInvalidClaims)
 			return
 		}
 
 		email, ok := claims["email"].(string)
 		if !ok {
-			logger.Error("Invalid email claim type")
+			logger.Error("Invalid email claim type", nil)
 			m.metrics.AuthFailures.WithLabelValues("invalid_email").Inc()
-			http.Error(w, "Invalid token claims", http.StatusUnauthorized)
+			problem.Write(w, r, errInvalidClaims)
 			return
 		}
 
 		roles, ok := claims["roles"].([]interface{})
 		if !ok {
-			logger.Error("Invalid roles claim type")
+			logger.Error("Invalid roles claim type", nil)
 			m.metrics.AuthFailures.WithLabelValues("invalid_roles").Inc()
-			http.Error(w, "Invalid token claims", http.StatusUnauthorized)
+			problem.Write(w, r, errInvalidClaims)
 			return
 		}
 
 		ctx = context.WithValue(ctx, UserIDKey, userID)
 		ctx = context.WithValue(ctx, EmailKey, email)
 		ctx = context.WithValue(ctx, RolesKey, roles)
-
-		logger.Debug("JWT token validated successfully",
-			zap.String("user_id", userID),
-			zap.String("email", email),
-			zap.Any("roles", roles))
+		ctx = logging.ContextWithFields(ctx, zap.String("user_id", userID))
+		sharedmw.AnnotateAccessLog(ctx, zap.String("user_id", userID))
 
 		m.metrics.AuthSuccess.Inc()
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// UserIDFromContext returns the ID of the user authenticated by ValidateJWT.
+func UserIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	raw, ok := ctx.Value(UserIDKey).(string)
+	if !ok {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(raw)
+	return id, err == nil
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gorilla/handlers"
+)
+
+// CORSConfig lists what cross-origin callers may do. An origin of "*"
+// allows any origin and cannot be combined with AllowCredentials.
+type CORSConfig struct {
+	Enabled          bool
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+}
+
+// NewCORS answers preflight requests and sets the CORS response headers.
+func NewCORS(config CORSConfig) func(http.Handler) http.Handler {
+	opts := []handlers.CORSOption{
+		handlers.AllowedOrigins(config.AllowedOrigins),
+		handlers.AllowedMethods(config.AllowedMethods),
+		handlers.AllowedHeaders(config.AllowedHeaders),
+		handlers.ExposedHeaders(config.ExposedHeaders),
+	}
+	if config.AllowCredentials {
+		opts = append(opts, handlers.AllowCredentials())
+	}
+	return handlers.CORS(opts...)
+}
+
+// reloadableCORS lets the CORS policy change while the router keeps using
+// the same middleware. A disabled policy passes requests through.
+type reloadableCORS struct {
+	current atomic.Pointer[func(http.Handler) http.Handler]
+}
+
+func newReloadableCORS(config CORSConfig) *reloadableCORS {
+	c := &reloadableCORS{}
+	c.set(config)
+	return c
+}
+
+func (c *reloadableCORS) set(config CORSConfig) {
+	var cors func(http.Handler) http.Handler
+	if config.Enabled {
+		cors = NewCORS(config)
+	}
+	c.current.Store(&cors)
+}
+
+func (c *reloadableCORS) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cors := *c.current.Load()
+		if cors == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cors(next).ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	apperrors "http_server/shared/errors"
+)
+
+// Errors written by the middleware as problem responses.
+var (
+	errMissingAuthorization = apperrors.NewAuthenticationError("Authorization header required", nil).WithCode("missing_authorization")
+	errInvalidAuthorization = apperrors.NewAuthenticationError("Invalid authorization format", nil).WithCode("invalid_authorization")
+	errTokenExpired         = apperrors.NewAuthenticationError("Token has expired", nil).WithCode("token_expired")
+	errInvalidToken         = apperrors.NewAuthenticationError("Invalid token", nil).WithCode("invalid_token")
+	errInvalidClaims        = apperrors.NewAuthenticationError("Invalid token claims", nil).WithCode("invalid_token_claims")
+	errUnauthenticated      = apperrors.NewAuthenticationError("Authentication required", nil).WithCode("unauthorized")
+	errForbidden            = apperrors.NewAuthorizationError("Insufficient permissions", nil).WithCode("forbidden")
+	errRateLimited          = apperrors.NewRateLimitedError("Rate limit exceeded", nil).WithCode("rate_limited")
+)
@@ -1,9 +1,12 @@
 package middleware
 
 import (
+	"net/http"
+
 	"http_server/auth-service/internal/service"
-	"http_server/auth-service/pkg/logging"
 	"http_server/auth-service/pkg/monitoring"
+	"http_server/shared/logging"
+	sharedmw "http_server/shared/middleware"
 )
 
 // Config holds all middleware configuration
@@ -15,13 +18,20 @@ type Config struct {
 	}
 	Security struct {
 		Enabled bool
+		Headers HeadersConfig
 	}
+	CORS CORSConfig
 	Auth struct {
 		Enabled bool
 	}
 	RBAC struct {
 		Enabled bool
 	}
+	MachineAuth struct {
+		Enabled bool
+		// Principals maps client certificate identities to principals
+		Principals map[string]string
+	}
 }
 
 // Middleware holds all middleware instances and their dependencies
@@ -30,6 +40,8 @@ type Middleware struct {
 	rbac      *RBACMiddleware
 	rateLimit *RateLimiter
 	security  *SecurityMiddleware
+	cors      *reloadableCORS
+	machine   *sharedmw.MachineAuth
 	logging   *logging.Logger
 	metrics   *monitoring.Metrics
 }
@@ -55,12 +67,29 @@ func NewMiddleware(config *Config, authService service.AuthService, logger *logg
 	}
 
 	if config.Security.Enabled {
-		m.security = NewSecurityMiddleware(config.RateLimit.RequestsPerMinute, config.RateLimit.Burst)
+		m.security = NewSecurityMiddleware(config.Security.Headers)
 	}
 
+	if config.MachineAuth.Enabled {
+		m.machine = sharedmw.NewMachineAuth(config.MachineAuth.Principals, logger)
+	}
+
+	// CORS is always installed so that a reload can turn it on
+	m.cors = newReloadableCORS(config.CORS)
+
 	return m
 }
 
+// Reload applies the settings that can change without rebuilding the
+// router: the rate limits and the CORS policy. Turning rate limiting on or
+// off still needs a restart.
+func (m *Middleware) Reload(config *Config) {
+	if m.rateLimit != nil && config.RateLimit.Enabled {
+		m.rateLimit.SetLimit(config.RateLimit.RequestsPerMinute, config.RateLimit.Burst)
+	}
+	m.cors.set(config.CORS)
+}
+
 // GetAuthMiddleware returns the auth middleware instance
 func (m *Middleware) GetAuthMiddleware() *AuthMiddleware {
 	return m.auth
@@ -80,3 +109,15 @@ func (m *Middleware) GetRateLimiter() *RateLimiter {
 func (m *Middleware) GetSecurityMiddleware() *SecurityMiddleware {
 	return m.security
 }
+
+// GetCORS returns the CORS middleware; it passes requests through while
+// CORS is disabled
+func (m *Middleware) GetCORS() func(http.Handler) http.Handler {
+	return m.cors.Middleware
+}
+
+// GetMachineAuthMiddleware returns the client certificate middleware, or nil
+// when mutual TLS is not configured
+func (m *Middleware) GetMachineAuthMiddleware() *sharedmw.MachineAuth {
+	return m.machine
+}
@@ -1,23 +1,36 @@
 package middleware
 
 import (
+	"net"
 	"net/http"
 	"sync"
 	"time"
 
+	"http_server/shared/problem"
+
 	"golang.org/x/time/rate"
 )
 
+// idleLimiterTTL is how long a client's limiter is kept after its last
+// request.
+const idleLimiterTTL = time.Hour
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter limits each client IP to a token bucket of its own.
 type RateLimiter struct {
-	limiters map[string]*rate.Limiter
-	mutex    sync.RWMutex
+	limiters map[string]*clientLimiter
+	mutex    sync.Mutex
 	r        rate.Limit
 	b        int
 }
 
 func NewRateLimiter(requestsPerMinute int, burst int) *RateLimiter {
 	rl := &RateLimiter{
-		limiters: make(map[string]*rate.Limiter),
+		limiters: make(map[string]*clientLimiter),
 		r:        rate.Limit(float64(requestsPerMinute) / 60.0),
 		b:        burst,
 	}
@@ -29,23 +42,35 @@ func (rl *RateLimiter) getLimiter(ip string) *rate.Limiter {
 	rl.mutex.Lock()
 	defer rl.mutex.Unlock()
 
-	limiter, exists := rl.limiters[ip]
+	client, exists := rl.limiters[ip]
 	if !exists {
-		limiter = rate.NewLimiter(rl.r, rl.b)
-		rl.limiters[ip] = limiter
+		client = &clientLimiter{limiter: rate.NewLimiter(rl.r, rl.b)}
+		rl.limiters[ip] = client
 	}
+	client.lastSeen = time.Now()
 
-	return limiter
+	return client.limiter
+}
+
+// SetLimit changes the limits, including for clients already seen.
+func (rl *RateLimiter) SetLimit(requestsPerMinute int, burst int) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	rl.r = rate.Limit(float64(requestsPerMinute) / 60.0)
+	rl.b = burst
+	for _, client := range rl.limiters {
+		client.limiter.SetLimit(rl.r)
+		client.limiter.SetBurst(rl.b)
+	}
 }
 
 func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := r.RemoteAddr
-		limiter := rl.getLimiter(ip)
+		limiter := rl.getLimiter(clientIP(r))
 
 		if !limiter.Allow() {
-			w.WriteHeader(http.StatusTooManyRequests)
-			w.Write([]byte("rate limit exceeded"))
+			problem.Write(w, r, errRateLimited)
 			return
 		}
 
@@ -57,11 +82,21 @@ func (rl *RateLimiter) cleanup() {
 	for {
 		time.Sleep(time.Minute)
 		rl.mutex.Lock()
-		for ip := range rl.limiters {
-			if time.Since(time.Now()) > time.Hour {
+		for ip, client := range rl.limiters {
+			if time.Since(client.lastSeen) > idleLimiterTTL {
 				delete(rl.limiters, ip)
 			}
 		}
 		rl.mutex.Unlock()
 	}
 }
+
+// clientIP strips the port from the remote address so every connection
+// from one client shares a limiter.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -4,10 +4,10 @@ import (
 	"net/http"
 
 	"http_server/auth-service/internal/service"
-	"http_server/auth-service/pkg/logging"
 	"http_server/auth-service/pkg/monitoring"
+	"http_server/shared/logging"
+	"http_server/shared/problem"
 
-	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -28,35 +28,25 @@ func NewRBACMiddleware(authService service.AuthService, logger *logging.Logger,
 func (m *RBACMiddleware) RequireRole(roles ...string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			traceID := r.Context().Value("trace_id").(string)
-			logger := m.logger.WithContext(r.Context()).With(zap.String("trace_id", traceID))
+			logger := m.logger.WithContext(r.Context())
 			logger.Debug("Checking user role permissions", zap.Strings("required_roles", roles))
 
 			m.metrics.RBACRequests.Inc()
 
-			userID, ok := r.Context().Value("user_id").(string)
+			userUUID, ok := UserIDFromContext(r.Context())
 			if !ok {
-				logger.Error("User ID not found in context")
+				logger.Error("User ID not found in context", nil)
 				m.metrics.RBACFailures.WithLabelValues("missing_user_id").Inc()
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
-				return
-			}
-
-			userUUID, err := uuid.Parse(userID)
-			if err != nil {
-				logger.Error("Invalid user ID format", zap.Error(err), zap.String("user_id", userID))
-				m.metrics.RBACFailures.WithLabelValues("invalid_user_id").Inc()
-				http.Error(w, "Invalid user ID", http.StatusBadRequest)
+				problem.Write(w, r, errUnauthenticated)
 				return
 			}
+			userID := userUUID.String()
 
 			userRoles, err := m.authService.GetUserRoles(r.Context(), userUUID)
 			if err != nil {
-				logger.Error("Failed to get user roles",
-					zap.Error(err),
-					zap.String("user_id", userID))
+				logger.Error("Failed to get user roles", err, zap.String("user_id", userID))
 				m.metrics.RBACFailures.WithLabelValues("role_lookup_failed").Inc()
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				problem.Write(w, r, err)
 				return
 			}
 
@@ -79,7 +69,7 @@ func (m *RBACMiddleware) RequireRole(roles ...string) func(http.Handler) http.Ha
 					zap.Strings("required_roles", roles),
 					zap.Strings("user_roles", userRoles))
 				m.metrics.RBACFailures.WithLabelValues("insufficient_permissions").Inc()
-				http.Error(w, "Forbidden", http.StatusForbidden)
+				problem.Write(w, r, errForbidden)
 				return
 			}
 
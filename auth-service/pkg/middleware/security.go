@@ -1,57 +1,36 @@
 package middleware
 
 import (
-	"context"
+	"fmt"
 	"net/http"
 	"time"
-
-	"github.com/google/uuid"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/trace"
-	"golang.org/x/time/rate"
 )
 
-// contextKey is a custom type for context keys to avoid collisions
-type contextKey string
-
-// RequestIDKey is the context key for request ID
-const RequestIDKey = contextKey("request_id")
-
-type SecurityMiddleware struct {
-	limiter *rate.Limiter
-	tracer  trace.Tracer
-}
-
-func NewSecurityMiddleware(requestsPerMinute int, burst int) *SecurityMiddleware {
-	return &SecurityMiddleware{
-		limiter: rate.NewLimiter(rate.Every(time.Minute/time.Duration(requestsPerMinute)), burst),
-		tracer:  otel.Tracer("auth-service"),
-	}
+// HeadersConfig selects the optional headers set by SecureHeaders.
+type HeadersConfig struct {
+	EnableCSP     bool
+	CSPDirectives string
+	EnableHSTS    bool
+	HSTSMaxAge    time.Duration
 }
 
-func (m *SecurityMiddleware) RateLimit(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !m.limiter.Allow() {
-			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
-			return
-		}
-		next.ServeHTTP(w, r)
-	})
+type SecurityMiddleware struct {
+	csp  string
+	hsts string
 }
 
-func (m *SecurityMiddleware) RequestID(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestID := r.Header.Get("X-Request-ID")
-		if requestID == "" {
-			requestID = uuid.New().String()
+func NewSecurityMiddleware(config HeadersConfig) *SecurityMiddleware {
+	m := &SecurityMiddleware{}
+	if config.EnableCSP {
+		m.csp = config.CSPDirectives
+		if m.csp == "" {
+			m.csp = "default-src 'self'; frame-ancestors 'none'"
 		}
-
-		ctx := context.WithValue(r.Context(), RequestIDKey, requestID)
-		w.Header().Set("X-Request-ID", requestID)
-
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+	}
+	if config.EnableHSTS {
+		m.hsts = fmt.Sprintf("max-age=%d; includeSubDomains", int64(config.HSTSMaxAge.Seconds()))
+	}
+	return m
 }
 
 func (m *SecurityMiddleware) SecureHeaders(next http.Handler) http.Handler {
@@ -59,23 +38,13 @@ func (m *SecurityMiddleware) SecureHeaders(next http.Handler) http.Handler {
 		w.Header().Set("X-Frame-Options", "DENY")
 		w.Header().Set("X-Content-Type-Options", "nosniff")
 		w.Header().Set("X-XSS-Protection", "1; mode=block")
-		w.Header().Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
-		w.Header().Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+		if m.csp != "" {
+			w.Header().Set("Content-Security-Policy", m.csp)
+		}
+		if m.hsts != "" {
+			w.Header().Set("Strict-Transport-Security", m.hsts)
+		}
 
 		next.ServeHTTP(w, r)
 	})
 }
-
-func (m *SecurityMiddleware) Tracing(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx, span := m.tracer.Start(r.Context(), "http_request")
-		defer span.End()
-
-		span.SetAttributes(
-			attribute.String("http.method", r.Method),
-			attribute.String("http.url", r.URL.String()),
-		)
-
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
-}
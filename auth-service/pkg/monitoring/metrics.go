@@ -1,13 +1,13 @@
 package monitoring
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 type Metrics struct {
-	RequestDuration         *prometheus.HistogramVec
-	RequestTotal            *prometheus.CounterVec
 	ErrorTotal              *prometheus.CounterVec
 	RegisterRequests        prometheus.Counter
 	RegisterFailures        *prometheus.CounterVec
@@ -25,21 +25,21 @@ type Metrics struct {
 	RBACRequests            prometheus.Counter
 	RBACFailures            *prometheus.CounterVec
 	RBACSuccess             prometheus.Counter
-	Uptime                  prometheus.Gauge
+	Uptime                  prometheus.GaugeFunc
 }
 
-func NewMetrics(namespace string) *Metrics {
+// NewMetrics creates the auth collectors under namespace and registers them
+// with reg, or with the default registry if reg is nil. Each registry can
+// hold only one set.
+func NewMetrics(namespace string, reg prometheus.Registerer) *Metrics {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	factory := promauto.With(reg)
+	start := time.Now()
+
 	return &Metrics{
-		RequestDuration: promauto.NewHistogramVec(
-			prometheus.HistogramOpts{
-				Namespace: namespace,
-				Name:      "request_duration_seconds",
-				Help:      "Duration of HTTP requests in seconds",
-				Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
-			},
-			[]string{"handler", "method", "status"},
-		),
-		TokenValidationDuration: promauto.NewSummary(
+		TokenValidationDuration: factory.NewSummary(
 			prometheus.SummaryOpts{
 				Namespace:  namespace,
 				Name:       "token_validation_duration_seconds",
@@ -47,30 +47,22 @@ func NewMetrics(namespace string) *Metrics {
 				Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
 			},
 		),
-		RBACRequests: promauto.NewCounter(prometheus.CounterOpts{
+		RBACRequests: factory.NewCounter(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "rbac_requests_total",
 			Help:      "Total number of RBAC requests",
 		}),
-		RBACFailures: promauto.NewCounterVec(prometheus.CounterOpts{
+		RBACFailures: factory.NewCounterVec(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "rbac_failures_total",
 			Help:      "Total number of RBAC failures",
 		}, []string{"reason"}),
-		RBACSuccess: promauto.NewCounter(prometheus.CounterOpts{
+		RBACSuccess: factory.NewCounter(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "rbac_success_total",
 			Help:      "Total number of successful RBAC checks",
 		}),
-		RequestTotal: promauto.NewCounterVec(
-			prometheus.CounterOpts{
-				Namespace: namespace,
-				Name:      "requests_total",
-				Help:      "Total number of HTTP requests",
-			},
-			[]string{"handler", "method", "status"},
-		),
-		ErrorTotal: promauto.NewCounterVec(
+		ErrorTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Name:      "errors_total",
@@ -78,89 +70,77 @@ func NewMetrics(namespace string) *Metrics {
 			},
 			[]string{"handler", "type"},
 		),
-		RegisterRequests: promauto.NewCounter(prometheus.CounterOpts{
+		RegisterRequests: factory.NewCounter(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "register_requests_total",
 			Help:      "Total number of registration requests",
 		}),
-		RegisterFailures: promauto.NewCounterVec(prometheus.CounterOpts{
+		RegisterFailures: factory.NewCounterVec(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "register_failures_total",
 			Help:      "Total number of registration failures",
 		}, []string{"reason"}),
-		RegisterSuccess: promauto.NewCounter(prometheus.CounterOpts{
+		RegisterSuccess: factory.NewCounter(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "register_success_total",
 			Help:      "Total number of successful registrations",
 		}),
-		LoginRequests: promauto.NewCounter(prometheus.CounterOpts{
+		LoginRequests: factory.NewCounter(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "login_requests_total",
 			Help:      "Total number of login requests",
 		}),
-		LoginFailures: promauto.NewCounterVec(prometheus.CounterOpts{
+		LoginFailures: factory.NewCounterVec(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "login_failures_total",
 			Help:      "Total number of login failures",
 		}, []string{"reason"}),
-		LoginSuccess: promauto.NewCounter(prometheus.CounterOpts{
+		LoginSuccess: factory.NewCounter(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "login_success_total",
 			Help:      "Total number of successful logins",
 		}),
-		AuthRequests: promauto.NewCounter(prometheus.CounterOpts{
+		AuthRequests: factory.NewCounter(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "auth_requests_total",
 			Help:      "Total number of authentication requests",
 		}),
-		AuthFailures: promauto.NewCounterVec(prometheus.CounterOpts{
+		AuthFailures: factory.NewCounterVec(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "auth_failures_total",
 			Help:      "Total number of authentication failures",
 		}, []string{"reason"}),
-		AuthSuccess: promauto.NewCounter(prometheus.CounterOpts{
+		AuthSuccess: factory.NewCounter(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "auth_success_total",
 			Help:      "Total number of successful authentications",
 		}),
-		TokenValidationRequests: promauto.NewCounter(prometheus.CounterOpts{
+		TokenValidationRequests: factory.NewCounter(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "token_validation_requests_total",
 			Help:      "Total number of token validation requests",
 		}),
-		TokenValidationFailures: promauto.NewCounterVec(prometheus.CounterOpts{
+		TokenValidationFailures: factory.NewCounterVec(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "token_validation_failures_total",
 			Help:      "Total number of token validation failures",
 		}, []string{"reason"}),
-		TokenValidationSuccess: promauto.NewCounter(prometheus.CounterOpts{
+		TokenValidationSuccess: factory.NewCounter(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "token_validation_success_total",
 			Help:      "Total number of successful token validations",
 		}),
 
-		Uptime: promauto.NewGauge(prometheus.GaugeOpts{
+		Uptime: factory.NewGaugeFunc(prometheus.GaugeOpts{
 			Namespace: namespace,
 			Name:      "uptime_seconds",
 			Help:      "The uptime of the service in seconds",
+		}, func() float64 {
+			return time.Since(start).Seconds()
 		}),
 	}
 }
 
-func (m *Metrics) RecordUptime(uptime float64) {
-	m.Uptime.Set(uptime)
-}
-
-func (m *Metrics) RecordRequest(handler, method, status string, duration float64) {
-	labels := prometheus.Labels{
-		"handler": handler,
-		"method":  method,
-		"status":  status,
-	}
-	m.RequestDuration.With(labels).Observe(duration)
-	m.RequestTotal.With(labels).Inc()
-}
-
 func (m *Metrics) RecordError(handler, errorType string) {
 	m.ErrorTotal.With(prometheus.Labels{
 		"handler": handler,
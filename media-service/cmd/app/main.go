@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"http_server/media-service/internal/config"
+	"http_server/media-service/internal/domain/models"
+	"http_server/media-service/internal/domain/repository"
+	"http_server/media-service/internal/handler"
+	"http_server/media-service/internal/server"
+	"http_server/media-service/internal/service"
+	"http_server/media-service/internal/signing"
+	"http_server/media-service/internal/storage"
+	"http_server/shared/database"
+	"http_server/shared/jwtkeys"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+	"http_server/shared/secrets"
+)
+
+func main() {
+	// Load configuration
+	configPath := os.Getenv("CONFIG_PATH")
+	if configPath == "" {
+		configPath = "config.yaml"
+	}
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Initialize logger
+	logger, err := logging.NewLogger(&logging.Config{
+		ServiceName: "media-service",
+		Environment: os.Getenv("APP_ENV"),
+		LogLevel:    cfg.Logging.Level,
+		FilePath:    cfg.Logging.FilePath,
+		MaxSize:     10, // 10MB
+		MaxBackups:  5,
+		MaxAge:      30, // 30 days
+		Compress:    true,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer logger.Sync()
+
+	// Read secrets, refusing placeholder values outside development
+	secretProvider, err := secrets.NewProvider(cfg.Secrets)
+	if err != nil {
+		logger.Fatal("Failed to initialize secrets provider", err)
+	}
+	if err := config.ResolveSecrets(context.Background(), cfg, secretProvider); err != nil {
+		logger.Fatal("Failed to read secrets", err)
+	}
+	if !secrets.IsDevelopment(os.Getenv("APP_ENV")) {
+		if err := config.CheckSecrets(cfg); err != nil {
+			logger.Fatal("Refusing to start with default secrets", err)
+		}
+	}
+
+	// Initialize database connection
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		logger.Fatal("Failed to connect to database", err)
+	}
+
+	if err := db.AutoMigrate(&models.Media{}, &models.Upload{}, &models.UploadChunk{}, &models.ProcessingJob{}, &models.Rendition{}); err != nil {
+		logger.Fatal("Failed to auto-migrate database", err)
+	}
+
+	// Initialize blob storage
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		logger.Fatal("Failed to initialize storage", err)
+	}
+
+	// Initialize URL signing
+	signer, err := signing.NewSigner(cfg.Signing)
+	if err != nil {
+		logger.Fatal("Failed to initialize URL signer", err)
+	}
+	clientIP, err := signing.NewClientIP(cfg.Signing.TrustedProxies)
+	if err != nil {
+		logger.Fatal("Failed to parse trusted proxies", err)
+	}
+
+	// Initialize repositories and services
+	mediaRepo := repository.NewMediaRepository(db)
+	uploadRepo := repository.NewUploadRepository(db)
+	processingRepo := repository.NewProcessingRepository(db)
+	imageProcessor := service.NewImageProcessor(mediaRepo, processingRepo, store, cfg.Images, logger)
+	mediaService := service.NewMediaService(mediaRepo, store, imageProcessor, cfg.Storage, logger)
+	urlService := service.NewSignedURLService(mediaRepo, imageProcessor, signer, cfg.Signing, cfg.Images, logger)
+	uploadService := service.NewUploadService(uploadRepo, mediaService, store, cfg.Storage, cfg.Uploads, logger)
+
+	// Initialize handlers and middleware
+	handlers := server.Handlers{
+		Media:  handler.NewMediaHandler(mediaService, imageProcessor, cfg.Storage.MaxFileSize, logger),
+		Upload: handler.NewUploadHandler(uploadService, cfg.Storage.MaxFileSize, logger),
+		Signed: handler.NewSignedURLHandler(urlService, mediaService, imageProcessor, clientIP, logger),
+	}
+	jwtKeys := jwtkeys.New([]byte(cfg.JWT.SecretKey))
+	authMiddleware := middleware.NewJWTAuth(jwtKeys, logger)
+
+	// Initialize health checks and server
+	sqlDB, err := db.DB()
+	if err != nil {
+		logger.Fatal("Failed to get database instance", err)
+	}
+	healthRegistry := server.NewHealth(cfg, sqlDB, jwtKeys)
+	srv := server.NewServer(cfg, handlers, healthRegistry, authMiddleware, logger)
+
+	// Start background work: upload garbage collection and image processing
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	go uploadService.RunCollector(bgCtx)
+	// Pick up rotations of the JWT key
+	if cfg.Secrets.Provider != "env" {
+		go secrets.Watch(bgCtx, secretProvider, config.SecretJWTKey, cfg.JWT.SecretKey, cfg.Secrets.RefreshInterval,
+			jwtKeys.Rotator(os.Getenv("APP_ENV"), logger), logger)
+	}
+
+	processorDone := make(chan struct{})
+	go func() {
+		imageProcessor.Run(bgCtx)
+		close(processorDone)
+	}()
+
+	// Serve until SIGINT or SIGTERM, then drain in-flight requests
+	if err := srv.Run(context.Background()); err != nil {
+		logger.Error("Server stopped with error", err)
+	}
+
+	// Let in-flight image jobs finish; unfinished ones resume on next start.
+	stopBackground()
+	select {
+	case <-processorDone:
+	case <-time.After(cfg.Server.ShutdownTimeout):
+		logger.Warn("Timed out waiting for image processing to stop")
+	}
+
+	logger.Info("Server shutdown completed")
+}
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	sharedconfig "http_server/shared/config"
+	"http_server/shared/database"
+)
+
+type Config struct {
+	Server   ServerConfig   `mapstructure:"server"`
+	Health   HealthConfig   `mapstructure:"health"`
+	Database DatabaseConfig `mapstructure:"database"`
+	JWT      JWTConfig      `mapstructure:"jwt"`
+	Secrets  SecretsConfig  `mapstructure:"secrets"`
+	Storage  StorageConfig  `mapstructure:"storage"`
+	Uploads  UploadsConfig  `mapstructure:"uploads"`
+	Images   ImagesConfig   `mapstructure:"images"`
+	Signing  SigningConfig  `mapstructure:"signing"`
+	Logging  LoggingConfig  `mapstructure:"logging"`
+}
+
+type ServerConfig struct {
+	Port            int           `mapstructure:"port"`
+	Timeout         time.Duration `mapstructure:"timeout"`
+	ReadTimeout     time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout    time.Duration `mapstructure:"write_timeout"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// DrainDelay is how long readiness fails before the listener closes on
+	// shutdown, giving load balancers time to stop routing here.
+	DrainDelay time.Duration `mapstructure:"drain_delay"`
+}
+
+// HealthConfig controls the dependency checks behind the readiness probe.
+// Results are cached for CacheTTL; each check is bounded by Timeout.
+type HealthConfig struct {
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+// DatabaseConfig holds the connection and pool settings.
+type DatabaseConfig = database.Config
+
+type JWTConfig struct {
+	SecretKey string `mapstructure:"secret_key"`
+}
+
+type StorageConfig struct {
+	Driver       string   `mapstructure:"driver"`
+	Path         string   `mapstructure:"path"`
+	TempDir      string   `mapstructure:"temp_dir"`
+	MaxFileSize  int64    `mapstructure:"max_file_size"`
+	AllowedTypes []string `mapstructure:"allowed_types"`
+	S3           S3Config `mapstructure:"s3"`
+}
+
+type S3Config struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+}
+
+// UploadsConfig controls resumable uploads. The total size of an upload is
+// bounded by StorageConfig.MaxFileSize.
+type UploadsConfig struct {
+	MaxChunkSize int64         `mapstructure:"max_chunk_size"`
+	TTL          time.Duration `mapstructure:"ttl"`
+	GCInterval   time.Duration `mapstructure:"gc_interval"`
+}
+
+type ImagesConfig struct {
+	Workers         int               `mapstructure:"workers"`
+	QueueSize       int               `mapstructure:"queue_size"`
+	MaxAttempts     int               `mapstructure:"max_attempts"`
+	JobTimeout      time.Duration     `mapstructure:"job_timeout"`
+	RequeueInterval time.Duration     `mapstructure:"requeue_interval"`
+	MaxPixels       int64             `mapstructure:"max_pixels"`
+	JPEGQuality     int               `mapstructure:"jpeg_quality"`
+	BlurhashX       int               `mapstructure:"blurhash_x"`
+	BlurhashY       int               `mapstructure:"blurhash_y"`
+	Renditions      []RenditionConfig `mapstructure:"renditions"`
+}
+
+type RenditionConfig struct {
+	Name   string `mapstructure:"name"`
+	Width  int    `mapstructure:"width"`
+	Height int    `mapstructure:"height"`
+	Fit    string `mapstructure:"fit"`
+}
+
+// SigningConfig controls signed media URLs. Keys maps key IDs to secrets;
+// only ActiveKey signs, all keys verify.
+type SigningConfig struct {
+	ActiveKey      string            `mapstructure:"active_key"`
+	Keys           map[string]string `mapstructure:"keys"`
+	BaseURL        string            `mapstructure:"base_url"`
+	DefaultTTL     time.Duration     `mapstructure:"default_ttl"`
+	MaxTTL         time.Duration     `mapstructure:"max_ttl"`
+	TrustedProxies []string          `mapstructure:"trusted_proxies"`
+}
+
+type LoggingConfig struct {
+	Level    string `mapstructure:"level"`
+	FilePath string `mapstructure:"file_path"`
+}
+
+func LoadConfig(path string) (*Config, error) {
+	var config Config
+	if err := sharedconfig.Load(path, &config); err != nil {
+		return nil, err
+	}
+
+	applyDefaults(&config)
+
+	if err := validateConfig(&config); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return &config, nil
+}
+
+func applyDefaults(config *Config) {
+	config.Secrets.ApplyDefaults()
+	if config.Server.ShutdownTimeout == 0 {
+		config.Server.ShutdownTimeout = 30 * time.Second
+	}
+	if config.Health.CacheTTL == 0 {
+		config.Health.CacheTTL = 2 * time.Second
+	}
+	if config.Health.Timeout == 0 {
+		config.Health.Timeout = 2 * time.Second
+	}
+	if config.Storage.Driver == "" {
+		config.Storage.Driver = "local"
+	}
+	if config.Uploads.MaxChunkSize == 0 {
+		config.Uploads.MaxChunkSize = 5 << 20
+	}
+	if config.Uploads.TTL == 0 {
+		config.Uploads.TTL = 24 * time.Hour
+	}
+	if config.Uploads.GCInterval == 0 {
+		config.Uploads.GCInterval = 10 * time.Minute
+	}
+	if config.Images.Workers == 0 {
+		config.Images.Workers = 2
+	}
+	if config.Images.QueueSize == 0 {
+		config.Images.QueueSize = 100
+	}
+	if config.Images.MaxAttempts == 0 {
+		config.Images.MaxAttempts = 3
+	}
+	if config.Images.JobTimeout == 0 {
+		config.Images.JobTimeout = time.Minute
+	}
+	if config.Images.RequeueInterval == 0 {
+		config.Images.RequeueInterval = 30 * time.Second
+	}
+	if config.Images.MaxPixels == 0 {
+		config.Images.MaxPixels = 40_000_000
+	}
+	if config.Images.JPEGQuality == 0 {
+		config.Images.JPEGQuality = 85
+	}
+	if config.Images.BlurhashX == 0 {
+		config.Images.BlurhashX = 4
+	}
+	if config.Images.BlurhashY == 0 {
+		config.Images.BlurhashY = 3
+	}
+	// viper lowercases map keys, so key IDs are matched case-insensitively.
+	config.Signing.ActiveKey = strings.ToLower(config.Signing.ActiveKey)
+	if config.Signing.DefaultTTL == 0 {
+		config.Signing.DefaultTTL = time.Hour
+	}
+	if config.Signing.MaxTTL == 0 {
+		config.Signing.MaxTTL = 24 * time.Hour
+	}
+	for i := range config.Images.Renditions {
+		if config.Images.Renditions[i].Fit == "" {
+			config.Images.Renditions[i].Fit = "contain"
+		}
+	}
+}
+
+func validateConfig(config *Config) error {
+	if config.Server.Port == 0 {
+		return fmt.Errorf("server port is required")
+	}
+	if config.JWT.SecretKey == "" {
+		return fmt.Errorf("JWT secret key is required")
+	}
+	if err := config.Secrets.Validate(); err != nil {
+		return err
+	}
+	if config.Database.Host == "" || config.Database.DBName == "" {
+		return fmt.Errorf("database host and name are required")
+	}
+	if config.Storage.MaxFileSize <= 0 {
+		return fmt.Errorf("storage max file size must be positive")
+	}
+	if len(config.Storage.AllowedTypes) == 0 {
+		return fmt.Errorf("at least one allowed media type is required")
+	}
+
+	if config.Uploads.MaxChunkSize < 0 || config.Uploads.TTL < 0 || config.Uploads.GCInterval < 0 {
+		return fmt.Errorf("upload limits must not be negative")
+	}
+
+	if config.Images.Workers < 1 || config.Images.QueueSize < 1 {
+		return fmt.Errorf("image workers and queue size must be positive")
+	}
+	if config.Images.JPEGQuality < 1 || config.Images.JPEGQuality > 100 {
+		return fmt.Errorf("image JPEG quality must be between 1 and 100")
+	}
+	if config.Images.BlurhashX < 1 || config.Images.BlurhashX > 9 || config.Images.BlurhashY < 1 || config.Images.BlurhashY > 9 {
+		return fmt.Errorf("blurhash components must be between 1 and 9")
+	}
+	seen := make(map[string]bool, len(config.Images.Renditions))
+	for _, r := range config.Images.Renditions {
+		if r.Name == "" || seen[r.Name] {
+			return fmt.Errorf("rendition names must be non-empty and unique")
+		}
+		seen[r.Name] = true
+		if r.Width < 1 || r.Height < 1 {
+			return fmt.Errorf("rendition %q must have positive width and height", r.Name)
+		}
+		if r.Fit != "contain" && r.Fit != "cover" {
+			return fmt.Errorf("rendition %q has unsupported fit %q", r.Name, r.Fit)
+		}
+	}
+
+	if _, ok := config.Signing.Keys[config.Signing.ActiveKey]; !ok {
+		return fmt.Errorf("active signing key %q is not configured", config.Signing.ActiveKey)
+	}
+	for id, secret := range config.Signing.Keys {
+		if len(secret) < 32 {
+			return fmt.Errorf("signing key %q must be at least 32 bytes", id)
+		}
+	}
+	if config.Signing.DefaultTTL > config.Signing.MaxTTL {
+		return fmt.Errorf("signing default TTL exceeds max TTL")
+	}
+
+	switch config.Storage.Driver {
+	case "local":
+		if config.Storage.Path == "" {
+			return fmt.Errorf("storage path is required for the local driver")
+		}
+	case "s3":
+		if config.Storage.S3.Endpoint == "" || config.Storage.S3.Bucket == "" {
+			return fmt.Errorf("S3 endpoint and bucket are required for the s3 driver")
+		}
+	default:
+		return fmt.Errorf("unsupported storage driver %q", config.Storage.Driver)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"context"
+	"sort"
+
+	"http_server/shared/secrets"
+)
+
+// Names of the secrets read through the secrets provider.
+const (
+	SecretJWTKey           = "jwt_secret_key"
+	SecretDatabasePassword = "db_password"
+)
+
+// SecretsConfig selects where secrets are read from. The JWT key is looked
+// up again every refresh interval, so a rotation needs no restart.
+type SecretsConfig = secrets.Config
+
+// secretFields lists each secret with the setting it overrides.
+func secretFields(config *Config) []secrets.Field {
+	return []secrets.Field{
+		{Name: SecretJWTKey, Value: &config.JWT.SecretKey},
+		{Name: SecretDatabasePassword, Value: &config.Database.Password},
+	}
+}
+
+// ResolveSecrets replaces the secret settings with the provider's values.
+func ResolveSecrets(ctx context.Context, config *Config, provider secrets.SecretProvider) error {
+	return secrets.Resolve(ctx, provider, secretFields(config))
+}
+
+// CheckSecrets rejects secrets, including the URL signing keys, left at a
+// known placeholder value.
+func CheckSecrets(config *Config) error {
+	fields := secretFields(config)
+	ids := make([]string, 0, len(config.Signing.Keys))
+	for id := range config.Signing.Keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		key := config.Signing.Keys[id]
+		fields = append(fields, secrets.Field{Name: "signing key " + id, Value: &key})
+	}
+	return secrets.CheckDefaults(fields)
+}
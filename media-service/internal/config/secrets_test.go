@@ -0,0 +1,29 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckSecretsRejectsPlaceholderSigningKeys(t *testing.T) {
+	config := &Config{}
+	config.JWT.SecretKey = "a-real-signing-key"
+	config.Database.Password = "a-real-db-password"
+	config.Signing.Keys = map[string]string{
+		"primary": "change-me-media-url-signing-secret-0001",
+		"next":    "a-real-url-signing-key-with-32-bytes",
+	}
+
+	err := CheckSecrets(config)
+	if err == nil || !strings.Contains(err.Error(), "signing key primary") {
+		t.Fatalf("CheckSecrets = %v, want the placeholder primary key reported", err)
+	}
+	if strings.Contains(err.Error(), "signing key next") {
+		t.Errorf("CheckSecrets = %v, real key reported", err)
+	}
+
+	config.Signing.Keys["primary"] = "another-real-url-signing-key-32-bytes"
+	if err := CheckSecrets(config); err != nil {
+		t.Errorf("CheckSecrets = %v, want nil for real secrets", err)
+	}
+}
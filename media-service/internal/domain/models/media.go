@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Media is an uploaded file as seen by its owner. The bytes live in storage
+// under a key derived from Hash, so identical uploads share one blob.
+type Media struct {
+	ID          uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid"`
+	OwnerID     uuid.UUID  `json:"owner_id" gorm:"type:uuid;not null;index"`
+	Hash        string     `json:"hash" gorm:"size:64;not null;index"`
+	ContentType string     `json:"content_type" gorm:"size:128;not null"`
+	Size        int64      `json:"size" gorm:"not null"`
+	Filename    string     `json:"filename,omitempty" gorm:"size:255"`
+	Width       int        `json:"width,omitempty"`
+	Height      int        `json:"height,omitempty"`
+	Blurhash    string     `json:"blurhash,omitempty" gorm:"size:64"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Processing job statuses
+const (
+	JobPending    = "pending"
+	JobProcessing = "processing"
+	JobCompleted  = "completed"
+	JobFailed     = "failed"
+)
+
+// ProcessingJob tracks generation of renditions for one image. There is at
+// most one job per media.
+type ProcessingJob struct {
+	MediaID    uuid.UUID  `json:"media_id" gorm:"primaryKey;type:uuid"`
+	Status     string     `json:"status" gorm:"size:16;not null;index"`
+	Attempts   int        `json:"attempts" gorm:"not null;default:0"`
+	Error      string     `json:"error,omitempty" gorm:"size:512"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Rendition is a resized copy of an image. Renditions of identical uploads
+// share the object stored under Key.
+type Rendition struct {
+	MediaID     uuid.UUID `json:"-" gorm:"primaryKey;type:uuid"`
+	Name        string    `json:"name" gorm:"primaryKey;size:32"`
+	Key         string    `json:"-" gorm:"size:255;not null"`
+	ContentType string    `json:"content_type" gorm:"size:128;not null"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
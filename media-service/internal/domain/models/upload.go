@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Upload is a resumable upload in progress. Received bytes are kept as
+// UploadChunks in storage until Offset reaches Length, at which point they
+// are assembled into a Media.
+type Upload struct {
+	ID           uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid"`
+	OwnerID      uuid.UUID  `json:"owner_id" gorm:"type:uuid;not null;index"`
+	Length       int64      `json:"length" gorm:"not null"`
+	Offset       int64      `json:"offset" gorm:"not null;default:0"`
+	Filename     string     `json:"filename,omitempty" gorm:"size:255"`
+	DeclaredType string     `json:"declared_type,omitempty" gorm:"size:128"`
+	MediaID      *uuid.UUID `json:"media_id,omitempty" gorm:"type:uuid"`
+	ExpiresAt    time.Time  `json:"expires_at" gorm:"not null;index"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Completed reports whether the upload has been assembled into a Media.
+func (u *Upload) Completed() bool {
+	return u.MediaID != nil
+}
+
+// UploadChunk is one PATCH worth of bytes stored under Key.
+type UploadChunk struct {
+	UploadID  uuid.UUID `gorm:"primaryKey;type:uuid"`
+	Offset    int64     `gorm:"primaryKey"`
+	Size      int64     `gorm:"not null"`
+	Key       string    `gorm:"size:255;not null"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"http_server/media-service/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type MediaRepository interface {
+	Create(ctx context.Context, media *models.Media) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Media, error)
+	// FindByIDs returns the live media among ids, in no particular order.
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Media, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	// UpdateImageInfo records the upright dimensions and blurhash of an image.
+	UpdateImageInfo(ctx context.Context, id uuid.UUID, width, height int, blurhash string) error
+	// CountByHash returns how many live media records reference the blob.
+	CountByHash(ctx context.Context, hash string) (int64, error)
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"http_server/media-service/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type mediaRepository struct {
+	db *gorm.DB
+}
+
+func NewMediaRepository(db *gorm.DB) MediaRepository {
+	return &mediaRepository{db: db}
+}
+
+func (r *mediaRepository) Create(ctx context.Context, media *models.Media) error {
+	result := r.db.WithContext(ctx).Create(media)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateKey
+		}
+		return result.Error
+	}
+	return nil
+}
+
+func (r *mediaRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Media, error) {
+	var media models.Media
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND deleted_at IS NULL", id).
+		First(&media)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, result.Error
+	}
+	return &media, nil
+}
+
+func (r *mediaRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Media, error) {
+	if len(ids) == 0 {
+		return []models.Media{}, nil
+	}
+
+	var media []models.Media
+	result := r.db.WithContext(ctx).
+		Where("id IN ? AND deleted_at IS NULL", ids).
+		Find(&media)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return media, nil
+}
+
+func (r *mediaRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.Media{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Update("deleted_at", time.Now().UTC())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mediaRepository) UpdateImageInfo(ctx context.Context, id uuid.UUID, width, height int, blurhash string) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.Media{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"width":      width,
+			"height":     height,
+			"blurhash":   blurhash,
+			"updated_at": time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mediaRepository) CountByHash(ctx context.Context, hash string) (int64, error) {
+	var count int64
+	result := r.db.WithContext(ctx).
+		Model(&models.Media{}).
+		Where("hash = ? AND deleted_at IS NULL", hash).
+		Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
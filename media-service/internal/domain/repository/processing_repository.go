@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"http_server/media-service/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type ProcessingRepository interface {
+	CreateJob(ctx context.Context, job *models.ProcessingJob) error
+	FindJob(ctx context.Context, mediaID uuid.UUID) (*models.ProcessingJob, error)
+	// ClaimJob moves a job to processing and counts the attempt. Pending jobs
+	// can always be claimed; processing jobs only once started before
+	// staleBefore, which recovers jobs abandoned by a crashed worker. It
+	// returns ErrConflict if the job cannot be claimed.
+	ClaimJob(ctx context.Context, mediaID uuid.UUID, staleBefore time.Time) (*models.ProcessingJob, error)
+	FinishJob(ctx context.Context, mediaID uuid.UUID, status, errMsg string) error
+	// ListClaimable returns jobs that ClaimJob would accept, oldest first.
+	ListClaimable(ctx context.Context, staleBefore time.Time, limit int) ([]models.ProcessingJob, error)
+	// SaveRendition creates or replaces a rendition.
+	SaveRendition(ctx context.Context, rendition *models.Rendition) error
+	FindRendition(ctx context.Context, mediaID uuid.UUID, name string) (*models.Rendition, error)
+	ListRenditions(ctx context.Context, mediaID uuid.UUID) ([]models.Rendition, error)
+}
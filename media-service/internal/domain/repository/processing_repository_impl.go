@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"http_server/media-service/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type processingRepository struct {
+	db *gorm.DB
+}
+
+func NewProcessingRepository(db *gorm.DB) ProcessingRepository {
+	return &processingRepository{db: db}
+}
+
+func (r *processingRepository) CreateJob(ctx context.Context, job *models.ProcessingJob) error {
+	result := r.db.WithContext(ctx).Create(job)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateKey
+		}
+		return result.Error
+	}
+	return nil
+}
+
+func (r *processingRepository) FindJob(ctx context.Context, mediaID uuid.UUID) (*models.ProcessingJob, error) {
+	var job models.ProcessingJob
+	result := r.db.WithContext(ctx).First(&job, "media_id = ?", mediaID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, result.Error
+	}
+	return &job, nil
+}
+
+func (r *processingRepository) ClaimJob(ctx context.Context, mediaID uuid.UUID, staleBefore time.Time) (*models.ProcessingJob, error) {
+	now := time.Now().UTC()
+
+	var claimed []models.ProcessingJob
+	result := r.db.WithContext(ctx).
+		Model(&claimed).
+		Clauses(clause.Returning{}).
+		Where("media_id = ?", mediaID).
+		Where("status = ? OR (status = ? AND started_at < ?)", models.JobPending, models.JobProcessing, staleBefore).
+		Updates(map[string]interface{}{
+			"status":     models.JobProcessing,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"started_at": now,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(claimed) == 0 {
+		return nil, ErrConflict
+	}
+	return &claimed[0], nil
+}
+
+func (r *processingRepository) FinishJob(ctx context.Context, mediaID uuid.UUID, status, errMsg string) error {
+	now := time.Now().UTC()
+	updates := map[string]interface{}{
+		"status":     status,
+		"error":      errMsg,
+		"updated_at": now,
+	}
+	if status == models.JobCompleted || status == models.JobFailed {
+		updates["finished_at"] = now
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&models.ProcessingJob{}).
+		Where("media_id = ?", mediaID).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *processingRepository) ListClaimable(ctx context.Context, staleBefore time.Time, limit int) ([]models.ProcessingJob, error) {
+	var jobs []models.ProcessingJob
+	result := r.db.WithContext(ctx).
+		Where("status = ? OR (status = ? AND started_at < ?)", models.JobPending, models.JobProcessing, staleBefore).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&jobs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return jobs, nil
+}
+
+func (r *processingRepository) SaveRendition(ctx context.Context, rendition *models.Rendition) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "media_id"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"key", "content_type", "width", "height", "size", "created_at"}),
+	}).Create(rendition).Error
+}
+
+func (r *processingRepository) FindRendition(ctx context.Context, mediaID uuid.UUID, name string) (*models.Rendition, error) {
+	var rendition models.Rendition
+	result := r.db.WithContext(ctx).First(&rendition, "media_id = ? AND name = ?", mediaID, name)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, result.Error
+	}
+	return &rendition, nil
+}
+
+func (r *processingRepository) ListRenditions(ctx context.Context, mediaID uuid.UUID) ([]models.Rendition, error) {
+	var renditions []models.Rendition
+	result := r.db.WithContext(ctx).
+		Where("media_id = ?", mediaID).
+		Order("width ASC").
+		Find(&renditions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return renditions, nil
+}
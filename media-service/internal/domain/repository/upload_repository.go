@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"http_server/media-service/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type UploadRepository interface {
+	Create(ctx context.Context, upload *models.Upload) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Upload, error)
+	// AppendChunk records chunk and advances the upload offset past it,
+	// provided the offset still equals chunk.Offset. Otherwise it returns
+	// ErrConflict and records nothing.
+	AppendChunk(ctx context.Context, chunk *models.UploadChunk, expiresAt time.Time) error
+	// ListChunks returns the chunks of an upload in offset order.
+	ListChunks(ctx context.Context, uploadID uuid.UUID) ([]models.UploadChunk, error)
+	// MarkCompleted links the upload to its media and drops its chunk rows.
+	// It returns ErrConflict if the upload was already completed.
+	MarkCompleted(ctx context.Context, id, mediaID uuid.UUID) error
+	// Delete removes the upload and its chunk rows.
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListExpired(ctx context.Context, before time.Time, limit int) ([]models.Upload, error)
+}
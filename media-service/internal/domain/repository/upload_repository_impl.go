@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"http_server/media-service/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type uploadRepository struct {
+	db *gorm.DB
+}
+
+func NewUploadRepository(db *gorm.DB) UploadRepository {
+	return &uploadRepository{db: db}
+}
+
+func (r *uploadRepository) Create(ctx context.Context, upload *models.Upload) error {
+	result := r.db.WithContext(ctx).Create(upload)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateKey
+		}
+		return result.Error
+	}
+	return nil
+}
+
+func (r *uploadRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Upload, error) {
+	var upload models.Upload
+	result := r.db.WithContext(ctx).First(&upload, "id = ?", id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, result.Error
+	}
+	return &upload, nil
+}
+
+func (r *uploadRepository) AppendChunk(ctx context.Context, chunk *models.UploadChunk, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Upload{}).
+			Where("id = ? AND \"offset\" = ? AND media_id IS NULL", chunk.UploadID, chunk.Offset).
+			Updates(map[string]interface{}{
+				"offset":     chunk.Offset + chunk.Size,
+				"expires_at": expiresAt,
+				"updated_at": time.Now().UTC(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrConflict
+		}
+
+		if err := tx.Create(chunk).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrConflict
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *uploadRepository) ListChunks(ctx context.Context, uploadID uuid.UUID) ([]models.UploadChunk, error) {
+	var chunks []models.UploadChunk
+	result := r.db.WithContext(ctx).
+		Where("upload_id = ?", uploadID).
+		Order("\"offset\" ASC").
+		Find(&chunks)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return chunks, nil
+}
+
+func (r *uploadRepository) MarkCompleted(ctx context.Context, id, mediaID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Upload{}).
+			Where("id = ? AND media_id IS NULL", id).
+			Updates(map[string]interface{}{
+				"media_id":   mediaID,
+				"updated_at": time.Now().UTC(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrConflict
+		}
+
+		return tx.Where("upload_id = ?", id).Delete(&models.UploadChunk{}).Error
+	})
+}
+
+func (r *uploadRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("upload_id = ?", id).Delete(&models.UploadChunk{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&models.Upload{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *uploadRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]models.Upload, error) {
+	var uploads []models.Upload
+	result := r.db.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&uploads)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return uploads, nil
+}
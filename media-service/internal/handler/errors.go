@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"http_server/media-service/internal/service"
+	apperrors "http_server/shared/errors"
+	"http_server/shared/problem"
+)
+
+// Stable error codes reported in problem responses. Clients may rely on
+// these, so existing values must not change.
+const (
+	CodeInvalidPayload      = "invalid_payload"
+	CodeUnauthorized        = "unauthorized"
+	CodeForbidden           = "forbidden"
+	CodeInvalidMediaID      = "invalid_media_id"
+	CodeInvalidMultipart    = "invalid_multipart"
+	CodeMissingFile         = "missing_file"
+	CodeInvalidIP           = "invalid_ip"
+	CodeMediaNotFound       = "media_not_found"
+	CodeJobNotFound         = "job_not_found"
+	CodeRenditionNotFound   = "rendition_not_found"
+	CodeFileTooLarge        = "file_too_large"
+	CodeEmptyFile           = "empty_file"
+	CodeUnsupportedType     = "unsupported_type"
+	CodeTypeMismatch        = "type_mismatch"
+	CodeInvalidImage        = "invalid_image"
+	CodeSignatureInvalid    = "signature_invalid"
+	CodeInvalidTTL          = "invalid_ttl"
+	CodeBatchTooLarge       = "batch_too_large"
+	CodeUnsupportedVersion  = "unsupported_tus_version"
+	CodeInvalidUploadHeader = "invalid_upload_header"
+	CodeInvalidContentType  = "invalid_content_type"
+	CodeUploadNotFound      = "upload_not_found"
+	CodeUploadExpired       = "upload_expired"
+	CodeInvalidUploadLength = "invalid_upload_length"
+	CodeOffsetMismatch      = "offset_mismatch"
+	CodeChunkTooLarge       = "chunk_too_large"
+	CodeChecksumMismatch    = "checksum_mismatch"
+	CodeUnsupportedChecksum = "unsupported_checksum"
+)
+
+var (
+	errInvalidPayload     = apperrors.NewBadRequestError("Invalid request payload", nil).WithCode(CodeInvalidPayload)
+	errUnauthorized       = apperrors.NewAuthenticationError("Authentication required", nil).WithCode(CodeUnauthorized)
+	errInvalidMediaID     = apperrors.NewBadRequestError("Invalid media ID", nil).WithCode(CodeInvalidMediaID)
+	errInvalidMultipart   = apperrors.NewBadRequestError("Invalid multipart body", nil).WithCode(CodeInvalidMultipart)
+	errMissingFile        = apperrors.NewBadRequestError("Missing file field", nil).WithCode(CodeMissingFile)
+	errInvalidIP          = apperrors.NewValidationError("Invalid IP address", nil).WithCode(CodeInvalidIP)
+	errMediaNotFound      = apperrors.NewNotFoundError("Media not found", nil).WithCode(CodeMediaNotFound)
+	errUploadNotFound     = apperrors.NewNotFoundError("Upload not found", nil).WithCode(CodeUploadNotFound)
+	errUnsupportedVersion = apperrors.New(apperrors.ErrorTypePrecondition, "Unsupported Tus-Resumable version", nil).WithCode(CodeUnsupportedVersion)
+	errInvalidContentType = apperrors.New(apperrors.ErrorTypeUnsupportedMedia, "Content-Type must be "+offsetContentType, nil).WithCode(CodeInvalidContentType)
+	errInvalidLength      = apperrors.NewBadRequestError("Invalid Upload-Length header", nil).WithCode(CodeInvalidUploadHeader)
+	errInvalidMetadata    = apperrors.NewBadRequestError("Invalid Upload-Metadata header", nil).WithCode(CodeInvalidUploadHeader)
+	errInvalidOffset      = apperrors.NewBadRequestError("Invalid Upload-Offset header", nil).WithCode(CodeInvalidUploadHeader)
+	errInvalidChecksum    = apperrors.NewBadRequestError("Invalid Upload-Checksum header", nil).WithCode(CodeInvalidUploadHeader)
+)
+
+// serviceErrors maps service sentinel errors onto client-facing errors.
+// Anything not listed is reported as an internal error.
+var serviceErrors = []struct {
+	err    error
+	appErr *apperrors.AppError
+}{
+	{service.ErrMediaNotFound, errMediaNotFound},
+	{service.ErrJobNotFound, apperrors.NewNotFoundError("Media is not being processed", nil).WithCode(CodeJobNotFound)},
+	{service.ErrRenditionNotFound, apperrors.NewNotFoundError("Rendition not found", nil).WithCode(CodeRenditionNotFound)},
+	{service.ErrForbidden, apperrors.NewAuthorizationError("Operation not permitted", nil).WithCode(CodeForbidden)},
+	{service.ErrEmptyFile, apperrors.NewValidationError("File is empty", nil).WithCode(CodeEmptyFile)},
+	{service.ErrUnsupportedType, apperrors.New(apperrors.ErrorTypeUnsupportedMedia, "Unsupported media type", nil).WithCode(CodeUnsupportedType)},
+	{service.ErrTypeMismatch, apperrors.New(apperrors.ErrorTypeUnsupportedMedia, "Declared type does not match file content", nil).WithCode(CodeTypeMismatch)},
+	{service.ErrInvalidImage, apperrors.NewValidationError("File is not a valid image", nil).WithCode(CodeInvalidImage)},
+	{service.ErrSignatureInvalid, apperrors.NewAuthorizationError("Invalid or expired signature", nil).WithCode(CodeSignatureInvalid)},
+	{service.ErrInvalidTTL, apperrors.NewValidationError("ttl_seconds exceeds the maximum", nil).WithCode(CodeInvalidTTL)},
+	{service.ErrBatchTooLarge, apperrors.NewValidationError("Too many media_ids", nil).WithCode(CodeBatchTooLarge)},
+	{service.ErrUploadNotFound, errUploadNotFound},
+	{service.ErrUploadExpired, apperrors.New(apperrors.ErrorTypeGone, "Upload expired", nil).WithCode(CodeUploadExpired)},
+	{service.ErrInvalidUploadLength, apperrors.NewValidationError("Upload-Length must be positive", nil).WithCode(CodeInvalidUploadLength)},
+	{service.ErrOffsetMismatch, apperrors.NewConflictError("Upload-Offset does not match the current offset", nil).WithCode(CodeOffsetMismatch)},
+	{service.ErrChunkTooLarge, apperrors.New(apperrors.ErrorTypeTooLarge, "Chunk exceeds the remaining length or chunk size limit", nil).WithCode(CodeChunkTooLarge)},
+	{service.ErrChecksumMismatch, apperrors.New(apperrors.ErrorTypeChecksumMismatch, "Checksum mismatch", nil).WithCode(CodeChecksumMismatch)},
+	{service.ErrUnsupportedChecksum, apperrors.NewBadRequestError("Unsupported checksum algorithm", nil).WithCode(CodeUnsupportedChecksum)},
+}
+
+// toAppError translates a service error for the client, keeping the original
+// as the cause for logging.
+func toAppError(err error) *apperrors.AppError {
+	if appErr, ok := apperrors.As(err); ok {
+		return appErr
+	}
+	for _, mapping := range serviceErrors {
+		if errors.Is(err, mapping.err) {
+			appErr := *mapping.appErr
+			appErr.Err = err
+			return &appErr
+		}
+	}
+	return apperrors.NewInternalError("Internal server error", err)
+}
+
+// withFileLimit reports ErrFileTooLarge together with the configured limit,
+// which the static table cannot know.
+func withFileLimit(err error, maxFileSize int64) error {
+	if errors.Is(err, service.ErrFileTooLarge) {
+		return apperrors.New(apperrors.ErrorTypeTooLarge, fmt.Sprintf("File exceeds the %d byte limit", maxFileSize), err).WithCode(CodeFileTooLarge)
+	}
+	return err
+}
+
+func respondWithProblem(w http.ResponseWriter, r *http.Request, err error) {
+	problem.Write(w, r, toAppError(err))
+}
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"http_server/media-service/internal/domain/models"
+	"http_server/media-service/internal/service"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// defaultMaxAge is how long clients may cache content fetched with a token.
+const defaultMaxAge = 24 * time.Hour
+
+// multipartOverhead is the slack allowed on top of the file size for
+// multipart boundaries and part headers.
+const multipartOverhead = 64 << 10
+
+type MediaHandler struct {
+	mediaService service.MediaService
+	processor    service.ImageProcessor
+	maxFileSize  int64
+	logger       *logging.Logger
+}
+
+func NewMediaHandler(mediaService service.MediaService, processor service.ImageProcessor, maxFileSize int64, logger *logging.Logger) *MediaHandler {
+	return &MediaHandler{
+		mediaService: mediaService,
+		processor:    processor,
+		maxFileSize:  maxFileSize,
+		logger:       logger,
+	}
+}
+
+// Upload serves POST /media. It accepts either multipart/form-data with the
+// file in a "file" field, or the raw file as the request body with its type
+// in Content-Type and an optional ?filename= query parameter.
+func (h *MediaHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxFileSize+multipartOverhead)
+
+	input := service.UploadInput{OwnerID: userID}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			respondWithProblem(w, r, errInvalidMultipart)
+			return
+		}
+
+		part, err := nextFilePart(reader)
+		if err != nil {
+			respondWithProblem(w, r, errMissingFile)
+			return
+		}
+		defer part.Close()
+
+		input.Filename = part.FileName()
+		input.DeclaredType = part.Header.Get("Content-Type")
+		input.Body = part
+	} else {
+		input.Filename = r.URL.Query().Get("filename")
+		input.DeclaredType = r.Header.Get("Content-Type")
+		input.Body = r.Body
+	}
+
+	media, err := h.mediaService.Upload(r.Context(), input)
+	if err != nil {
+		logger.Warn("Upload failed", zap.Error(err))
+		respondWithProblem(w, r, withFileLimit(err, h.maxFileSize))
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, media)
+}
+
+// Get serves GET /media/{id}
+func (h *MediaHandler) Get(w http.ResponseWriter, r *http.Request) {
+	mediaID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidMediaID)
+		return
+	}
+
+	media, err := h.mediaService.Get(r.Context(), mediaID)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, media)
+}
+
+// Download serves GET /media/{id}/content with support for Range,
+// If-Range and conditional requests.
+func (h *MediaHandler) Download(w http.ResponseWriter, r *http.Request) {
+	mediaID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidMediaID)
+		return
+	}
+
+	media, content, err := h.mediaService.Open(r.Context(), mediaID)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+	defer content.Close()
+
+	serveObject(w, r, content, media.ContentType, media.Hash, media.Filename, media.CreatedAt, defaultMaxAge)
+}
+
+// Processing serves GET /media/{id}/processing with the status of rendition
+// generation for an image.
+func (h *MediaHandler) Processing(w http.ResponseWriter, r *http.Request) {
+	mediaID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidMediaID)
+		return
+	}
+
+	if _, err := h.mediaService.Get(r.Context(), mediaID); err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	status, err := h.processor.Status(r.Context(), mediaID)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, status)
+}
+
+// Rendition serves GET /media/{id}/renditions/{name}
+func (h *MediaHandler) Rendition(w http.ResponseWriter, r *http.Request) {
+	mediaID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidMediaID)
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+	rendition, content, err := h.processor.OpenRendition(r.Context(), mediaID, name)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+	defer content.Close()
+
+	serveObject(w, r, content, rendition.ContentType, renditionETag(rendition), "", rendition.CreatedAt, defaultMaxAge)
+}
+
+// Delete serves DELETE /media/{id}
+func (h *MediaHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	mediaID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidMediaID)
+		return
+	}
+
+	if err := h.mediaService.Delete(r.Context(), mediaID, userID, middleware.RolesFromContext(r.Context())); err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveObject streams stored content with caching headers, delegating
+// Range, If-Range and conditional request handling to http.ServeContent.
+func serveObject(w http.ResponseWriter, r *http.Request, content io.ReadSeeker, contentType, etag, filename string, modified time.Time, maxAge time.Duration) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", fmt.Sprintf(`"%s"`, etag))
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(maxAge.Seconds())))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if filename != "" {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": filename}))
+	}
+
+	http.ServeContent(w, r, "", modified, content)
+}
+
+func renditionETag(rendition *models.Rendition) string {
+	return fmt.Sprintf("%s-%s-%d", rendition.MediaID, rendition.Name, rendition.CreatedAt.Unix())
+}
+
+// nextFilePart advances the reader to the "file" form field.
+func nextFilePart(reader *multipart.Reader) (*multipart.Part, error) {
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("file field not found")
+			}
+			return nil, err
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
+
+// pathUUID parses the named mux route variable as a UUID.
+func pathUUID(r *http.Request, name string) (uuid.UUID, error) {
+	return uuid.Parse(mux.Vars(r)[name])
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"time"
+
+	"http_server/media-service/internal/service"
+	"http_server/media-service/internal/signing"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type SignURLRequest struct {
+	Rendition  string `json:"rendition,omitempty"`
+	TTLSeconds int    `json:"ttl_seconds,omitempty"`
+	// BindIP binds the URL to the caller's address. IP binds it to the given
+	// address instead, for servers requesting URLs on a client's behalf.
+	BindIP bool   `json:"bind_ip,omitempty"`
+	IP     string `json:"ip,omitempty"`
+	// BindUser binds the URL to the authenticated caller.
+	BindUser bool `json:"bind_user,omitempty"`
+}
+
+type SignURLsRequest struct {
+	SignURLRequest
+	MediaIDs []uuid.UUID `json:"media_ids"`
+}
+
+type SignedURLHandler struct {
+	urlService   service.SignedURLService
+	mediaService service.MediaService
+	processor    service.ImageProcessor
+	clientIP     *signing.ClientIP
+	logger       *logging.Logger
+}
+
+func NewSignedURLHandler(urlService service.SignedURLService, mediaService service.MediaService, processor service.ImageProcessor, clientIP *signing.ClientIP, logger *logging.Logger) *SignedURLHandler {
+	return &SignedURLHandler{
+		urlService:   urlService,
+		mediaService: mediaService,
+		processor:    processor,
+		clientIP:     clientIP,
+		logger:       logger,
+	}
+}
+
+// Sign serves POST /media/{id}/signed-url
+func (h *SignedURLHandler) Sign(w http.ResponseWriter, r *http.Request) {
+	mediaID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidMediaID)
+		return
+	}
+
+	var req SignURLRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithProblem(w, r, errInvalidPayload)
+			return
+		}
+	}
+
+	input, ok := h.signInput(w, r, req)
+	if !ok {
+		return
+	}
+	input.MediaID = mediaID
+
+	signed, err := h.urlService.Sign(r.Context(), input)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, signed)
+}
+
+// SignMany serves POST /media/signed-urls
+func (h *SignedURLHandler) SignMany(w http.ResponseWriter, r *http.Request) {
+	var req SignURLsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithProblem(w, r, errInvalidPayload)
+		return
+	}
+
+	input, ok := h.signInput(w, r, req.SignURLRequest)
+	if !ok {
+		return
+	}
+
+	urls, err := h.urlService.SignMany(r.Context(), req.MediaIDs, input)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{"urls": urls})
+}
+
+// Content serves GET /signed/media/{id} without a bearer token.
+func (h *SignedURLHandler) Content(w http.ResponseWriter, r *http.Request) {
+	mediaID, maxAge, ok := h.verify(w, r, "")
+	if !ok {
+		return
+	}
+
+	media, content, err := h.mediaService.Open(r.Context(), mediaID)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+	defer content.Close()
+
+	serveObject(w, r, content, media.ContentType, media.Hash, media.Filename, media.CreatedAt, maxAge)
+}
+
+// Rendition serves GET /signed/media/{id}/renditions/{name} without a
+// bearer token.
+func (h *SignedURLHandler) Rendition(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	mediaID, maxAge, ok := h.verify(w, r, name)
+	if !ok {
+		return
+	}
+
+	rendition, content, err := h.processor.OpenRendition(r.Context(), mediaID, name)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+	defer content.Close()
+
+	serveObject(w, r, content, rendition.ContentType, renditionETag(rendition), "", rendition.CreatedAt, maxAge)
+}
+
+// verify checks the signature of a public request and returns how long the
+// response may be cached, which never outlives the URL.
+func (h *SignedURLHandler) verify(w http.ResponseWriter, r *http.Request, rendition string) (uuid.UUID, time.Duration, bool) {
+	mediaID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errMediaNotFound)
+		return uuid.Nil, 0, false
+	}
+
+	input := service.VerifyURLInput{
+		MediaID:   mediaID,
+		Rendition: rendition,
+		Query:     r.URL.Query(),
+		ClientIP:  h.clientIP.FromRequest(r),
+	}
+	if userID, ok := middleware.UserIDFromContext(r.Context()); ok {
+		input.UserID = &userID
+	}
+
+	expiresAt, err := h.urlService.Verify(r.Context(), input)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return uuid.Nil, 0, false
+	}
+	return mediaID, time.Until(expiresAt), true
+}
+
+func (h *SignedURLHandler) signInput(w http.ResponseWriter, r *http.Request, req SignURLRequest) (service.SignURLInput, bool) {
+	input := service.SignURLInput{
+		Rendition: req.Rendition,
+		TTL:       time.Duration(req.TTLSeconds) * time.Second,
+	}
+
+	switch {
+	case req.IP != "":
+		ip := net.ParseIP(req.IP)
+		if ip == nil {
+			respondWithProblem(w, r, errInvalidIP)
+			return input, false
+		}
+		input.BindIP = ip.String()
+	case req.BindIP:
+		input.BindIP = h.clientIP.FromRequest(r)
+	}
+
+	if req.BindUser {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			respondWithProblem(w, r, errUnauthorized)
+			return input, false
+		}
+		input.BindUser = &userID
+	}
+	return input, true
+}
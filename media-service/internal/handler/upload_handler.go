@@ -0,0 +1,287 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"http_server/media-service/internal/domain/models"
+	"http_server/media-service/internal/service"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+
+	"go.uber.org/zap"
+)
+
+// The handler speaks the tus 1.0.0 resumable upload protocol with the
+// creation, expiration, checksum and termination extensions.
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,expiration,checksum,termination"
+
+	offsetContentType = "application/offset+octet-stream"
+)
+
+type UploadHandler struct {
+	uploadService service.UploadService
+	maxFileSize   int64
+	logger        *logging.Logger
+}
+
+func NewUploadHandler(uploadService service.UploadService, maxFileSize int64, logger *logging.Logger) *UploadHandler {
+	return &UploadHandler{
+		uploadService: uploadService,
+		maxFileSize:   maxFileSize,
+		logger:        logger,
+	}
+}
+
+// Options serves OPTIONS /uploads and advertises the server's capabilities.
+// It is reachable without a token so clients can discover them up front.
+func (h *UploadHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.Header().Set("Tus-Version", tusVersion)
+	w.Header().Set("Tus-Extension", tusExtensions)
+	w.Header().Set("Tus-Max-Size", strconv.FormatInt(h.maxFileSize, 10))
+	w.Header().Set("Tus-Checksum-Algorithm", strings.Join(service.ChecksumAlgorithms(), ","))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Create serves POST /uploads
+func (h *UploadHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if !h.checkVersion(w, r) {
+		return
+	}
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil {
+		respondWithProblem(w, r, errInvalidLength)
+		return
+	}
+
+	metadata, err := parseUploadMetadata(r.Header.Get("Upload-Metadata"))
+	if err != nil {
+		respondWithProblem(w, r, errInvalidMetadata)
+		return
+	}
+
+	upload, err := h.uploadService.CreateUpload(r.Context(), service.CreateUploadInput{
+		OwnerID:      userID,
+		Length:       length,
+		Filename:     firstNonEmpty(metadata["filename"], metadata["name"]),
+		DeclaredType: firstNonEmpty(metadata["filetype"], metadata["type"]),
+	})
+	if err != nil {
+		respondWithProblem(w, r, withFileLimit(err, h.maxFileSize))
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/uploads/"+upload.ID.String())
+	w.Header().Set("Upload-Offset", "0")
+	setUploadExpires(w, upload)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// Head serves HEAD /uploads/{id} and reports how much has been received.
+func (h *UploadHandler) Head(w http.ResponseWriter, r *http.Request) {
+	if !h.checkVersion(w, r) {
+		return
+	}
+
+	upload, ok := h.loadUpload(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	setUploadExpires(w, upload)
+	setMediaID(w, upload)
+	w.WriteHeader(http.StatusOK)
+}
+
+// Patch serves PATCH /uploads/{id} and appends one chunk.
+func (h *UploadHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	if !h.checkVersion(w, r) {
+		return
+	}
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	uploadID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errUploadNotFound)
+		return
+	}
+
+	if r.Header.Get("Content-Type") != offsetContentType {
+		respondWithProblem(w, r, errInvalidContentType)
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		respondWithProblem(w, r, errInvalidOffset)
+		return
+	}
+
+	checksum, err := parseUploadChecksum(r.Header.Get("Upload-Checksum"))
+	if err != nil {
+		respondWithProblem(w, r, errInvalidChecksum)
+		return
+	}
+
+	upload, err := h.uploadService.WriteChunk(r.Context(), service.ChunkInput{
+		UploadID: uploadID,
+		OwnerID:  userID,
+		Offset:   offset,
+		Body:     r.Body,
+		Checksum: checksum,
+	})
+	if err != nil {
+		logger.Warn("Chunk rejected", zap.Error(err), zap.String("upload_id", uploadID.String()))
+		respondWithProblem(w, r, withFileLimit(err, h.maxFileSize))
+		return
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	setUploadExpires(w, upload)
+	setMediaID(w, upload)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Delete serves DELETE /uploads/{id} and abandons the upload.
+func (h *UploadHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if !h.checkVersion(w, r) {
+		return
+	}
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	uploadID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errUploadNotFound)
+		return
+	}
+
+	if err := h.uploadService.CancelUpload(r.Context(), uploadID, userID); err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UploadHandler) loadUpload(w http.ResponseWriter, r *http.Request) (*models.Upload, bool) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return nil, false
+	}
+
+	uploadID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errUploadNotFound)
+		return nil, false
+	}
+
+	upload, err := h.uploadService.GetUpload(r.Context(), uploadID, userID)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return nil, false
+	}
+	return upload, true
+}
+
+// checkVersion rejects requests for a protocol version other than ours and
+// sets Tus-Resumable on the response.
+func (h *UploadHandler) checkVersion(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	if r.Header.Get("Tus-Resumable") != tusVersion {
+		w.Header().Set("Tus-Version", tusVersion)
+		respondWithProblem(w, r, errUnsupportedVersion)
+		return false
+	}
+	return true
+}
+
+func setUploadExpires(w http.ResponseWriter, upload *models.Upload) {
+	if !upload.Completed() {
+		w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// setMediaID tells the client which media a finished upload produced.
+func setMediaID(w http.ResponseWriter, upload *models.Upload) {
+	if upload.Completed() {
+		w.Header().Set("Media-Id", upload.MediaID.String())
+	}
+}
+
+// parseUploadMetadata decodes "key base64value,key2 base64value2".
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("empty metadata key")
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, err
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+// parseUploadChecksum decodes "algorithm base64digest". An empty header
+// means the client did not ask for verification.
+func parseUploadChecksum(header string) (*service.Checksum, error) {
+	if header == "" {
+		return nil, nil
+	}
+
+	algorithm, encoded, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok {
+		return nil, errors.New("malformed checksum")
+	}
+	digest, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return &service.Checksum{Algorithm: strings.ToLower(algorithm), Digest: digest}, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,310 @@
+package handler_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"http_server/media-service/internal/config"
+	"http_server/media-service/internal/domain/models"
+	"http_server/media-service/internal/domain/repository"
+	"http_server/media-service/internal/handler"
+	"http_server/media-service/internal/server"
+	"http_server/media-service/internal/service"
+	"http_server/media-service/internal/storage"
+	"http_server/shared/health"
+	"http_server/shared/jwtkeys"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var testKey = []byte("test-signing-key")
+
+// fakeUploads keeps uploads and their chunks in memory with the same
+// offset check as the database repository.
+type fakeUploads struct {
+	mu      sync.Mutex
+	uploads map[uuid.UUID]models.Upload
+	chunks  map[uuid.UUID][]models.UploadChunk
+}
+
+func (f *fakeUploads) Create(ctx context.Context, upload *models.Upload) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.uploads[upload.ID] = *upload
+	return nil
+}
+
+func (f *fakeUploads) FindByID(ctx context.Context, id uuid.UUID) (*models.Upload, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	upload, ok := f.uploads[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &upload, nil
+}
+
+func (f *fakeUploads) AppendChunk(ctx context.Context, chunk *models.UploadChunk, expiresAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	upload, ok := f.uploads[chunk.UploadID]
+	if !ok || upload.Offset != chunk.Offset {
+		return repository.ErrConflict
+	}
+	upload.Offset += chunk.Size
+	upload.ExpiresAt = expiresAt
+	f.uploads[upload.ID] = upload
+	f.chunks[upload.ID] = append(f.chunks[upload.ID], *chunk)
+	return nil
+}
+
+func (f *fakeUploads) ListChunks(ctx context.Context, uploadID uuid.UUID) ([]models.UploadChunk, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	chunks := append([]models.UploadChunk(nil), f.chunks[uploadID]...)
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].Offset < chunks[j].Offset })
+	return chunks, nil
+}
+
+func (f *fakeUploads) MarkCompleted(ctx context.Context, id, mediaID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	upload := f.uploads[id]
+	if upload.MediaID != nil {
+		return repository.ErrConflict
+	}
+	upload.MediaID = &mediaID
+	f.uploads[id] = upload
+	delete(f.chunks, id)
+	return nil
+}
+
+func (f *fakeUploads) Delete(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.uploads[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(f.uploads, id)
+	delete(f.chunks, id)
+	return nil
+}
+
+func (f *fakeUploads) ListExpired(ctx context.Context, before time.Time, limit int) ([]models.Upload, error) {
+	return nil, nil
+}
+
+// fakeMedia records the assembled content of completed uploads.
+type fakeMedia struct {
+	service.MediaService
+	mu       sync.Mutex
+	received map[uuid.UUID]string
+}
+
+func (f *fakeMedia) Upload(ctx context.Context, in service.UploadInput) (*models.Media, error) {
+	data, err := io.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+	media := &models.Media{ID: uuid.New(), OwnerID: in.OwnerID, Filename: in.Filename, Size: int64(len(data))}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.received[media.ID] = string(data)
+	return media, nil
+}
+
+// failingReader stands in for a connection that drops mid-chunk.
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+type tusFixture struct {
+	uploads *fakeUploads
+	media   *fakeMedia
+	store   storage.Storage
+	cfg     config.StorageConfig
+	logger  *logging.Logger
+}
+
+func newTusFixture(t *testing.T) *tusFixture {
+	t.Helper()
+	logger, err := logging.NewLogger(&logging.Config{ServiceName: "test", LogLevel: "error", FilePath: "stderr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tusFixture{
+		uploads: &fakeUploads{uploads: make(map[uuid.UUID]models.Upload), chunks: make(map[uuid.UUID][]models.UploadChunk)},
+		media:   &fakeMedia{received: make(map[uuid.UUID]string)},
+		store:   store,
+		cfg:     config.StorageConfig{MaxFileSize: 1 << 20, TempDir: t.TempDir()},
+		logger:  logger,
+	}
+}
+
+// newInstance builds a service instance over the shared repository and
+// store, as another replica or a restarted process would see them.
+func (f *tusFixture) newInstance() http.Handler {
+	uploadService := service.NewUploadService(f.uploads, f.media, f.store, f.cfg,
+		config.UploadsConfig{MaxChunkSize: 1 << 20, TTL: time.Hour}, f.logger)
+	return server.NewRouter(server.Handlers{
+		Upload: handler.NewUploadHandler(uploadService, f.cfg.MaxFileSize, f.logger),
+	}, health.New(health.Config{}), middleware.NewJWTAuth(jwtkeys.New(testKey), f.logger), f.logger)
+}
+
+func bearer(t *testing.T, userID uuid.UUID) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID.String(),
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
+
+func tusRequest(t *testing.T, h http.Handler, auth, method, target string, body io.Reader, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set("Authorization", auth)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func patch(t *testing.T, h http.Handler, auth, location, offset string, body io.Reader, checksum string) *httptest.ResponseRecorder {
+	t.Helper()
+	headers := map[string]string{"Content-Type": "application/offset+octet-stream", "Upload-Offset": offset}
+	if checksum != "" {
+		headers["Upload-Checksum"] = checksum
+	}
+	return tusRequest(t, h, auth, http.MethodPatch, location, body, headers)
+}
+
+func sha256Checksum(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return "sha256 " + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestTusUploadResumesAfterInterruption(t *testing.T) {
+	f := newTusFixture(t)
+	first := f.newInstance()
+	auth := bearer(t, uuid.New())
+
+	rec := tusRequest(t, first, auth, http.MethodPost, "/api/v1/uploads", nil, map[string]string{
+		"Upload-Length":   "10",
+		"Upload-Metadata": "filename " + base64.StdEncoding.EncodeToString([]byte("digits.txt")),
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", rec.Code, rec.Body)
+	}
+	location := rec.Header().Get("Location")
+
+	if rec := patch(t, first, auth, location, "0", strings.NewReader("0123"), ""); rec.Code != http.StatusNoContent || rec.Header().Get("Upload-Offset") != "4" {
+		t.Fatalf("first chunk = %d, offset %q", rec.Code, rec.Header().Get("Upload-Offset"))
+	}
+
+	// The connection drops part way through the second chunk: nothing of it
+	// is kept, so the client resumes from the last acknowledged offset.
+	dropped := io.MultiReader(strings.NewReader("456"), failingReader{})
+	if rec := patch(t, first, auth, location, "4", dropped, ""); rec.Code < 400 {
+		t.Fatalf("interrupted chunk status = %d, want an error", rec.Code)
+	}
+	// A chunk whose checksum does not match is dropped the same way.
+	if rec := patch(t, first, auth, location, "4", strings.NewReader("456789"), sha256Checksum("456780")); rec.Code != 460 {
+		t.Errorf("bad checksum status = %d, want 460", rec.Code)
+	}
+
+	// The client restarts against another instance and asks where to resume.
+	second := f.newInstance()
+	rec = tusRequest(t, second, auth, http.MethodHead, location, nil, nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Upload-Offset") != "4" || rec.Header().Get("Upload-Length") != "10" {
+		t.Fatalf("HEAD = %d, offset %q, length %q", rec.Code, rec.Header().Get("Upload-Offset"), rec.Header().Get("Upload-Length"))
+	}
+	if rec := patch(t, second, auth, location, "2", strings.NewReader("23456789"), ""); rec.Code != http.StatusConflict {
+		t.Errorf("stale offset status = %d, want 409", rec.Code)
+	}
+
+	rec = patch(t, second, auth, location, "4", strings.NewReader("456789"), sha256Checksum("456789"))
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Upload-Offset") != "10" {
+		t.Fatalf("final chunk = %d, offset %q: %s", rec.Code, rec.Header().Get("Upload-Offset"), rec.Body)
+	}
+	mediaID, err := uuid.Parse(rec.Header().Get("Media-Id"))
+	if err != nil {
+		t.Fatalf("Media-Id = %q", rec.Header().Get("Media-Id"))
+	}
+	if got := f.media.received[mediaID]; got != "0123456789" {
+		t.Errorf("assembled content = %q, want 0123456789", got)
+	}
+
+	// Completed uploads keep answering HEAD with the media they produced.
+	rec = tusRequest(t, first, auth, http.MethodHead, location, nil, nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Media-Id") != mediaID.String() {
+		t.Errorf("HEAD after completion = %d, Media-Id %q", rec.Code, rec.Header().Get("Media-Id"))
+	}
+}
+
+func TestTusUploadIsPrivateToOwner(t *testing.T) {
+	f := newTusFixture(t)
+	h := f.newInstance()
+	owner := bearer(t, uuid.New())
+	other := bearer(t, uuid.New())
+
+	rec := tusRequest(t, h, owner, http.MethodPost, "/api/v1/uploads", nil, map[string]string{"Upload-Length": "4"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d", rec.Code)
+	}
+	location := rec.Header().Get("Location")
+
+	if rec := tusRequest(t, h, other, http.MethodHead, location, nil, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("HEAD by another user = %d, want 404", rec.Code)
+	}
+	if rec := patch(t, h, other, location, "0", strings.NewReader("data"), ""); rec.Code != http.StatusNotFound {
+		t.Errorf("PATCH by another user = %d, want 404", rec.Code)
+	}
+
+	if rec := tusRequest(t, h, owner, http.MethodDelete, location, nil, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE = %d", rec.Code)
+	}
+	if rec := tusRequest(t, h, owner, http.MethodHead, location, nil, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("HEAD after DELETE = %d, want 404", rec.Code)
+	}
+}
+
+func TestTusRejectsOtherProtocolVersions(t *testing.T) {
+	f := newTusFixture(t)
+	h := f.newInstance()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/uploads", nil)
+	req.Header.Set("Authorization", bearer(t, uuid.New()))
+	req.Header.Set("Tus-Resumable", "0.2.2")
+	req.Header.Set("Upload-Length", "4")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusPreconditionFailed || rec.Header().Get("Tus-Version") != "1.0.0" {
+		t.Errorf("status = %d, Tus-Version %q, want 412 and 1.0.0", rec.Code, rec.Header().Get("Tus-Version"))
+	}
+}
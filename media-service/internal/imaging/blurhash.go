@@ -0,0 +1,124 @@
+package imaging
+
+import (
+	"image"
+	"math"
+	"strings"
+)
+
+// blurhashSampleSize is the edge length images are reduced to before the
+// transform; blurhash only keeps a few low frequencies, so more pixels would
+// not change the result.
+const blurhashSampleSize = 64
+
+const base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// Blurhash encodes img as a BlurHash (https://blurha.sh) string with the
+// given number of horizontal and vertical components (1-9 each).
+func Blurhash(img image.Image, xComponents, yComponents int) string {
+	xComponents = clampInt(xComponents, 1, 9)
+	yComponents = clampInt(yComponents, 1, 9)
+
+	sample := Resize(img, blurhashSampleSize, blurhashSampleSize, FitContain)
+	b := sample.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	// Convert to linear light once up front.
+	linear := make([][3]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := nrgbaAt(sample, b.Min.X+x, b.Min.Y+y)
+			linear[y*w+x] = [3]float64{sRGBToLinear(c.R), sRGBToLinear(c.G), sRGBToLinear(c.B)}
+		}
+	}
+
+	factors := make([][3]float64, 0, xComponents*yComponents)
+	for j := 0; j < yComponents; j++ {
+		for i := 0; i < xComponents; i++ {
+			normalisation := 2.0
+			if i == 0 && j == 0 {
+				normalisation = 1
+			}
+
+			var sum [3]float64
+			for y := 0; y < h; y++ {
+				cy := math.Cos(math.Pi * float64(j) * float64(y) / float64(h))
+				for x := 0; x < w; x++ {
+					basis := normalisation * math.Cos(math.Pi*float64(i)*float64(x)/float64(w)) * cy
+					px := linear[y*w+x]
+					sum[0] += basis * px[0]
+					sum[1] += basis * px[1]
+					sum[2] += basis * px[2]
+				}
+			}
+
+			scale := 1 / float64(w*h)
+			factors = append(factors, [3]float64{sum[0] * scale, sum[1] * scale, sum[2] * scale})
+		}
+	}
+
+	var hash strings.Builder
+	hash.WriteString(encode83((xComponents-1)+(yComponents-1)*9, 1))
+
+	dc, ac := factors[0], factors[1:]
+	maximumValue := 1.0
+	if len(ac) > 0 {
+		actualMax := 0.0
+		for _, f := range ac {
+			actualMax = math.Max(actualMax, math.Max(math.Abs(f[0]), math.Max(math.Abs(f[1]), math.Abs(f[2]))))
+		}
+		quantisedMax := clampInt(int(math.Floor(actualMax*166-0.5)), 0, 82)
+		maximumValue = float64(quantisedMax+1) / 166
+		hash.WriteString(encode83(quantisedMax, 1))
+	} else {
+		hash.WriteString(encode83(0, 1))
+	}
+
+	hash.WriteString(encode83(linearToSRGB(dc[0])<<16+linearToSRGB(dc[1])<<8+linearToSRGB(dc[2]), 4))
+	for _, f := range ac {
+		quant := func(v float64) int {
+			return clampInt(int(math.Floor(signPow(v/maximumValue, 0.5)*9+9.5)), 0, 18)
+		}
+		hash.WriteString(encode83(quant(f[0])*19*19+quant(f[1])*19+quant(f[2]), 2))
+	}
+	return hash.String()
+}
+
+func encode83(value, length int) string {
+	out := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		out[i] = base83Chars[value%83]
+		value /= 83
+	}
+	return string(out)
+}
+
+func sRGBToLinear(c uint8) float64 {
+	v := float64(c) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) int {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,292 @@
+package imaging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrMalformedImage is returned when an image cannot be parsed far enough to
+// remove its metadata.
+var ErrMalformedImage = errors.New("malformed image")
+
+const (
+	markerSOI  = 0xD8
+	markerSOS  = 0xDA
+	markerAPP0 = 0xE0
+	markerAPP1 = 0xE1 // EXIF (camera, GPS, timestamps) and XMP
+	markerAPPD = 0xED // Photoshop IRB / IPTC
+	markerCOM  = 0xFE
+
+	exifTagOrientation = 0x0112
+)
+
+var (
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+	exifHeader   = []byte("Exif\x00\x00")
+)
+
+// droppedPNGChunks carry free-form text, EXIF or timestamps. Everything else,
+// including colour profiles and gamma, is kept so rendering is unchanged.
+var droppedPNGChunks = map[string]bool{
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"eXIf": true,
+	"tIME": true,
+}
+
+// CanStripMetadata reports whether StripMetadata understands contentType.
+func CanStripMetadata(contentType string) bool {
+	return contentType == "image/jpeg" || contentType == "image/png"
+}
+
+// StripMetadata copies an image from r to w without EXIF, XMP, IPTC and
+// comment data. Pixel data is copied verbatim, so the result is bit-for-bit
+// identical in appearance. For JPEGs the EXIF orientation is the one value
+// preserved, rewritten as a minimal EXIF block, because dropping it would
+// display photos from phones sideways.
+func StripMetadata(contentType string, r io.Reader, w io.Writer) error {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEG(r, w)
+	case "image/png":
+		return stripPNG(r, w)
+	default:
+		return fmt.Errorf("cannot strip metadata from %s", contentType)
+	}
+}
+
+type jpegSegment struct {
+	marker  byte
+	payload []byte
+}
+
+func stripJPEG(r io.Reader, w io.Writer) error {
+	br := bufio.NewReader(r)
+
+	var soi [2]byte
+	if _, err := io.ReadFull(br, soi[:]); err != nil || soi[0] != 0xFF || soi[1] != markerSOI {
+		return ErrMalformedImage
+	}
+
+	// Header segments are small, so they are collected first: the
+	// orientation must be known before anything is written.
+	var kept []jpegSegment
+	orientation := 1
+	for {
+		marker, err := readMarker(br)
+		if err != nil {
+			return err
+		}
+		if marker == markerSOS {
+			break
+		}
+
+		var length [2]byte
+		if _, err := io.ReadFull(br, length[:]); err != nil {
+			return ErrMalformedImage
+		}
+		n := int(binary.BigEndian.Uint16(length[:]))
+		if n < 2 {
+			return ErrMalformedImage
+		}
+		payload := make([]byte, n-2)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			return ErrMalformedImage
+		}
+
+		switch marker {
+		case markerAPP1:
+			if o := exifOrientation(payload); o != 0 {
+				orientation = o
+			}
+		case markerAPPD, markerCOM:
+		default:
+			kept = append(kept, jpegSegment{marker: marker, payload: payload})
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.Write([]byte{0xFF, markerSOI})
+
+	// JFIF requires APP0 to come first when present.
+	if len(kept) > 0 && kept[0].marker == markerAPP0 {
+		writeSegment(bw, kept[0])
+		kept = kept[1:]
+	}
+	if orientation != 1 {
+		writeSegment(bw, jpegSegment{marker: markerAPP1, payload: orientationEXIF(orientation)})
+	}
+	for _, seg := range kept {
+		writeSegment(bw, seg)
+	}
+
+	bw.Write([]byte{0xFF, markerSOS})
+	if _, err := io.Copy(bw, br); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// readMarker reads the next marker, skipping fill bytes.
+func readMarker(br *bufio.Reader) (byte, error) {
+	b, err := br.ReadByte()
+	if err != nil || b != 0xFF {
+		return 0, ErrMalformedImage
+	}
+	for {
+		b, err = br.ReadByte()
+		if err != nil {
+			return 0, ErrMalformedImage
+		}
+		if b != 0xFF {
+			return b, nil
+		}
+	}
+}
+
+func writeSegment(w *bufio.Writer, seg jpegSegment) {
+	var header [4]byte
+	header[0] = 0xFF
+	header[1] = seg.marker
+	binary.BigEndian.PutUint16(header[2:], uint16(len(seg.payload)+2))
+	w.Write(header[:])
+	w.Write(seg.payload)
+}
+
+// exifOrientation returns the Orientation tag from an APP1 payload, or 0 if
+// the payload is not EXIF or has no valid orientation.
+func exifOrientation(payload []byte) int {
+	if !bytes.HasPrefix(payload, exifHeader) {
+		return 0
+	}
+	tiff := payload[len(exifHeader):]
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == exifTagOrientation {
+			o := int(order.Uint16(tiff[entry+8:]))
+			if o >= 1 && o <= 8 {
+				return o
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// orientationEXIF builds an EXIF payload holding only the Orientation tag.
+func orientationEXIF(orientation int) []byte {
+	var b bytes.Buffer
+	b.Write(exifHeader)
+	b.WriteString("MM")
+	binary.Write(&b, binary.BigEndian, uint16(42)) // TIFF magic
+	binary.Write(&b, binary.BigEndian, uint32(8))  // IFD0 offset
+	binary.Write(&b, binary.BigEndian, uint16(1))  // entry count
+	binary.Write(&b, binary.BigEndian, uint16(exifTagOrientation))
+	binary.Write(&b, binary.BigEndian, uint16(3)) // SHORT
+	binary.Write(&b, binary.BigEndian, uint32(1)) // value count
+	binary.Write(&b, binary.BigEndian, uint16(orientation))
+	binary.Write(&b, binary.BigEndian, uint16(0)) // padding
+	binary.Write(&b, binary.BigEndian, uint32(0)) // no next IFD
+	return b.Bytes()
+}
+
+// JPEGOrientation returns the EXIF orientation (1-8) of a JPEG, defaulting to
+// 1 when none is recorded.
+func JPEGOrientation(r io.Reader) int {
+	br := bufio.NewReader(r)
+
+	var soi [2]byte
+	if _, err := io.ReadFull(br, soi[:]); err != nil || soi[0] != 0xFF || soi[1] != markerSOI {
+		return 1
+	}
+	for {
+		marker, err := readMarker(br)
+		if err != nil || marker == markerSOS {
+			return 1
+		}
+
+		var length [2]byte
+		if _, err := io.ReadFull(br, length[:]); err != nil {
+			return 1
+		}
+		n := int(binary.BigEndian.Uint16(length[:]))
+		if n < 2 {
+			return 1
+		}
+		payload := make([]byte, n-2)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			return 1
+		}
+		if marker == markerAPP1 {
+			if o := exifOrientation(payload); o != 0 {
+				return o
+			}
+		}
+	}
+}
+
+func stripPNG(r io.Reader, w io.Writer) error {
+	br := bufio.NewReader(r)
+
+	sig := make([]byte, len(pngSignature))
+	if _, err := io.ReadFull(br, sig); err != nil || !bytes.Equal(sig, pngSignature) {
+		return ErrMalformedImage
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.Write(sig)
+
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(br, header[:]); err != nil {
+			return ErrMalformedImage
+		}
+		length := int64(binary.BigEndian.Uint32(header[:4]))
+		chunkType := string(header[4:])
+
+		// Chunk data plus its CRC; CRCs cover single chunks, so dropping
+		// whole chunks keeps the file valid.
+		body := io.LimitReader(br, length+4)
+		if droppedPNGChunks[chunkType] {
+			if _, err := io.Copy(io.Discard, body); err != nil {
+				return ErrMalformedImage
+			}
+			continue
+		}
+
+		bw.Write(header[:])
+		if n, err := io.Copy(bw, body); err != nil || n != length+4 {
+			return ErrMalformedImage
+		}
+		if chunkType == "IEND" {
+			return bw.Flush()
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+)
+
+// ErrTooManyPixels is returned for images whose declared dimensions exceed
+// the decode limit, which guards against decompression bombs.
+var ErrTooManyPixels = errors.New("image dimensions exceed limit")
+
+// Fit modes for Resize
+const (
+	// FitContain scales the image to fit inside the box, keeping all of it.
+	FitContain = "contain"
+	// FitCover scales and center-crops the image to fill the box exactly.
+	FitCover = "cover"
+)
+
+// Decode reads an image of the given content type after checking that its
+// dimensions stay within maxPixels. JPEGs are rotated upright according to
+// their EXIF orientation. GIFs decode to their first frame.
+func Decode(r io.ReadSeeker, contentType string, maxPixels int64) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedImage, err)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	orientation := 1
+	if contentType == "image/jpeg" {
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		orientation = JPEGOrientation(r)
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var img image.Image
+	switch contentType {
+	case "image/jpeg":
+		img, err = jpeg.Decode(r)
+	case "image/png":
+		img, err = png.Decode(r)
+	case "image/gif":
+		img, err = gif.Decode(r)
+	default:
+		return nil, fmt.Errorf("cannot decode %s", contentType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedImage, err)
+	}
+
+	return Orient(img, orientation), nil
+}
+
+// Orient applies an EXIF orientation (1-8) so the result displays upright
+// without metadata.
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90 clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90 counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// Resize scales img to fit a width x height box using the given fit mode.
+// Images are never enlarged; a source smaller than the box is only cropped
+// (for FitCover) to the box's aspect ratio.
+func Resize(img image.Image, width, height int, fit string) image.Image {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	src := b
+
+	var dw, dh int
+	switch fit {
+	case FitCover:
+		// Largest centered region with the target aspect ratio.
+		cw, ch := sw, sw*height/width
+		if ch > sh {
+			cw, ch = sh*width/height, sh
+		}
+		x0 := b.Min.X + (sw-cw)/2
+		y0 := b.Min.Y + (sh-ch)/2
+		src = image.Rect(x0, y0, x0+cw, y0+ch)
+
+		dw, dh = width, height
+		if cw < width {
+			dw, dh = cw, ch
+		}
+	default:
+		dw, dh = sw, sh
+		if sw > width || sh > height {
+			if sw*height > sh*width {
+				dw, dh = width, sh*width/sw
+			} else {
+				dw, dh = sw*height/sh, height
+			}
+		}
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, src, draw.Src, nil)
+	return dst
+}
+
+// Encode writes img as JPEG, or as PNG when it has transparent pixels, and
+// returns the bytes with their content type.
+func Encode(img image.Image, quality int) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if hasAlpha(img) {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/png", nil
+	}
+
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "image/jpeg", nil
+}
+
+func hasAlpha(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return !o.Opaque()
+	}
+
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nrgbaAt returns the non-premultiplied 8-bit colour at (x, y).
+func nrgbaAt(img image.Image, x, y int) color.NRGBA {
+	return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+
+	"http_server/media-service/internal/config"
+	"http_server/shared/health"
+	"http_server/shared/jwtkeys"
+)
+
+// NewHealth registers the checks behind the readiness probe: the database
+// and a usable JWT key.
+func NewHealth(cfg *config.Config, sqlDB *sql.DB, jwtKeys *jwtkeys.Keys) *health.Registry {
+	registry := health.New(health.Config{
+		CacheTTL: cfg.Health.CacheTTL,
+		Timeout:  cfg.Health.Timeout,
+	})
+
+	registry.Register("database", health.PingChecker(sqlDB))
+	registry.Register("jwt_key", health.CheckerFunc(func(ctx context.Context) error {
+		return jwtKeys.Check()
+	}))
+
+	return registry
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"http_server/media-service/internal/handler"
+	"http_server/shared/health"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+	"http_server/shared/problem"
+
+	"github.com/gorilla/mux"
+)
+
+type Handlers struct {
+	Media  *handler.MediaHandler
+	Upload *handler.UploadHandler
+	Signed *handler.SignedURLHandler
+}
+
+func NewRouter(h Handlers, healthRegistry *health.Registry, authMiddleware *middleware.JWTAuth, logger *logging.Logger) *mux.Router {
+	r := mux.NewRouter()
+	r.NotFoundHandler = problem.NotFoundHandler()
+	r.MethodNotAllowedHandler = problem.MethodNotAllowedHandler()
+
+	// Request ID, access logging and panic recovery
+	r.Use(middleware.RequestID, middleware.Logging(logger), middleware.Recovery(logger))
+
+	// Health probes; /health is kept as an alias of /readyz for existing
+	// callers.
+	r.Handle("/livez", healthRegistry.LiveHandler()).Methods("GET")
+	r.Handle("/readyz", healthRegistry.ReadyHandler()).Methods("GET")
+	r.Handle("/health", healthRegistry.ReadyHandler()).Methods("GET")
+
+	// Signed URLs carry their own authorization. A bearer token is only
+	// checked, if present, for URLs bound to a user.
+	signed := r.PathPrefix("/signed").Subrouter()
+	signed.Use(authMiddleware.OptionalJWT)
+	signed.HandleFunc("/media/{id}", h.Signed.Content).Methods("GET", "HEAD")
+	signed.HandleFunc("/media/{id}/renditions/{name}", h.Signed.Rendition).Methods("GET", "HEAD")
+
+	// tus capability discovery is unauthenticated
+	r.HandleFunc("/api/v1/uploads", h.Upload.Options).Methods("OPTIONS")
+
+	// API routes
+	api := r.PathPrefix("/api/v1").Subrouter()
+	api.Use(authMiddleware.ValidateJWT)
+
+	// Media
+	api.HandleFunc("/media", h.Media.Upload).Methods("POST")
+	api.HandleFunc("/media/signed-urls", h.Signed.SignMany).Methods("POST")
+	api.HandleFunc("/media/{id}", h.Media.Get).Methods("GET")
+	api.HandleFunc("/media/{id}/content", h.Media.Download).Methods("GET", "HEAD")
+	api.HandleFunc("/media/{id}", h.Media.Delete).Methods("DELETE")
+	api.HandleFunc("/media/{id}/processing", h.Media.Processing).Methods("GET")
+	api.HandleFunc("/media/{id}/renditions/{name}", h.Media.Rendition).Methods("GET", "HEAD")
+	api.HandleFunc("/media/{id}/signed-url", h.Signed.Sign).Methods("POST")
+
+	// Resumable uploads
+	api.HandleFunc("/uploads", h.Upload.Create).Methods("POST")
+	api.HandleFunc("/uploads/{id}", h.Upload.Head).Methods("HEAD")
+	api.HandleFunc("/uploads/{id}", h.Upload.Patch).Methods("PATCH")
+	api.HandleFunc("/uploads/{id}", h.Upload.Delete).Methods("DELETE")
+
+	return r
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"http_server/media-service/internal/config"
+	"http_server/media-service/internal/domain/models"
+	"http_server/media-service/internal/domain/repository"
+	"http_server/media-service/internal/imaging"
+	"http_server/media-service/internal/storage"
+	"http_server/shared/logging"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrJobNotFound       = errors.New("processing job not found")
+	ErrRenditionNotFound = errors.New("rendition not found")
+)
+
+// processableTypes are the image types the decoder understands.
+var processableTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// ProcessingStatus is a job together with the renditions produced so far.
+type ProcessingStatus struct {
+	models.ProcessingJob
+	Renditions []models.Rendition `json:"renditions"`
+}
+
+type ImageProcessor interface {
+	// Supports reports whether renditions can be generated for contentType.
+	Supports(contentType string) bool
+	// Submit records a processing job for media and queues it.
+	Submit(ctx context.Context, media *models.Media) error
+	Status(ctx context.Context, mediaID uuid.UUID) (*ProcessingStatus, error)
+	// OpenRendition returns a rendition and a seekable reader over it. The
+	// caller must close the reader.
+	OpenRendition(ctx context.Context, mediaID uuid.UUID, name string) (*models.Rendition, io.ReadSeekCloser, error)
+	// DeleteRenditions removes the stored rendition objects for the content
+	// of media. Call it once no media references that content.
+	DeleteRenditions(ctx context.Context, media *models.Media) error
+	// Run starts the worker pool and blocks until ctx is done and in-flight
+	// jobs have finished.
+	Run(ctx context.Context)
+}
+
+// imageProcessor renders images on a fixed pool of workers fed by a bounded
+// queue. Jobs are persisted before they are queued, so a full queue or a
+// restart only delays them: a periodic sweep re-queues pending jobs and
+// jobs whose worker has been silent for longer than twice the job timeout.
+type imageProcessor struct {
+	mediaRepo      repository.MediaRepository
+	processingRepo repository.ProcessingRepository
+	store          storage.Storage
+	config         config.ImagesConfig
+	queue          chan uuid.UUID
+	logger         *logging.Logger
+}
+
+func NewImageProcessor(mediaRepo repository.MediaRepository, processingRepo repository.ProcessingRepository, store storage.Storage, cfg config.ImagesConfig, logger *logging.Logger) ImageProcessor {
+	return &imageProcessor{
+		mediaRepo:      mediaRepo,
+		processingRepo: processingRepo,
+		store:          store,
+		config:         cfg,
+		queue:          make(chan uuid.UUID, cfg.QueueSize),
+		logger:         logger,
+	}
+}
+
+func (p *imageProcessor) Supports(contentType string) bool {
+	return processableTypes[contentType]
+}
+
+func (p *imageProcessor) Submit(ctx context.Context, media *models.Media) error {
+	now := time.Now().UTC()
+	job := &models.ProcessingJob{
+		MediaID:   media.ID,
+		Status:    models.JobPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := p.processingRepo.CreateJob(ctx, job); err != nil {
+		if errors.Is(err, repository.ErrDuplicateKey) {
+			return nil
+		}
+		return fmt.Errorf("failed to create processing job: %w", err)
+	}
+
+	p.enqueue(media.ID)
+	return nil
+}
+
+func (p *imageProcessor) Status(ctx context.Context, mediaID uuid.UUID) (*ProcessingStatus, error) {
+	job, err := p.processingRepo.FindJob(ctx, mediaID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrJobNotFound
+		}
+		p.logger.WithContext(ctx).Error("Failed to find processing job", err, zap.String("media_id", mediaID.String()))
+		return nil, fmt.Errorf("failed to find processing job: %w", err)
+	}
+
+	renditions, err := p.processingRepo.ListRenditions(ctx, mediaID)
+	if err != nil {
+		p.logger.WithContext(ctx).Error("Failed to list renditions", err, zap.String("media_id", mediaID.String()))
+		return nil, fmt.Errorf("failed to list renditions: %w", err)
+	}
+	if renditions == nil {
+		renditions = []models.Rendition{}
+	}
+
+	return &ProcessingStatus{ProcessingJob: *job, Renditions: renditions}, nil
+}
+
+func (p *imageProcessor) OpenRendition(ctx context.Context, mediaID uuid.UUID, name string) (*models.Rendition, io.ReadSeekCloser, error) {
+	if _, err := p.mediaRepo.FindByID(ctx, mediaID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrMediaNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to find media: %w", err)
+	}
+
+	rendition, err := p.processingRepo.FindRendition(ctx, mediaID, name)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrRenditionNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to find rendition: %w", err)
+	}
+
+	content, err := p.store.Open(ctx, rendition.Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, nil, ErrRenditionNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to open rendition: %w", err)
+	}
+	return rendition, content, nil
+}
+
+func (p *imageProcessor) DeleteRenditions(ctx context.Context, media *models.Media) error {
+	// Keys are derived from the hash rather than read from the media's own
+	// rendition rows, so renditions rendered for an earlier upload of the
+	// same content are removed too.
+	for _, rc := range p.config.Renditions {
+		if err := p.store.Delete(ctx, storage.RenditionKey(media.Hash, rc.Name)); err != nil {
+			return fmt.Errorf("failed to delete rendition %s: %w", rc.Name, err)
+		}
+	}
+	return nil
+}
+
+func (p *imageProcessor) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < p.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+
+	ticker := time.NewTicker(p.config.RequeueInterval)
+	defer ticker.Stop()
+
+	p.requeue(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+			p.requeue(ctx)
+		}
+	}
+}
+
+func (p *imageProcessor) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case mediaID := <-p.queue:
+			p.process(mediaID)
+		}
+	}
+}
+
+// enqueue queues a job without blocking. A job that does not fit stays
+// pending and is picked up by the next sweep.
+func (p *imageProcessor) enqueue(mediaID uuid.UUID) {
+	select {
+	case p.queue <- mediaID:
+	default:
+		p.logger.Debug("Processing queue full, deferring job", zap.String("media_id", mediaID.String()))
+	}
+}
+
+// requeue feeds claimable jobs from the database into an idle queue.
+func (p *imageProcessor) requeue(ctx context.Context) {
+	if len(p.queue) > 0 {
+		return
+	}
+
+	jobs, err := p.processingRepo.ListClaimable(ctx, p.staleBefore(), p.config.QueueSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.Warn("Failed to list pending processing jobs", zap.Error(err))
+		}
+		return
+	}
+	for _, job := range jobs {
+		p.enqueue(job.MediaID)
+	}
+}
+
+// process runs one job. It deliberately ignores the pool's context so that
+// shutdown lets in-flight jobs finish within their own timeout.
+func (p *imageProcessor) process(mediaID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.JobTimeout)
+	defer cancel()
+
+	logger := p.logger.WithFields(map[string]interface{}{"media_id": mediaID.String()})
+
+	job, err := p.processingRepo.ClaimJob(ctx, mediaID, p.staleBefore())
+	if err != nil {
+		if !errors.Is(err, repository.ErrConflict) {
+			logger.Warn("Failed to claim processing job", zap.Error(err))
+		}
+		return
+	}
+
+	started := time.Now()
+	err = p.render(ctx, mediaID)
+	if err == nil {
+		if err := p.processingRepo.FinishJob(ctx, mediaID, models.JobCompleted, ""); err != nil {
+			logger.Warn("Failed to mark processing job completed", zap.Error(err))
+		}
+		logger.Info("Image processed", zap.Duration("duration", time.Since(started)))
+		return
+	}
+
+	status := models.JobPending
+	if isPermanentProcessingError(err) || job.Attempts >= p.config.MaxAttempts {
+		status = models.JobFailed
+	}
+	logger.Warn("Image processing failed",
+		zap.Error(err),
+		zap.Int("attempt", job.Attempts),
+		zap.String("status", status))
+
+	if err := p.processingRepo.FinishJob(context.Background(), mediaID, status, truncate(err.Error(), 512)); err != nil {
+		logger.Warn("Failed to record processing failure", zap.Error(err))
+	}
+}
+
+// render produces every configured rendition plus the blurhash of an image.
+func (p *imageProcessor) render(ctx context.Context, mediaID uuid.UUID) error {
+	media, err := p.mediaRepo.FindByID(ctx, mediaID)
+	if err != nil {
+		return err
+	}
+
+	content, err := p.store.Open(ctx, storage.BlobKey(media.Hash))
+	if err != nil {
+		return fmt.Errorf("failed to open blob: %w", err)
+	}
+	// Decoding seeks back to the start several times, which is cheap in
+	// memory and expensive against object storage.
+	raw, err := io.ReadAll(content)
+	content.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read blob: %w", err)
+	}
+
+	img, err := imaging.Decode(bytes.NewReader(raw), media.ContentType, p.config.MaxPixels)
+	if err != nil {
+		return err
+	}
+
+	for _, rc := range p.config.Renditions {
+		resized := imaging.Resize(img, rc.Width, rc.Height, rc.Fit)
+		encoded, contentType, err := imaging.Encode(resized, p.config.JPEGQuality)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s rendition: %w", rc.Name, err)
+		}
+
+		key := storage.RenditionKey(media.Hash, rc.Name)
+		if err := p.store.Put(ctx, key, bytes.NewReader(encoded), int64(len(encoded)), contentType); err != nil {
+			return fmt.Errorf("failed to store %s rendition: %w", rc.Name, err)
+		}
+
+		if err := p.processingRepo.SaveRendition(ctx, &models.Rendition{
+			MediaID:     media.ID,
+			Name:        rc.Name,
+			Key:         key,
+			ContentType: contentType,
+			Width:       resized.Bounds().Dx(),
+			Height:      resized.Bounds().Dy(),
+			Size:        int64(len(encoded)),
+			CreatedAt:   time.Now().UTC(),
+		}); err != nil {
+			return fmt.Errorf("failed to save %s rendition: %w", rc.Name, err)
+		}
+	}
+
+	bounds := img.Bounds()
+	blurhash := imaging.Blurhash(img, p.config.BlurhashX, p.config.BlurhashY)
+	return p.mediaRepo.UpdateImageInfo(ctx, media.ID, bounds.Dx(), bounds.Dy(), blurhash)
+}
+
+func (p *imageProcessor) staleBefore() time.Time {
+	return time.Now().UTC().Add(-2 * p.config.JobTimeout)
+}
+
+// isPermanentProcessingError reports whether retrying cannot succeed.
+func isPermanentProcessingError(err error) bool {
+	return errors.Is(err, imaging.ErrMalformedImage) ||
+		errors.Is(err, imaging.ErrTooManyPixels) ||
+		errors.Is(err, repository.ErrNotFound)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
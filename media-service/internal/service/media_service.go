@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"http_server/media-service/internal/config"
+	"http_server/media-service/internal/domain/models"
+	"http_server/media-service/internal/domain/repository"
+	"http_server/media-service/internal/imaging"
+	"http_server/media-service/internal/storage"
+	"http_server/shared/logging"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const roleAdmin = "admin"
+
+var (
+	ErrMediaNotFound   = errors.New("media not found")
+	ErrFileTooLarge    = errors.New("file exceeds maximum size")
+	ErrEmptyFile       = errors.New("file is empty")
+	ErrUnsupportedType = errors.New("unsupported media type")
+	ErrTypeMismatch    = errors.New("declared type does not match content")
+	ErrForbidden       = errors.New("operation not permitted")
+	ErrInvalidImage    = errors.New("file is not a valid image")
+)
+
+// UploadInput is a single file received from a client.
+type UploadInput struct {
+	OwnerID      uuid.UUID
+	Filename     string
+	DeclaredType string
+	Body         io.Reader
+}
+
+type MediaService interface {
+	Upload(ctx context.Context, in UploadInput) (*models.Media, error)
+	Get(ctx context.Context, id uuid.UUID) (*models.Media, error)
+	// Open returns the media record and a seekable reader over its content.
+	// The caller must close the reader.
+	Open(ctx context.Context, id uuid.UUID) (*models.Media, io.ReadSeekCloser, error)
+	Delete(ctx context.Context, id, requesterID uuid.UUID, requesterRoles []string) error
+}
+
+type mediaService struct {
+	mediaRepo repository.MediaRepository
+	store     storage.Storage
+	config    config.StorageConfig
+	allowed   map[string]bool
+	processor ImageProcessor
+	logger    *logging.Logger
+}
+
+func NewMediaService(mediaRepo repository.MediaRepository, store storage.Storage, processor ImageProcessor, cfg config.StorageConfig, logger *logging.Logger) MediaService {
+	allowed := make(map[string]bool, len(cfg.AllowedTypes))
+	for _, t := range cfg.AllowedTypes {
+		allowed[t] = true
+	}
+
+	return &mediaService{
+		mediaRepo: mediaRepo,
+		store:     store,
+		config:    cfg,
+		allowed:   allowed,
+		processor: processor,
+		logger:    logger,
+	}
+}
+
+func (s *mediaService) Upload(ctx context.Context, in UploadInput) (*models.Media, error) {
+	logger := s.logger.WithContext(ctx)
+
+	// Spool to disk so the content can be inspected and hashed before
+	// anything reaches storage, and so large files never sit in memory.
+	tmp, err := os.CreateTemp(s.config.TempDir, "media-upload-*")
+	if err != nil {
+		logger.Error("Failed to create spool file", err)
+		return nil, fmt.Errorf("failed to create spool file: %w", err)
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	size, err := io.Copy(tmp, io.LimitReader(in.Body, s.config.MaxFileSize+1))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, ErrFileTooLarge
+		}
+		logger.Error("Failed to read upload", err)
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if size > s.config.MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+	if size == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := tmp.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read spool file: %w", err)
+	}
+
+	contentType := detectContentType(head[:n])
+	if !s.allowed[contentType] {
+		logger.Warn("Rejected upload with unsupported type", zap.String("content_type", contentType))
+		return nil, ErrUnsupportedType
+	}
+
+	filename := sanitizeFilename(in.Filename)
+	if err := checkDeclaredType(contentType, in.DeclaredType, filename); err != nil {
+		logger.Warn("Rejected upload with spoofed type",
+			zap.String("sniffed", contentType),
+			zap.String("declared", in.DeclaredType),
+			zap.String("filename", filename))
+		return nil, err
+	}
+
+	content, size, err := s.stripMetadata(tmp, size, contentType)
+	if err != nil {
+		if errors.Is(err, imaging.ErrMalformedImage) {
+			logger.Warn("Rejected malformed image", zap.String("content_type", contentType))
+			return nil, ErrInvalidImage
+		}
+		logger.Error("Failed to strip metadata", err)
+		return nil, fmt.Errorf("failed to strip metadata: %w", err)
+	}
+	if content != tmp {
+		defer func() {
+			content.Close()
+			os.Remove(content.Name())
+		}()
+	}
+
+	// Hashing happens after stripping so that copies of a photo that differ
+	// only in metadata deduplicate to the same blob.
+	hasher := sha256.New()
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(hasher, content); err != nil {
+		return nil, fmt.Errorf("failed to hash upload: %w", err)
+	}
+
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	if err := s.storeBlob(ctx, hash, content, size, contentType); err != nil {
+		logger.Error("Failed to store blob", err, zap.String("hash", hash))
+		return nil, fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	now := time.Now().UTC()
+	media := &models.Media{
+		ID:          uuid.New(),
+		OwnerID:     in.OwnerID,
+		Hash:        hash,
+		ContentType: contentType,
+		Size:        size,
+		Filename:    filename,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.mediaRepo.Create(ctx, media); err != nil {
+		logger.Error("Failed to create media record", err, zap.String("hash", hash))
+		return nil, fmt.Errorf("failed to create media record: %w", err)
+	}
+
+	if s.processor != nil && s.processor.Supports(contentType) {
+		if err := s.processor.Submit(ctx, media); err != nil {
+			logger.Warn("Failed to submit image processing job", zap.Error(err), zap.String("media_id", media.ID.String()))
+		}
+	}
+
+	logger.Info("Media uploaded",
+		zap.String("media_id", media.ID.String()),
+		zap.String("content_type", contentType),
+		zap.Int64("size", size))
+	return media, nil
+}
+
+// stripMetadata removes EXIF, GPS and similar metadata from images before
+// they are stored. It returns the file holding the cleaned content, which is
+// src itself for types that carry no strippable metadata.
+func (s *mediaService) stripMetadata(src *os.File, size int64, contentType string) (*os.File, int64, error) {
+	if !imaging.CanStripMetadata(contentType) {
+		return src, size, nil
+	}
+
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+	clean, err := os.CreateTemp(s.config.TempDir, "media-clean-*")
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create spool file: %w", err)
+	}
+
+	if err := imaging.StripMetadata(contentType, src, clean); err != nil {
+		clean.Close()
+		os.Remove(clean.Name())
+		return nil, 0, err
+	}
+
+	info, err := clean.Stat()
+	if err != nil {
+		clean.Close()
+		os.Remove(clean.Name())
+		return nil, 0, err
+	}
+	return clean, info.Size(), nil
+}
+
+// storeBlob uploads the spooled content unless a blob with the same hash is
+// already stored.
+func (s *mediaService) storeBlob(ctx context.Context, hash string, file *os.File, size int64, contentType string) error {
+	key := storage.BlobKey(hash)
+
+	_, err := s.store.Stat(ctx, key)
+	if err == nil {
+		s.logger.WithContext(ctx).Debug("Deduplicated upload", zap.String("hash", hash))
+		return nil
+	}
+	if !errors.Is(err, storage.ErrObjectNotFound) {
+		return err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return s.store.Put(ctx, key, file, size, contentType)
+}
+
+func (s *mediaService) Get(ctx context.Context, id uuid.UUID) (*models.Media, error) {
+	media, err := s.mediaRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrMediaNotFound
+		}
+		s.logger.WithContext(ctx).Error("Failed to find media", err, zap.String("media_id", id.String()))
+		return nil, fmt.Errorf("failed to find media: %w", err)
+	}
+	return media, nil
+}
+
+func (s *mediaService) Open(ctx context.Context, id uuid.UUID) (*models.Media, io.ReadSeekCloser, error) {
+	media, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.store.Open(ctx, storage.BlobKey(media.Hash))
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			s.logger.WithContext(ctx).Error("Blob missing for media", err, zap.String("media_id", id.String()))
+			return nil, nil, ErrMediaNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to open blob: %w", err)
+	}
+	return media, content, nil
+}
+
+func (s *mediaService) Delete(ctx context.Context, id, requesterID uuid.UUID, requesterRoles []string) error {
+	logger := s.logger.WithContext(ctx)
+
+	media, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if media.OwnerID != requesterID && !hasRole(requesterRoles, roleAdmin) {
+		return ErrForbidden
+	}
+
+	if err := s.mediaRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrMediaNotFound
+		}
+		logger.Error("Failed to delete media", err, zap.String("media_id", id.String()))
+		return fmt.Errorf("failed to delete media: %w", err)
+	}
+
+	// Drop the blob once nothing references it. An upload of the same content
+	// racing with this check may find the blob gone on download; it is
+	// restored by uploading again.
+	refs, err := s.mediaRepo.CountByHash(ctx, media.Hash)
+	if err != nil {
+		logger.Warn("Failed to count blob references", zap.Error(err), zap.String("hash", media.Hash))
+		return nil
+	}
+	if refs == 0 {
+		if err := s.store.Delete(ctx, storage.BlobKey(media.Hash)); err != nil {
+			logger.Warn("Failed to delete unreferenced blob", zap.Error(err), zap.String("hash", media.Hash))
+		}
+		if s.processor != nil {
+			if err := s.processor.DeleteRenditions(ctx, media); err != nil {
+				logger.Warn("Failed to delete renditions", zap.Error(err), zap.String("hash", media.Hash))
+			}
+		}
+	}
+
+	logger.Info("Media deleted", zap.String("media_id", id.String()))
+	return nil
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"http_server/media-service/internal/config"
+	"http_server/media-service/internal/domain/repository"
+	"http_server/media-service/internal/signing"
+	"http_server/shared/logging"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxSignBatch bounds how many URLs one SignMany call produces.
+const maxSignBatch = 100
+
+var (
+	ErrInvalidTTL       = errors.New("invalid signed URL TTL")
+	ErrBatchTooLarge    = errors.New("too many media in one request")
+	ErrSignatureInvalid = errors.New("invalid or expired signature")
+)
+
+type SignURLInput struct {
+	MediaID uuid.UUID
+	// Rendition selects a named rendition; empty means the original.
+	Rendition string
+	// TTL is how long the URL stays valid; zero means the configured default.
+	TTL time.Duration
+	// BindIP restricts the URL to requests from this client address.
+	BindIP string
+	// BindUser restricts the URL to requests authenticated as this user.
+	BindUser *uuid.UUID
+}
+
+type SignedURL struct {
+	MediaID   uuid.UUID `json:"media_id"`
+	Rendition string    `json:"rendition,omitempty"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// VerifyURLInput describes a request for a signed URL.
+type VerifyURLInput struct {
+	MediaID   uuid.UUID
+	Rendition string
+	Query     url.Values
+	ClientIP  string
+	// UserID is the authenticated requester, if the request carried a token.
+	UserID *uuid.UUID
+}
+
+type SignedURLService interface {
+	Sign(ctx context.Context, in SignURLInput) (*SignedURL, error)
+	// SignMany signs one URL per media using the other fields of template.
+	// Media that do not exist, or lack the requested rendition type, are
+	// left out of the result.
+	SignMany(ctx context.Context, mediaIDs []uuid.UUID, template SignURLInput) ([]SignedURL, error)
+	// Verify checks a signed URL request and returns when the URL expires.
+	Verify(ctx context.Context, in VerifyURLInput) (time.Time, error)
+}
+
+type signedURLService struct {
+	mediaRepo  repository.MediaRepository
+	processor  ImageProcessor
+	signer     *signing.Signer
+	renditions map[string]bool
+	config     config.SigningConfig
+	logger     *logging.Logger
+}
+
+func NewSignedURLService(mediaRepo repository.MediaRepository, processor ImageProcessor, signer *signing.Signer, cfg config.SigningConfig, images config.ImagesConfig, logger *logging.Logger) SignedURLService {
+	renditions := make(map[string]bool, len(images.Renditions))
+	for _, r := range images.Renditions {
+		renditions[r.Name] = true
+	}
+
+	return &signedURLService{
+		mediaRepo:  mediaRepo,
+		processor:  processor,
+		signer:     signer,
+		renditions: renditions,
+		config:     cfg,
+		logger:     logger,
+	}
+}
+
+func (s *signedURLService) Sign(ctx context.Context, in SignURLInput) (*SignedURL, error) {
+	ttl, err := s.checkInput(in)
+	if err != nil {
+		return nil, err
+	}
+
+	media, err := s.mediaRepo.FindByID(ctx, in.MediaID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrMediaNotFound
+		}
+		s.logger.WithContext(ctx).Error("Failed to find media", err, zap.String("media_id", in.MediaID.String()))
+		return nil, fmt.Errorf("failed to find media: %w", err)
+	}
+	if in.Rendition != "" && !s.processor.Supports(media.ContentType) {
+		return nil, ErrRenditionNotFound
+	}
+
+	signed := s.sign(media.ID, in, ttl)
+	return &signed, nil
+}
+
+func (s *signedURLService) SignMany(ctx context.Context, mediaIDs []uuid.UUID, template SignURLInput) ([]SignedURL, error) {
+	if len(mediaIDs) > maxSignBatch {
+		return nil, ErrBatchTooLarge
+	}
+	ttl, err := s.checkInput(template)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := s.mediaRepo.FindByIDs(ctx, mediaIDs)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to find media", err)
+		return nil, fmt.Errorf("failed to find media: %w", err)
+	}
+
+	urls := make([]SignedURL, 0, len(found))
+	for _, media := range found {
+		if template.Rendition != "" && !s.processor.Supports(media.ContentType) {
+			continue
+		}
+		urls = append(urls, s.sign(media.ID, template, ttl))
+	}
+	return urls, nil
+}
+
+func (s *signedURLService) Verify(ctx context.Context, in VerifyURLInput) (time.Time, error) {
+	logger := s.logger.WithContext(ctx)
+
+	claims, err := s.signer.Verify(in.MediaID, in.Rendition, in.Query)
+	if err != nil {
+		logger.Debug("Rejected signed URL", zap.Error(err), zap.String("media_id", in.MediaID.String()))
+		return time.Time{}, ErrSignatureInvalid
+	}
+	if claims.IP != "" && claims.IP != in.ClientIP {
+		logger.Debug("Signed URL used from another address",
+			zap.String("media_id", in.MediaID.String()),
+			zap.String("client_ip", in.ClientIP))
+		return time.Time{}, ErrSignatureInvalid
+	}
+	if claims.UserID != nil && (in.UserID == nil || *claims.UserID != *in.UserID) {
+		logger.Debug("Signed URL used by another user", zap.String("media_id", in.MediaID.String()))
+		return time.Time{}, ErrSignatureInvalid
+	}
+	return claims.ExpiresAt, nil
+}
+
+func (s *signedURLService) checkInput(in SignURLInput) (time.Duration, error) {
+	ttl := in.TTL
+	if ttl == 0 {
+		ttl = s.config.DefaultTTL
+	}
+	if ttl < 0 || ttl > s.config.MaxTTL {
+		return 0, ErrInvalidTTL
+	}
+	if in.Rendition != "" && !s.renditions[in.Rendition] {
+		return 0, ErrRenditionNotFound
+	}
+	return ttl, nil
+}
+
+func (s *signedURLService) sign(mediaID uuid.UUID, in SignURLInput, ttl time.Duration) SignedURL {
+	// Whole seconds, since that is what the URL carries.
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second).UTC()
+
+	query := s.signer.Sign(signing.Claims{
+		MediaID:   mediaID,
+		Rendition: in.Rendition,
+		ExpiresAt: expiresAt,
+		IP:        in.BindIP,
+		UserID:    in.BindUser,
+	})
+
+	return SignedURL{
+		MediaID:   mediaID,
+		Rendition: in.Rendition,
+		URL:       s.config.BaseURL + SignedPath(mediaID, in.Rendition) + "?" + query.Encode(),
+		ExpiresAt: expiresAt,
+	}
+}
+
+// SignedPath returns the public path under which a signed URL is served.
+func SignedPath(mediaID uuid.UUID, rendition string) string {
+	path := "/signed/media/" + mediaID.String()
+	if rendition != "" {
+		path += "/renditions/" + url.PathEscape(rendition)
+	}
+	return path
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// sniffLen is the number of leading bytes inspected by http.DetectContentType
+const sniffLen = 512
+
+// extensionTypes maps file extensions to the media type their content must
+// have. It only needs to cover types we may accept; unknown extensions are
+// treated as a mismatch.
+var extensionTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".jpe":  "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".mp4":  "video/mp4",
+	".m4v":  "video/mp4",
+	".webp": "image/webp",
+}
+
+// detectContentType identifies the media type from the file's magic bytes,
+// ignoring whatever the client claims.
+func detectContentType(head []byte) string {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+// checkDeclaredType rejects uploads whose declared Content-Type or filename
+// extension disagrees with the sniffed type, e.g. an HTML page sent as
+// "avatar.png" with Content-Type image/png.
+func checkDeclaredType(sniffed, declared, filename string) error {
+	if declared != "" && declared != "application/octet-stream" {
+		mediaType, _, err := mime.ParseMediaType(declared)
+		if err != nil || !strings.EqualFold(mediaType, sniffed) {
+			return ErrTypeMismatch
+		}
+	}
+
+	if ext := strings.ToLower(filepath.Ext(filename)); ext != "" {
+		if extensionTypes[ext] != sniffed {
+			return ErrTypeMismatch
+		}
+	}
+	return nil
+}
+
+// sanitizeFilename keeps only the base name of a client-supplied filename.
+func sanitizeFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" {
+		return ""
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	return name
+}
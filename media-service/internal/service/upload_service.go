@@ -0,0 +1,506 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"http_server/media-service/internal/config"
+	"http_server/media-service/internal/domain/models"
+	"http_server/media-service/internal/domain/repository"
+	"http_server/media-service/internal/storage"
+	"http_server/shared/logging"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// gcBatchSize bounds how many expired uploads are loaded per query.
+const gcBatchSize = 100
+
+var (
+	ErrUploadNotFound      = errors.New("upload not found")
+	ErrUploadExpired       = errors.New("upload expired")
+	ErrInvalidUploadLength = errors.New("invalid upload length")
+	ErrOffsetMismatch      = errors.New("upload offset mismatch")
+	ErrChunkTooLarge       = errors.New("chunk exceeds remaining length or chunk size limit")
+	ErrChecksumMismatch    = errors.New("chunk checksum mismatch")
+	ErrUnsupportedChecksum = errors.New("unsupported checksum algorithm")
+)
+
+// checksumAlgorithms are the digests accepted for per-chunk verification.
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+}
+
+// ChecksumAlgorithms returns the supported chunk checksum algorithms.
+func ChecksumAlgorithms() []string {
+	names := make([]string, 0, len(checksumAlgorithms))
+	for name := range checksumAlgorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Checksum is a client-supplied digest of a chunk.
+type Checksum struct {
+	Algorithm string
+	Digest    []byte
+}
+
+type CreateUploadInput struct {
+	OwnerID      uuid.UUID
+	Length       int64
+	Filename     string
+	DeclaredType string
+}
+
+// ChunkInput is the body of one PATCH request, which must start at Offset.
+type ChunkInput struct {
+	UploadID uuid.UUID
+	OwnerID  uuid.UUID
+	Offset   int64
+	Body     io.Reader
+	Checksum *Checksum
+}
+
+type UploadService interface {
+	CreateUpload(ctx context.Context, in CreateUploadInput) (*models.Upload, error)
+	// GetUpload returns the upload if it exists and belongs to ownerID.
+	GetUpload(ctx context.Context, id, ownerID uuid.UUID) (*models.Upload, error)
+	// WriteChunk appends a chunk and returns the updated upload. Once the
+	// final byte arrives the upload is assembled and its MediaID is set.
+	WriteChunk(ctx context.Context, in ChunkInput) (*models.Upload, error)
+	CancelUpload(ctx context.Context, id, ownerID uuid.UUID) error
+	// CollectExpired removes uploads past their expiry along with their
+	// chunks and returns how many were removed.
+	CollectExpired(ctx context.Context) (int, error)
+	// RunCollector calls CollectExpired periodically until ctx is done.
+	RunCollector(ctx context.Context)
+}
+
+// uploadService implements resumable uploads. Every chunk is verified on a
+// spool file and then stored as its own object, so uploads survive restarts
+// and can be resumed against any instance. On completion the chunks are
+// streamed through MediaService.Upload, which applies the same validation
+// and deduplication as a single-request upload.
+type uploadService struct {
+	uploadRepo   repository.UploadRepository
+	mediaService MediaService
+	store        storage.Storage
+	storage      config.StorageConfig
+	config       config.UploadsConfig
+	logger       *logging.Logger
+}
+
+func NewUploadService(uploadRepo repository.UploadRepository, mediaService MediaService, store storage.Storage, storageCfg config.StorageConfig, cfg config.UploadsConfig, logger *logging.Logger) UploadService {
+	return &uploadService{
+		uploadRepo:   uploadRepo,
+		mediaService: mediaService,
+		store:        store,
+		storage:      storageCfg,
+		config:       cfg,
+		logger:       logger,
+	}
+}
+
+func (s *uploadService) CreateUpload(ctx context.Context, in CreateUploadInput) (*models.Upload, error) {
+	if in.Length <= 0 {
+		return nil, ErrInvalidUploadLength
+	}
+	if in.Length > s.storage.MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+
+	now := time.Now().UTC()
+	upload := &models.Upload{
+		ID:           uuid.New(),
+		OwnerID:      in.OwnerID,
+		Length:       in.Length,
+		Filename:     sanitizeFilename(in.Filename),
+		DeclaredType: in.DeclaredType,
+		ExpiresAt:    now.Add(s.config.TTL),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.uploadRepo.Create(ctx, upload); err != nil {
+		s.logger.WithContext(ctx).Error("Failed to create upload", err)
+		return nil, fmt.Errorf("failed to create upload: %w", err)
+	}
+
+	s.logger.WithContext(ctx).Info("Upload created",
+		zap.String("upload_id", upload.ID.String()),
+		zap.Int64("length", upload.Length))
+	return upload, nil
+}
+
+func (s *uploadService) GetUpload(ctx context.Context, id, ownerID uuid.UUID) (*models.Upload, error) {
+	upload, err := s.findOwned(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if !upload.Completed() && time.Now().After(upload.ExpiresAt) {
+		return nil, ErrUploadExpired
+	}
+	return upload, nil
+}
+
+// findOwned loads an upload regardless of expiry. Other users' uploads are
+// reported as missing rather than forbidden so upload IDs cannot be probed.
+func (s *uploadService) findOwned(ctx context.Context, id, ownerID uuid.UUID) (*models.Upload, error) {
+	upload, err := s.uploadRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUploadNotFound
+		}
+		s.logger.WithContext(ctx).Error("Failed to find upload", err, zap.String("upload_id", id.String()))
+		return nil, fmt.Errorf("failed to find upload: %w", err)
+	}
+	if upload.OwnerID != ownerID {
+		return nil, ErrUploadNotFound
+	}
+	return upload, nil
+}
+
+func (s *uploadService) WriteChunk(ctx context.Context, in ChunkInput) (*models.Upload, error) {
+	logger := s.logger.WithContext(ctx)
+
+	upload, err := s.GetUpload(ctx, in.UploadID, in.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if in.Offset != upload.Offset {
+		return nil, ErrOffsetMismatch
+	}
+	if upload.Completed() {
+		return upload, nil
+	}
+
+	var newHash func() hash.Hash
+	if in.Checksum != nil {
+		var ok bool
+		if newHash, ok = checksumAlgorithms[in.Checksum.Algorithm]; !ok {
+			return nil, ErrUnsupportedChecksum
+		}
+	}
+
+	limit := upload.Length - upload.Offset
+	if limit > s.config.MaxChunkSize {
+		limit = s.config.MaxChunkSize
+	}
+
+	tmp, err := os.CreateTemp(s.storage.TempDir, "media-chunk-*")
+	if err != nil {
+		logger.Error("Failed to create spool file", err)
+		return nil, fmt.Errorf("failed to create spool file: %w", err)
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	var dst io.Writer = tmp
+	var hasher hash.Hash
+	if newHash != nil {
+		hasher = newHash()
+		dst = io.MultiWriter(tmp, hasher)
+	}
+
+	size, err := io.Copy(dst, io.LimitReader(in.Body, limit+1))
+	if err != nil {
+		logger.Warn("Failed to read chunk", zap.Error(err), zap.String("upload_id", upload.ID.String()))
+		return nil, fmt.Errorf("failed to read chunk: %w", err)
+	}
+	if size > limit {
+		return nil, ErrChunkTooLarge
+	}
+	if hasher != nil && !bytes.Equal(hasher.Sum(nil), in.Checksum.Digest) {
+		logger.Warn("Rejected chunk with bad checksum",
+			zap.String("upload_id", upload.ID.String()),
+			zap.Int64("offset", in.Offset))
+		return nil, ErrChecksumMismatch
+	}
+
+	if size > 0 {
+		if err := s.appendChunk(ctx, upload, tmp, size); err != nil {
+			return nil, err
+		}
+	}
+
+	if upload.Offset == upload.Length {
+		return s.complete(ctx, upload)
+	}
+	return upload, nil
+}
+
+// appendChunk stores the spooled chunk and advances upload past it.
+func (s *uploadService) appendChunk(ctx context.Context, upload *models.Upload, file *os.File, size int64) error {
+	logger := s.logger.WithContext(ctx)
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	// Keys are unique per attempt so that a request losing the offset race
+	// below cannot clobber the winner's object when cleaning up.
+	key := chunkKey(upload.ID, uuid.New())
+	if err := s.store.Put(ctx, key, file, size, "application/octet-stream"); err != nil {
+		logger.Error("Failed to store chunk", err, zap.String("upload_id", upload.ID.String()))
+		return fmt.Errorf("failed to store chunk: %w", err)
+	}
+
+	chunk := &models.UploadChunk{
+		UploadID:  upload.ID,
+		Offset:    upload.Offset,
+		Size:      size,
+		Key:       key,
+		CreatedAt: time.Now().UTC(),
+	}
+	expiresAt := time.Now().UTC().Add(s.config.TTL)
+	if err := s.uploadRepo.AppendChunk(ctx, chunk, expiresAt); err != nil {
+		if delErr := s.store.Delete(ctx, key); delErr != nil {
+			logger.Warn("Failed to delete orphaned chunk", zap.Error(delErr), zap.String("key", key))
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return ErrOffsetMismatch
+		}
+		logger.Error("Failed to record chunk", err, zap.String("upload_id", upload.ID.String()))
+		return fmt.Errorf("failed to record chunk: %w", err)
+	}
+
+	upload.Offset += size
+	upload.ExpiresAt = expiresAt
+	return nil
+}
+
+// complete assembles the chunks of a fully received upload into a Media. If
+// assembly fails for a transient reason the upload is left as is, and a
+// zero-length PATCH at the final offset retries it.
+func (s *uploadService) complete(ctx context.Context, upload *models.Upload) (*models.Upload, error) {
+	logger := s.logger.WithContext(ctx)
+
+	chunks, err := s.uploadRepo.ListChunks(ctx, upload.ID)
+	if err != nil {
+		logger.Error("Failed to list chunks", err, zap.String("upload_id", upload.ID.String()))
+		return nil, fmt.Errorf("failed to list chunks: %w", err)
+	}
+
+	keys := make([]string, len(chunks))
+	var next int64
+	for i, chunk := range chunks {
+		if chunk.Offset != next {
+			logger.Error("Upload has a gap between chunks", nil,
+				zap.String("upload_id", upload.ID.String()),
+				zap.Int64("offset", next))
+			return nil, fmt.Errorf("upload %s is missing bytes at offset %d", upload.ID, next)
+		}
+		keys[i] = chunk.Key
+		next += chunk.Size
+	}
+
+	body := &chunkReader{ctx: ctx, store: s.store, keys: keys}
+	media, err := s.mediaService.Upload(ctx, UploadInput{
+		OwnerID:      upload.OwnerID,
+		Filename:     upload.Filename,
+		DeclaredType: upload.DeclaredType,
+		Body:         body,
+	})
+	body.Close()
+	if err != nil {
+		if isRejection(err) {
+			// The content itself is unacceptable, so there is nothing to resume.
+			s.discard(ctx, upload.ID, keys)
+		}
+		return nil, err
+	}
+
+	if err := s.uploadRepo.MarkCompleted(ctx, upload.ID, media.ID); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			// A concurrent retry finished first; keep its media and drop ours.
+			if delErr := s.mediaService.Delete(ctx, media.ID, upload.OwnerID, nil); delErr != nil {
+				logger.Warn("Failed to delete duplicate media", zap.Error(delErr), zap.String("media_id", media.ID.String()))
+			}
+			return s.GetUpload(ctx, upload.ID, upload.OwnerID)
+		}
+		logger.Error("Failed to complete upload", err, zap.String("upload_id", upload.ID.String()))
+		return nil, fmt.Errorf("failed to complete upload: %w", err)
+	}
+
+	s.deleteChunkObjects(ctx, keys)
+
+	upload.MediaID = &media.ID
+	logger.Info("Upload completed",
+		zap.String("upload_id", upload.ID.String()),
+		zap.String("media_id", media.ID.String()))
+	return upload, nil
+}
+
+func (s *uploadService) CancelUpload(ctx context.Context, id, ownerID uuid.UUID) error {
+	upload, err := s.findOwned(ctx, id, ownerID)
+	if err != nil {
+		return err
+	}
+
+	keys, err := s.chunkKeys(ctx, upload.ID)
+	if err != nil {
+		return err
+	}
+	return s.discard(ctx, upload.ID, keys)
+}
+
+func (s *uploadService) CollectExpired(ctx context.Context) (int, error) {
+	logger := s.logger.WithContext(ctx)
+	removed := 0
+
+	for {
+		expired, err := s.uploadRepo.ListExpired(ctx, time.Now().UTC(), gcBatchSize)
+		if err != nil {
+			logger.Error("Failed to list expired uploads", err)
+			return removed, fmt.Errorf("failed to list expired uploads: %w", err)
+		}
+
+		for _, upload := range expired {
+			keys, err := s.chunkKeys(ctx, upload.ID)
+			if err != nil {
+				return removed, err
+			}
+			if err := s.discard(ctx, upload.ID, keys); err != nil && !errors.Is(err, ErrUploadNotFound) {
+				return removed, err
+			}
+			removed++
+		}
+
+		if len(expired) < gcBatchSize {
+			return removed, nil
+		}
+	}
+}
+
+func (s *uploadService) RunCollector(ctx context.Context) {
+	ticker := time.NewTicker(s.config.GCInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := s.CollectExpired(ctx)
+			if err != nil {
+				s.logger.Warn("Upload garbage collection failed", zap.Error(err))
+				continue
+			}
+			if removed > 0 {
+				s.logger.Info("Removed expired uploads", zap.Int("count", removed))
+			}
+		}
+	}
+}
+
+func (s *uploadService) chunkKeys(ctx context.Context, uploadID uuid.UUID) ([]string, error) {
+	chunks, err := s.uploadRepo.ListChunks(ctx, uploadID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to list chunks", err, zap.String("upload_id", uploadID.String()))
+		return nil, fmt.Errorf("failed to list chunks: %w", err)
+	}
+
+	keys := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		keys[i] = chunk.Key
+	}
+	return keys, nil
+}
+
+// discard deletes an upload record and the chunk objects it references.
+// Rows go first so a failure never leaves a record pointing at missing data.
+func (s *uploadService) discard(ctx context.Context, uploadID uuid.UUID, keys []string) error {
+	if err := s.uploadRepo.Delete(ctx, uploadID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrUploadNotFound
+		}
+		s.logger.WithContext(ctx).Error("Failed to delete upload", err, zap.String("upload_id", uploadID.String()))
+		return fmt.Errorf("failed to delete upload: %w", err)
+	}
+
+	s.deleteChunkObjects(ctx, keys)
+	return nil
+}
+
+func (s *uploadService) deleteChunkObjects(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			s.logger.WithContext(ctx).Warn("Failed to delete chunk", zap.Error(err), zap.String("key", key))
+		}
+	}
+}
+
+// isRejection reports whether err means the uploaded content was refused,
+// as opposed to a failure worth retrying.
+func isRejection(err error) bool {
+	return errors.Is(err, ErrFileTooLarge) ||
+		errors.Is(err, ErrEmptyFile) ||
+		errors.Is(err, ErrUnsupportedType) ||
+		errors.Is(err, ErrTypeMismatch) ||
+		errors.Is(err, ErrInvalidImage)
+}
+
+func chunkKey(uploadID, chunkID uuid.UUID) string {
+	return fmt.Sprintf("uploads/%s/%s", uploadID, chunkID)
+}
+
+// chunkReader reads a sequence of stored objects as one stream, opening
+// each only when the previous one is exhausted.
+type chunkReader struct {
+	ctx     context.Context
+	store   storage.Storage
+	keys    []string
+	current io.ReadCloser
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.keys) == 0 {
+				return 0, io.EOF
+			}
+			object, err := r.store.Open(r.ctx, r.keys[0])
+			if err != nil {
+				return 0, fmt.Errorf("failed to open chunk %s: %w", r.keys[0], err)
+			}
+			r.current = object
+			r.keys = r.keys[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			r.current.Close()
+			r.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *chunkReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
@@ -0,0 +1,72 @@
+package signing
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIP resolves the address of the client behind any trusted proxies.
+// X-Forwarded-For is only believed when the direct peer is trusted, and is
+// walked from the right so a client cannot spoof entries added by our own
+// proxies.
+type ClientIP struct {
+	trusted []*net.IPNet
+}
+
+func NewClientIP(trustedProxies []string) (*ClientIP, error) {
+	trusted := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, cidr := range trustedProxies {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		trusted = append(trusted, network)
+	}
+	return &ClientIP{trusted: trusted}, nil
+}
+
+func (c *ClientIP) FromRequest(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !c.isTrusted(host) {
+		return normalizeIP(host)
+	}
+
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if !c.isTrusted(hop) {
+			return normalizeIP(hop)
+		}
+		host = hop
+	}
+	return normalizeIP(host)
+}
+
+func (c *ClientIP) isTrusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range c.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeIP returns the canonical text form so that equal addresses
+// compare equal as strings.
+func normalizeIP(addr string) string {
+	if ip := net.ParseIP(addr); ip != nil {
+		return ip.String()
+	}
+	return addr
+}
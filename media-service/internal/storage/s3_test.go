@@ -0,0 +1,247 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"http_server/media-service/internal/config"
+)
+
+const (
+	testAccessKey = "AKIDEXAMPLE"
+	testSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	testRegion    = "eu-west-1"
+)
+
+type s3StubObject struct {
+	data        []byte
+	contentType string
+}
+
+// s3Stub is an in-memory S3 bucket that checks the SigV4 signature of every
+// request against testSecretKey, as S3 would, and rejects a mismatch with
+// 403 and the reason in the body.
+type s3Stub struct {
+	mu      sync.Mutex
+	objects map[string]s3StubObject
+	ranges  []string
+}
+
+func newS3Stub(t *testing.T) (*s3Stub, *httptest.Server) {
+	stub := &s3Stub{objects: make(map[string]s3StubObject)}
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+	return stub, server
+}
+
+func (s *s3Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := s.verify(r); err != nil {
+		http.Error(w, "SignatureDoesNotMatch: "+err.Error(), http.StatusForbidden)
+		return
+	}
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != "media" {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	object, ok := s.objects[key]
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil || int64(len(data)) != r.ContentLength {
+			http.Error(w, "IncompleteBody", http.StatusBadRequest)
+			return
+		}
+		s.objects[key] = s3StubObject{data: data, contentType: r.Header.Get("Content-Type")}
+	case http.MethodHead, http.MethodGet:
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		data := object.data
+		status := http.StatusOK
+		if rng := r.Header.Get("Range"); rng != "" {
+			s.ranges = append(s.ranges, rng)
+			start, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+			data = data[start:]
+			status = http.StatusPartialContent
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+		w.WriteHeader(status)
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case http.MethodDelete:
+		// S3 answers 204 whether or not the key existed.
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// verify recomputes the request signature from what arrived on the wire.
+func (s *s3Stub) verify(r *http.Request) error {
+	auth := r.Header.Get("Authorization")
+	fields := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(auth, "AWS4-HMAC-SHA256 "), ", ") {
+		name, value, _ := strings.Cut(part, "=")
+		fields[name] = value
+	}
+	credential := strings.SplitN(fields["Credential"], "/", 2)
+	if len(credential) != 2 || credential[0] != testAccessKey {
+		return fmt.Errorf("bad credential %q", fields["Credential"])
+	}
+	scope := credential[1]
+	date := r.Header.Get("X-Amz-Date")
+	if !strings.HasPrefix(scope, date[:8]+"/"+testRegion+"/s3/aws4_request") {
+		return fmt.Errorf("bad scope %q", scope)
+	}
+
+	var headers strings.Builder
+	for _, name := range strings.Split(fields["SignedHeaders"], ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		headers.WriteString(name + ":" + value + "\n")
+	}
+	canonical := strings.Join([]string{
+		r.Method, r.URL.EscapedPath(), r.URL.RawQuery, headers.String(),
+		fields["SignedHeaders"], r.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+	stringToSign := "AWS4-HMAC-SHA256\n" + date + "\n" + scope + "\n" + hexSHA256([]byte(canonical))
+
+	key := []byte("AWS4" + testSecretKey)
+	for _, part := range strings.Split(scope, "/") {
+		key = hmacSHA256(key, part)
+	}
+	if want := hex.EncodeToString(hmacSHA256(key, stringToSign)); fields["Signature"] != want {
+		return fmt.Errorf("signature %s, want %s", fields["Signature"], want)
+	}
+	return nil
+}
+
+func newTestS3Storage(server *httptest.Server, secretKey string) *S3Storage {
+	store := NewS3Storage(config.S3Config{
+		Endpoint:  server.URL + "/",
+		Region:    testRegion,
+		Bucket:    "media",
+		AccessKey: testAccessKey,
+		SecretKey: secretKey,
+	})
+	store.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+	return store
+}
+
+func TestS3StorageRoundTrip(t *testing.T) {
+	stub, server := newS3Stub(t)
+	store := newTestS3Storage(server, testSecretKey)
+	ctx := context.Background()
+	content := []byte("hello, object storage")
+
+	// Keys with characters that need escaping must sign and address the same
+	// object.
+	for _, key := range []string{BlobKey("ab12cd34ef"), "uploads/a file+name/ü.bin"} {
+		if err := store.Put(ctx, key, bytes.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+			t.Fatalf("Put(%q): %v", key, err)
+		}
+		if got := stub.objects[key]; !bytes.Equal(got.data, content) || got.contentType != "text/plain" {
+			t.Errorf("stored %q = %q (%s)", key, got.data, got.contentType)
+		}
+
+		info, err := store.Stat(ctx, key)
+		if err != nil {
+			t.Fatalf("Stat(%q): %v", key, err)
+		}
+		if info.Size != int64(len(content)) || info.LastModified.IsZero() {
+			t.Errorf("Stat(%q) = %+v", key, info)
+		}
+
+		object, err := store.Open(ctx, key)
+		if err != nil {
+			t.Fatalf("Open(%q): %v", key, err)
+		}
+		data, err := io.ReadAll(object)
+		object.Close()
+		if err != nil || !bytes.Equal(data, content) {
+			t.Errorf("read %q = %q, %v", key, data, err)
+		}
+	}
+}
+
+func TestS3StorageSeekUsesRangeRequests(t *testing.T) {
+	stub, server := newS3Stub(t)
+	store := newTestS3Storage(server, testSecretKey)
+	ctx := context.Background()
+	content := []byte("0123456789")
+	if err := store.Put(ctx, "object", bytes.NewReader(content), int64(len(content)), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	object, err := store.Open(ctx, "object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer object.Close()
+
+	if pos, err := object.Seek(-4, io.SeekEnd); err != nil || pos != 6 {
+		t.Fatalf("Seek = %d, %v, want 6", pos, err)
+	}
+	data, err := io.ReadAll(object)
+	if err != nil || string(data) != "6789" {
+		t.Errorf("read after seek = %q, %v, want 6789", data, err)
+	}
+	if _, err := object.Seek(2, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 3)
+	if _, err := io.ReadFull(object, buf); err != nil || string(buf) != "234" {
+		t.Errorf("read at 2 = %q, %v, want 234", buf, err)
+	}
+	if want := []string{"bytes=6-", "bytes=2-"}; strings.Join(stub.ranges, ",") != strings.Join(want, ",") {
+		t.Errorf("ranges = %v, want %v", stub.ranges, want)
+	}
+	if _, err := object.Seek(-1, io.SeekStart); err == nil {
+		t.Error("Seek to a negative position succeeded")
+	}
+}
+
+func TestS3StorageMissingObjects(t *testing.T) {
+	_, server := newS3Stub(t)
+	store := newTestS3Storage(server, testSecretKey)
+	ctx := context.Background()
+
+	if _, err := store.Stat(ctx, "missing"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Stat = %v, want ErrObjectNotFound", err)
+	}
+	if _, err := store.Open(ctx, "missing"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Open = %v, want ErrObjectNotFound", err)
+	}
+	if err := store.Delete(ctx, "missing"); err != nil {
+		t.Errorf("Delete of a missing key = %v, want nil", err)
+	}
+}
+
+func TestS3StorageReportsErrors(t *testing.T) {
+	_, server := newS3Stub(t)
+	store := newTestS3Storage(server, "wrong-secret")
+
+	err := store.Put(context.Background(), "object", strings.NewReader("x"), 1, "")
+	if err == nil || errors.Is(err, ErrObjectNotFound) || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Put with a wrong secret = %v, want a 403 error", err)
+	}
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"http_server/media-service/internal/config"
+)
+
+// ErrObjectNotFound is returned when a key does not exist in the store
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// Storage is a flat blob store addressed by slash-separated keys.
+type Storage interface {
+	// Put stores size bytes read from r under key, replacing any existing object.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns a seekable reader over the object. Seeking is cheap so the
+	// reader can back HTTP range requests.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// Delete removes the object. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// New builds the store selected by cfg.Driver.
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStorage(cfg.Path)
+	case "s3":
+		return NewS3Storage(cfg.S3), nil
+	default:
+		return nil, fmt.Errorf("unsupported storage driver %q", cfg.Driver)
+	}
+}
+
+// BlobKey returns the content-addressed key for a SHA-256 digest in hex.
+// Two levels of fan-out keep directories small on filesystems.
+func BlobKey(hash string) string {
+	return fmt.Sprintf("blobs/%s/%s/%s", hash[0:2], hash[2:4], hash)
+}
+
+// RenditionKey returns the key of a named rendition derived from the blob
+// with the given hash.
+func RenditionKey(hash, name string) string {
+	return fmt.Sprintf("renditions/%s/%s/%s/%s", hash[0:2], hash[2:4], hash, name)
+}
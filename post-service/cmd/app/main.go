@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"http_server/post-service/internal/cache"
+	"http_server/post-service/internal/client"
+	"http_server/post-service/internal/config"
+	"http_server/post-service/internal/domain/models"
+	"http_server/post-service/internal/domain/repository"
+	"http_server/post-service/internal/handler"
+	"http_server/post-service/internal/server"
+	"http_server/post-service/internal/service"
+	"http_server/shared/database"
+	"http_server/shared/jwtkeys"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+	"http_server/shared/secrets"
+	sharedserver "http_server/shared/server"
+)
+
+func main() {
+	// Load configuration
+	configPath := os.Getenv("CONFIG_PATH")
+	if configPath == "" {
+		configPath = "config.yaml"
+	}
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Initialize logger
+	logger, err := logging.NewLogger(&logging.Config{
+		ServiceName: "post-service",
+		Environment: os.Getenv("APP_ENV"),
+		LogLevel:    cfg.Logging.Level,
+		FilePath:    cfg.Logging.FilePath,
+		MaxSize:     10, // 10MB
+		MaxBackups:  5,
+		MaxAge:      30, // 30 days
+		Compress:    true,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer logger.Sync()
+
+	// Read secrets, refusing placeholder values outside development
+	secretProvider, err := secrets.NewProvider(cfg.Secrets)
+	if err != nil {
+		logger.Fatal("Failed to initialize secrets provider", err)
+	}
+	if err := config.ResolveSecrets(context.Background(), cfg, secretProvider); err != nil {
+		logger.Fatal("Failed to read secrets", err)
+	}
+	if !secrets.IsDevelopment(os.Getenv("APP_ENV")) {
+		if err := config.CheckSecrets(cfg); err != nil {
+			logger.Fatal("Refusing to start with default secrets", err)
+		}
+	}
+
+	// Initialize database connection
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		logger.Fatal("Failed to connect to database", err)
+	}
+
+	if err := db.AutoMigrate(&models.Post{}, &models.Comment{}, &models.Reaction{}, &models.CounterShard{}); err != nil {
+		logger.Fatal("Failed to auto-migrate database", err)
+	}
+
+	// Initialize cache and clients
+	feedCache, err := cache.New(cfg.Cache)
+	if err != nil {
+		logger.Fatal("Failed to initialize cache", err)
+	}
+	// The user-service internal API needs a client certificate
+	userTLS, err := sharedserver.NewClientTLSReloader(sharedserver.ClientTLSConfig{
+		CertFile:       cfg.Services.UserServiceTLS.CertFile,
+		KeyFile:        cfg.Services.UserServiceTLS.KeyFile,
+		CAFile:         cfg.Services.UserServiceTLS.CAFile,
+		ServerName:     cfg.Services.UserServiceTLS.ServerName,
+		ReloadInterval: cfg.Services.UserServiceTLS.ReloadInterval,
+	}, logger)
+	if err != nil {
+		logger.Fatal("Failed to load user service client certificate", err)
+	}
+	followGraph := client.NewUserServiceClient(cfg.Services.UserServiceURL, cfg.Services.Timeout, sharedserver.NewClientTransport(userTLS))
+
+	// Initialize repositories and services
+	postRepo := repository.NewPostRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	reactionRepo := repository.NewReactionRepository(db)
+	counterRepo := repository.NewCounterRepository(db)
+	ranker := service.NewRanker(cfg.Feed.Boosts)
+	feedService := service.NewFeedService(postRepo, followGraph, feedCache, cfg.Feed, logger)
+	postService := service.NewPostService(postRepo, ranker, feedService, logger)
+	commentService := service.NewCommentService(commentRepo, postRepo, counterRepo, cfg.Comments, logger, service.NewAuditLogHook(logger))
+	reactionService := service.NewReactionService(reactionRepo, postRepo, counterRepo, cfg.Comments, logger)
+
+	// Initialize handlers and middleware
+	handlers := server.Handlers{
+		Post:     handler.NewPostHandler(postService, logger),
+		Feed:     handler.NewFeedHandler(feedService, logger),
+		Comment:  handler.NewCommentHandler(commentService, logger),
+		Reaction: handler.NewReactionHandler(reactionService, logger),
+	}
+	jwtKeys := jwtkeys.New([]byte(cfg.JWT.SecretKey))
+	authMiddleware := middleware.NewJWTAuth(jwtKeys, logger)
+
+	// Initialize health checks and server
+	sqlDB, err := db.DB()
+	if err != nil {
+		logger.Fatal("Failed to get database instance", err)
+	}
+	healthRegistry := server.NewHealth(cfg, sqlDB, jwtKeys)
+	srv := server.NewServer(cfg, handlers, healthRegistry, authMiddleware, logger)
+
+	// Start background workers
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go userTLS.Watch(workerCtx)
+	// Pick up rotations of the JWT key
+	if cfg.Secrets.Provider != "env" {
+		go secrets.Watch(workerCtx, secretProvider, config.SecretJWTKey, cfg.JWT.SecretKey, cfg.Secrets.RefreshInterval,
+			jwtKeys.Rotator(os.Getenv("APP_ENV"), logger), logger)
+	}
+
+	// Serve until SIGINT or SIGTERM, then drain in-flight requests
+	if err := srv.Run(context.Background()); err != nil {
+		logger.Fatal("Server stopped with error", err)
+	}
+
+	logger.Info("Server shutdown completed")
+}
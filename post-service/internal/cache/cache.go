@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"http_server/post-service/internal/config"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrCacheMiss is returned by Get when the key is absent or expired
+var ErrCacheMiss = errors.New("cache miss")
+
+// Cache is a byte-oriented key/value store with per-entry expiry. Values are
+// opaque to the cache; callers own the encoding.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// New builds the cache selected by cfg.Driver.
+func New(cfg config.CacheConfig) (Cache, error) {
+	switch cfg.Driver {
+	case "", "memory":
+		return NewMemoryCache(cfg.MaxEntries), nil
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		return NewRedisCache(client), nil
+	default:
+		return nil, fmt.Errorf("unsupported cache driver %q", cfg.Driver)
+	}
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FollowGraph answers follow-graph questions owned by user-service.
+type FollowGraph interface {
+	// Following returns the IDs of the accounts userID follows.
+	Following(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type userServiceClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewUserServiceClient calls the user-service internal API through
+// transport, which presents the client certificate it requires. A nil
+// transport uses http.DefaultTransport.
+func NewUserServiceClient(baseURL string, timeout time.Duration, transport http.RoundTripper) FollowGraph {
+	return &userServiceClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout, Transport: transport},
+	}
+}
+
+type followingResponse struct {
+	UserIDs []uuid.UUID `json:"user_ids"`
+}
+
+func (c *userServiceClient) Following(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	url := fmt.Sprintf("%s/internal/v1/users/%s/following", c.baseURL, userID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build following request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call user service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user service returned status %d", resp.StatusCode)
+	}
+
+	var body followingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode following response: %w", err)
+	}
+	return body.UserIDs, nil
+}
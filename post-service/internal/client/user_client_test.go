@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+	sharedserver "http_server/shared/server"
+	"http_server/shared/server/tlstest"
+
+	"github.com/google/uuid"
+)
+
+const postServiceURI = "spiffe://cluster.local/ns/default/sa/post-service"
+
+func TestUserServiceClientPresentsCertificate(t *testing.T) {
+	logger, err := logging.NewLogger(&logging.Config{ServiceName: "test", LogLevel: "error", FilePath: "stderr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := tlstest.NewCA(t)
+	followed := uuid.New()
+
+	// Stands in for the user-service internal listener
+	machineAuth := middleware.NewMachineAuth(map[string]string{postServiceURI: "post-service"}, logger)
+	certFile, keyFile := ca.ServerFiles(t, "user-service")
+	serverTLS, err := sharedserver.NewTLSReloader(sharedserver.TLSConfig{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCAFile: ca.File,
+		ClientAuth:   sharedserver.ClientAuthRequire,
+	}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(machineAuth.RequireClientCert(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(followingResponse{UserIDs: []uuid.UUID{followed}})
+	})))
+	srv.TLS = serverTLS.Config()
+	srv.StartTLS()
+	defer srv.Close()
+
+	certFile, keyFile = ca.ClientFiles(t, "post-service", postServiceURI)
+	clientTLS, err := sharedserver.NewClientTLSReloader(sharedserver.ClientTLSConfig{
+		CertFile: certFile,
+		KeyFile:  keyFile,
+		CAFile:   ca.File,
+	}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph := NewUserServiceClient(srv.URL, 5*time.Second, sharedserver.NewClientTransport(clientTLS))
+
+	ids, err := graph.Following(context.Background(), uuid.New())
+	if err != nil {
+		t.Fatalf("Following: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != followed {
+		t.Errorf("Following = %v, want [%s]", ids, followed)
+	}
+}
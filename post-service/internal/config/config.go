@@ -0,0 +1,197 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	sharedconfig "http_server/shared/config"
+	"http_server/shared/database"
+)
+
+type Config struct {
+	Server   ServerConfig   `mapstructure:"server"`
+	Health   HealthConfig   `mapstructure:"health"`
+	Database DatabaseConfig `mapstructure:"database"`
+	Services ServicesConfig `mapstructure:"services"`
+	JWT      JWTConfig      `mapstructure:"jwt"`
+	Secrets  SecretsConfig  `mapstructure:"secrets"`
+	Cache    CacheConfig    `mapstructure:"cache"`
+	Feed     FeedConfig     `mapstructure:"feed"`
+	Comments CommentsConfig `mapstructure:"comments"`
+	Logging  LoggingConfig  `mapstructure:"logging"`
+}
+
+type ServerConfig struct {
+	Port            int           `mapstructure:"port"`
+	Timeout         time.Duration `mapstructure:"timeout"`
+	ReadTimeout     time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout    time.Duration `mapstructure:"write_timeout"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// DrainDelay is how long readiness fails before the listener closes on
+	// shutdown, giving load balancers time to stop routing here.
+	DrainDelay time.Duration `mapstructure:"drain_delay"`
+}
+
+// HealthConfig controls the dependency checks behind the readiness probe.
+// Results are cached for CacheTTL; each check is bounded by Timeout.
+type HealthConfig struct {
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+// DatabaseConfig holds the connection and pool settings.
+type DatabaseConfig = database.Config
+
+type ServicesConfig struct {
+	AuthServiceURL string `mapstructure:"auth_service_url"`
+	// UserServiceURL points at the user-service internal listener, which
+	// only accepts mutual TLS with the certificate in UserServiceTLS.
+	UserServiceURL string          `mapstructure:"user_service_url"`
+	UserServiceTLS ClientTLSConfig `mapstructure:"user_service_tls"`
+	Timeout        time.Duration   `mapstructure:"timeout"`
+}
+
+// ClientTLSConfig is the client certificate presented to another service
+// over mutual TLS and the CAs its certificate must chain to; an empty
+// CAFile uses the system roots. ServerName overrides the host in the URL
+// when checking the server certificate. The files are reloaded when they
+// change, checked every ReloadInterval.
+type ClientTLSConfig struct {
+	CertFile       string        `mapstructure:"cert_file"`
+	KeyFile        string        `mapstructure:"key_file"`
+	CAFile         string        `mapstructure:"ca_file"`
+	ServerName     string        `mapstructure:"server_name"`
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+}
+
+type JWTConfig struct {
+	SecretKey string `mapstructure:"secret_key"`
+}
+
+type CacheConfig struct {
+	Driver     string      `mapstructure:"driver"`
+	MaxEntries int         `mapstructure:"max_entries"`
+	Redis      RedisConfig `mapstructure:"redis"`
+}
+
+type RedisConfig struct {
+	Addr     string `mapstructure:"addr"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+}
+
+type FeedConfig struct {
+	DefaultPageSize int                      `mapstructure:"default_page_size"`
+	MaxPageSize     int                      `mapstructure:"max_page_size"`
+	HotWindowSize   int                      `mapstructure:"hot_window_size"`
+	HotWindowTTL    time.Duration            `mapstructure:"hot_window_ttl"`
+	FollowingTTL    time.Duration            `mapstructure:"following_ttl"`
+	Boosts          map[string]time.Duration `mapstructure:"boosts"`
+}
+
+type CommentsConfig struct {
+	MaxDepth        int `mapstructure:"max_depth"`
+	MaxLength       int `mapstructure:"max_length"`
+	DefaultPageSize int `mapstructure:"default_page_size"`
+	MaxPageSize     int `mapstructure:"max_page_size"`
+}
+
+type LoggingConfig struct {
+	Level    string `mapstructure:"level"`
+	FilePath string `mapstructure:"file_path"`
+}
+
+func LoadConfig(path string) (*Config, error) {
+	var config Config
+	if err := sharedconfig.Load(path, &config); err != nil {
+		return nil, err
+	}
+
+	applyDefaults(&config)
+
+	if err := validateConfig(&config); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return &config, nil
+}
+
+func applyDefaults(config *Config) {
+	config.Secrets.ApplyDefaults()
+	if config.Server.ShutdownTimeout == 0 {
+		config.Server.ShutdownTimeout = 30 * time.Second
+	}
+	if config.Health.CacheTTL == 0 {
+		config.Health.CacheTTL = 2 * time.Second
+	}
+	if config.Health.Timeout == 0 {
+		config.Health.Timeout = 2 * time.Second
+	}
+	if config.Services.Timeout == 0 {
+		config.Services.Timeout = 5 * time.Second
+	}
+	if config.Cache.Driver == "" {
+		config.Cache.Driver = "memory"
+	}
+	if config.Cache.MaxEntries == 0 {
+		config.Cache.MaxEntries = 10000
+	}
+	if config.Feed.DefaultPageSize == 0 {
+		config.Feed.DefaultPageSize = 20
+	}
+	if config.Feed.MaxPageSize == 0 {
+		config.Feed.MaxPageSize = 100
+	}
+	if config.Feed.HotWindowSize == 0 {
+		config.Feed.HotWindowSize = 200
+	}
+	if config.Feed.HotWindowTTL == 0 {
+		config.Feed.HotWindowTTL = 30 * time.Second
+	}
+	if config.Feed.FollowingTTL == 0 {
+		config.Feed.FollowingTTL = time.Minute
+	}
+	if config.Comments.MaxDepth == 0 {
+		config.Comments.MaxDepth = 5
+	}
+	if config.Comments.MaxLength == 0 {
+		config.Comments.MaxLength = 2000
+	}
+	if config.Comments.DefaultPageSize == 0 {
+		config.Comments.DefaultPageSize = 20
+	}
+	if config.Comments.MaxPageSize == 0 {
+		config.Comments.MaxPageSize = 100
+	}
+}
+
+func validateConfig(config *Config) error {
+	if config.Server.Port == 0 {
+		return fmt.Errorf("server port is required")
+	}
+	if config.JWT.SecretKey == "" {
+		return fmt.Errorf("JWT secret key is required")
+	}
+	if err := config.Secrets.Validate(); err != nil {
+		return err
+	}
+	if config.Database.Host == "" || config.Database.DBName == "" {
+		return fmt.Errorf("database host and name are required")
+	}
+	if config.Services.UserServiceURL == "" {
+		return fmt.Errorf("user service URL is required")
+	}
+	if config.Services.UserServiceTLS.CertFile == "" || config.Services.UserServiceTLS.KeyFile == "" {
+		return fmt.Errorf("user service tls cert_file and key_file are required")
+	}
+	if config.Cache.Driver != "memory" && config.Cache.Driver != "redis" {
+		return fmt.Errorf("unsupported cache driver %q", config.Cache.Driver)
+	}
+	if config.Feed.DefaultPageSize > config.Feed.MaxPageSize {
+		return fmt.Errorf("feed default page size exceeds max page size")
+	}
+	if config.Comments.DefaultPageSize > config.Comments.MaxPageSize {
+		return fmt.Errorf("comments default page size exceeds max page size")
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Comment is a comment on a post or a reply to another comment. Replies form
+// a tree rooted at a top-level comment; Depth is 0 for top-level comments.
+type Comment struct {
+	ID         uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid"`
+	PostID     uuid.UUID  `json:"post_id" gorm:"type:uuid;not null;index:idx_comments_thread,priority:1"`
+	ParentID   *uuid.UUID `json:"parent_id,omitempty" gorm:"type:uuid;index:idx_comments_thread,priority:2"`
+	RootID     *uuid.UUID `json:"root_id,omitempty" gorm:"type:uuid"`
+	AuthorID   uuid.UUID  `json:"author_id" gorm:"type:uuid;not null;index"`
+	Content    string     `json:"content" gorm:"type:text;not null"`
+	Depth      int        `json:"depth" gorm:"not null;default:0"`
+	ReplyCount int64      `json:"reply_count" gorm:"-"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"index:idx_comments_thread,priority:3"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy  *uuid.UUID `json:"-" gorm:"type:uuid"`
+}
+
+// Position returns the keyset position of the comment within its thread.
+func (c *Comment) Position() TimePosition {
+	return TimePosition{At: c.CreatedAt, ID: c.ID}
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// Counter names
+const (
+	CounterComments = "comments"
+	CounterReplies  = "replies"
+)
+
+// ReactionCounter returns the counter name tracking reactions of kind.
+func ReactionCounter(kind string) string {
+	return "reactions:" + kind
+}
+
+// CounterShard is one slice of an aggregated counter. Each increment lands on
+// a random shard so concurrent writers to a popular post or comment update
+// different rows; the counter value is the sum over all shards.
+type CounterShard struct {
+	SubjectID uuid.UUID `gorm:"primaryKey;type:uuid"`
+	Name      string    `gorm:"primaryKey;size:64"`
+	Shard     int       `gorm:"primaryKey"`
+	Value     int64     `gorm:"not null;default:0"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FeedPosition identifies a post's place in a ranked feed. Feeds are ordered
+// by (RankScore DESC, ID DESC), so a position is a total order key that stays
+// valid while new posts are inserted, whether they rank above or below it.
+type FeedPosition struct {
+	Score int64     `json:"s"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// After reports whether p sorts after other in feed order, i.e. whether p
+// would be returned on a later page than other.
+func (p FeedPosition) After(other FeedPosition) bool {
+	if p.Score != other.Score {
+		return p.Score < other.Score
+	}
+	return p.ID.String() < other.ID.String()
+}
+
+// Position returns the feed position of the post.
+func (p *Post) Position() FeedPosition {
+	return FeedPosition{Score: p.RankScore, ID: p.ID}
+}
+
+// TimePosition identifies an item in a list ordered by (CreatedAt ASC, ID ASC),
+// as used for comments and reactions.
+type TimePosition struct {
+	At time.Time `json:"t"`
+	ID uuid.UUID `json:"id"`
+}
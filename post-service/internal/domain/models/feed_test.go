@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestFeedPositionAfter(t *testing.T) {
+	low := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	high := uuid.MustParse("ffffffff-0000-0000-0000-000000000000")
+
+	tests := []struct {
+		name     string
+		p, other FeedPosition
+		want     bool
+	}{
+		{"lower score", FeedPosition{Score: 1, ID: high}, FeedPosition{Score: 2, ID: low}, true},
+		{"higher score", FeedPosition{Score: 2, ID: low}, FeedPosition{Score: 1, ID: high}, false},
+		{"same score, lower ID", FeedPosition{Score: 1, ID: low}, FeedPosition{Score: 1, ID: high}, true},
+		{"same score, higher ID", FeedPosition{Score: 1, ID: high}, FeedPosition{Score: 1, ID: low}, false},
+		{"same position", FeedPosition{Score: 1, ID: low}, FeedPosition{Score: 1, ID: low}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.After(tt.other); got != tt.want {
+				t.Errorf("After = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Post struct {
+	ID         uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid"`
+	AuthorID   uuid.UUID  `json:"author_id" gorm:"type:uuid;not null;index:idx_posts_author_rank,priority:1"`
+	AuthorRole string     `json:"author_role,omitempty" gorm:"size:64"`
+	Content    string     `json:"content" gorm:"type:text;not null"`
+	RankScore  int64      `json:"-" gorm:"not null;index:idx_posts_rank;index:idx_posts_author_rank,priority:2"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+}
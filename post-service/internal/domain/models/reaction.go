@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Supported reaction kinds
+const (
+	ReactionLike  = "like"
+	ReactionLove  = "love"
+	ReactionLaugh = "laugh"
+	ReactionWow   = "wow"
+	ReactionSad   = "sad"
+	ReactionAngry = "angry"
+)
+
+// ReactionKinds lists every reaction a user may leave on a post
+var ReactionKinds = []string{
+	ReactionLike,
+	ReactionLove,
+	ReactionLaugh,
+	ReactionWow,
+	ReactionSad,
+	ReactionAngry,
+}
+
+// Reaction is a user's reaction to a post. A user has at most one reaction
+// per post; reacting again replaces the kind.
+type Reaction struct {
+	PostID    uuid.UUID `json:"post_id" gorm:"primaryKey;type:uuid"`
+	UserID    uuid.UUID `json:"user_id" gorm:"primaryKey;type:uuid"`
+	Kind      string    `json:"kind" gorm:"size:16;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Position returns the keyset position of the reaction within its post.
+func (r *Reaction) Position() TimePosition {
+	return TimePosition{At: r.CreatedAt, ID: r.UserID}
+}
+
+// IsValidReactionKind reports whether kind is one of ReactionKinds.
+func IsValidReactionKind(kind string) bool {
+	for _, k := range ReactionKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"http_server/post-service/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type CommentRepository interface {
+	// Create stores the comment and bumps the post's comment counter and, for
+	// replies, the parent's reply counter in the same transaction.
+	Create(ctx context.Context, comment *models.Comment) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Comment, error)
+	// SoftDelete blanks the comment's content and marks it deleted so the
+	// thread structure below it survives, and decrements the counters.
+	SoftDelete(ctx context.Context, comment *models.Comment, deletedBy uuid.UUID) error
+	// ListThread returns the direct children of parentID on the post (top-level
+	// comments when parentID is nil) oldest first, after the given position.
+	ListThread(ctx context.Context, postID uuid.UUID, parentID *uuid.UUID, after *models.TimePosition, limit int) ([]models.Comment, error)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"http_server/post-service/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrDuplicateKey
+			}
+			return err
+		}
+
+		if err := incrementCounter(tx, comment.PostID, models.CounterComments, 1); err != nil {
+			return err
+		}
+		if comment.ParentID != nil {
+			return incrementCounter(tx, *comment.ParentID, models.CounterReplies, 1)
+		}
+		return nil
+	})
+}
+
+func (r *commentRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Comment, error) {
+	var comment models.Comment
+	result := r.db.WithContext(ctx).First(&comment, "id = ?", id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, result.Error
+	}
+	return &comment, nil
+}
+
+func (r *commentRepository) SoftDelete(ctx context.Context, comment *models.Comment, deletedBy uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Comment{}).
+			Where("id = ? AND deleted_at IS NULL", comment.ID).
+			Updates(map[string]interface{}{
+				"content":    "",
+				"deleted_at": time.Now().UTC(),
+				"deleted_by": deletedBy,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		if err := incrementCounter(tx, comment.PostID, models.CounterComments, -1); err != nil {
+			return err
+		}
+		if comment.ParentID != nil {
+			return incrementCounter(tx, *comment.ParentID, models.CounterReplies, -1)
+		}
+		return nil
+	})
+}
+
+func (r *commentRepository) ListThread(ctx context.Context, postID uuid.UUID, parentID *uuid.UUID, after *models.TimePosition, limit int) ([]models.Comment, error) {
+	query := r.db.WithContext(ctx).Where("post_id = ?", postID)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	if after != nil {
+		query = query.Where("(created_at, id) > (?, ?)", after.At, after.ID)
+	}
+
+	var comments []models.Comment
+	result := query.
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&comments)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return comments, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"math/rand/v2"
+
+	"http_server/post-service/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// counterShards is the number of rows each counter is spread over
+const counterShards = 16
+
+type CounterRepository interface {
+	// Totals returns the value of every counter of the given subjects, keyed
+	// by subject and counter name. Counters that were never touched are absent.
+	Totals(ctx context.Context, subjectIDs []uuid.UUID) (map[uuid.UUID]map[string]int64, error)
+}
+
+type counterRepository struct {
+	db *gorm.DB
+}
+
+func NewCounterRepository(db *gorm.DB) CounterRepository {
+	return &counterRepository{db: db}
+}
+
+func (r *counterRepository) Totals(ctx context.Context, subjectIDs []uuid.UUID) (map[uuid.UUID]map[string]int64, error) {
+	totals := make(map[uuid.UUID]map[string]int64, len(subjectIDs))
+	if len(subjectIDs) == 0 {
+		return totals, nil
+	}
+
+	var rows []struct {
+		SubjectID uuid.UUID
+		Name      string
+		Total     int64
+	}
+	result := r.db.WithContext(ctx).
+		Model(&models.CounterShard{}).
+		Select("subject_id, name, SUM(value) AS total").
+		Where("subject_id IN ?", subjectIDs).
+		Group("subject_id, name").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for _, row := range rows {
+		if totals[row.SubjectID] == nil {
+			totals[row.SubjectID] = make(map[string]int64)
+		}
+		totals[row.SubjectID][row.Name] = row.Total
+	}
+	return totals, nil
+}
+
+// incrementCounter adds delta to a random shard of the named counter. It is
+// meant to run inside the transaction that performs the counted change.
+func incrementCounter(tx *gorm.DB, subjectID uuid.UUID, name string, delta int64) error {
+	shard := &models.CounterShard{
+		SubjectID: subjectID,
+		Name:      name,
+		Shard:     rand.IntN(counterShards),
+		Value:     delta,
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "subject_id"}, {Name: "name"}, {Name: "shard"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"value": gorm.Expr("counter_shards.value + ?", delta),
+		}),
+	}).Create(shard).Error
+}
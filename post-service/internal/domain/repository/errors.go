@@ -0,0 +1,14 @@
+package repository
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when a requested resource is not found
+	ErrNotFound = errors.New("resource not found")
+
+	// ErrDuplicateKey is returned when attempting to create a resource with a duplicate unique key
+	ErrDuplicateKey = errors.New("duplicate key")
+
+	// ErrConflict is returned when concurrent writes kept a change from applying
+	ErrConflict = errors.New("concurrent update conflict")
+)
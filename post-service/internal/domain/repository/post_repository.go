@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"http_server/post-service/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type PostRepository interface {
+	Create(ctx context.Context, post *models.Post) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Post, error)
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Post, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// ListByAuthors returns posts written by any of the given authors in feed
+	// order, starting strictly after the given position (nil means the top).
+	ListByAuthors(ctx context.Context, authorIDs []uuid.UUID, after *models.FeedPosition, limit int) ([]models.Post, error)
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"http_server/post-service/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type postRepository struct {
+	db *gorm.DB
+}
+
+func NewPostRepository(db *gorm.DB) PostRepository {
+	return &postRepository{db: db}
+}
+
+func (r *postRepository) Create(ctx context.Context, post *models.Post) error {
+	result := r.db.WithContext(ctx).Create(post)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateKey
+		}
+		return result.Error
+	}
+	return nil
+}
+
+func (r *postRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Post, error) {
+	var post models.Post
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND deleted_at IS NULL", id).
+		First(&post)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, result.Error
+	}
+	return &post, nil
+}
+
+func (r *postRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Post, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var posts []models.Post
+	result := r.db.WithContext(ctx).
+		Where("id IN ? AND deleted_at IS NULL", ids).
+		Find(&posts)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return posts, nil
+}
+
+func (r *postRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.Post{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Update("deleted_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postRepository) ListByAuthors(ctx context.Context, authorIDs []uuid.UUID, after *models.FeedPosition, limit int) ([]models.Post, error) {
+	if len(authorIDs) == 0 {
+		return nil, nil
+	}
+
+	query := r.db.WithContext(ctx).
+		Where("author_id IN ? AND deleted_at IS NULL", authorIDs)
+	if after != nil {
+		query = query.Where("(rank_score, id) < (?, ?)", after.Score, after.ID)
+	}
+
+	var posts []models.Post
+	result := query.
+		Order("rank_score DESC, id DESC").
+		Limit(limit).
+		Find(&posts)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return posts, nil
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"http_server/post-service/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type ReactionRepository interface {
+	// Upsert sets the user's reaction on the post, replacing any previous
+	// kind, and keeps the per-kind counters in step.
+	Upsert(ctx context.Context, reaction *models.Reaction) error
+	// Delete removes the user's reaction on the post. It returns ErrNotFound
+	// if the user had not reacted.
+	Delete(ctx context.Context, postID, userID uuid.UUID) error
+	List(ctx context.Context, postID uuid.UUID, after *models.TimePosition, limit int) ([]models.Reaction, error)
+}
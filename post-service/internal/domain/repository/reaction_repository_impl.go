@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+
+	"http_server/post-service/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type reactionRepository struct {
+	db *gorm.DB
+}
+
+func NewReactionRepository(db *gorm.DB) ReactionRepository {
+	return &reactionRepository{db: db}
+}
+
+// upsertAttempts bounds how often Upsert retries after losing an insert
+// race. A lost race is only repeated if the winning row is deleted again
+// before it can be read, so more than one retry is rare.
+const upsertAttempts = 3
+
+func (r *reactionRepository) Upsert(ctx context.Context, reaction *models.Reaction) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for attempt := 0; attempt < upsertAttempts; attempt++ {
+			applied, err := upsertReaction(tx, reaction)
+			if err != nil || applied {
+				return err
+			}
+		}
+		return ErrConflict
+	})
+}
+
+// upsertReaction stores reaction or changes the kind of the user's existing
+// reaction, keeping the counters in step. It reports false, without changes,
+// when a concurrent request from the same user inserted the row first; the
+// caller then retries, finding and updating that row.
+func upsertReaction(tx *gorm.DB, reaction *models.Reaction) (bool, error) {
+	// The (post_id, user_id) row is only ever contended by the same user,
+	// so locking it is cheap and serialises double-taps.
+	var existing models.Reaction
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("post_id = ? AND user_id = ?", reaction.PostID, reaction.UserID).
+		Limit(1).
+		Find(&existing)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction)
+		if created.Error != nil {
+			return false, created.Error
+		}
+		if created.RowsAffected == 0 {
+			// The conflicting insert has committed by now, so reading
+			// again finds its row, possibly with another kind.
+			return false, nil
+		}
+		return true, incrementCounter(tx, reaction.PostID, models.ReactionCounter(reaction.Kind), 1)
+	}
+
+	if existing.Kind == reaction.Kind {
+		*reaction = existing
+		return true, nil
+	}
+
+	previous := existing.Kind
+	if err := tx.Model(&existing).Update("kind", reaction.Kind).Error; err != nil {
+		return false, err
+	}
+	if err := incrementCounter(tx, reaction.PostID, models.ReactionCounter(previous), -1); err != nil {
+		return false, err
+	}
+	if err := incrementCounter(tx, reaction.PostID, models.ReactionCounter(reaction.Kind), 1); err != nil {
+		return false, err
+	}
+	*reaction = existing
+	return true, nil
+}
+
+func (r *reactionRepository) Delete(ctx context.Context, postID, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var removed []models.Reaction
+		result := tx.Clauses(clause.Returning{}).
+			Where("post_id = ? AND user_id = ?", postID, userID).
+			Delete(&removed)
+		if result.Error != nil {
+			return result.Error
+		}
+		if len(removed) == 0 {
+			return ErrNotFound
+		}
+		return incrementCounter(tx, postID, models.ReactionCounter(removed[0].Kind), -1)
+	})
+}
+
+func (r *reactionRepository) List(ctx context.Context, postID uuid.UUID, after *models.TimePosition, limit int) ([]models.Reaction, error) {
+	query := r.db.WithContext(ctx).Where("post_id = ?", postID)
+	if after != nil {
+		query = query.Where("(created_at, user_id) > (?, ?)", after.At, after.ID)
+	}
+
+	var reactions []models.Reaction
+	result := query.
+		Order("created_at ASC, user_id ASC").
+		Limit(limit).
+		Find(&reactions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return reactions, nil
+}
@@ -0,0 +1,130 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"http_server/post-service/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDB connects to the PostgreSQL database named by
+// POST_SERVICE_TEST_DATABASE_URL, skipping the test when it is unset.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("POST_SERVICE_TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("POST_SERVICE_TEST_DATABASE_URL not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.Reaction{}, &models.CounterShard{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func reactionTotals(t *testing.T, db *gorm.DB, postID uuid.UUID) map[string]int64 {
+	t.Helper()
+	totals, err := NewCounterRepository(db).Totals(context.Background(), []uuid.UUID{postID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]int64)
+	for name, total := range totals[postID] {
+		if total != 0 {
+			counts[name] = total
+		}
+	}
+	return counts
+}
+
+func TestReactionUpsertSwitchesKind(t *testing.T) {
+	db := testDB(t)
+	repo := NewReactionRepository(db)
+	ctx := context.Background()
+	postID, userID := uuid.New(), uuid.New()
+
+	steps := []struct {
+		kind string
+		want map[string]int64
+	}{
+		{models.ReactionLike, map[string]int64{"reactions:like": 1}},
+		{models.ReactionLike, map[string]int64{"reactions:like": 1}},
+		{models.ReactionLove, map[string]int64{"reactions:love": 1}},
+		{models.ReactionSad, map[string]int64{"reactions:sad": 1}},
+		{models.ReactionLike, map[string]int64{"reactions:like": 1}},
+	}
+	for i, step := range steps {
+		now := time.Now().UTC()
+		reaction := &models.Reaction{PostID: postID, UserID: userID, Kind: step.kind, CreatedAt: now, UpdatedAt: now}
+		if err := repo.Upsert(ctx, reaction); err != nil {
+			t.Fatalf("step %d: Upsert(%s): %v", i, step.kind, err)
+		}
+		if reaction.Kind != step.kind {
+			t.Errorf("step %d: stored kind = %s, want %s", i, reaction.Kind, step.kind)
+		}
+		if got := reactionTotals(t, db, postID); !equalCounts(got, step.want) {
+			t.Errorf("step %d: counters = %v, want %v", i, got, step.want)
+		}
+	}
+}
+
+func TestReactionUpsertConcurrentKinds(t *testing.T) {
+	db := testDB(t)
+	repo := NewReactionRepository(db)
+	ctx := context.Background()
+	postID, userID := uuid.New(), uuid.New()
+
+	// Both requests race to insert; whichever loses must still apply its
+	// kind to the winner's row, leaving one reaction counted once.
+	kinds := []string{models.ReactionLike, models.ReactionLove}
+	var wg sync.WaitGroup
+	errs := make([]error, len(kinds))
+	for i, kind := range kinds {
+		wg.Add(1)
+		go func(i int, kind string) {
+			defer wg.Done()
+			now := time.Now().UTC()
+			errs[i] = repo.Upsert(ctx, &models.Reaction{PostID: postID, UserID: userID, Kind: kind, CreatedAt: now, UpdatedAt: now})
+		}(i, kind)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Upsert(%s): %v", kinds[i], err)
+		}
+	}
+
+	var stored models.Reaction
+	if err := db.Where("post_id = ? AND user_id = ?", postID, userID).First(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int64{models.ReactionCounter(stored.Kind): 1}
+	if got := reactionTotals(t, db, postID); !equalCounts(got, want) {
+		t.Errorf("counters = %v, want %v", got, want)
+	}
+}
+
+func equalCounts(a, b map[string]int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if b[name] != value {
+			return false
+		}
+	}
+	return true
+}
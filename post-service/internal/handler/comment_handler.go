@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"http_server/post-service/internal/service"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type CommentHandler struct {
+	commentService service.CommentService
+	logger         *logging.Logger
+}
+
+func NewCommentHandler(commentService service.CommentService, logger *logging.Logger) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+		logger:         logger,
+	}
+}
+
+type CreateCommentRequest struct {
+	Content  string     `json:"content"`
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+}
+
+// Create serves POST /posts/{id}/comments
+func (h *CommentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	postID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidPostID)
+		return
+	}
+
+	var req CreateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithContext(r.Context()).Warn("Failed to decode request payload", zap.Error(err))
+		respondWithProblem(w, r, errInvalidPayload)
+		return
+	}
+
+	comment, err := h.commentService.AddComment(r.Context(), postID, userID, req.ParentID, req.Content)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, comment)
+}
+
+// List serves GET /posts/{id}/comments?cursor=&limit=
+func (h *CommentHandler) List(w http.ResponseWriter, r *http.Request) {
+	postID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidPostID)
+		return
+	}
+
+	cursor, limit := pageParams(r)
+	page, err := h.commentService.ListComments(r.Context(), postID, cursor, limit)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// Replies serves GET /comments/{id}/replies?cursor=&limit=
+func (h *CommentHandler) Replies(w http.ResponseWriter, r *http.Request) {
+	commentID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidCommentID)
+		return
+	}
+
+	cursor, limit := pageParams(r)
+	page, err := h.commentService.ListReplies(r.Context(), commentID, cursor, limit)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// Delete serves DELETE /comments/{id}?reason=
+func (h *CommentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	commentID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidCommentID)
+		return
+	}
+
+	reason := r.URL.Query().Get("reason")
+	err = h.commentService.DeleteComment(r.Context(), commentID, userID, middleware.RolesFromContext(r.Context()), reason)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
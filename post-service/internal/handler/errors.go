@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"http_server/post-service/internal/service"
+	apperrors "http_server/shared/errors"
+	"http_server/shared/problem"
+)
+
+// Stable error codes reported in problem responses. Clients may rely on
+// these, so existing values must not change.
+const (
+	CodeInvalidPayload   = "invalid_payload"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeInvalidPostID    = "invalid_post_id"
+	CodeInvalidCommentID = "invalid_comment_id"
+	CodeInvalidUserID    = "invalid_user_id"
+	CodeInvalidCursor    = "invalid_cursor"
+	CodePostNotFound     = "post_not_found"
+	CodeCommentNotFound  = "comment_not_found"
+	CodeReactionNotFound = "reaction_not_found"
+	CodeInvalidContent   = "invalid_content"
+	CodeInvalidComment   = "invalid_comment"
+	CodeInvalidReaction  = "invalid_reaction"
+	CodeMaxDepthExceeded = "max_depth_exceeded"
+)
+
+var (
+	errInvalidPayload   = apperrors.NewBadRequestError("Invalid request payload", nil).WithCode(CodeInvalidPayload)
+	errUnauthorized     = apperrors.NewAuthenticationError("Authentication required", nil).WithCode(CodeUnauthorized)
+	errInvalidPostID    = apperrors.NewBadRequestError("Invalid post ID", nil).WithCode(CodeInvalidPostID)
+	errInvalidCommentID = apperrors.NewBadRequestError("Invalid comment ID", nil).WithCode(CodeInvalidCommentID)
+	errInvalidUserID    = apperrors.NewBadRequestError("Invalid user ID", nil).WithCode(CodeInvalidUserID)
+)
+
+// serviceErrors maps service sentinel errors onto client-facing errors.
+// Anything not listed is reported as an internal error.
+var serviceErrors = []struct {
+	err    error
+	appErr *apperrors.AppError
+}{
+	{service.ErrPostNotFound, apperrors.NewNotFoundError("Post not found", nil).WithCode(CodePostNotFound)},
+	{service.ErrCommentNotFound, apperrors.NewNotFoundError("Comment not found", nil).WithCode(CodeCommentNotFound)},
+	{service.ErrReactionNotFound, apperrors.NewNotFoundError("Reaction not found", nil).WithCode(CodeReactionNotFound)},
+	{service.ErrForbidden, apperrors.NewAuthorizationError("Operation not permitted", nil).WithCode(CodeForbidden)},
+	{service.ErrInvalidContent, apperrors.NewValidationError("Post content must be between 1 and 5000 characters", nil).WithCode(CodeInvalidContent)},
+	{service.ErrInvalidComment, apperrors.NewValidationError("Invalid comment content", nil).WithCode(CodeInvalidComment)},
+	{service.ErrInvalidReaction, apperrors.NewValidationError("Invalid reaction kind", nil).WithCode(CodeInvalidReaction)},
+	{service.ErrMaxDepthExceeded, apperrors.New(apperrors.ErrorTypeUnprocessable, "Maximum reply depth exceeded", nil).WithCode(CodeMaxDepthExceeded)},
+	{service.ErrInvalidCursor, apperrors.NewBadRequestError("Invalid cursor", nil).WithCode(CodeInvalidCursor)},
+}
+
+// toAppError translates a service error for the client, keeping the original
+// as the cause for logging.
+func toAppError(err error) *apperrors.AppError {
+	if appErr, ok := apperrors.As(err); ok {
+		return appErr
+	}
+	for _, mapping := range serviceErrors {
+		if errors.Is(err, mapping.err) {
+			appErr := *mapping.appErr
+			appErr.Err = err
+			return &appErr
+		}
+	}
+	return apperrors.NewInternalError("Internal server error", err)
+}
+
+func respondWithProblem(w http.ResponseWriter, r *http.Request, err error) {
+	problem.Write(w, r, toAppError(err))
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+
+	"http_server/post-service/internal/service"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+)
+
+type FeedHandler struct {
+	feedService service.FeedService
+	logger      *logging.Logger
+}
+
+func NewFeedHandler(feedService service.FeedService, logger *logging.Logger) *FeedHandler {
+	return &FeedHandler{
+		feedService: feedService,
+		logger:      logger,
+	}
+}
+
+// Home serves GET /feed?cursor=&limit=
+func (h *FeedHandler) Home(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	cursor, limit := pageParams(r)
+	page, err := h.feedService.HomeFeed(r.Context(), userID, cursor, limit)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// Author serves GET /users/{id}/posts?cursor=&limit=
+func (h *FeedHandler) Author(w http.ResponseWriter, r *http.Request) {
+	authorID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidUserID)
+		return
+	}
+
+	cursor, limit := pageParams(r)
+	page, err := h.feedService.AuthorFeed(r.Context(), authorID, cursor, limit)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
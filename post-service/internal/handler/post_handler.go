@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"http_server/post-service/internal/service"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+
+	"go.uber.org/zap"
+)
+
+type PostHandler struct {
+	postService service.PostService
+	logger      *logging.Logger
+}
+
+func NewPostHandler(postService service.PostService, logger *logging.Logger) *PostHandler {
+	return &PostHandler{
+		postService: postService,
+		logger:      logger,
+	}
+}
+
+type CreatePostRequest struct {
+	Content string `json:"content"`
+}
+
+func (h *PostHandler) Create(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	var req CreatePostRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("Failed to decode request payload", zap.Error(err))
+		respondWithProblem(w, r, errInvalidPayload)
+		return
+	}
+
+	post, err := h.postService.CreatePost(r.Context(), userID, middleware.RolesFromContext(r.Context()), req.Content)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, post)
+}
+
+func (h *PostHandler) Get(w http.ResponseWriter, r *http.Request) {
+	postID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidPostID)
+		return
+	}
+
+	post, err := h.postService.GetPost(r.Context(), postID)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, post)
+}
+
+func (h *PostHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	postID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidPostID)
+		return
+	}
+
+	err = h.postService.DeletePost(r.Context(), postID, userID, middleware.RolesFromContext(r.Context()))
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"http_server/post-service/internal/service"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+
+	"go.uber.org/zap"
+)
+
+type ReactionHandler struct {
+	reactionService service.ReactionService
+	logger          *logging.Logger
+}
+
+func NewReactionHandler(reactionService service.ReactionService, logger *logging.Logger) *ReactionHandler {
+	return &ReactionHandler{
+		reactionService: reactionService,
+		logger:          logger,
+	}
+}
+
+type ReactRequest struct {
+	Kind string `json:"kind"`
+}
+
+// React serves PUT /posts/{id}/reactions
+func (h *ReactionHandler) React(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	postID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidPostID)
+		return
+	}
+
+	var req ReactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithContext(r.Context()).Warn("Failed to decode request payload", zap.Error(err))
+		respondWithProblem(w, r, errInvalidPayload)
+		return
+	}
+
+	reaction, err := h.reactionService.React(r.Context(), postID, userID, req.Kind)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, reaction)
+}
+
+// Unreact serves DELETE /posts/{id}/reactions
+func (h *ReactionHandler) Unreact(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	postID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidPostID)
+		return
+	}
+
+	if err := h.reactionService.Unreact(r.Context(), postID, userID); err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// List serves GET /posts/{id}/reactions?cursor=&limit=
+func (h *ReactionHandler) List(w http.ResponseWriter, r *http.Request) {
+	postID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidPostID)
+		return
+	}
+
+	cursor, limit := pageParams(r)
+	page, err := h.reactionService.ListReactions(r.Context(), postID, cursor, limit)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// Stats serves GET /posts/{id}/stats
+func (h *ReactionHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	postID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidPostID)
+		return
+	}
+
+	stats, err := h.reactionService.Stats(r.Context(), postID)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, stats)
+}
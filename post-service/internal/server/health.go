@@ -0,0 +1,30 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+
+	"http_server/post-service/internal/config"
+	"http_server/shared/health"
+	"http_server/shared/jwtkeys"
+)
+
+// NewHealth registers the checks behind the readiness probe. The database and
+// a usable JWT key are critical; Redis only degrades the service, since feeds
+// are rebuilt from the database on a cache error.
+func NewHealth(cfg *config.Config, sqlDB *sql.DB, jwtKeys *jwtkeys.Keys) *health.Registry {
+	registry := health.New(health.Config{
+		CacheTTL: cfg.Health.CacheTTL,
+		Timeout:  cfg.Health.Timeout,
+	})
+
+	registry.Register("database", health.PingChecker(sqlDB))
+	registry.Register("jwt_key", health.CheckerFunc(func(ctx context.Context) error {
+		return jwtKeys.Check()
+	}))
+	if cfg.Cache.Driver == "redis" {
+		registry.Register("redis", health.RedisChecker(cfg.Cache.Redis.Addr, cfg.Cache.Redis.Password), health.NonCritical())
+	}
+
+	return registry
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"http_server/post-service/internal/handler"
+	"http_server/shared/health"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+	"http_server/shared/problem"
+
+	"github.com/gorilla/mux"
+)
+
+type Handlers struct {
+	Post     *handler.PostHandler
+	Feed     *handler.FeedHandler
+	Comment  *handler.CommentHandler
+	Reaction *handler.ReactionHandler
+}
+
+func NewRouter(h Handlers, healthRegistry *health.Registry, authMiddleware *middleware.JWTAuth, logger *logging.Logger) *mux.Router {
+	r := mux.NewRouter()
+	r.NotFoundHandler = problem.NotFoundHandler()
+	r.MethodNotAllowedHandler = problem.MethodNotAllowedHandler()
+
+	// Request ID, access logging and panic recovery
+	r.Use(middleware.RequestID, middleware.Logging(logger), middleware.Recovery(logger))
+
+	// Health probes; /health is kept as an alias of /readyz for existing
+	// callers.
+	r.Handle("/livez", healthRegistry.LiveHandler()).Methods("GET")
+	r.Handle("/readyz", healthRegistry.ReadyHandler()).Methods("GET")
+	r.Handle("/health", healthRegistry.ReadyHandler()).Methods("GET")
+
+	// API routes
+	api := r.PathPrefix("/api/v1").Subrouter()
+	api.Use(authMiddleware.ValidateJWT)
+
+	// Posts
+	api.HandleFunc("/posts", h.Post.Create).Methods("POST")
+	api.HandleFunc("/posts/{id}", h.Post.Get).Methods("GET")
+	api.HandleFunc("/posts/{id}", h.Post.Delete).Methods("DELETE")
+
+	// Comments
+	api.HandleFunc("/posts/{id}/comments", h.Comment.Create).Methods("POST")
+	api.HandleFunc("/posts/{id}/comments", h.Comment.List).Methods("GET")
+	api.HandleFunc("/comments/{id}/replies", h.Comment.Replies).Methods("GET")
+	api.HandleFunc("/comments/{id}", h.Comment.Delete).Methods("DELETE")
+
+	// Reactions
+	api.HandleFunc("/posts/{id}/reactions", h.Reaction.React).Methods("PUT")
+	api.HandleFunc("/posts/{id}/reactions", h.Reaction.Unreact).Methods("DELETE")
+	api.HandleFunc("/posts/{id}/reactions", h.Reaction.List).Methods("GET")
+	api.HandleFunc("/posts/{id}/stats", h.Reaction.Stats).Methods("GET")
+
+	// Feeds
+	api.HandleFunc("/feed", h.Feed.Home).Methods("GET")
+	api.HandleFunc("/users/{id}/posts", h.Feed.Author).Methods("GET")
+
+	return r
+}
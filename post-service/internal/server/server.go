@@ -0,0 +1,22 @@
+package server
+
+import (
+	"http_server/post-service/internal/config"
+	"http_server/shared/health"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+	sharedserver "http_server/shared/server"
+)
+
+func NewServer(cfg *config.Config, handlers Handlers, healthRegistry *health.Registry, authMiddleware *middleware.JWTAuth, logger *logging.Logger) *sharedserver.Server {
+	router := NewRouter(handlers, healthRegistry, authMiddleware, logger)
+
+	return sharedserver.New(sharedserver.Config{
+		Port:            cfg.Server.Port,
+		ReadTimeout:     cfg.Server.ReadTimeout,
+		WriteTimeout:    cfg.Server.WriteTimeout,
+		ShutdownTimeout: cfg.Server.ShutdownTimeout,
+		DrainDelay:      cfg.Server.DrainDelay,
+		OnDrain:         healthRegistry.SetDraining,
+	}, router, logger)
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"http_server/post-service/internal/config"
+	"http_server/post-service/internal/domain/models"
+	"http_server/post-service/internal/domain/repository"
+	"http_server/shared/logging"
+	"http_server/shared/pagination"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrCommentNotFound  = errors.New("comment not found")
+	ErrInvalidComment   = errors.New("invalid comment content")
+	ErrMaxDepthExceeded = errors.New("maximum reply depth exceeded")
+)
+
+type CommentPage struct {
+	Comments   []models.Comment `json:"comments"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+type CommentService interface {
+	// AddComment comments on a post, or replies to parentID when it is set.
+	AddComment(ctx context.Context, postID, authorID uuid.UUID, parentID *uuid.UUID, content string) (*models.Comment, error)
+	// DeleteComment removes a comment. Authors may delete their own comments;
+	// the post owner and admins may remove any comment on the post.
+	DeleteComment(ctx context.Context, commentID, requesterID uuid.UUID, requesterRoles []string, reason string) error
+	ListComments(ctx context.Context, postID uuid.UUID, cursor string, limit int) (*CommentPage, error)
+	ListReplies(ctx context.Context, commentID uuid.UUID, cursor string, limit int) (*CommentPage, error)
+}
+
+type commentService struct {
+	commentRepo repository.CommentRepository
+	postRepo    repository.PostRepository
+	counterRepo repository.CounterRepository
+	config      config.CommentsConfig
+	hooks       []ModerationHook
+	logger      *logging.Logger
+}
+
+func NewCommentService(commentRepo repository.CommentRepository, postRepo repository.PostRepository, counterRepo repository.CounterRepository, cfg config.CommentsConfig, logger *logging.Logger, hooks ...ModerationHook) CommentService {
+	return &commentService{
+		commentRepo: commentRepo,
+		postRepo:    postRepo,
+		counterRepo: counterRepo,
+		config:      cfg,
+		hooks:       hooks,
+		logger:      logger,
+	}
+}
+
+func (s *commentService) AddComment(ctx context.Context, postID, authorID uuid.UUID, parentID *uuid.UUID, content string) (*models.Comment, error) {
+	logger := s.logger.WithContext(ctx)
+
+	content = strings.TrimSpace(content)
+	if content == "" || utf8.RuneCountInString(content) > s.config.MaxLength {
+		return nil, ErrInvalidComment
+	}
+
+	if _, err := s.findPost(ctx, postID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	comment := &models.Comment{
+		ID:        uuid.New(),
+		PostID:    postID,
+		AuthorID:  authorID,
+		Content:   content,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if parentID != nil {
+		parent, err := s.findComment(ctx, *parentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.PostID != postID || parent.DeletedAt != nil {
+			return nil, ErrCommentNotFound
+		}
+		if parent.Depth+1 > s.config.MaxDepth {
+			return nil, ErrMaxDepthExceeded
+		}
+
+		root := parent.ID
+		if parent.RootID != nil {
+			root = *parent.RootID
+		}
+		comment.ParentID = &parent.ID
+		comment.RootID = &root
+		comment.Depth = parent.Depth + 1
+	}
+
+	if err := s.commentRepo.Create(ctx, comment); err != nil {
+		logger.Error("Failed to create comment", err, zap.String("post_id", postID.String()))
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	logger.Info("Comment created",
+		zap.String("comment_id", comment.ID.String()),
+		zap.String("post_id", postID.String()),
+		zap.Int("depth", comment.Depth))
+	return comment, nil
+}
+
+func (s *commentService) DeleteComment(ctx context.Context, commentID, requesterID uuid.UUID, requesterRoles []string, reason string) error {
+	logger := s.logger.WithContext(ctx)
+
+	comment, err := s.findComment(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.DeletedAt != nil {
+		return ErrCommentNotFound
+	}
+
+	moderatorRole := ""
+	if comment.AuthorID != requesterID {
+		moderatorRole, err = s.moderatorRole(ctx, comment, requesterID, requesterRoles)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.commentRepo.SoftDelete(ctx, comment, requesterID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrCommentNotFound
+		}
+		logger.Error("Failed to delete comment", err, zap.String("comment_id", commentID.String()))
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	if moderatorRole != "" {
+		action := ModerationAction{
+			CommentID:     comment.ID,
+			PostID:        comment.PostID,
+			AuthorID:      comment.AuthorID,
+			ModeratorID:   requesterID,
+			ModeratorRole: moderatorRole,
+			Reason:        reason,
+			At:            time.Now().UTC(),
+		}
+		for _, hook := range s.hooks {
+			hook.CommentRemoved(ctx, action)
+		}
+	}
+
+	logger.Info("Comment deleted", zap.String("comment_id", commentID.String()), zap.String("requester_id", requesterID.String()))
+	return nil
+}
+
+// moderatorRole decides whether requesterID may remove someone else's comment
+// and in which capacity.
+func (s *commentService) moderatorRole(ctx context.Context, comment *models.Comment, requesterID uuid.UUID, requesterRoles []string) (string, error) {
+	if hasRole(requesterRoles, roleAdmin) {
+		return ModeratorAdmin, nil
+	}
+
+	post, err := s.findPost(ctx, comment.PostID)
+	if err != nil && !errors.Is(err, ErrPostNotFound) {
+		return "", err
+	}
+	if post != nil && post.AuthorID == requesterID {
+		return ModeratorPostOwner, nil
+	}
+
+	s.logger.WithContext(ctx).Warn("Attempted to delete comment of another user",
+		zap.String("comment_id", comment.ID.String()),
+		zap.String("requester_id", requesterID.String()))
+	return "", ErrForbidden
+}
+
+func (s *commentService) ListComments(ctx context.Context, postID uuid.UUID, cursor string, limit int) (*CommentPage, error) {
+	if _, err := s.findPost(ctx, postID); err != nil {
+		return nil, err
+	}
+	return s.listThread(ctx, postID, nil, cursor, limit)
+}
+
+func (s *commentService) ListReplies(ctx context.Context, commentID uuid.UUID, cursor string, limit int) (*CommentPage, error) {
+	parent, err := s.findComment(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.listThread(ctx, parent.PostID, &parent.ID, cursor, limit)
+}
+
+func (s *commentService) listThread(ctx context.Context, postID uuid.UUID, parentID *uuid.UUID, cursor string, limit int) (*CommentPage, error) {
+	logger := s.logger.WithContext(ctx)
+	limit = pagination.ClampLimit(limit, s.config.DefaultPageSize, s.config.MaxPageSize)
+
+	after, err := pagination.DecodeCursor[models.TimePosition](cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.ListThread(ctx, postID, parentID, after, limit)
+	if err != nil {
+		logger.Error("Failed to list comments", err, zap.String("post_id", postID.String()))
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+
+	ids := make([]uuid.UUID, len(comments))
+	for i := range comments {
+		ids[i] = comments[i].ID
+	}
+	totals, err := s.counterRepo.Totals(ctx, ids)
+	if err != nil {
+		logger.Error("Failed to load reply counts", err, zap.String("post_id", postID.String()))
+		return nil, fmt.Errorf("failed to load reply counts: %w", err)
+	}
+	for i := range comments {
+		comments[i].ReplyCount = totals[comments[i].ID][models.CounterReplies]
+	}
+
+	page := &CommentPage{Comments: comments}
+	if page.Comments == nil {
+		page.Comments = []models.Comment{}
+	}
+	if len(comments) == limit {
+		page.NextCursor = pagination.EncodeCursor(comments[len(comments)-1].Position())
+	}
+	return page, nil
+}
+
+func (s *commentService) findPost(ctx context.Context, postID uuid.UUID) (*models.Post, error) {
+	post, err := s.postRepo.FindByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrPostNotFound
+		}
+		s.logger.WithContext(ctx).Error("Failed to find post", err, zap.String("post_id", postID.String()))
+		return nil, fmt.Errorf("failed to find post: %w", err)
+	}
+	return post, nil
+}
+
+func (s *commentService) findComment(ctx context.Context, commentID uuid.UUID) (*models.Comment, error) {
+	comment, err := s.commentRepo.FindByID(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		s.logger.WithContext(ctx).Error("Failed to find comment", err, zap.String("comment_id", commentID.String()))
+		return nil, fmt.Errorf("failed to find comment: %w", err)
+	}
+	return comment, nil
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"http_server/post-service/internal/cache"
+	"http_server/post-service/internal/client"
+	"http_server/post-service/internal/config"
+	"http_server/post-service/internal/domain/models"
+	"http_server/post-service/internal/domain/repository"
+	"http_server/shared/logging"
+	"http_server/shared/pagination"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type FeedPage struct {
+	Posts      []models.Post `json:"posts"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+type FeedService interface {
+	// HomeFeed returns posts by the accounts userID follows, plus userID's
+	// own posts, in ranked order.
+	HomeFeed(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*FeedPage, error)
+	// AuthorFeed returns posts written by authorID in ranked order.
+	AuthorFeed(ctx context.Context, authorID uuid.UUID, cursor string, limit int) (*FeedPage, error)
+	// InvalidateHomeFeed drops the cached hot window for userID.
+	InvalidateHomeFeed(ctx context.Context, userID uuid.UUID) error
+}
+
+// feedService builds feeds with fan-out on read: a home feed is a query over
+// the posts of everyone the reader follows. The top HotWindowSize positions of
+// each home feed are cached for HotWindowTTL so that scrolling through the
+// first pages does not repeat the query.
+type feedService struct {
+	postRepo repository.PostRepository
+	graph    client.FollowGraph
+	cache    cache.Cache
+	config   config.FeedConfig
+	logger   *logging.Logger
+}
+
+func NewFeedService(postRepo repository.PostRepository, graph client.FollowGraph, cache cache.Cache, cfg config.FeedConfig, logger *logging.Logger) FeedService {
+	return &feedService{
+		postRepo: postRepo,
+		graph:    graph,
+		cache:    cache,
+		config:   cfg,
+		logger:   logger,
+	}
+}
+
+func (s *feedService) HomeFeed(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*FeedPage, error) {
+	logger := s.logger.WithContext(ctx)
+	limit = s.pageSize(limit)
+
+	after, err := pagination.DecodeCursor[models.FeedPosition](cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	window, err := s.hotWindow(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := pageFromWindow(window, after, limit)
+	if len(positions) == limit || len(window) < s.config.HotWindowSize {
+		// The window either covers this page completely or holds the whole
+		// feed, so the database does not need to be consulted.
+		return s.hydrate(ctx, positions, limit)
+	}
+
+	logger.Debug("Home feed page outside hot window", zap.String("user_id", userID.String()))
+	authors, err := s.authors(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := s.postRepo.ListByAuthors(ctx, authors, after, limit)
+	if err != nil {
+		logger.Error("Failed to list feed posts", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to list feed posts: %w", err)
+	}
+	return newFeedPage(posts, limit), nil
+}
+
+func (s *feedService) AuthorFeed(ctx context.Context, authorID uuid.UUID, cursor string, limit int) (*FeedPage, error) {
+	limit = s.pageSize(limit)
+
+	after, err := pagination.DecodeCursor[models.FeedPosition](cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := s.postRepo.ListByAuthors(ctx, []uuid.UUID{authorID}, after, limit)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to list author posts", err, zap.String("author_id", authorID.String()))
+		return nil, fmt.Errorf("failed to list author posts: %w", err)
+	}
+	return newFeedPage(posts, limit), nil
+}
+
+func (s *feedService) InvalidateHomeFeed(ctx context.Context, userID uuid.UUID) error {
+	return s.cache.Delete(ctx, homeFeedKey(userID))
+}
+
+// hotWindow returns the cached top of userID's home feed, rebuilding it from
+// the database on a miss. Cache failures degrade to an uncached rebuild.
+func (s *feedService) hotWindow(ctx context.Context, userID uuid.UUID) ([]models.FeedPosition, error) {
+	logger := s.logger.WithContext(ctx)
+	key := homeFeedKey(userID)
+
+	raw, err := s.cache.Get(ctx, key)
+	if err == nil {
+		var window []models.FeedPosition
+		if err := json.Unmarshal(raw, &window); err == nil {
+			return window, nil
+		}
+		logger.Warn("Discarding corrupt hot window", zap.String("user_id", userID.String()))
+	} else if !errors.Is(err, cache.ErrCacheMiss) {
+		logger.Warn("Failed to read hot window from cache", zap.Error(err))
+	}
+
+	authors, err := s.authors(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := s.postRepo.ListByAuthors(ctx, authors, nil, s.config.HotWindowSize)
+	if err != nil {
+		logger.Error("Failed to build hot window", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to build hot window: %w", err)
+	}
+
+	window := make([]models.FeedPosition, len(posts))
+	for i := range posts {
+		window[i] = posts[i].Position()
+	}
+
+	if raw, err := json.Marshal(window); err == nil {
+		if err := s.cache.Set(ctx, key, raw, s.config.HotWindowTTL); err != nil {
+			logger.Warn("Failed to cache hot window", zap.Error(err))
+		}
+	}
+
+	return window, nil
+}
+
+// authors returns the accounts whose posts make up userID's home feed.
+func (s *feedService) authors(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	logger := s.logger.WithContext(ctx)
+	key := followingKey(userID)
+
+	var following []uuid.UUID
+	raw, err := s.cache.Get(ctx, key)
+	if err == nil && json.Unmarshal(raw, &following) == nil {
+		return append(following, userID), nil
+	}
+	if err != nil && !errors.Is(err, cache.ErrCacheMiss) {
+		logger.Warn("Failed to read following list from cache", zap.Error(err))
+	}
+
+	following, err = s.graph.Following(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to fetch following list", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to fetch following list: %w", err)
+	}
+
+	if raw, err := json.Marshal(following); err == nil {
+		if err := s.cache.Set(ctx, key, raw, s.config.FollowingTTL); err != nil {
+			logger.Warn("Failed to cache following list", zap.Error(err))
+		}
+	}
+
+	return append(following, userID), nil
+}
+
+// hydrate loads the posts at the given positions, preserving their order.
+// Posts deleted since the window was cached are skipped; the cursor still
+// advances past them.
+func (s *feedService) hydrate(ctx context.Context, positions []models.FeedPosition, limit int) (*FeedPage, error) {
+	ids := make([]uuid.UUID, len(positions))
+	for i, pos := range positions {
+		ids[i] = pos.ID
+	}
+
+	found, err := s.postRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to load feed posts", err)
+		return nil, fmt.Errorf("failed to load feed posts: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]models.Post, len(found))
+	for _, post := range found {
+		byID[post.ID] = post
+	}
+
+	page := &FeedPage{Posts: make([]models.Post, 0, len(positions))}
+	for _, pos := range positions {
+		if post, ok := byID[pos.ID]; ok {
+			page.Posts = append(page.Posts, post)
+		}
+	}
+	if len(positions) == limit {
+		page.NextCursor = pagination.EncodeCursor(positions[len(positions)-1])
+	}
+	return page, nil
+}
+
+func (s *feedService) pageSize(limit int) int {
+	return pagination.ClampLimit(limit, s.config.DefaultPageSize, s.config.MaxPageSize)
+}
+
+// pageFromWindow returns up to limit positions from window that sort after
+// the cursor position.
+func pageFromWindow(window []models.FeedPosition, after *models.FeedPosition, limit int) []models.FeedPosition {
+	start := 0
+	if after != nil {
+		start = len(window)
+		for i, pos := range window {
+			if pos.After(*after) {
+				start = i
+				break
+			}
+		}
+	}
+
+	end := start + limit
+	if end > len(window) {
+		end = len(window)
+	}
+	return window[start:end]
+}
+
+func newFeedPage(posts []models.Post, limit int) *FeedPage {
+	page := &FeedPage{Posts: posts}
+	if page.Posts == nil {
+		page.Posts = []models.Post{}
+	}
+	if len(posts) == limit {
+		page.NextCursor = pagination.EncodeCursor(posts[len(posts)-1].Position())
+	}
+	return page
+}
+
+func homeFeedKey(userID uuid.UUID) string {
+	return "post-service:feed:home:" + userID.String()
+}
+
+func followingKey(userID uuid.UUID) string {
+	return "post-service:following:" + userID.String()
+}
@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"http_server/post-service/internal/cache"
+	"http_server/post-service/internal/config"
+	"http_server/post-service/internal/domain/models"
+	"http_server/post-service/internal/domain/repository"
+	"http_server/shared/logging"
+	"http_server/shared/pagination"
+
+	"github.com/google/uuid"
+)
+
+// fakePosts keeps posts in memory and counts the feed queries.
+type fakePosts struct {
+	repository.PostRepository
+	mu          sync.Mutex
+	posts       []models.Post
+	listQueries int
+}
+
+func (f *fakePosts) add(post models.Post) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.posts = append(f.posts, post)
+}
+
+func (f *fakePosts) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Post, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var found []models.Post
+	for _, post := range f.posts {
+		for _, id := range ids {
+			if post.ID == id && post.DeletedAt == nil {
+				found = append(found, post)
+			}
+		}
+	}
+	return found, nil
+}
+
+func (f *fakePosts) ListByAuthors(ctx context.Context, authorIDs []uuid.UUID, after *models.FeedPosition, limit int) ([]models.Post, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.listQueries++
+
+	var matched []models.Post
+	for _, post := range f.posts {
+		if post.DeletedAt != nil || !containsID(authorIDs, post.AuthorID) {
+			continue
+		}
+		if after != nil && !post.Position().After(*after) {
+			continue
+		}
+		matched = append(matched, post)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[j].Position().After(matched[i].Position())
+	})
+	if len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched, nil
+}
+
+func (f *fakePosts) queries() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.listQueries
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeGraph struct {
+	mu        sync.Mutex
+	following map[uuid.UUID][]uuid.UUID
+	calls     int
+}
+
+func (f *fakeGraph) Following(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	return f.following[userID], nil
+}
+
+// brokenCache fails every operation, like an unreachable Redis.
+type brokenCache struct{}
+
+var errCacheDown = errors.New("cache unavailable")
+
+func (brokenCache) Get(ctx context.Context, key string) ([]byte, error) { return nil, errCacheDown }
+func (brokenCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errCacheDown
+}
+func (brokenCache) Delete(ctx context.Context, keys ...string) error { return errCacheDown }
+
+type feedFixture struct {
+	feeds  *feedService
+	posts  *fakePosts
+	graph  *fakeGraph
+	reader uuid.UUID
+	// want is the reader's home feed, top first.
+	want []uuid.UUID
+}
+
+// newFeedFixture builds a home feed of count posts by the reader, an author
+// they follow and, invisible to them, a stranger, served through c with a
+// hot window of windowSize positions.
+func newFeedFixture(t *testing.T, c cache.Cache, windowSize, count int) *feedFixture {
+	t.Helper()
+	logger, err := logging.NewLogger(&logging.Config{ServiceName: "test", LogLevel: "error", FilePath: "stderr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, followed, stranger := uuid.New(), uuid.New(), uuid.New()
+	f := &feedFixture{
+		posts:  &fakePosts{},
+		graph:  &fakeGraph{following: map[uuid.UUID][]uuid.UUID{reader: {followed}}},
+		reader: reader,
+	}
+	for i := 0; i < count; i++ {
+		author := followed
+		if i%3 == 0 {
+			author = reader
+		}
+		post := models.Post{ID: uuid.New(), AuthorID: author, RankScore: int64(1000 - i)}
+		f.posts.add(post)
+		f.want = append(f.want, post.ID)
+		f.posts.add(models.Post{ID: uuid.New(), AuthorID: stranger, RankScore: int64(1000 - i)})
+	}
+
+	cfg := config.FeedConfig{
+		DefaultPageSize: 3,
+		MaxPageSize:     10,
+		HotWindowSize:   windowSize,
+		HotWindowTTL:    time.Minute,
+		FollowingTTL:    time.Minute,
+	}
+	f.feeds = NewFeedService(f.posts, f.graph, c, cfg, logger).(*feedService)
+	return f
+}
+
+// readAll pages through the reader's home feed and returns the post IDs.
+func (f *feedFixture) readAll(t *testing.T) []uuid.UUID {
+	t.Helper()
+	var ids []uuid.UUID
+	cursor := ""
+	for page := 0; page < 100; page++ {
+		result, err := f.feeds.HomeFeed(context.Background(), f.reader, cursor, 3)
+		if err != nil {
+			t.Fatalf("HomeFeed page %d: %v", page, err)
+		}
+		for _, post := range result.Posts {
+			ids = append(ids, post.ID)
+		}
+		if result.NextCursor == "" {
+			return ids
+		}
+		cursor = result.NextCursor
+	}
+	t.Fatal("HomeFeed did not end")
+	return nil
+}
+
+func equalIDs(a, b []uuid.UUID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestHomeFeedPagesInRankOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		cache      func() cache.Cache
+		windowSize int
+		count      int
+	}{
+		{"window covers feed", func() cache.Cache { return cache.NewMemoryCache(100) }, 20, 10},
+		{"feed extends past window", func() cache.Cache { return cache.NewMemoryCache(100) }, 5, 10},
+		{"page boundary at window end", func() cache.Cache { return cache.NewMemoryCache(100) }, 6, 10},
+		{"cache unavailable", func() cache.Cache { return brokenCache{} }, 5, 10},
+		{"empty feed", func() cache.Cache { return cache.NewMemoryCache(100) }, 5, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFeedFixture(t, tt.cache(), tt.windowSize, tt.count)
+			if got := f.readAll(t); !equalIDs(got, f.want) {
+				t.Errorf("home feed = %v, want %v", got, f.want)
+			}
+		})
+	}
+}
+
+func TestHomeFeedServesWindowFromCache(t *testing.T) {
+	f := newFeedFixture(t, cache.NewMemoryCache(100), 6, 10)
+	ctx := context.Background()
+
+	first, err := f.feeds.HomeFeed(ctx, f.reader, "", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.posts.queries() != 1 || f.graph.calls != 1 {
+		t.Fatalf("first page: %d queries, %d graph calls, want 1 and 1", f.posts.queries(), f.graph.calls)
+	}
+
+	// The second page is inside the cached window.
+	if _, err := f.feeds.HomeFeed(ctx, f.reader, first.NextCursor, 3); err != nil {
+		t.Fatal(err)
+	}
+	if f.posts.queries() != 1 {
+		t.Errorf("page inside the window ran %d queries, want none beyond the first", f.posts.queries()-1)
+	}
+
+	// The third page is past it and goes to the database, reusing the
+	// cached following list.
+	second, _ := f.feeds.HomeFeed(ctx, f.reader, first.NextCursor, 3)
+	if _, err := f.feeds.HomeFeed(ctx, f.reader, second.NextCursor, 3); err != nil {
+		t.Fatal(err)
+	}
+	if f.posts.queries() != 2 || f.graph.calls != 1 {
+		t.Errorf("page past the window: %d queries, %d graph calls, want 2 and 1", f.posts.queries(), f.graph.calls)
+	}
+}
+
+func TestInvalidateHomeFeedShowsNewPosts(t *testing.T) {
+	f := newFeedFixture(t, cache.NewMemoryCache(100), 20, 4)
+	ctx := context.Background()
+	if _, err := f.feeds.HomeFeed(ctx, f.reader, "", 3); err != nil {
+		t.Fatal(err)
+	}
+
+	post := models.Post{ID: uuid.New(), AuthorID: f.reader, RankScore: 5000}
+	f.posts.add(post)
+	page, _ := f.feeds.HomeFeed(ctx, f.reader, "", 3)
+	if page.Posts[0].ID == post.ID {
+		t.Fatal("new post shown before the window was invalidated; the cache is not used")
+	}
+
+	if err := f.feeds.InvalidateHomeFeed(ctx, f.reader); err != nil {
+		t.Fatal(err)
+	}
+	page, err := f.feeds.HomeFeed(ctx, f.reader, "", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Posts[0].ID != post.ID {
+		t.Errorf("top post = %s, want the new post %s", page.Posts[0].ID, post.ID)
+	}
+}
+
+func TestHomeFeedSkipsPostsDeletedFromWindow(t *testing.T) {
+	f := newFeedFixture(t, cache.NewMemoryCache(100), 20, 6)
+	ctx := context.Background()
+	if _, err := f.feeds.HomeFeed(ctx, f.reader, "", 3); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted := time.Now()
+	f.posts.mu.Lock()
+	for i := range f.posts.posts {
+		if f.posts.posts[i].ID == f.want[1] {
+			f.posts.posts[i].DeletedAt = &deleted
+		}
+	}
+	f.posts.mu.Unlock()
+
+	want := append([]uuid.UUID{f.want[0]}, f.want[2:]...)
+	if got := f.readAll(t); !equalIDs(got, want) {
+		t.Errorf("home feed = %v, want %v", got, want)
+	}
+}
+
+func TestHomeFeedRejectsInvalidCursor(t *testing.T) {
+	f := newFeedFixture(t, cache.NewMemoryCache(100), 5, 3)
+	if _, err := f.feeds.HomeFeed(context.Background(), f.reader, "not a cursor", 3); !errors.Is(err, pagination.ErrInvalidCursor) {
+		t.Errorf("HomeFeed = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestPageFromWindow(t *testing.T) {
+	window := make([]models.FeedPosition, 5)
+	for i := range window {
+		window[i] = models.FeedPosition{Score: int64(50 - i*10), ID: uuid.New()}
+	}
+	between := models.FeedPosition{Score: 35, ID: uuid.New()}
+	below := models.FeedPosition{Score: 1, ID: uuid.New()}
+	above := models.FeedPosition{Score: 100, ID: uuid.New()}
+
+	tests := []struct {
+		name  string
+		after *models.FeedPosition
+		limit int
+		want  []models.FeedPosition
+	}{
+		{"first page", nil, 2, window[:2]},
+		{"first page larger than window", nil, 10, window},
+		{"after a window position", &window[1], 2, window[2:4]},
+		{"after the last position", &window[4], 2, window[5:]},
+		{"cursor between positions", &between, 2, window[2:4]},
+		{"cursor below the window", &below, 2, window[5:]},
+		{"cursor above the window", &above, 2, window[:2]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pageFromWindow(window, tt.after, tt.limit)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d positions, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("position %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
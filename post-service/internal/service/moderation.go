@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"http_server/shared/logging"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Moderator roles recorded on a ModerationAction
+const (
+	ModeratorPostOwner = "post_owner"
+	ModeratorAdmin     = "admin"
+)
+
+// ModerationAction describes a comment removed by someone other than its author.
+type ModerationAction struct {
+	CommentID     uuid.UUID
+	PostID        uuid.UUID
+	AuthorID      uuid.UUID
+	ModeratorID   uuid.UUID
+	ModeratorRole string
+	Reason        string
+	At            time.Time
+}
+
+// ModerationHook is notified after a moderation action has been committed.
+// Hooks run synchronously on the request path and must not block for long;
+// failures are theirs to handle.
+type ModerationHook interface {
+	CommentRemoved(ctx context.Context, action ModerationAction)
+}
+
+type auditLogHook struct {
+	logger *logging.Logger
+}
+
+// NewAuditLogHook returns a ModerationHook that writes every action to the
+// service log as an audit record.
+func NewAuditLogHook(logger *logging.Logger) ModerationHook {
+	return &auditLogHook{logger: logger}
+}
+
+func (h *auditLogHook) CommentRemoved(ctx context.Context, action ModerationAction) {
+	h.logger.WithContext(ctx).Info("Comment removed by moderator",
+		zap.String("audit", "comment_removed"),
+		zap.String("comment_id", action.CommentID.String()),
+		zap.String("post_id", action.PostID.String()),
+		zap.String("author_id", action.AuthorID.String()),
+		zap.String("moderator_id", action.ModeratorID.String()),
+		zap.String("moderator_role", action.ModeratorRole),
+		zap.String("reason", action.Reason),
+		zap.Time("at", action.At),
+	)
+}
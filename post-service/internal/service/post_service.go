@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"http_server/post-service/internal/domain/models"
+	"http_server/post-service/internal/domain/repository"
+	"http_server/shared/logging"
+	"http_server/shared/pagination"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	maxContentLength = 5000
+	roleAdmin        = "admin"
+)
+
+var (
+	ErrPostNotFound   = errors.New("post not found")
+	ErrForbidden      = errors.New("operation not permitted")
+	ErrInvalidContent = errors.New("invalid post content")
+	ErrInvalidCursor  = pagination.ErrInvalidCursor
+)
+
+type PostService interface {
+	CreatePost(ctx context.Context, authorID uuid.UUID, authorRoles []string, content string) (*models.Post, error)
+	GetPost(ctx context.Context, id uuid.UUID) (*models.Post, error)
+	DeletePost(ctx context.Context, id, requesterID uuid.UUID, requesterRoles []string) error
+}
+
+type postService struct {
+	postRepo repository.PostRepository
+	ranker   *Ranker
+	feeds    FeedService
+	logger   *logging.Logger
+}
+
+func NewPostService(postRepo repository.PostRepository, ranker *Ranker, feeds FeedService, logger *logging.Logger) PostService {
+	return &postService{
+		postRepo: postRepo,
+		ranker:   ranker,
+		feeds:    feeds,
+		logger:   logger,
+	}
+}
+
+func (s *postService) CreatePost(ctx context.Context, authorID uuid.UUID, authorRoles []string, content string) (*models.Post, error) {
+	logger := s.logger.WithContext(ctx)
+
+	content = strings.TrimSpace(content)
+	if content == "" || utf8.RuneCountInString(content) > maxContentLength {
+		return nil, ErrInvalidContent
+	}
+
+	now := time.Now().UTC()
+	role := s.ranker.PrimaryRole(authorRoles)
+	post := &models.Post{
+		ID:         uuid.New(),
+		AuthorID:   authorID,
+		AuthorRole: role,
+		Content:    content,
+		RankScore:  s.ranker.Score(now, role),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := s.postRepo.Create(ctx, post); err != nil {
+		logger.Error("Failed to create post", err, zap.String("author_id", authorID.String()))
+		return nil, fmt.Errorf("failed to create post: %w", err)
+	}
+
+	// Authors expect to see their own post right away; followers pick it up
+	// when their hot window expires.
+	if err := s.feeds.InvalidateHomeFeed(ctx, authorID); err != nil {
+		logger.Warn("Failed to invalidate author feed", zap.Error(err), zap.String("author_id", authorID.String()))
+	}
+
+	logger.Info("Post created", zap.String("post_id", post.ID.String()), zap.String("author_id", authorID.String()))
+	return post, nil
+}
+
+func (s *postService) GetPost(ctx context.Context, id uuid.UUID) (*models.Post, error) {
+	post, err := s.postRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrPostNotFound
+		}
+		s.logger.WithContext(ctx).Error("Failed to find post", err, zap.String("post_id", id.String()))
+		return nil, fmt.Errorf("failed to find post: %w", err)
+	}
+	return post, nil
+}
+
+func (s *postService) DeletePost(ctx context.Context, id, requesterID uuid.UUID, requesterRoles []string) error {
+	logger := s.logger.WithContext(ctx)
+
+	post, err := s.GetPost(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if post.AuthorID != requesterID && !hasRole(requesterRoles, roleAdmin) {
+		logger.Warn("Attempted to delete post of another user",
+			zap.String("post_id", id.String()),
+			zap.String("requester_id", requesterID.String()))
+		return ErrForbidden
+	}
+
+	if err := s.postRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrPostNotFound
+		}
+		logger.Error("Failed to delete post", err, zap.String("post_id", id.String()))
+		return fmt.Errorf("failed to delete post: %w", err)
+	}
+
+	logger.Info("Post deleted", zap.String("post_id", id.String()), zap.String("requester_id", requesterID.String()))
+	return nil
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
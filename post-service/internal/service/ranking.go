@@ -0,0 +1,43 @@
+package service
+
+import (
+	"time"
+)
+
+// Ranker assigns each post a static rank score at creation time. The score is
+// the creation time in milliseconds shifted forward by the boost configured
+// for the author's role, so boosted authors stay near the top of feeds for
+// longer. Because scores never change, the relative order of existing posts
+// is fixed and a keyset cursor never repeats or skips them.
+//
+// Scores are not monotonic in creation time, though. A boosted post scores
+// as if it were created up to its boost in the future, so while that lasts,
+// newer unboosted posts rank below it. A post created after a client fetched
+// a page can therefore land below that client's cursor and show up on a
+// later page instead of above the feed.
+type Ranker struct {
+	boosts map[string]time.Duration
+}
+
+func NewRanker(boosts map[string]time.Duration) *Ranker {
+	return &Ranker{boosts: boosts}
+}
+
+// PrimaryRole returns the role among roles that carries the largest boost,
+// or an empty string if none of them is boosted.
+func (r *Ranker) PrimaryRole(roles []string) string {
+	var primary string
+	var best time.Duration
+	for _, role := range roles {
+		if boost, ok := r.boosts[role]; ok && boost > best {
+			primary, best = role, boost
+		}
+	}
+	return primary
+}
+
+// Score computes the rank score for a post created at createdAt by an author
+// whose primary role is role.
+func (r *Ranker) Score(createdAt time.Time, role string) int64 {
+	return createdAt.UnixMilli() + r.boosts[role].Milliseconds()
+}
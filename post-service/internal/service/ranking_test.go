@@ -0,0 +1,69 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRankerPrimaryRole(t *testing.T) {
+	ranker := NewRanker(map[string]time.Duration{
+		"official_news":    6 * time.Hour,
+		"important_person": 2 * time.Hour,
+	})
+
+	tests := []struct {
+		name  string
+		roles []string
+		want  string
+	}{
+		{"no roles", nil, ""},
+		{"unboosted roles", []string{"user", "admin"}, ""},
+		{"one boosted role", []string{"user", "important_person"}, "important_person"},
+		{"largest boost wins", []string{"important_person", "official_news"}, "official_news"},
+		{"order does not matter", []string{"official_news", "important_person"}, "official_news"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ranker.PrimaryRole(tt.roles); got != tt.want {
+				t.Errorf("PrimaryRole(%v) = %q, want %q", tt.roles, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankerScore(t *testing.T) {
+	ranker := NewRanker(map[string]time.Duration{"official_news": time.Hour})
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		createdAt time.Time
+		role      string
+		want      int64
+	}{
+		{"unboosted", createdAt, "", createdAt.UnixMilli()},
+		{"unknown role", createdAt, "user", createdAt.UnixMilli()},
+		{"boosted", createdAt, "official_news", createdAt.Add(time.Hour).UnixMilli()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ranker.Score(tt.createdAt, tt.role); got != tt.want {
+				t.Errorf("Score = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankerBoostOutranksNewerPosts(t *testing.T) {
+	ranker := NewRanker(map[string]time.Duration{"official_news": time.Hour})
+	boosted := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// Posted within the boost, a later unboosted post ranks lower; once the
+	// boost has elapsed it ranks higher.
+	if ranker.Score(boosted.Add(30*time.Minute), "") >= ranker.Score(boosted, "official_news") {
+		t.Error("post inside the boost window outranks the boosted post")
+	}
+	if ranker.Score(boosted.Add(2*time.Hour), "") <= ranker.Score(boosted, "official_news") {
+		t.Error("post after the boost window ranks below the boosted post")
+	}
+}
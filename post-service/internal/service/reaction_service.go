@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"http_server/post-service/internal/config"
+	"http_server/post-service/internal/domain/models"
+	"http_server/post-service/internal/domain/repository"
+	"http_server/shared/logging"
+	"http_server/shared/pagination"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidReaction  = errors.New("invalid reaction kind")
+	ErrReactionNotFound = errors.New("reaction not found")
+)
+
+// PostStats holds the aggregated interaction counters of a post.
+type PostStats struct {
+	Comments  int64            `json:"comments"`
+	Reactions map[string]int64 `json:"reactions"`
+}
+
+type ReactionPage struct {
+	Reactions  []models.Reaction `json:"reactions"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+type ReactionService interface {
+	React(ctx context.Context, postID, userID uuid.UUID, kind string) (*models.Reaction, error)
+	Unreact(ctx context.Context, postID, userID uuid.UUID) error
+	ListReactions(ctx context.Context, postID uuid.UUID, cursor string, limit int) (*ReactionPage, error)
+	Stats(ctx context.Context, postID uuid.UUID) (*PostStats, error)
+}
+
+type reactionService struct {
+	reactionRepo repository.ReactionRepository
+	postRepo     repository.PostRepository
+	counterRepo  repository.CounterRepository
+	config       config.CommentsConfig
+	logger       *logging.Logger
+}
+
+func NewReactionService(reactionRepo repository.ReactionRepository, postRepo repository.PostRepository, counterRepo repository.CounterRepository, cfg config.CommentsConfig, logger *logging.Logger) ReactionService {
+	return &reactionService{
+		reactionRepo: reactionRepo,
+		postRepo:     postRepo,
+		counterRepo:  counterRepo,
+		config:       cfg,
+		logger:       logger,
+	}
+}
+
+func (s *reactionService) React(ctx context.Context, postID, userID uuid.UUID, kind string) (*models.Reaction, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !models.IsValidReactionKind(kind) {
+		return nil, ErrInvalidReaction
+	}
+	if err := s.ensurePost(ctx, postID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	reaction := &models.Reaction{
+		PostID:    postID,
+		UserID:    userID,
+		Kind:      kind,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.reactionRepo.Upsert(ctx, reaction); err != nil {
+		logger.Error("Failed to save reaction", err, zap.String("post_id", postID.String()))
+		return nil, fmt.Errorf("failed to save reaction: %w", err)
+	}
+	reaction.Kind = kind
+
+	return reaction, nil
+}
+
+func (s *reactionService) Unreact(ctx context.Context, postID, userID uuid.UUID) error {
+	if err := s.reactionRepo.Delete(ctx, postID, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrReactionNotFound
+		}
+		s.logger.WithContext(ctx).Error("Failed to remove reaction", err, zap.String("post_id", postID.String()))
+		return fmt.Errorf("failed to remove reaction: %w", err)
+	}
+	return nil
+}
+
+func (s *reactionService) ListReactions(ctx context.Context, postID uuid.UUID, cursor string, limit int) (*ReactionPage, error) {
+	limit = pagination.ClampLimit(limit, s.config.DefaultPageSize, s.config.MaxPageSize)
+
+	after, err := pagination.DecodeCursor[models.TimePosition](cursor)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensurePost(ctx, postID); err != nil {
+		return nil, err
+	}
+
+	reactions, err := s.reactionRepo.List(ctx, postID, after, limit)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to list reactions", err, zap.String("post_id", postID.String()))
+		return nil, fmt.Errorf("failed to list reactions: %w", err)
+	}
+
+	page := &ReactionPage{Reactions: reactions}
+	if page.Reactions == nil {
+		page.Reactions = []models.Reaction{}
+	}
+	if len(reactions) == limit {
+		page.NextCursor = pagination.EncodeCursor(reactions[len(reactions)-1].Position())
+	}
+	return page, nil
+}
+
+func (s *reactionService) Stats(ctx context.Context, postID uuid.UUID) (*PostStats, error) {
+	if err := s.ensurePost(ctx, postID); err != nil {
+		return nil, err
+	}
+
+	totals, err := s.counterRepo.Totals(ctx, []uuid.UUID{postID})
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to load post counters", err, zap.String("post_id", postID.String()))
+		return nil, fmt.Errorf("failed to load post counters: %w", err)
+	}
+
+	counters := totals[postID]
+	stats := &PostStats{
+		Comments:  counters[models.CounterComments],
+		Reactions: make(map[string]int64, len(models.ReactionKinds)),
+	}
+	for _, kind := range models.ReactionKinds {
+		stats.Reactions[kind] = counters[models.ReactionCounter(kind)]
+	}
+	return stats, nil
+}
+
+func (s *reactionService) ensurePost(ctx context.Context, postID uuid.UUID) error {
+	if _, err := s.postRepo.FindByID(ctx, postID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrPostNotFound
+		}
+		s.logger.WithContext(ctx).Error("Failed to find post", err, zap.String("post_id", postID.String()))
+		return fmt.Errorf("failed to find post: %w", err)
+	}
+	return nil
+}
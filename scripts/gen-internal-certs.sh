@@ -0,0 +1,39 @@
+#!/bin/bash
+# Generates a development CA and the certificates the services use for
+# mutual TLS on internal APIs. The SPIFFE ID in each certificate is what the
+# receiving service maps to a principal. Not for production use.
+set -euo pipefail
+
+dir=${1:-certs/internal}
+days=365
+trust_domain=spiffe://cluster.local/ns/default/sa
+
+mkdir -p "$dir"
+cd "$dir"
+
+if [ ! -f ca.key ]; then
+    openssl req -x509 -nodes -newkey ec -pkeyopt ec_paramgen_curve:P-256 -days "$days" \
+        -subj "/CN=internal-ca" -keyout ca.key -out ca.crt
+fi
+
+# issue SERVICE: one certificate per service, serving its internal API
+# under its DNS name and calling other services' as its SPIFFE ID
+issue() {
+    local name=$1
+    openssl req -nodes -newkey ec -pkeyopt ec_paramgen_curve:P-256 \
+        -subj "/CN=$name" -keyout "$name.key" -out "$name.csr"
+    openssl x509 -req -in "$name.csr" -CA ca.crt -CAkey ca.key -CAcreateserial -days "$days" \
+        -extfile <(printf 'subjectAltName=DNS:%s,DNS:localhost,URI:%s/%s\nextendedKeyUsage=serverAuth,clientAuth\n' \
+            "$name" "$trust_domain" "$name") \
+        -out "$name.crt"
+    rm "$name.csr"
+}
+
+for service in auth-service user-service post-service; do
+    issue "$service"
+done
+
+# The containers run as non-root users
+chmod 644 ./*.key ./*.crt
+chmod 600 ca.key
+echo "Internal certificates written to $dir"
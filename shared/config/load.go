@@ -0,0 +1,90 @@
+// Package config loads service configuration files.
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// Option adjusts how Load decodes a file.
+type Option func(*mapstructure.DecoderConfig)
+
+// Strict makes keys that match no field an error, so typos and stale
+// settings are reported instead of silently ignored.
+func Strict() Option {
+	return func(dc *mapstructure.DecoderConfig) {
+		dc.ErrorUnused = true
+	}
+}
+
+// Load reads the YAML file at path, expands environment placeholders in its
+// string values (see ExpandEnv) and decodes the result into out using
+// mapstructure tags. Durations such as "15s", numbers, booleans and
+// comma-separated lists are converted from the expanded strings on the way.
+// Decoding problems are reported together, one per line.
+func Load(path string, out interface{}, opts ...Option) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	parsed := viper.New()
+	parsed.SetConfigType("yaml")
+	if err := parsed.ReadConfig(bytes.NewReader(raw)); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	// Placeholders are expanded after parsing, so values with characters
+	// YAML treats specially, such as '#', ': ' or quotes, stay intact
+	v := viper.New()
+	if err := v.MergeConfigMap(expandValues(parsed.AllSettings()).(map[string]interface{})); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	decoderOpts := make([]viper.DecoderConfigOption, len(opts))
+	for i, opt := range opts {
+		decoderOpts[i] = viper.DecoderConfigOption(opt)
+	}
+	if err := v.Unmarshal(out, decoderOpts...); err != nil {
+		return fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return nil
+}
+
+// ExpandEnv replaces ${VAR} and ${VAR:-default} in value with the value of
+// VAR, or with default when VAR is unset or empty. Quotes around a default
+// are dropped.
+func ExpandEnv(value string) string {
+	return envPattern.ReplaceAllStringFunc(value, func(match string) string {
+		groups := envPattern.FindStringSubmatch(match)
+		if value, ok := os.LookupEnv(groups[1]); ok && value != "" {
+			return value
+		}
+		return strings.Trim(groups[2], `"`)
+	})
+}
+
+// expandValues applies ExpandEnv to every string in a parsed document.
+func expandValues(value interface{}) interface{} {
+	switch value := value.(type) {
+	case string:
+		return ExpandEnv(value)
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = expandValues(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = expandValues(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Name     string        `mapstructure:"name"`
+	Password string        `mapstructure:"password"`
+	Port     int           `mapstructure:"port"`
+	Enabled  bool          `mapstructure:"enabled"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+	Origins  []string      `mapstructure:"origins"`
+	Routes   []testRoute   `mapstructure:"routes"`
+}
+
+type testRoute struct {
+	Path string `mapstructure:"path"`
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadExpandsPlaceholders(t *testing.T) {
+	t.Setenv("TEST_PORT", "9090")
+	t.Setenv("TEST_ENABLED", "true")
+	t.Setenv("TEST_ROUTE", "/api")
+	path := writeConfig(t, `
+name: ${TEST_NAME:-"auth service"}
+port: ${TEST_PORT:-8080}
+enabled: ${TEST_ENABLED:-false}
+timeout: ${TEST_TIMEOUT:-15s}
+origins: ${TEST_ORIGINS:-a.example,b.example}
+routes:
+- path: ${TEST_ROUTE}
+`)
+
+	var got testConfig
+	if err := Load(path, &got); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := testConfig{
+		Name:    "auth service",
+		Port:    9090,
+		Enabled: true,
+		Timeout: 15 * time.Second,
+		Origins: []string{"a.example", "b.example"},
+		Routes:  []testRoute{{Path: "/api"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadKeepsSpecialCharactersInValues(t *testing.T) {
+	for _, value := range []string{
+		"pa#ss word",
+		"key: value",
+		`quo"te's`,
+		"line\nbreak",
+		"- item",
+	} {
+		t.Setenv("TEST_PASSWORD", value)
+		path := writeConfig(t, "password: ${TEST_PASSWORD}\nport: 1\n")
+
+		var got testConfig
+		if err := Load(path, &got); err != nil {
+			t.Fatalf("Load with %q: %v", value, err)
+		}
+		if got.Password != value || got.Port != 1 {
+			t.Errorf("Load with %q = %+v", value, got)
+		}
+	}
+}
+
+func TestLoadStrictRejectsUnknownKeys(t *testing.T) {
+	path := writeConfig(t, "name: x\nnmae: y\n")
+
+	var got testConfig
+	if err := Load(path, &got); err != nil {
+		t.Fatalf("Load without Strict: %v", err)
+	}
+	err := Load(path, &got, Strict())
+	if err == nil || !strings.Contains(err.Error(), "nmae") {
+		t.Errorf("Load with Strict = %v, want an error naming nmae", err)
+	}
+}
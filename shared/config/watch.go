@@ -0,0 +1,48 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Watch calls reload whenever the file at path changes or the process
+// receives SIGHUP, until ctx is done. The file is polled every interval
+// rather than watched, so edits made by replacing the file or swapping a
+// Kubernetes ConfigMap symlink are noticed too.
+func Watch(ctx context.Context, path string, interval time.Duration, reload func()) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := modTime(path)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		case <-ticker.C:
+			current := modTime(path)
+			if current.Equal(last) {
+				continue
+			}
+			last = current
+		}
+		reload()
+	}
+}
+
+// modTime returns the zero time when the file cannot be read, so its
+// reappearance counts as a change.
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,33 @@
+package database
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestOpenKeepsPasswordOutOfErrors(t *testing.T) {
+	// Nothing listens on a port that was just released, so the initial
+	// ping fails.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	_, err = Open(Config{Host: "127.0.0.1", Port: port, User: "app", Password: "s3cret-password", DBName: "app", SSLMode: "disable"})
+	if err == nil {
+		t.Fatal("Open succeeded without a server")
+	}
+	if strings.Contains(err.Error(), "s3cret-password") {
+		t.Errorf("error quotes the password: %v", err)
+	}
+}
+
+func TestOpenRejectsInvalidSettings(t *testing.T) {
+	_, err := Open(Config{Host: "localhost", Port: 5432, DBName: "app", SSLMode: "sometimes"})
+	if err == nil || !strings.Contains(err.Error(), "invalid database settings") {
+		t.Errorf("Open = %v, want invalid database settings", err)
+	}
+}
@@ -0,0 +1,194 @@
+// Package errors defines AppError, a typed error that maps onto an HTTP
+// status code.
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+type ErrorType string
+
+const (
+	ErrorTypeValidation       ErrorType = "VALIDATION"
+	ErrorTypeAuthentication   ErrorType = "AUTHENTICATION"
+	ErrorTypeAuthorization    ErrorType = "AUTHORIZATION"
+	ErrorTypeNotFound         ErrorType = "NOT_FOUND"
+	ErrorTypeInternal         ErrorType = "INTERNAL"
+	ErrorTypeConflict         ErrorType = "CONFLICT"
+	ErrorTypeBadRequest       ErrorType = "BAD_REQUEST"
+	ErrorTypeRateLimited      ErrorType = "RATE_LIMITED"
+	ErrorTypeUnavailable      ErrorType = "UNAVAILABLE"
+	ErrorTypeLocked           ErrorType = "LOCKED"
+	ErrorTypeMethodNotAllowed ErrorType = "METHOD_NOT_ALLOWED"
+	ErrorTypeGone             ErrorType = "GONE"
+	ErrorTypePrecondition     ErrorType = "PRECONDITION_FAILED"
+	ErrorTypeTooLarge         ErrorType = "TOO_LARGE"
+	ErrorTypeUnsupportedMedia ErrorType = "UNSUPPORTED_MEDIA_TYPE"
+	ErrorTypeUnprocessable    ErrorType = "UNPROCESSABLE"
+	// ErrorTypeChecksumMismatch is the tus checksum extension's 460 status.
+	ErrorTypeChecksumMismatch ErrorType = "CHECKSUM_MISMATCH"
+)
+
+var statusCodes = map[ErrorType]int{
+	ErrorTypeValidation:       http.StatusBadRequest,
+	ErrorTypeAuthentication:   http.StatusUnauthorized,
+	ErrorTypeAuthorization:    http.StatusForbidden,
+	ErrorTypeNotFound:         http.StatusNotFound,
+	ErrorTypeInternal:         http.StatusInternalServerError,
+	ErrorTypeConflict:         http.StatusConflict,
+	ErrorTypeBadRequest:       http.StatusBadRequest,
+	ErrorTypeRateLimited:      http.StatusTooManyRequests,
+	ErrorTypeUnavailable:      http.StatusServiceUnavailable,
+	ErrorTypeLocked:           http.StatusLocked,
+	ErrorTypeMethodNotAllowed: http.StatusMethodNotAllowed,
+	ErrorTypeGone:             http.StatusGone,
+	ErrorTypePrecondition:     http.StatusPreconditionFailed,
+	ErrorTypeTooLarge:         http.StatusRequestEntityTooLarge,
+	ErrorTypeUnsupportedMedia: http.StatusUnsupportedMediaType,
+	ErrorTypeUnprocessable:    http.StatusUnprocessableEntity,
+	ErrorTypeChecksumMismatch: 460,
+}
+
+// AppError is an error that is safe to show to clients. Message and Fields
+// are client-facing; Err and Stack are for logs only. Code is a stable,
+// machine-readable identifier such as "user_exists"; it defaults to the
+// lower-cased Type.
+type AppError struct {
+	Type    ErrorType    `json:"type"`
+	Code    string       `json:"code,omitempty"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+	Err     error        `json:"error,omitempty"`
+	Stack   string       `json:"stack,omitempty"`
+}
+
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s (%v)", e.Type, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Type, e.Message)
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the HTTP status code for the error type. Unknown types
+// map to 500.
+func (e *AppError) StatusCode() int {
+	if code, ok := statusCodes[e.Type]; ok {
+		return code
+	}
+	return http.StatusInternalServerError
+}
+
+// ErrorCode returns Code, or the lower-cased Type when Code is empty.
+func (e *AppError) ErrorCode() string {
+	if e.Code != "" {
+		return e.Code
+	}
+	return strings.ToLower(string(e.Type))
+}
+
+// WithCode sets the stable error code reported to clients.
+func (e *AppError) WithCode(code string) *AppError {
+	e.Code = code
+	return e
+}
+
+// WithFields appends per-field details, typically for validation errors.
+func (e *AppError) WithFields(fields ...FieldError) *AppError {
+	e.Fields = append(e.Fields, fields...)
+	return e
+}
+
+func (e *AppError) WithStack() *AppError {
+	const depth = 32
+	var pcs [depth]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var builder strings.Builder
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&builder, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	e.Stack = builder.String()
+	return e
+}
+
+// As returns the first AppError in err's chain.
+func As(err error) (*AppError, bool) {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// StatusCode returns the HTTP status code for err: the AppError mapping if
+// err wraps one, 500 otherwise.
+func StatusCode(err error) int {
+	if appErr, ok := As(err); ok {
+		return appErr.StatusCode()
+	}
+	return http.StatusInternalServerError
+}
+
+func New(errType ErrorType, message string, err error) *AppError {
+	return &AppError{
+		Type:    errType,
+		Message: message,
+		Err:     err,
+	}
+}
+
+func NewValidationError(message string, err error) *AppError {
+	return New(ErrorTypeValidation, message, err)
+}
+
+func NewAuthenticationError(message string, err error) *AppError {
+	return New(ErrorTypeAuthentication, message, err)
+}
+
+func NewAuthorizationError(message string, err error) *AppError {
+	return New(ErrorTypeAuthorization, message, err)
+}
+
+func NewNotFoundError(message string, err error) *AppError {
+	return New(ErrorTypeNotFound, message, err)
+}
+
+func NewInternalError(message string, err error) *AppError {
+	return New(ErrorTypeInternal, message, err)
+}
+
+func NewConflictError(message string, err error) *AppError {
+	return New(ErrorTypeConflict, message, err)
+}
+
+func NewBadRequestError(message string, err error) *AppError {
+	return New(ErrorTypeBadRequest, message, err)
+}
+
+func NewRateLimitedError(message string, err error) *AppError {
+	return New(ErrorTypeRateLimited, message, err)
+}
+
+func NewUnavailableError(message string, err error) *AppError {
+	return New(ErrorTypeUnavailable, message, err)
+}
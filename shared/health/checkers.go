@@ -0,0 +1,68 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Pinger is implemented by *sql.DB.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// PingChecker checks a database connection pool.
+func PingChecker(db Pinger) Checker {
+	return CheckerFunc(db.PingContext)
+}
+
+// RedisChecker opens a connection to addr and expects PONG in reply to PING,
+// authenticating first when password is set. It needs no Redis client.
+func RedisChecker(addr, password string) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		} else {
+			conn.SetDeadline(time.Now().Add(5 * time.Second))
+		}
+
+		reader := bufio.NewReader(conn)
+		if password != "" {
+			if err := redisCommand(conn, reader, "+OK", "AUTH", password); err != nil {
+				return fmt.Errorf("redis auth failed: %w", err)
+			}
+		}
+		return redisCommand(conn, reader, "+PONG", "PING")
+	})
+}
+
+// redisCommand sends args as a RESP array and checks the one-line reply.
+func redisCommand(conn net.Conn, reader *bufio.Reader, want string, args ...string) error {
+	var cmd strings.Builder
+	fmt.Fprintf(&cmd, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&cmd, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := conn.Write([]byte(cmd.String())); err != nil {
+		return err
+	}
+
+	reply, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	reply = strings.TrimRight(reply, "\r\n")
+	if reply != want {
+		return fmt.Errorf("unexpected reply %q", reply)
+	}
+	return nil
+}
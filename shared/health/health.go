@@ -0,0 +1,261 @@
+// Package health runs dependency checks for liveness and readiness probes.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusUp Status = "up"
+	// StatusDegraded means a non-critical check failed; the service still
+	// accepts traffic.
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+	// StatusDraining means the service is shutting down and should receive
+	// no new traffic.
+	StatusDraining Status = "draining"
+)
+
+// Checker reports whether a dependency is usable. It should return promptly
+// once ctx is done.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to Checker.
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+type Config struct {
+	// CacheTTL is how long a report is reused before checks run again, so
+	// frequent probes do not hammer dependencies.
+	CacheTTL time.Duration
+	// Timeout bounds each check unless overridden when registering it.
+	Timeout time.Duration
+}
+
+// Option adjusts a registered check.
+type Option func(*check)
+
+// NonCritical marks a check whose failure degrades the service without
+// making it unready.
+func NonCritical() Option {
+	return func(c *check) { c.critical = false }
+}
+
+// WithTimeout overrides the default timeout for one check.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *check) { c.timeout = timeout }
+}
+
+type check struct {
+	name     string
+	checker  Checker
+	critical bool
+	timeout  time.Duration
+}
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+	Status   Status  `json:"status"`
+	Critical bool    `json:"critical"`
+	Duration float64 `json:"duration_ms"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// Report is the combined outcome of all checks.
+type Report struct {
+	Status    Status                 `json:"status"`
+	CheckedAt time.Time              `json:"checked_at"`
+	Checks    map[string]CheckResult `json:"checks"`
+}
+
+// Registry holds the registered checks and the latest report.
+type Registry struct {
+	config   Config
+	draining atomic.Bool
+
+	mu       sync.Mutex
+	checks   []check
+	cached   *Report
+	inflight chan struct{}
+}
+
+func New(config Config) *Registry {
+	if config.CacheTTL == 0 {
+		config.CacheTTL = 2 * time.Second
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 2 * time.Second
+	}
+	return &Registry{config: config}
+}
+
+// Register adds a check. Checks are critical unless NonCritical is given.
+func (r *Registry) Register(name string, checker Checker, opts ...Option) {
+	c := check{name: name, checker: checker, critical: true, timeout: r.config.Timeout}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, c)
+	r.cached = nil
+}
+
+// SetDraining marks the service as shutting down. Readiness fails from then
+// on, while liveness keeps passing so in-flight requests can finish.
+func (r *Registry) SetDraining() {
+	r.draining.Store(true)
+}
+
+// Report returns the latest report, running the checks if the cached one is
+// older than CacheTTL. Concurrent callers share a single run.
+func (r *Registry) Report(ctx context.Context) Report {
+	r.mu.Lock()
+	for {
+		if r.cached != nil && time.Since(r.cached.CheckedAt) < r.config.CacheTTL {
+			report := *r.cached
+			r.mu.Unlock()
+			return r.withDraining(report)
+		}
+		if r.inflight == nil {
+			break
+		}
+		wait := r.inflight
+		r.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return Report{Status: StatusDown, CheckedAt: time.Now().UTC()}
+		}
+		r.mu.Lock()
+	}
+	done := make(chan struct{})
+	r.inflight = done
+	checks := append([]check(nil), r.checks...)
+	r.mu.Unlock()
+
+	// The run is shared, so one caller going away must not cut it short.
+	report := run(context.WithoutCancel(ctx), checks)
+
+	r.mu.Lock()
+	r.cached = &report
+	r.inflight = nil
+	r.mu.Unlock()
+	close(done)
+
+	return r.withDraining(report)
+}
+
+func (r *Registry) withDraining(report Report) Report {
+	if r.draining.Load() {
+		report.Status = StatusDraining
+	}
+	return report
+}
+
+func run(ctx context.Context, checks []check) Report {
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c check) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := c.checker.Check(ctx)
+			result := CheckResult{
+				Status:   StatusUp,
+				Critical: c.critical,
+				Duration: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				result.Status = StatusDown
+				result.Error = err.Error()
+			}
+			results[i] = result
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:    StatusUp,
+		CheckedAt: time.Now().UTC(),
+		Checks:    make(map[string]CheckResult, len(checks)),
+	}
+	for i, c := range checks {
+		result := results[i]
+		report.Checks[c.name] = result
+		if result.Status == StatusUp {
+			continue
+		}
+		if c.critical {
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// LiveHandler answers liveness probes. It runs no checks: a failing
+// dependency is not fixed by restarting this process.
+func (r *Registry) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]Status{"status": StatusUp})
+	})
+}
+
+// ReadyHandler answers readiness probes with 200 while the service can take
+// traffic and 503 otherwise. Only the overall status is returned, so it is
+// safe to expose publicly.
+func (r *Registry) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var status Status
+		if r.draining.Load() {
+			status = StatusDraining
+		} else {
+			status = r.Report(req.Context()).Status
+		}
+		writeJSON(w, statusCode(status), map[string]Status{"status": status})
+	})
+}
+
+// ReportHandler serves the full report, including check errors. It is meant
+// for operators and should not be exposed publicly.
+func (r *Registry) ReportHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Report(req.Context())
+		writeJSON(w, statusCode(report.Status), report)
+	})
+}
+
+func statusCode(status Status) int {
+	switch status {
+	case StatusUp, StatusDegraded:
+		return http.StatusOK
+	default:
+		return http.StatusServiceUnavailable
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
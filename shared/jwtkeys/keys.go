@@ -0,0 +1,120 @@
+// Package jwtkeys holds the HMAC keys that sign and verify access tokens,
+// following rotations of the shared secret.
+package jwtkeys
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"http_server/shared/logging"
+	"http_server/shared/secrets"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenLifetime is how long access tokens stay valid. A retired key keeps
+// verifying for this long after a rotation, so no issued token is cut short.
+const TokenLifetime = 24 * time.Hour
+
+// Keys holds the key that signs new tokens and the keys accepted when
+// verifying them. Services that only verify tokens hold the same set, so a
+// rotation of the shared secret is accepted everywhere.
+type Keys struct {
+	mu       sync.RWMutex
+	current  []byte
+	previous []retiredKey
+}
+
+// retiredKey still verifies tokens signed before a rotation until they
+// have all expired.
+type retiredKey struct {
+	key   []byte
+	until time.Time
+}
+
+func New(key []byte) *Keys {
+	return &Keys{current: key}
+}
+
+// Rotate makes key the signing key. Tokens signed with the previous key
+// stay valid until they expire.
+func (k *Keys) Rotate(key []byte) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if bytes.Equal(key, k.current) {
+		return
+	}
+	k.previous = append(k.previous, retiredKey{key: k.current, until: time.Now().Add(TokenLifetime)})
+	k.current = key
+}
+
+// SigningKey returns the key new tokens are signed with.
+func (k *Keys) SigningKey() []byte {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.current
+}
+
+// VerificationKeys returns the signing key followed by the retired keys
+// whose tokens may still be valid, dropping the others.
+func (k *Keys) VerificationKeys() jwt.VerificationKeySet {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := time.Now()
+	active := k.previous[:0]
+	for _, retired := range k.previous {
+		if now.Before(retired.until) {
+			active = append(active, retired)
+		}
+	}
+	k.previous = active
+
+	keys := jwt.VerificationKeySet{Keys: []jwt.VerificationKey{k.current}}
+	for _, retired := range k.previous {
+		keys.Keys = append(keys.Keys, retired.key)
+	}
+	return keys
+}
+
+// Check signs a short-lived probe token with the signing key and verifies
+// it against the key set, so it fails when there is no usable key, for
+// instance after a rotation to an empty secret.
+func (k *Keys) Check() error {
+	key := k.SigningKey()
+	if len(key) == 0 {
+		return errors.New("no JWT signing key")
+	}
+
+	probe, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return fmt.Errorf("failed to sign probe token: %w", err)
+	}
+	_, err = jwt.Parse(probe, func(*jwt.Token) (interface{}, error) {
+		return k.VerificationKeys(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return fmt.Errorf("probe token does not verify: %w", err)
+	}
+	return nil
+}
+
+// Rotator returns the callback for secrets.Watch that rotates to a new
+// value of the secret. Outside development, as named by env, a placeholder
+// value is ignored and the current key kept.
+func (k *Keys) Rotator(env string, logger *logging.Logger) func(value string) {
+	return func(value string) {
+		if !secrets.IsDevelopment(env) && secrets.IsDefault(value) {
+			logger.Error("Ignoring JWT signing key rotation", errors.New("new key is a known default value"))
+			return
+		}
+		k.Rotate([]byte(value))
+		logger.Info("Rotated JWT signing key")
+	}
+}
@@ -0,0 +1,87 @@
+package jwtkeys
+
+import (
+	"testing"
+	"time"
+
+	"http_server/shared/logging"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func signWith(t *testing.T, key []byte) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func verifies(keys *Keys, token string) bool {
+	_, err := jwt.Parse(token, func(*jwt.Token) (interface{}, error) {
+		return keys.VerificationKeys(), nil
+	})
+	return err == nil
+}
+
+func TestKeysRotate(t *testing.T) {
+	keys := New([]byte("first-key"))
+	oldToken := signWith(t, keys.SigningKey())
+
+	keys.Rotate([]byte("second-key"))
+	if got := string(keys.SigningKey()); got != "second-key" {
+		t.Fatalf("signing key = %q, want second-key", got)
+	}
+	if !verifies(keys, oldToken) {
+		t.Error("token signed before the rotation no longer verifies")
+	}
+	if !verifies(keys, signWith(t, keys.SigningKey())) {
+		t.Error("token signed with the new key does not verify")
+	}
+
+	keys.previous[0].until = time.Now().Add(-time.Second)
+	if verifies(keys, oldToken) {
+		t.Error("retired key still verifies after its tokens expired")
+	}
+}
+
+func TestKeysCheck(t *testing.T) {
+	keys := New([]byte("signing-key"))
+	if err := keys.Check(); err != nil {
+		t.Fatalf("Check = %v, want nil", err)
+	}
+
+	keys.Rotate(nil)
+	if err := keys.Check(); err == nil {
+		t.Error("Check = nil after rotating to an empty key, want an error")
+	}
+}
+
+func TestRotatorRejectsDefaults(t *testing.T) {
+	logger, err := logging.NewLogger(&logging.Config{ServiceName: "test", LogLevel: "fatal", FilePath: "stderr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		env     string
+		key     string
+		rotated bool
+	}{
+		{"production", "a-real-signing-key", true},
+		{"production", "your-secret-key", false},
+		{"", "changeme", false},
+		{"development", "your-secret-key", true},
+		{"test", "test-secret-key", true},
+	}
+	for _, tt := range tests {
+		keys := New([]byte("initial-key"))
+		keys.Rotator(tt.env, logger)(tt.key)
+		if got := string(keys.SigningKey()) == tt.key; got != tt.rotated {
+			t.Errorf("rotating to %q in %q: rotated = %v, want %v", tt.key, tt.env, got, tt.rotated)
+		}
+	}
+}
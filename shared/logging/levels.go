@@ -0,0 +1,201 @@
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	apperrors "http_server/shared/errors"
+	"http_server/shared/problem"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// PackageLevel overrides the level for entries logged from Package, a Go
+// import path such as "http_server/auth-service/internal/grpcserver", and
+// the packages below it.
+type PackageLevel struct {
+	Package string
+	Level   string
+}
+
+type packageLevel struct {
+	prefix string
+	level  zapcore.Level
+}
+
+// levels decides which entries are written: the global level, unless a
+// package override matches the entry's caller.
+type levels struct {
+	global zap.AtomicLevel
+
+	mu       sync.Mutex
+	packages atomic.Pointer[[]packageLevel]
+}
+
+func newLevels(global zap.AtomicLevel, overrides []PackageLevel) (*levels, error) {
+	l := &levels{global: global}
+	l.packages.Store(&[]packageLevel{})
+	for _, override := range overrides {
+		if err := l.setPackage(override.Package, override.Level); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// setPackage adds or replaces the override for pkg; an empty level
+// removes it.
+func (l *levels) setPackage(pkg, level string) error {
+	var parsed zapcore.Level
+	if level != "" {
+		var err error
+		if parsed, err = zapcore.ParseLevel(level); err != nil {
+			return err
+		}
+	}
+	pkg = strings.TrimSuffix(pkg, "/")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	current := *l.packages.Load()
+	updated := make([]packageLevel, 0, len(current)+1)
+	for _, p := range current {
+		if p.prefix != pkg {
+			updated = append(updated, p)
+		}
+	}
+	if level != "" {
+		updated = append(updated, packageLevel{prefix: pkg, level: parsed})
+	}
+	// The most specific override wins
+	sort.Slice(updated, func(i, j int) bool { return len(updated[i].prefix) > len(updated[j].prefix) })
+	l.packages.Store(&updated)
+	return nil
+}
+
+// Enabled reports whether any entry at lvl could be written.
+func (l *levels) Enabled(lvl zapcore.Level) bool {
+	if l.global.Enabled(lvl) {
+		return true
+	}
+	for _, p := range *l.packages.Load() {
+		if p.level.Enabled(lvl) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *levels) forCaller(caller zapcore.EntryCaller) zapcore.Level {
+	packages := *l.packages.Load()
+	if len(packages) > 0 && caller.Defined {
+		pkg := packageOf(caller.Function)
+		for _, p := range packages {
+			if pkg == p.prefix || strings.HasPrefix(pkg, p.prefix+"/") {
+				return p.level
+			}
+		}
+	}
+	return l.global.Level()
+}
+
+// packageOf extracts the import path from a function name such as
+// "http_server/shared/logging.(*Logger).Info".
+func packageOf(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
+
+// levelCore applies the levels to the wrapped core. Package overrides
+// need the caller, which zap only fills in after Check, so entries are
+// filtered again in Write.
+type levelCore struct {
+	zapcore.Core
+	levels *levels
+}
+
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	return c.levels.Enabled(lvl)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *levelCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if !c.levels.forCaller(entry.Caller).Enabled(entry.Level) {
+		return nil
+	}
+	return c.Core.Write(entry, fields)
+}
+
+// levelState is the body of the level endpoint.
+type levelState struct {
+	Level    string            `json:"level"`
+	Packages map[string]string `json:"packages"`
+}
+
+// levelChange sets the global level, or the level of Package when given.
+// An empty Level removes a package override.
+type levelChange struct {
+	Package string `json:"package"`
+	Level   string `json:"level"`
+}
+
+// LevelHandler reports the levels on GET and changes them on PUT with a
+// body such as {"level":"debug"} or
+// {"package":"http_server/auth-service/internal/service","level":"debug"}.
+// It should only be reachable by operators.
+func (l *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var change levelChange
+			if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+				problem.Write(w, r, apperrors.NewBadRequestError("Invalid request body", err))
+				return
+			}
+			var err error
+			if change.Package != "" {
+				err = l.SetPackageLevel(change.Package, change.Level)
+			} else {
+				err = l.SetLevel(change.Level)
+			}
+			if err != nil {
+				problem.Write(w, r, apperrors.NewValidationError("Invalid log level", err))
+				return
+			}
+			l.Info("Log level changed", zap.String("package", change.Package), zap.String("level", change.Level))
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		state := levelState{
+			Level:    l.levels.global.Level().String(),
+			Packages: make(map[string]string),
+		}
+		for _, p := range *l.levels.packages.Load() {
+			state.Packages[p.prefix] = p.level.String()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(state)
+	})
+}
@@ -0,0 +1,228 @@
+// Package logging provides the zap-based logger shared by all services.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type Config struct {
+	ServiceName string
+	Environment string
+	LogLevel    string
+	// FilePath, MaxSize, MaxBackups, MaxAge, Compress and Format are used
+	// when Sinks is empty: FilePath "stdout" or "stderr" writes that stream
+	// in Format, any other path a rotated JSON file echoed to stdout.
+	FilePath   string
+	MaxSize    int
+	MaxBackups int
+	MaxAge     int
+	Compress   bool
+	Format     string
+	Output     string
+	TimeFormat string
+	// Sinks lists where entries are written.
+	Sinks []SinkConfig
+	// PackageLevels override LogLevel for entries logged from some
+	// packages.
+	PackageLevels []PackageLevel
+	// PIIFields names fields holding personal data, logged as an
+	// HMAC-SHA256 of the value keyed with PIIKey. Defaults to
+	// DefaultPIIFields. Tokens, passwords and similar secrets are always
+	// redacted.
+	PIIFields []string
+	// PIIKey keys the personal data hashes. Without it a random key is
+	// used, and hashes of the same value differ between processes.
+	PIIKey []byte
+}
+
+type Logger struct {
+	*zap.Logger
+	levels *levels
+}
+
+func NewLogger(config *Config) (*Logger, error) {
+	// Configure log level; it can be changed later with SetLevel
+	level := zap.NewAtomicLevel()
+	if err := level.UnmarshalText([]byte(config.LogLevel)); err != nil {
+		level.SetLevel(zapcore.InfoLevel)
+	}
+	levels, err := newLevels(level, config.PackageLevels)
+	if err != nil {
+		return nil, fmt.Errorf("invalid package log level: %w", err)
+	}
+
+	// Configure encoder
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "timestamp"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	if config.TimeFormat != "" {
+		encoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout(config.TimeFormat)
+	}
+
+	piiFields := config.PIIFields
+	if piiFields == nil {
+		piiFields = DefaultPIIFields
+	}
+	redactor, err := newRedactor(piiFields, config.PIIKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PII key: %w", err)
+	}
+
+	sinks := config.Sinks
+	if len(sinks) == 0 {
+		sinks = legacySinks(config)
+	}
+	cores := make([]zapcore.Core, 0, len(sinks))
+	for _, sink := range sinks {
+		core, err := newSinkCore(sink, config, encoderConfig, redactor)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, core)
+	}
+
+	// Create logger
+	logger := zap.New(&levelCore{Core: zapcore.NewTee(cores...), levels: levels},
+		zap.AddCaller(),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+		zap.Fields(
+			zap.String("environment", config.Environment),
+			zap.String("service", config.ServiceName),
+			zap.Time("boot_time", time.Now()),
+		),
+	)
+
+	return &Logger{Logger: logger, levels: levels}, nil
+}
+
+// SetLevel changes the minimum level of this logger and every logger
+// derived from it.
+func (l *Logger) SetLevel(level string) error {
+	parsed, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.levels.global.SetLevel(parsed)
+	return nil
+}
+
+// SetPackageLevel overrides the level for entries logged from pkg and the
+// packages below it. An empty level removes the override.
+func (l *Logger) SetPackageLevel(pkg, level string) error {
+	return l.levels.setPackage(pkg, level)
+}
+
+// Sampled returns a logger that, per message and level, writes the first
+// entries each second and then only every thereafter-th one. It suits
+// high-volume entries such as successful requests.
+func (l *Logger) Sampled(first, thereafter int) *Logger {
+	sampled := l.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewSamplerWithOptions(core, time.Second, first, thereafter)
+	}))
+	return &Logger{Logger: sampled, levels: l.levels}
+}
+
+type fieldsKey struct{}
+
+// ContextWithFields returns a copy of ctx carrying fields that WithContext
+// adds to every entry, e.g. the request ID set by the middleware.
+func ContextWithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	existing, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	merged := make([]zap.Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// WithContext returns a logger annotated with the fields stored in ctx and
+// the IDs of the active trace span, if any.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	if ctx == nil {
+		return l
+	}
+
+	existing, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	fields := make([]zap.Field, 0, len(existing)+2)
+	fields = append(fields, existing...)
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		fields = append(fields,
+			zap.String("trace_id", span.TraceID().String()),
+			zap.String("span_id", span.SpanID().String()),
+		)
+	}
+	if len(fields) == 0 {
+		return l
+	}
+
+	return &Logger{Logger: l.With(fields...), levels: l.levels}
+}
+
+// WithFields adds structured fields to the logger
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	zapFields := make([]zap.Field, 0, len(fields))
+	for k, v := range fields {
+		zapFields = append(zapFields, zap.Any(k, v))
+	}
+	return &Logger{Logger: l.With(zapFields...), levels: l.levels}
+}
+
+// Metrics logs metrics data in a format suitable for Grafana
+func (l *Logger) Metrics(name string, value interface{}, tags map[string]string) {
+	fields := make([]zap.Field, 0, len(tags)+2)
+	fields = append(fields,
+		zap.String("metric_name", name),
+		zap.Any("metric_value", value),
+	)
+
+	for k, v := range tags {
+		fields = append(fields, zap.String(fmt.Sprintf("tag_%s", k), v))
+	}
+
+	l.Info("metric", fields...)
+}
+
+// caller skips the wrapper methods below, so entries report the line that
+// called them, which the package levels rely on
+func (l *Logger) caller() *zap.Logger {
+	return l.Logger.WithOptions(zap.AddCallerSkip(1))
+}
+
+// Error logs an error message with stack trace
+func (l *Logger) Error(msg string, err error, fields ...zap.Field) {
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	l.caller().Error(msg, fields...)
+}
+
+// Fatal logs a fatal error message and exits
+func (l *Logger) Fatal(msg string, err error, fields ...zap.Field) {
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	l.caller().Fatal(msg, fields...)
+}
+
+// Debug logs a debug message
+func (l *Logger) Debug(msg string, fields ...zap.Field) {
+	if !l.Core().Enabled(zapcore.DebugLevel) {
+		return
+	}
+	l.caller().Debug(msg, fields...)
+}
+
+// Warn logs a warning message
+func (l *Logger) Warn(msg string, fields ...zap.Field) {
+	l.caller().Warn(msg, fields...)
+}
+
+// Sync flushes any buffered log entries
+func (l *Logger) Sync() error {
+	return l.Logger.Sync()
+}
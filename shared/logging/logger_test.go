@@ -0,0 +1,120 @@
+package logging
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// fileLogger logs to a JSON file sink and returns a function reading the
+// entries written so far.
+func fileLogger(t *testing.T, config Config) (*Logger, func() []map[string]interface{}) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.log")
+	config.ServiceName = "test"
+	config.Sinks = []SinkConfig{{Type: SinkFile, Path: path}}
+	logger, err := NewLogger(&config)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+
+	return logger, func() []map[string]interface{} {
+		logger.Sync()
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var entries []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(string(raw)), "\n") {
+			if line == "" {
+				continue
+			}
+			var entry map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("invalid entry %q: %v", line, err)
+			}
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+}
+
+func TestNewLoggerStreams(t *testing.T) {
+	for _, path := range []string{"", "stdout", "stderr"} {
+		if _, err := NewLogger(&Config{ServiceName: "test", FilePath: path}); err != nil {
+			t.Errorf("NewLogger with FilePath %q: %v", path, err)
+		}
+	}
+}
+
+func TestLoggerRedactsSecretsAndPII(t *testing.T) {
+	logger, entries := fileLogger(t, Config{LogLevel: "info"})
+	logger.Info("login",
+		zap.String("password", "hunter2"),
+		zap.String("refresh_token", "abc"),
+		zap.String("email", "someone@example.com"),
+		zap.String("user_id", "42"))
+
+	got := entries()
+	if len(got) != 1 {
+		t.Fatalf("entries = %d, want 1", len(got))
+	}
+	entry := got[0]
+	if entry["password"] != redactedValue || entry["refresh_token"] != redactedValue {
+		t.Errorf("secrets not redacted: %v", entry)
+	}
+	email, _ := entry["email"].(string)
+	if !strings.HasPrefix(email, "hmac:") || strings.Contains(email, "someone") {
+		t.Errorf("email = %q, want a hash", email)
+	}
+	if entry["user_id"] != "42" {
+		t.Errorf("user_id = %v, want 42", entry["user_id"])
+	}
+}
+
+func TestRedactorKeysPIIHashes(t *testing.T) {
+	hash := func(key []byte) string {
+		r, err := newRedactor([]string{"email"}, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, _ := r.redact("email", "someone@example.com")
+		return value
+	}
+
+	if hash([]byte("key-one")) != hash([]byte("key-one")) {
+		t.Error("the same key hashes a value differently")
+	}
+	if hash([]byte("key-one")) == hash([]byte("key-two")) {
+		t.Error("different keys hash a value the same")
+	}
+	if hash(nil) == hash(nil) {
+		t.Error("redactors without a key share a hash")
+	}
+}
+
+func TestLoggerLevels(t *testing.T) {
+	logger, entries := fileLogger(t, Config{LogLevel: "warn"})
+	logger.Info("hidden")
+	if err := logger.SetLevel("debug"); err != nil {
+		t.Fatal(err)
+	}
+	logger.Debug("shown")
+	if err := logger.SetPackageLevel("http_server/shared/logging", "error"); err != nil {
+		t.Fatal(err)
+	}
+	logger.Warn("hidden by package level")
+	logger.Error("shown by package level", nil)
+
+	var messages []string
+	for _, entry := range entries() {
+		messages = append(messages, entry["msg"].(string))
+	}
+	if want := "shown,shown by package level"; strings.Join(messages, ",") != want {
+		t.Errorf("messages = %v, want %s", messages, want)
+	}
+}
@@ -0,0 +1,244 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// otlpBatchSize records trigger an export before the next tick.
+	otlpBatchSize = 512
+	// otlpMaxQueue bounds the records held while the receiver is down;
+	// newer records are dropped beyond it.
+	otlpMaxQueue = 10000
+	otlpInterval = time.Second
+)
+
+// otlpExporter batches log records and posts them to an OTLP/HTTP
+// receiver as protobuf.
+type otlpExporter struct {
+	url      string
+	client   *http.Client
+	resource *resourcepb.Resource
+
+	mu      sync.Mutex
+	records []*logspb.LogRecord
+	full    chan struct{}
+}
+
+// otlpCore turns entries into OTLP log records. Fields become attributes,
+// and the trace_id and span_id fields set by WithContext link the record
+// to its trace.
+type otlpCore struct {
+	exporter *otlpExporter
+	redactor *redactor
+	fields   []zapcore.Field
+}
+
+func newOTLPCore(endpoint string, config *Config, redactor *redactor) zapcore.Core {
+	exporter := &otlpExporter{
+		url:    strings.TrimRight(endpoint, "/") + "/v1/logs",
+		client: &http.Client{Timeout: 10 * time.Second},
+		resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+			stringAttribute("service.name", config.ServiceName),
+			stringAttribute("deployment.environment", config.Environment),
+		}},
+		full: make(chan struct{}, 1),
+	}
+	go exporter.run()
+	return &otlpCore{exporter: exporter, redactor: redactor}
+}
+
+func (c *otlpCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *otlpCore) With(fields []zapcore.Field) zapcore.Core {
+	combined := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	combined = append(combined, c.fields...)
+	combined = append(combined, fields...)
+	return &otlpCore{exporter: c.exporter, redactor: c.redactor, fields: combined}
+}
+
+func (c *otlpCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, c)
+}
+
+func (c *otlpCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field, _ = c.redactor.redactField(field)
+		field.AddTo(enc)
+	}
+	for _, field := range fields {
+		field, _ = c.redactor.redactField(field)
+		field.AddTo(enc)
+	}
+
+	record := &logspb.LogRecord{
+		TimeUnixNano:         uint64(entry.Time.UnixNano()),
+		ObservedTimeUnixNano: uint64(time.Now().UnixNano()),
+		SeverityNumber:       severity(entry.Level),
+		SeverityText:         entry.Level.CapitalString(),
+		Body:                 &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: entry.Message}},
+	}
+	if traceID, ok := enc.Fields["trace_id"].(string); ok {
+		record.TraceId, _ = hex.DecodeString(traceID)
+		delete(enc.Fields, "trace_id")
+	}
+	if spanID, ok := enc.Fields["span_id"].(string); ok {
+		record.SpanId, _ = hex.DecodeString(spanID)
+		delete(enc.Fields, "span_id")
+	}
+	if entry.Caller.Defined {
+		enc.Fields["code.function"] = entry.Caller.Function
+		enc.Fields["code.filepath"] = entry.Caller.File
+		enc.Fields["code.lineno"] = int64(entry.Caller.Line)
+	}
+	if entry.Stack != "" {
+		enc.Fields["exception.stacktrace"] = entry.Stack
+	}
+
+	keys := make([]string, 0, len(enc.Fields))
+	for key := range enc.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		record.Attributes = append(record.Attributes, &commonpb.KeyValue{Key: key, Value: anyValue(enc.Fields[key])})
+	}
+
+	c.exporter.add(record)
+	return nil
+}
+
+func (c *otlpCore) Sync() error {
+	return c.exporter.flush()
+}
+
+func (e *otlpExporter) add(record *logspb.LogRecord) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.records) >= otlpMaxQueue {
+		return
+	}
+	e.records = append(e.records, record)
+	if len(e.records) >= otlpBatchSize {
+		select {
+		case e.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (e *otlpExporter) run() {
+	ticker := time.NewTicker(otlpInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-e.full:
+		}
+		if err := e.flush(); err != nil {
+			// The logger cannot log its own failures
+			fmt.Fprintf(os.Stderr, "failed to export logs: %v\n", err)
+		}
+	}
+}
+
+// flush posts the queued records. They are dropped when the export fails,
+// so a receiver outage cannot exhaust memory.
+func (e *otlpExporter) flush() error {
+	e.mu.Lock()
+	records := e.records
+	e.records = nil
+	e.mu.Unlock()
+	if len(records) == 0 {
+		return nil
+	}
+
+	body, err := proto.Marshal(&collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource:  e.resource,
+			ScopeLogs: []*logspb.ScopeLogs{{Scope: &commonpb.InstrumentationScope{Name: "http_server/shared/logging"}, LogRecords: records}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.client.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("log receiver returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func severity(level zapcore.Level) logspb.SeverityNumber {
+	switch level {
+	case zapcore.DebugLevel:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG
+	case zapcore.InfoLevel:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_INFO
+	case zapcore.WarnLevel:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_WARN
+	case zapcore.ErrorLevel:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_ERROR
+	default:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_FATAL
+	}
+}
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+// anyValue converts a value produced by zapcore.MapObjectEncoder.
+func anyValue(v interface{}) *commonpb.AnyValue {
+	switch v := v.(type) {
+	case string:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}
+	case bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v}}
+	case int64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v}}
+	case int:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case float64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v}}
+	case []interface{}:
+		values := make([]*commonpb.AnyValue, len(v))
+		for i, item := range v {
+			values[i] = anyValue(item)
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}
+	default:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: fmt.Sprint(v)}}
+	}
+}
@@ -0,0 +1,180 @@
+package logging
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactedValue replaces secrets in log output.
+const redactedValue = "[REDACTED]"
+
+// secretKeys are field names whose values are always removed, matched
+// case-insensitively.
+var secretKeys = []string{
+	"password",
+	"new_password",
+	"current_password",
+	"token",
+	"access_token",
+	"refresh_token",
+	"id_token",
+	"authorization",
+	"auth_header",
+	"cookie",
+	"set-cookie",
+	"secret",
+	"client_secret",
+	"api_key",
+}
+
+// DefaultPIIFields are hashed unless Config.PIIFields says otherwise.
+var DefaultPIIFields = []string{"email"}
+
+// redactor removes secrets and pseudonymizes personal data by field name.
+// It only inspects top-level field names; values nested inside objects are
+// left alone.
+type redactor struct {
+	secret map[string]bool
+	pii    map[string]bool
+	piiKey []byte
+}
+
+// newRedactor keys the personal data hashes with piiKey. Without a key a
+// random one is used, so hashes only correlate within this process.
+func newRedactor(piiFields []string, piiKey []byte) (*redactor, error) {
+	if len(piiKey) == 0 {
+		piiKey = make([]byte, 32)
+		if _, err := rand.Read(piiKey); err != nil {
+			return nil, err
+		}
+	}
+	r := &redactor{
+		secret: make(map[string]bool, len(secretKeys)),
+		pii:    make(map[string]bool, len(piiFields)),
+		piiKey: piiKey,
+	}
+	for _, key := range secretKeys {
+		r.secret[key] = true
+	}
+	for _, key := range piiFields {
+		r.pii[strings.ToLower(key)] = true
+	}
+	return r, nil
+}
+
+// redact returns the replacement for the value of key, if it needs one.
+// Personal data is replaced by a keyed hash, so entries about the same
+// person can still be correlated, while someone reading the logs without
+// the key cannot confirm a guessed value.
+func (r *redactor) redact(key, value string) (string, bool) {
+	key = strings.ToLower(key)
+	switch {
+	case r.secret[key]:
+		return redactedValue, true
+	case r.pii[key]:
+		mac := hmac.New(sha256.New, r.piiKey)
+		mac.Write([]byte(value))
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil)[:16]), true
+	default:
+		return "", false
+	}
+}
+
+func (r *redactor) redactField(field zapcore.Field) (zapcore.Field, bool) {
+	value, ok := r.redact(field.Key, field.String)
+	if !ok {
+		return field, false
+	}
+	if field.Type != zapcore.StringType {
+		value = redactedValue
+	}
+	return zap.String(field.Key, value), true
+}
+
+// redactingEncoder applies a redactor before the wrapped encoder sees the
+// fields.
+type redactingEncoder struct {
+	zapcore.Encoder
+	*redactor
+}
+
+func newRedactingEncoder(enc zapcore.Encoder, redactor *redactor) zapcore.Encoder {
+	return &redactingEncoder{Encoder: enc, redactor: redactor}
+}
+
+func (r *redactingEncoder) Clone() zapcore.Encoder {
+	return &redactingEncoder{Encoder: r.Encoder.Clone(), redactor: r.redactor}
+}
+
+// EncodeEntry handles the fields passed with the entry; fields added with
+// With go through the Add methods below.
+func (r *redactingEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	copied := false
+	for i, field := range fields {
+		replacement, ok := r.redactField(field)
+		if !ok {
+			continue
+		}
+		// The caller's slice must not be modified
+		if !copied {
+			fields = append([]zapcore.Field(nil), fields...)
+			copied = true
+		}
+		fields[i] = replacement
+	}
+	return r.Encoder.EncodeEntry(entry, fields)
+}
+
+func (r *redactingEncoder) AddString(key, value string) {
+	if replacement, ok := r.redact(key, value); ok {
+		value = replacement
+	}
+	r.Encoder.AddString(key, value)
+}
+
+func (r *redactingEncoder) AddByteString(key string, value []byte) {
+	if replacement, ok := r.redact(key, string(value)); ok {
+		r.Encoder.AddString(key, replacement)
+		return
+	}
+	r.Encoder.AddByteString(key, value)
+}
+
+func (r *redactingEncoder) AddBinary(key string, value []byte) {
+	if _, ok := r.redact(key, ""); ok {
+		r.Encoder.AddString(key, redactedValue)
+		return
+	}
+	r.Encoder.AddBinary(key, value)
+}
+
+func (r *redactingEncoder) AddReflected(key string, value interface{}) error {
+	if _, ok := r.redact(key, ""); ok {
+		r.Encoder.AddString(key, redactedValue)
+		return nil
+	}
+	return r.Encoder.AddReflected(key, value)
+}
+
+func (r *redactingEncoder) AddObject(key string, value zapcore.ObjectMarshaler) error {
+	if _, ok := r.redact(key, ""); ok {
+		r.Encoder.AddString(key, redactedValue)
+		return nil
+	}
+	return r.Encoder.AddObject(key, value)
+}
+
+func (r *redactingEncoder) AddArray(key string, value zapcore.ArrayMarshaler) error {
+	if _, ok := r.redact(key, ""); ok {
+		r.Encoder.AddString(key, redactedValue)
+		return nil
+	}
+	return r.Encoder.AddArray(key, value)
+}
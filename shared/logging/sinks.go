@@ -0,0 +1,173 @@
+package logging
+
+import (
+	"fmt"
+	"log/syslog"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Sink types accepted in SinkConfig.Type.
+const (
+	SinkStdout = "stdout"
+	SinkStderr = "stderr"
+	SinkFile   = "file"
+	SinkSyslog = "syslog"
+	SinkOTLP   = "otlp"
+)
+
+// SinkConfig is one destination for log entries.
+type SinkConfig struct {
+	Type string
+	// Format is "json" (default) or "console". OTLP sinks ignore it.
+	Format string
+
+	// Path, MaxSize (megabytes), MaxBackups, MaxAge (days) and Compress
+	// configure a file sink, which is rotated by size.
+	Path       string
+	MaxSize    int
+	MaxBackups int
+	MaxAge     int
+	Compress   bool
+
+	// Network ("udp" or "tcp") and Address locate a syslog server. Entries
+	// are tagged with the service name.
+	Network string
+	Address string
+
+	// Endpoint is the base URL of an OTLP/HTTP receiver, e.g.
+	// "http://otel-collector:4318"; entries are posted to /v1/logs.
+	Endpoint string
+}
+
+// legacySinks keeps the behaviour of configs without sinks: FilePath
+// "stdout" or "stderr" selects that stream, anything else is a rotated JSON
+// file that is also echoed to stdout.
+func legacySinks(config *Config) []SinkConfig {
+	switch config.FilePath {
+	case "", SinkStdout:
+		return []SinkConfig{{Type: SinkStdout, Format: config.Format}}
+	case SinkStderr:
+		return []SinkConfig{{Type: SinkStderr, Format: config.Format}}
+	}
+	return []SinkConfig{
+		{
+			Type:       SinkFile,
+			Format:     "json",
+			Path:       config.FilePath,
+			MaxSize:    config.MaxSize,
+			MaxBackups: config.MaxBackups,
+			MaxAge:     config.MaxAge,
+			Compress:   config.Compress,
+		},
+		{Type: SinkStdout, Format: "console"},
+	}
+}
+
+// newSinkCore builds the core writing to one sink. Level filtering is left
+// to the levelCore wrapping all sinks.
+func newSinkCore(sink SinkConfig, config *Config, encoderConfig zapcore.EncoderConfig, redactor *redactor) (zapcore.Core, error) {
+	if sink.Type == SinkOTLP {
+		if sink.Endpoint == "" {
+			return nil, fmt.Errorf("otlp log sink needs an endpoint")
+		}
+		return newOTLPCore(sink.Endpoint, config, redactor), nil
+	}
+
+	var encoder zapcore.Encoder
+	switch sink.Format {
+	case "", "json":
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case "console":
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", sink.Format)
+	}
+	encoder = newRedactingEncoder(encoder, redactor)
+
+	switch sink.Type {
+	case SinkStdout:
+		return zapcore.NewCore(encoder, zapcore.Lock(os.Stdout), zapcore.DebugLevel), nil
+	case SinkStderr:
+		return zapcore.NewCore(encoder, zapcore.Lock(os.Stderr), zapcore.DebugLevel), nil
+	case SinkFile:
+		if sink.Path == "" {
+			return nil, fmt.Errorf("file log sink needs a path")
+		}
+		if err := os.MkdirAll(filepath.Dir(sink.Path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create log directory: %v", err)
+		}
+		rotator := &lumberjack.Logger{
+			Filename:   sink.Path,
+			MaxSize:    sink.MaxSize,
+			MaxBackups: sink.MaxBackups,
+			MaxAge:     sink.MaxAge,
+			Compress:   sink.Compress,
+		}
+		return zapcore.NewCore(encoder, zapcore.AddSync(rotator), zapcore.DebugLevel), nil
+	case SinkSyslog:
+		network := sink.Network
+		if network == "" {
+			network = "udp"
+		}
+		writer, err := syslog.Dial(network, sink.Address, syslog.LOG_INFO|syslog.LOG_DAEMON, config.ServiceName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+		}
+		return &syslogCore{encoder: encoder, writer: writer}, nil
+	default:
+		return nil, fmt.Errorf("unknown log sink %q", sink.Type)
+	}
+}
+
+// syslogCore sends each entry as one syslog message with the severity
+// matching its level.
+type syslogCore struct {
+	encoder zapcore.Encoder
+	writer  *syslog.Writer
+}
+
+func (c *syslogCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &syslogCore{encoder: c.encoder.Clone(), writer: c.writer}
+	for _, field := range fields {
+		field.AddTo(clone.encoder)
+	}
+	return clone
+}
+
+func (c *syslogCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, c)
+}
+
+func (c *syslogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+
+	msg := buf.String()
+	switch {
+	case entry.Level >= zapcore.DPanicLevel:
+		return c.writer.Crit(msg)
+	case entry.Level == zapcore.ErrorLevel:
+		return c.writer.Err(msg)
+	case entry.Level == zapcore.WarnLevel:
+		return c.writer.Warning(msg)
+	case entry.Level == zapcore.InfoLevel:
+		return c.writer.Info(msg)
+	default:
+		return c.writer.Debug(msg)
+	}
+}
+
+func (c *syslogCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,75 @@
+// Package metrics provides the Prometheus collectors common to every HTTP
+// service.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// HTTPMetrics holds the request collectors fed by middleware.Metrics.
+type HTTPMetrics struct {
+	Requests *prometheus.CounterVec
+	Duration *prometheus.HistogramVec
+	InFlight prometheus.Gauge
+}
+
+// NewHTTPMetrics creates the collectors under namespace and registers them
+// with reg, or with the default registry if reg is nil.
+func NewHTTPMetrics(namespace string, reg prometheus.Registerer) *HTTPMetrics {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+
+	m := &HTTPMetrics{
+		Requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Total number of HTTP requests",
+		}, []string{"route", "method", "status"}),
+		Duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of HTTP requests in seconds",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"route", "method", "status"}),
+		InFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "Number of HTTP requests being served",
+		}),
+	}
+	reg.MustRegister(m.Requests, m.Duration, m.InFlight)
+	return m
+}
+
+// Observe records one completed request.
+func (m *HTTPMetrics) Observe(route, method, status string, duration time.Duration) {
+	labels := prometheus.Labels{"route": route, "method": method, "status": status}
+	m.Requests.With(labels).Inc()
+	m.Duration.With(labels).Observe(duration.Seconds())
+}
+
+// NewRegistry returns a registry with the Go runtime and process collectors
+// registered, for services that keep their metrics off the default registry.
+func NewRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return reg
+}
+
+// Handler serves the metrics in gatherer, or the default registry if it is
+// nil.
+func Handler(gatherer prometheus.Gatherer) http.Handler {
+	if gatherer == nil {
+		gatherer = prometheus.DefaultGatherer
+	}
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	apperrors "http_server/shared/errors"
+	"http_server/shared/jwtkeys"
+	"http_server/shared/logging"
+	"http_server/shared/problem"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// jwtContextKey is a custom type for auth-related context keys to avoid
+// collisions.
+type jwtContextKey string
+
+const (
+	userIDKey = jwtContextKey("user_id")
+	rolesKey  = jwtContextKey("roles")
+)
+
+var (
+	errMissingAuthorization = apperrors.NewAuthenticationError("Authorization header required", nil).WithCode("missing_authorization")
+	errInvalidAuthorization = apperrors.NewAuthenticationError("Invalid authorization format", nil).WithCode("invalid_authorization")
+	errTokenExpired         = apperrors.NewAuthenticationError("Token has expired", nil).WithCode("token_expired")
+	errInvalidToken         = apperrors.NewAuthenticationError("Invalid token", nil).WithCode("invalid_token")
+)
+
+// JWTAuth verifies access tokens issued by auth-service. Tokens are
+// HMAC-signed with the secret shared between the services, so no call to
+// auth-service is needed per request. Tokens signed with a key retired by a
+// rotation are accepted until they expire.
+type JWTAuth struct {
+	keys   *jwtkeys.Keys
+	logger *logging.Logger
+}
+
+func NewJWTAuth(keys *jwtkeys.Keys, logger *logging.Logger) *JWTAuth {
+	return &JWTAuth{
+		keys:   keys,
+		logger: logger,
+	}
+}
+
+// ValidateJWT rejects requests without a valid bearer token and stores the
+// user ID and roles from it in the request context.
+func (a *JWTAuth) ValidateJWT(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := a.logger.WithContext(r.Context())
+
+		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" {
+			logger.Warn("Missing authorization header")
+			problem.Write(w, r, errMissingAuthorization)
+			return
+		}
+
+		bearerToken := strings.Split(authHeader, " ")
+		if len(bearerToken) != 2 || bearerToken[0] != "Bearer" {
+			logger.Warn("Invalid authorization format")
+			problem.Write(w, r, errInvalidAuthorization)
+			return
+		}
+
+		userID, roles, err := a.parseToken(bearerToken[1])
+		if err != nil {
+			logger.Warn("Token validation failed", zap.Error(err))
+			if errors.Is(err, jwt.ErrTokenExpired) {
+				problem.Write(w, r, errTokenExpired)
+				return
+			}
+			problem.Write(w, r, errInvalidToken)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userIDKey, userID)
+		ctx = context.WithValue(ctx, rolesKey, roles)
+		ctx = logging.ContextWithFields(ctx, zap.String("user_id", userID.String()))
+		AnnotateAccessLog(ctx, zap.String("user_id", userID.String()))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// OptionalJWT authenticates the request when it carries an Authorization
+// header and lets anonymous requests through. A header that is present but
+// invalid is still rejected.
+func (a *JWTAuth) OptionalJWT(next http.Handler) http.Handler {
+	validate := a.ValidateJWT(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		validate.ServeHTTP(w, r)
+	})
+}
+
+func (a *JWTAuth) parseToken(tokenString string) (uuid.UUID, []string, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errInvalidToken
+		}
+		return a.keys.VerificationKeys(), nil
+	}, jwt.WithExpirationRequired())
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return uuid.Nil, nil, errInvalidToken
+	}
+
+	rawID, ok := claims["user_id"].(string)
+	if !ok {
+		return uuid.Nil, nil, errInvalidToken
+	}
+	userID, err := uuid.Parse(rawID)
+	if err != nil {
+		return uuid.Nil, nil, errInvalidToken
+	}
+
+	var roles []string
+	if rawRoles, ok := claims["roles"].([]interface{}); ok {
+		for _, role := range rawRoles {
+			if name, ok := role.(string); ok {
+				roles = append(roles, name)
+			}
+		}
+	}
+
+	return userID, roles, nil
+}
+
+// UserIDFromContext returns the authenticated user set by ValidateJWT.
+func UserIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	userID, ok := ctx.Value(userIDKey).(uuid.UUID)
+	return userID, ok
+}
+
+// RolesFromContext returns the roles of the authenticated user set by
+// ValidateJWT.
+func RolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesKey).([]string)
+	return roles
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"http_server/shared/jwtkeys"
+	"http_server/shared/logging"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var testKey = []byte("test-signing-key")
+
+func newTestLogger(t *testing.T) *logging.Logger {
+	t.Helper()
+	logger, err := logging.NewLogger(&logging.Config{ServiceName: "test", LogLevel: "error", FilePath: "stderr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+func testToken(t *testing.T, key []byte, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestValidateJWT(t *testing.T) {
+	userID := uuid.New()
+	valid := jwt.MapClaims{"user_id": userID.String(), "roles": []string{"user"}, "exp": time.Now().Add(time.Hour).Unix()}
+	expired := jwt.MapClaims{"user_id": userID.String(), "exp": time.Now().Add(-time.Hour).Unix()}
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantCode   string
+	}{
+		{"valid", "Bearer " + testToken(t, testKey, valid), http.StatusOK, ""},
+		{"missing", "", http.StatusUnauthorized, "missing_authorization"},
+		{"not bearer", "Basic abc", http.StatusUnauthorized, "invalid_authorization"},
+		{"expired", "Bearer " + testToken(t, testKey, expired), http.StatusUnauthorized, "token_expired"},
+		{"wrong key", "Bearer " + testToken(t, []byte("other-key"), valid), http.StatusUnauthorized, "invalid_token"},
+		{"no user", "Bearer " + testToken(t, testKey, jwt.MapClaims{"exp": time.Now().Add(time.Hour).Unix()}), http.StatusUnauthorized, "invalid_token"},
+	}
+
+	auth := NewJWTAuth(jwtkeys.New(testKey), newTestLogger(t))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUser uuid.UUID
+			var gotRoles []string
+			handler := auth.ValidateJWT(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUser, _ = UserIDFromContext(r.Context())
+				gotRoles = RolesFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantCode == "" {
+				if gotUser != userID || len(gotRoles) != 1 || gotRoles[0] != "user" {
+					t.Errorf("context user = %v, roles = %v", gotUser, gotRoles)
+				}
+				return
+			}
+			var body struct {
+				Code string `json:"code"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestValidateJWTAfterRotation(t *testing.T) {
+	keys := jwtkeys.New(testKey)
+	auth := NewJWTAuth(keys, newTestLogger(t))
+	handler := auth.ValidateJWT(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	claims := jwt.MapClaims{"user_id": uuid.NewString(), "exp": time.Now().Add(time.Hour).Unix()}
+	oldToken := testToken(t, testKey, claims)
+
+	keys.Rotate([]byte("rotated-signing-key"))
+	for name, token := range map[string]string{
+		"retired key": oldToken,
+		"new key":     testToken(t, []byte("rotated-signing-key"), claims),
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", name, rec.Code)
+		}
+	}
+}
+
+func TestOptionalJWT(t *testing.T) {
+	auth := NewJWTAuth(jwtkeys.New(testKey), newTestLogger(t))
+	handler := auth.OptionalJWT(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := UserIDFromContext(r.Context()); ok {
+			t.Error("anonymous request has a user")
+		}
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("anonymous status = %d, want 200", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer invalid")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("invalid token status = %d, want 401", rec.Code)
+	}
+}
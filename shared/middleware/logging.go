@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"http_server/shared/logging"
+
+	"go.uber.org/zap"
+)
+
+// LoggingOption adjusts the access log.
+type LoggingOption func(*loggingOptions)
+
+type loggingOptions struct {
+	sampleFirst      int
+	sampleThereafter int
+}
+
+// SampleSuccess logs only the first successful requests each second and
+// every thereafter-th one after that. Failed requests are always logged.
+func SampleSuccess(first, thereafter int) LoggingOption {
+	return func(o *loggingOptions) {
+		o.sampleFirst = first
+		o.sampleThereafter = thereafter
+	}
+}
+
+// accessLogFields collects fields added by inner handlers, which cannot
+// change the context the access log sees.
+type accessLogFields struct {
+	mu     sync.Mutex
+	fields []zap.Field
+}
+
+type accessLogKey struct{}
+
+// AnnotateAccessLog adds fields to the access log entry of the request
+// carrying ctx, e.g. the user ID once the caller is authenticated.
+func AnnotateAccessLog(ctx context.Context, fields ...zap.Field) {
+	if entry, ok := ctx.Value(accessLogKey{}).(*accessLogFields); ok {
+		entry.mu.Lock()
+		entry.fields = append(entry.fields, fields...)
+		entry.mu.Unlock()
+	}
+}
+
+// Logging writes the access log: one entry per request once it completes,
+// with the request and trace IDs from the context and the user ID when an
+// inner handler has set it with AnnotateAccessLog. Requests that fail with
+// a server error are logged at warn level; the handler logs the cause.
+func Logging(logger *logging.Logger, opts ...LoggingOption) func(http.Handler) http.Handler {
+	var options loggingOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	successLogger := logger
+	if options.sampleFirst > 0 {
+		successLogger = logger.Sampled(options.sampleFirst, options.sampleThereafter)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := wrapResponseWriter(w)
+			entry := &accessLogFields{}
+
+			next.ServeHTTP(rw, r.WithContext(context.WithValue(r.Context(), accessLogKey{}, entry)))
+
+			entry.mu.Lock()
+			fields := append([]zap.Field{
+				zap.String("method", r.Method),
+				zap.String("route", RouteTemplate(r)),
+				zap.Int("status", rw.status),
+				zap.Int("bytes", rw.bytes),
+				zap.Duration("latency", time.Since(start)),
+				zap.String("remote_addr", r.RemoteAddr),
+				zap.String("user_agent", r.UserAgent()),
+			}, entry.fields...)
+			entry.mu.Unlock()
+
+			switch {
+			case rw.status >= http.StatusInternalServerError:
+				logger.WithContext(r.Context()).Warn("request_completed", fields...)
+			case rw.status >= http.StatusBadRequest:
+				logger.WithContext(r.Context()).Info("request_completed", fields...)
+			default:
+				successLogger.WithContext(r.Context()).Info("request_completed", fields...)
+			}
+		})
+	}
+}
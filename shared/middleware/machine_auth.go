@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+
+	apperrors "http_server/shared/errors"
+	"http_server/shared/logging"
+	"http_server/shared/problem"
+
+	"go.uber.org/zap"
+)
+
+// machinePrincipalKey holds the principal of a service authenticated by
+// its client certificate.
+const machinePrincipalKey = jwtContextKey("machine_principal")
+
+var (
+	errClientCertRequired = apperrors.NewAuthenticationError("Client certificate required", nil).WithCode("client_certificate_required")
+	errUnknownClientCert  = apperrors.NewAuthorizationError("Client certificate not authorized", nil).WithCode("unknown_client_certificate")
+)
+
+// MachineAuth authenticates other services by the client certificate they
+// presented over mutual TLS. The certificate chain is verified by the TLS
+// listener; MachineAuth only maps a verified certificate to a principal.
+type MachineAuth struct {
+	principals map[string]string
+	logger     *logging.Logger
+}
+
+// NewMachineAuth maps certificate identities (URI SAN, DNS SAN or common
+// name) to principal names.
+func NewMachineAuth(principals map[string]string, logger *logging.Logger) *MachineAuth {
+	return &MachineAuth{
+		principals: principals,
+		logger:     logger,
+	}
+}
+
+// RequireClientCert rejects requests without a verified client certificate
+// mapped to a known principal.
+func (m *MachineAuth) RequireClientCert(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := m.logger.WithContext(r.Context())
+
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			logger.Warn("Missing verified client certificate", zap.String("path", r.URL.Path))
+			problem.Write(w, r, errClientCertRequired)
+			return
+		}
+
+		leaf := r.TLS.VerifiedChains[0][0]
+		principal, ok := m.Principal(leaf)
+		if !ok {
+			logger.Warn("Client certificate not mapped to a principal",
+				zap.Strings("identities", certIdentities(leaf)))
+			problem.Write(w, r, errUnknownClientCert)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), machinePrincipalKey, principal)
+		ctx = logging.ContextWithFields(ctx, zap.String("machine_principal", principal))
+		AnnotateAccessLog(ctx, zap.String("machine_principal", principal))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Principal returns the principal a verified client certificate maps to.
+func (m *MachineAuth) Principal(cert *x509.Certificate) (string, bool) {
+	for _, identity := range certIdentities(cert) {
+		if principal, ok := m.principals[identity]; ok {
+			return principal, true
+		}
+	}
+	return "", false
+}
+
+// certIdentities lists the names a certificate can be matched by, most
+// specific first.
+func certIdentities(cert *x509.Certificate) []string {
+	var identities []string
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	identities = append(identities, cert.DNSNames...)
+	if cert.Subject.CommonName != "" {
+		identities = append(identities, cert.Subject.CommonName)
+	}
+	return identities
+}
+
+// MachinePrincipalFromContext returns the principal authenticated by
+// RequireClientCert.
+func MachinePrincipalFromContext(ctx context.Context) (string, bool) {
+	principal, ok := ctx.Value(machinePrincipalKey).(string)
+	return principal, ok
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"http_server/shared/metrics"
+)
+
+// Metrics records request counts, latencies and in-flight requests, labelled
+// by route template rather than raw path.
+func Metrics(m *metrics.HTTPMetrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			m.InFlight.Inc()
+			defer m.InFlight.Dec()
+
+			rw := wrapResponseWriter(w)
+			next.ServeHTTP(rw, r)
+
+			m.Observe(RouteTemplate(r), r.Method, strconv.Itoa(rw.status), time.Since(start))
+		})
+	}
+}
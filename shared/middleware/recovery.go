@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	apperrors "http_server/shared/errors"
+	"http_server/shared/logging"
+	"http_server/shared/problem"
+
+	"go.uber.org/zap"
+)
+
+// Recovery turns a panic in the next handler into a logged 500 problem so
+// one bad request cannot take the process down.
+func Recovery(logger *logging.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := wrapResponseWriter(w)
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				logger.WithContext(r.Context()).Error("Recovered from panic",
+					fmt.Errorf("panic: %v", recovered),
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+					zap.Stack("stack"),
+				)
+
+				if !rw.wroteHeader {
+					problem.Write(rw, r, apperrors.NewInternalError("Internal server error", nil))
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
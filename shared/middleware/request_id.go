@@ -0,0 +1,60 @@
+// Package middleware provides HTTP middleware shared by all services. The
+// intended order, outermost first, is RequestID, Tracing, Logging, Metrics
+// and Recovery.
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"http_server/shared/logging"
+	"http_server/shared/requestid"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader carries the request ID between services and back to
+// clients.
+const RequestIDHeader = requestid.Header
+
+// maxRequestIDLength bounds IDs accepted from callers.
+const maxRequestIDLength = 128
+
+// RequestID reuses a well-formed incoming X-Request-ID or generates a new
+// one, echoes it in the response and stores it in the request context and in
+// the logger fields.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		ctx := requestid.NewContext(r.Context(), requestID)
+		ctx = logging.ContextWithFields(ctx, zap.String("request_id", requestID))
+		w.Header().Set(RequestIDHeader, requestID)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIDFromContext returns the ID assigned by RequestID.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	return requestid.FromContext(ctx)
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a server span for every request, continuing the trace
+// propagated by the caller. It uses the global tracer provider and
+// propagator, so it is a no-op until those are configured.
+func Tracing(serviceName string) func(http.Handler) http.Handler {
+	tracer := otel.Tracer(serviceName)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			route := RouteTemplate(r)
+
+			ctx, span := tracer.Start(ctx, fmt.Sprintf("%s %s", r.Method, route),
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("http.route", route),
+					attribute.String("url.path", r.URL.Path),
+				),
+			)
+			defer span.End()
+
+			if requestID, ok := RequestIDFromContext(ctx); ok {
+				span.SetAttributes(attribute.String("http.request_id", requestID))
+			}
+
+			rw := wrapResponseWriter(w)
+			next.ServeHTTP(rw, r.WithContext(ctx))
+
+			span.SetAttributes(attribute.Int("http.response.status_code", rw.status))
+			if rw.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(rw.status))
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// Package pagination turns keyset positions into opaque page cursors.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidCursor is returned for a cursor that was not produced by
+// EncodeCursor.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor turns a keyset position into an opaque token for clients.
+func EncodeCursor[T any](pos T) string {
+	raw, _ := json.Marshal(pos)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a token produced by EncodeCursor. An empty token means
+// the first page and yields a nil position.
+func DecodeCursor[T any](cursor string) (*T, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var pos T
+	if err := json.Unmarshal(raw, &pos); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &pos, nil
+}
+
+// ClampLimit applies the default page size to a missing limit and caps it at
+// the maximum.
+func ClampLimit(limit, defaultSize, maxSize int) int {
+	if limit <= 0 {
+		return defaultSize
+	}
+	if limit > maxSize {
+		return maxSize
+	}
+	return limit
+}
@@ -0,0 +1,54 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type position struct {
+	At time.Time `json:"at"`
+	ID uuid.UUID `json:"id"`
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := position{At: time.Date(2024, 3, 1, 12, 0, 0, 5, time.UTC), ID: uuid.New()}
+
+	got, err := DecodeCursor[position](EncodeCursor(want))
+	if err != nil {
+		t.Fatalf("DecodeCursor: %v", err)
+	}
+	if !got.At.Equal(want.At) || got.ID != want.ID {
+		t.Errorf("DecodeCursor = %+v, want %+v", *got, want)
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	if pos, err := DecodeCursor[position](""); pos != nil || err != nil {
+		t.Errorf("DecodeCursor(\"\") = %v, %v, want the first page", pos, err)
+	}
+	for _, cursor := range []string{"not base64!", "bm90IGpzb24", EncodeCursor("a string")} {
+		if _, err := DecodeCursor[position](cursor); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("DecodeCursor(%q) = %v, want ErrInvalidCursor", cursor, err)
+		}
+	}
+}
+
+func TestClampLimit(t *testing.T) {
+	tests := []struct {
+		limit, want int
+	}{
+		{0, 20},
+		{-5, 20},
+		{1, 1},
+		{100, 100},
+		{101, 100},
+	}
+	for _, tt := range tests {
+		if got := ClampLimit(tt.limit, 20, 100); got != tt.want {
+			t.Errorf("ClampLimit(%d) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Package problem writes errors as RFC 9457 application/problem+json
+// responses. Only the client-facing parts of an AppError are rendered: the
+// wrapped error never is, and server errors get a generic detail.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	apperrors "http_server/shared/errors"
+	"http_server/shared/requestid"
+)
+
+// ContentType is the media type of problem responses.
+const ContentType = "application/problem+json"
+
+// TypePrefix prefixes the error code to form the problem type URI.
+const TypePrefix = "urn:problem-type:"
+
+const (
+	internalCode   = "internal_error"
+	internalDetail = "An unexpected error occurred"
+)
+
+// Problem is an RFC 9457 problem details object. Code, RequestID and Errors
+// are extension members.
+type Problem struct {
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Status    int                    `json:"status"`
+	Detail    string                 `json:"detail,omitempty"`
+	Instance  string                 `json:"instance,omitempty"`
+	Code      string                 `json:"code"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Errors    []apperrors.FieldError `json:"errors,omitempty"`
+}
+
+// New builds the problem for err. Errors that are not AppErrors, and
+// AppErrors with a 5xx status, are reported as a generic internal error.
+func New(r *http.Request, err error) *Problem {
+	p := &Problem{
+		Status: http.StatusInternalServerError,
+		Code:   internalCode,
+		Detail: internalDetail,
+	}
+	if appErr, ok := apperrors.As(err); ok {
+		p.Status = appErr.StatusCode()
+		if p.Status < http.StatusInternalServerError {
+			p.Code = appErr.ErrorCode()
+			p.Detail = appErr.Message
+			p.Errors = appErr.Fields
+		} else if appErr.Code != "" {
+			p.Code = appErr.Code
+		}
+	}
+
+	p.Type = TypePrefix + p.Code
+	p.Title = http.StatusText(p.Status)
+	if r != nil {
+		p.Instance = r.URL.Path
+		p.RequestID, _ = requestid.FromContext(r.Context())
+	}
+	return p
+}
+
+// Write renders err as a problem response.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	p := New(r, err)
+	body, _ := json.Marshal(p)
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(p.Status)
+	w.Write(body)
+}
+
+// NotFoundHandler answers unmatched routes with a 404 problem.
+func NotFoundHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Write(w, r, apperrors.NewNotFoundError("Resource not found", nil).WithCode("route_not_found"))
+	})
+}
+
+// MethodNotAllowedHandler answers routes matched with the wrong method.
+func MethodNotAllowedHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Write(w, r, apperrors.New(apperrors.ErrorTypeMethodNotAllowed, "Method not allowed", nil))
+	})
+}
@@ -0,0 +1,22 @@
+// Package requestid stores the request ID in a context. It has no
+// dependencies so that any package can read the ID without importing the
+// middleware.
+package requestid
+
+import "context"
+
+// Header carries the request ID between services and back to clients.
+const Header = "X-Request-ID"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID stored by NewContext.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKey{}).(string)
+	return id, ok
+}
@@ -0,0 +1,65 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Config selects where secrets are read from. Provider is "env" (variables
+// such as JWT_SECRET_KEY, after EnvPrefix), "file" (one file per secret in
+// Dir, as mounted from a Kubernetes secret) or "vault". Secrets the provider
+// does not have keep their value from the configuration file. Rotating
+// secrets are looked up again every RefreshInterval.
+type Config struct {
+	Provider        string        `mapstructure:"provider"`
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+	EnvPrefix       string        `mapstructure:"env_prefix"`
+	Dir             string        `mapstructure:"dir"`
+	Vault           VaultConfig   `mapstructure:"vault"`
+}
+
+// ApplyDefaults reads secrets from the environment and refreshes them every
+// 30s unless configured otherwise.
+func (c *Config) ApplyDefaults() {
+	if c.Provider == "" {
+		c.Provider = "env"
+	}
+	if c.RefreshInterval == 0 {
+		c.RefreshInterval = 30 * time.Second
+	}
+}
+
+// Validate reports settings the selected provider cannot work with.
+func (c Config) Validate() error {
+	var errs []error
+	switch c.Provider {
+	case "env":
+	case "file":
+		if c.Dir == "" {
+			errs = append(errs, fmt.Errorf("secrets dir is required for the file provider"))
+		}
+	case "vault":
+		if c.Vault.Address == "" || c.Vault.Path == "" {
+			errs = append(errs, fmt.Errorf("secrets vault address and path are required for the vault provider"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown secrets provider %q", c.Provider))
+	}
+	if c.RefreshInterval < 0 {
+		errs = append(errs, fmt.Errorf("secrets refresh_interval must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// NewProvider builds the configured secrets backend.
+func NewProvider(c Config) (SecretProvider, error) {
+	switch c.Provider {
+	case "file":
+		return NewFileProvider(c.Dir), nil
+	case "vault":
+		return NewVaultProvider(c.Vault)
+	default:
+		return NewEnvProvider(c.EnvPrefix), nil
+	}
+}
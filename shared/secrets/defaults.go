@@ -0,0 +1,68 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// knownDefaults are placeholder values from sample configurations, which
+// must never protect a real deployment.
+var knownDefaults = map[string]bool{
+	"your-secret-key":                true,
+	"your-secret-key-here":           true,
+	"your-refresh-token-secret":      true,
+	"your-refresh-token-secret-here": true,
+	"test-secret-key":                true,
+	"secret":                         true,
+	"changeme":                       true,
+	"password":                       true,
+	"postgres":                       true,
+	"change-me-media-url-signing-secret-0001": true,
+}
+
+// IsDefault reports whether value is a known placeholder.
+func IsDefault(value string) bool {
+	return knownDefaults[value]
+}
+
+// IsDevelopment reports whether APP_ENV names a non-production environment,
+// where placeholder secrets are allowed.
+func IsDevelopment(env string) bool {
+	return env == "development" || env == "test"
+}
+
+// Field names a secret and the setting its value is written to.
+type Field struct {
+	Name  string
+	Value *string
+}
+
+// Resolve replaces each field's value with the provider's. Fields the
+// provider has no value for keep their current one.
+func Resolve(ctx context.Context, provider SecretProvider, fields []Field) error {
+	var errs []error
+	for _, field := range fields {
+		value, err := provider.Get(ctx, field.Name)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read secret %s: %w", field.Name, err))
+			continue
+		}
+		*field.Value = value
+	}
+	return errors.Join(errs...)
+}
+
+// CheckDefaults rejects fields left at a known placeholder value.
+func CheckDefaults(fields []Field) error {
+	var errs []error
+	for _, field := range fields {
+		if IsDefault(*field.Value) {
+			errs = append(errs, fmt.Errorf("secret %s is set to a known default value", field.Name))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,55 @@
+package secrets
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestIsDefault(t *testing.T) {
+	for value, want := range map[string]bool{
+		"your-secret-key":   true,
+		"changeme":          true,
+		"test-secret-key":   true,
+		"a-real-secret-key": false,
+		"Changeme!":         false,
+	} {
+		if got := IsDefault(value); got != want {
+			t.Errorf("IsDefault(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestIsDevelopment(t *testing.T) {
+	for env, want := range map[string]bool{
+		"development": true,
+		"test":        true,
+		"":            false,
+		"production":  false,
+		"staging":     false,
+		"Development": false,
+	} {
+		if got := IsDevelopment(env); got != want {
+			t.Errorf("IsDevelopment(%q) = %v, want %v", env, got, want)
+		}
+	}
+}
+
+func TestResolveAndCheckDefaults(t *testing.T) {
+	dir := t.TempDir()
+	writeSecret(t, dir, "jwt_secret_key", "a-real-signing-key")
+
+	jwtKey, dbPassword := "your-secret-key", "postgres"
+	fields := []Field{{"jwt_secret_key", &jwtKey}, {"db_password", &dbPassword}}
+	if err := Resolve(context.Background(), NewFileProvider(dir), fields); err != nil {
+		t.Fatalf("Resolve = %v", err)
+	}
+	if jwtKey != "a-real-signing-key" || dbPassword != "postgres" {
+		t.Errorf("resolved %q, %q, want the provider value and the kept one", jwtKey, dbPassword)
+	}
+
+	err := CheckDefaults(fields)
+	if err == nil || !strings.Contains(err.Error(), "db_password") || strings.Contains(err.Error(), "jwt_secret_key") {
+		t.Errorf("CheckDefaults = %v, want only db_password reported", err)
+	}
+}
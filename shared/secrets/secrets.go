@@ -0,0 +1,106 @@
+// Package secrets reads credentials from the environment, from files
+// mounted by Kubernetes or from a Vault-style HTTP API.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"http_server/shared/logging"
+
+	"go.uber.org/zap"
+)
+
+// ErrNotFound is returned when a provider has no value for a secret.
+var ErrNotFound = errors.New("secret not found")
+
+// SecretProvider looks up secrets by name, such as "jwt_secret_key". Every
+// call returns the current value, so rotated secrets are seen on the next
+// lookup.
+type SecretProvider interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+type envProvider struct {
+	prefix string
+}
+
+// NewEnvProvider reads secrets from environment variables named after the
+// secret in upper case, after prefix: "jwt_secret_key" with prefix "AUTH_"
+// is read from AUTH_JWT_SECRET_KEY.
+func NewEnvProvider(prefix string) SecretProvider {
+	return &envProvider{prefix: prefix}
+}
+
+func (p *envProvider) Get(ctx context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(p.prefix + strings.ToUpper(name))
+	if !ok || value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+type fileProvider struct {
+	dir string
+}
+
+// NewFileProvider reads each secret from the file of the same name in dir,
+// the layout of a Kubernetes secret mounted as a volume. Files are read on
+// every lookup, so updates to the mounted secret are picked up.
+func NewFileProvider(dir string) SecretProvider {
+	return &fileProvider{dir: dir}
+}
+
+func (p *fileProvider) Get(ctx context.Context, name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(p.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimRight(string(data), "\r\n")
+	if value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// Watch looks the named secret up every interval until ctx is done and
+// calls onChange when its value differs from the last one seen, starting
+// with initial. Failed lookups are logged and retried on the next tick;
+// the value itself is never logged.
+func Watch(ctx context.Context, provider SecretProvider, name, initial string, interval time.Duration, onChange func(value string), logger *logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := initial
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		value, err := provider.Get(ctx, name)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Error("Failed to refresh secret", err, zap.String("secret", name))
+			}
+			continue
+		}
+		if value == last {
+			continue
+		}
+		last = value
+		onChange(value)
+	}
+}
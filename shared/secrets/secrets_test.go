@@ -0,0 +1,103 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"http_server/shared/logging"
+)
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("AUTH_JWT_SECRET_KEY", "from-env")
+	t.Setenv("AUTH_EMPTY", "")
+	provider := NewEnvProvider("AUTH_")
+
+	value, err := provider.Get(context.Background(), "jwt_secret_key")
+	if err != nil || value != "from-env" {
+		t.Errorf("Get = %q, %v, want from-env", value, err)
+	}
+	for _, name := range []string{"empty", "missing"} {
+		if _, err := provider.Get(context.Background(), name); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q) = %v, want ErrNotFound", name, err)
+		}
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	writeSecret(t, dir, "db_password", "from-file\n")
+	writeSecret(t, dir, "empty", "\n")
+	provider := NewFileProvider(dir)
+
+	value, err := provider.Get(context.Background(), "db_password")
+	if err != nil || value != "from-file" {
+		t.Errorf("Get = %q, %v, want from-file without the newline", value, err)
+	}
+	for _, name := range []string{"empty", "missing"} {
+		if _, err := provider.Get(context.Background(), name); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q) = %v, want ErrNotFound", name, err)
+		}
+	}
+	for _, name := range []string{"", "../db_password", "nested/db_password", ".hidden"} {
+		if _, err := provider.Get(context.Background(), name); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q) = %v, want an invalid name error", name, err)
+		}
+	}
+
+	// Files are read on every lookup, so an updated mount is seen at once.
+	writeSecret(t, dir, "db_password", "rotated")
+	if value, _ := provider.Get(context.Background(), "db_password"); value != "rotated" {
+		t.Errorf("Get after update = %q, want rotated", value)
+	}
+}
+
+func TestWatchReportsRotations(t *testing.T) {
+	dir := t.TempDir()
+	writeSecret(t, dir, "jwt_secret_key", "first")
+	logger, err := logging.NewLogger(&logging.Config{ServiceName: "test", LogLevel: "fatal", FilePath: "stderr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan string, 10)
+	go Watch(ctx, NewFileProvider(dir), "jwt_secret_key", "first", 5*time.Millisecond, func(value string) {
+		changes <- value
+	}, logger)
+
+	// The initial value is not reported, and lookups failing while the
+	// file is missing keep the last value.
+	time.Sleep(30 * time.Millisecond)
+	if err := os.Remove(filepath.Join(dir, "jwt_secret_key")); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	writeSecret(t, dir, "jwt_secret_key", "second")
+
+	select {
+	case value := <-changes:
+		if value != "second" {
+			t.Fatalf("first change = %q, want second", value)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("rotation was not reported")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	cancel()
+	if len(changes) != 0 {
+		t.Errorf("unchanged value reported again: %q", <-changes)
+	}
+}
+
+func writeSecret(t *testing.T, dir, name, value string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,92 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultConfig locates a key/value secret in Vault, or any service with the
+// same HTTP API. Path is the secret's API path without the "/v1/" prefix,
+// e.g. "secret/data/auth-service" for a KV version 2 engine mounted at
+// "secret". Each key of the secret is a secret name.
+type VaultConfig struct {
+	Address string `mapstructure:"address"`
+	Token   string `mapstructure:"token"`
+	Path    string `mapstructure:"path"`
+	// Timeout bounds each request. Defaults to 5s.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+type vaultProvider struct {
+	config VaultConfig
+	client *http.Client
+}
+
+func NewVaultProvider(config VaultConfig) (SecretProvider, error) {
+	if config.Address == "" || config.Path == "" {
+		return nil, fmt.Errorf("vault address and path are required")
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 5 * time.Second
+	}
+	return &vaultProvider{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// vaultResponse covers both KV engine versions: version 1 returns the keys
+// in data, version 2 nests them in data.data.
+type vaultResponse struct {
+	Data map[string]json.RawMessage `json:"data"`
+}
+
+func (p *vaultProvider) Get(ctx context.Context, name string) (string, error) {
+	url := strings.TrimRight(p.config.Address, "/") + "/v1/" + strings.TrimLeft(p.config.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	if p.config.Token != "" {
+		req.Header.Set("X-Vault-Token", p.config.Token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status %d for %s", resp.StatusCode, p.config.Path)
+	}
+
+	var body vaultResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode vault response: %w", err)
+	}
+	data := body.Data
+	var nested map[string]json.RawMessage
+	if err := json.Unmarshal(data["data"], &nested); err == nil && nested != nil {
+		data = nested
+	}
+
+	raw, ok := data[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("vault secret %q is not a string", name)
+	}
+	if value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
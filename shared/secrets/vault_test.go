@@ -0,0 +1,116 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// vaultStub serves body at /v1/secret/data/auth-service to requests with
+// the test token.
+func vaultStub(t *testing.T, body *atomic.Value) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/auth-service" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body.Load().(string)))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVaultProvider(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"kv v2", `{"data": {"data": {"jwt_secret_key": "from-vault", "empty": "", "number": 1}, "metadata": {"version": 3}}}`},
+		{"kv v1", `{"data": {"jwt_secret_key": "from-vault", "empty": "", "number": 1}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body atomic.Value
+			body.Store(tt.body)
+			server := vaultStub(t, &body)
+			provider, err := NewVaultProvider(VaultConfig{Address: server.URL + "/", Token: "test-token", Path: "/secret/data/auth-service"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+
+			value, err := provider.Get(ctx, "jwt_secret_key")
+			if err != nil || value != "from-vault" {
+				t.Errorf("Get = %q, %v, want from-vault", value, err)
+			}
+			for _, name := range []string{"empty", "missing"} {
+				if _, err := provider.Get(ctx, name); !errors.Is(err, ErrNotFound) {
+					t.Errorf("Get(%q) = %v, want ErrNotFound", name, err)
+				}
+			}
+			if _, err := provider.Get(ctx, "number"); err == nil || errors.Is(err, ErrNotFound) {
+				t.Errorf("Get of a non-string = %v, want an error", err)
+			}
+		})
+	}
+}
+
+func TestVaultProviderErrors(t *testing.T) {
+	var body atomic.Value
+	body.Store(`not json`)
+	server := vaultStub(t, &body)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		config   VaultConfig
+		notFound bool
+	}{
+		{"bad token", VaultConfig{Address: server.URL, Token: "wrong", Path: "secret/data/auth-service"}, false},
+		{"unknown path", VaultConfig{Address: server.URL, Token: "test-token", Path: "secret/data/other"}, true},
+		{"bad body", VaultConfig{Address: server.URL, Token: "test-token", Path: "secret/data/auth-service"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewVaultProvider(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = provider.Get(ctx, "jwt_secret_key")
+			if err == nil || errors.Is(err, ErrNotFound) != tt.notFound {
+				t.Errorf("Get = %v, want not found %v", err, tt.notFound)
+			}
+		})
+	}
+
+	if _, err := NewVaultProvider(VaultConfig{Address: server.URL}); err == nil {
+		t.Error("NewVaultProvider without a path succeeded")
+	}
+}
+
+func TestVaultProviderSeesRotation(t *testing.T) {
+	var body atomic.Value
+	body.Store(`{"data": {"data": {"jwt_secret_key": "first"}}}`)
+	server := vaultStub(t, &body)
+	provider, err := NewVaultProvider(VaultConfig{Address: server.URL, Token: "test-token", Path: "secret/data/auth-service"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := provider.Get(context.Background(), "jwt_secret_key"); value != "first" {
+		t.Fatalf("Get = %q, want first", value)
+	}
+	body.Store(`{"data": {"data": {"jwt_secret_key": "second"}}}`)
+	if value, _ := provider.Get(context.Background(), "jwt_secret_key"); value != "second" {
+		t.Errorf("Get after rotation = %q, want second", value)
+	}
+}
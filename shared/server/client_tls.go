@@ -0,0 +1,94 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"http_server/shared/logging"
+)
+
+// ClientTLSConfig configures mutual TLS for calls to another service.
+type ClientTLSConfig struct {
+	// CertFile and KeyFile hold the certificate presented to the server.
+	CertFile string
+	KeyFile  string
+	// CAFile holds the CAs the server certificate must chain to. Empty
+	// uses the system roots.
+	CAFile string
+	// ServerName is checked against the server certificate instead of the
+	// host in the request URL.
+	ServerName string
+	// MinVersion is "1.2" (default) or "1.3".
+	MinVersion string
+	// ReloadInterval is how often the files are checked for changes.
+	// Defaults to 30s.
+	ReloadInterval time.Duration
+}
+
+// NewClientTLSReloader loads a client certificate and the server CAs,
+// reloading them like a server's when the files change. Use ClientConfig
+// for the TLS settings and run Watch to pick up rotations.
+func NewClientTLSReloader(config ClientTLSConfig, logger *logging.Logger) (*TLSReloader, error) {
+	r, err := NewTLSReloader(TLSConfig{
+		CertFile:       config.CertFile,
+		KeyFile:        config.KeyFile,
+		MinVersion:     config.MinVersion,
+		ClientCAFile:   config.CAFile,
+		ReloadInterval: config.ReloadInterval,
+	}, logger)
+	if err != nil {
+		return nil, err
+	}
+	r.serverName = config.ServerName
+	return r, nil
+}
+
+// ClientConfig returns a client config that presents the latest
+// certificate and verifies the server against the latest CAs.
+func (r *TLSReloader) ClientConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: r.base.MinVersion,
+		ServerName: r.serverName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.current.Load().cert, nil
+		},
+		// The standard verification would pin the CAs loaded at startup;
+		// VerifyConnection does the same checks against the current ones.
+		InsecureSkipVerify: true,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			return verifyServer(state, r.current.Load().clientCAs)
+		},
+	}
+}
+
+// verifyServer checks the server's chain against roots, or the system
+// roots when nil, and its certificate against the requested server name.
+func verifyServer(state tls.ConnectionState, roots *x509.CertPool) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("server presented no certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       state.ServerName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to verify server certificate: %w", err)
+	}
+	return nil
+}
+
+// NewClientTransport returns an HTTP transport that uses r for TLS.
+func NewClientTransport(r *TLSReloader) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = r.ClientConfig()
+	return transport
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"http_server/shared/logging"
+	"http_server/shared/server/tlstest"
+)
+
+func newTestLogger(t *testing.T) *logging.Logger {
+	t.Helper()
+	logger, err := logging.NewLogger(&logging.Config{ServiceName: "test", LogLevel: "error", FilePath: "stderr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+// startMutualTLSServer serves 204 to clients with a certificate from ca and
+// refuses the handshake otherwise.
+func startMutualTLSServer(t *testing.T, ca *tlstest.CA) string {
+	t.Helper()
+	certFile, keyFile := ca.ServerFiles(t, "server")
+	reloader, err := NewTLSReloader(TLSConfig{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCAFile: ca.File,
+		ClientAuth:   ClientAuthRequire,
+	}, newTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	srv.TLS = reloader.Config()
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestClientTLSReloader(t *testing.T) {
+	ca := tlstest.NewCA(t)
+	url := startMutualTLSServer(t, ca)
+	other := tlstest.NewCA(t)
+
+	tests := []struct {
+		name       string
+		clientCA   *tlstest.CA // issues the client certificate
+		trusted    *tlstest.CA // expected to have issued the server's
+		serverName string
+		wantErr    bool
+	}{
+		{"trusted both ways", ca, ca, "", false},
+		{"server from an untrusted CA", ca, other, "", true},
+		{"client from an untrusted CA", other, ca, "", true},
+		{"server name mismatch", ca, ca, "auth-service", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certFile, keyFile := tt.clientCA.ClientFiles(t, "client", "spiffe://test/client")
+			reloader, err := NewClientTLSReloader(ClientTLSConfig{
+				CertFile:   certFile,
+				KeyFile:    keyFile,
+				CAFile:     tt.trusted.File,
+				ServerName: tt.serverName,
+			}, newTestLogger(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := &http.Client{Transport: NewClientTransport(reloader)}
+
+			resp, err := client.Get(url)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Get = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
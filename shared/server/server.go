@@ -0,0 +1,120 @@
+// Package server wraps http.Server with signal handling and graceful
+// shutdown.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"http_server/shared/logging"
+
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	// Host is the address to listen on; empty listens on all interfaces.
+	Host            string
+	Port            int
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+	// DrainDelay is how long the server keeps serving after shutdown
+	// begins, so load balancers see readiness fail before connections are
+	// refused.
+	DrainDelay time.Duration
+	// OnDrain, if set, is called when shutdown begins, typically to fail
+	// readiness checks.
+	OnDrain func()
+	// TLS, if set, serves HTTPS instead of plain HTTP.
+	TLS *TLSConfig
+}
+
+type Server struct {
+	httpServer      *http.Server
+	shutdownTimeout time.Duration
+	drainDelay      time.Duration
+	onDrain         func()
+	tls             *TLSConfig
+	logger          *logging.Logger
+}
+
+func New(cfg Config, handler http.Handler, logger *logging.Logger) *Server {
+	shutdownTimeout := cfg.ShutdownTimeout
+	if shutdownTimeout == 0 {
+		shutdownTimeout = 30 * time.Second
+	}
+
+	return &Server{
+		httpServer: &http.Server{
+			Addr:         net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+			Handler:      handler,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			IdleTimeout:  cfg.IdleTimeout,
+		},
+		shutdownTimeout: shutdownTimeout,
+		drainDelay:      cfg.DrainDelay,
+		onDrain:         cfg.OnDrain,
+		tls:             cfg.TLS,
+		logger:          logger,
+	}
+}
+
+// Run serves until ctx is cancelled or the process receives SIGINT or
+// SIGTERM. It then calls OnDrain, keeps serving for DrainDelay, stops
+// accepting connections and waits up to the shutdown timeout for in-flight
+// requests. It returns nil after a clean shutdown.
+func (s *Server) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serve := s.httpServer.ListenAndServe
+	if s.tls != nil {
+		reloader, err := NewTLSReloader(*s.tls, s.logger)
+		if err != nil {
+			return err
+		}
+		s.httpServer.TLSConfig = reloader.Config()
+		go reloader.Watch(ctx)
+		serve = func() error { return s.httpServer.ListenAndServeTLS("", "") }
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.Info("Server listening", zap.String("addr", s.httpServer.Addr), zap.Bool("tls", s.tls != nil))
+		errCh <- serve()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	if s.onDrain != nil {
+		s.onDrain()
+	}
+	if s.drainDelay > 0 {
+		s.logger.Info("Draining before shutdown", zap.Duration("delay", s.drainDelay))
+		time.Sleep(s.drainDelay)
+	}
+
+	s.logger.Info("Shutting down server", zap.Duration("timeout", s.shutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("graceful shutdown failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"http_server/shared/logging"
+
+	"go.uber.org/zap"
+)
+
+// Client certificate policies accepted in TLSConfig.ClientAuth.
+const (
+	ClientAuthNone          = "none"
+	ClientAuthVerifyIfGiven = "verify_if_given"
+	ClientAuthRequire       = "require"
+)
+
+// TLSConfig enables HTTPS, and HTTP/2 with it.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// MinVersion is "1.2" (default) or "1.3".
+	MinVersion string
+	// CipherSuites restricts the TLS 1.2 suites, by Go name such as
+	// "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256". TLS 1.3 suites are not
+	// configurable. Empty keeps Go's secure defaults.
+	CipherSuites []string
+	// ClientCAFile enables mutual TLS: client certificates are verified
+	// against these CAs according to ClientAuth.
+	ClientCAFile string
+	// ClientAuth is "none", "verify_if_given" (default with a CA file) or
+	// "require".
+	ClientAuth string
+	// ReloadInterval is how often the files are checked for changes, so
+	// rotated certificates are picked up without a restart. Defaults to
+	// 30s.
+	ReloadInterval time.Duration
+}
+
+// certificates is what gets swapped on reload.
+type certificates struct {
+	cert *tls.Certificate
+	// clientCAs verifies the peer: clients on a server, the server on a
+	// client.
+	clientCAs *x509.CertPool
+	// stamp identifies the file versions the certificates were read from.
+	stamp string
+}
+
+// TLSReloader serves certificates from files and reloads them when the
+// files change. Files are polled rather than watched, since Kubernetes
+// rotates mounted secrets by swapping symlinks, which file watches miss.
+type TLSReloader struct {
+	config  TLSConfig
+	base    *tls.Config
+	current atomic.Pointer[certificates]
+	// serverName is checked against the server certificate by
+	// ClientConfig.
+	serverName string
+	logger     *logging.Logger
+}
+
+func NewTLSReloader(config TLSConfig, logger *logging.Logger) (*TLSReloader, error) {
+	if config.ReloadInterval == 0 {
+		config.ReloadInterval = 30 * time.Second
+	}
+
+	minVersion, err := parseTLSVersion(config.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	cipherSuites, err := parseCipherSuites(config.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	clientAuth, err := parseClientAuth(config.ClientAuth, config.ClientCAFile != "")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &TLSReloader{
+		config: config,
+		base: &tls.Config{
+			MinVersion:   minVersion,
+			CipherSuites: cipherSuites,
+			ClientAuth:   clientAuth,
+			NextProtos:   []string{"h2", "http/1.1"},
+		},
+		logger: logger,
+	}
+	certs, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.current.Store(certs)
+	return r, nil
+}
+
+// Config returns a config that always uses the latest certificates. It
+// offers HTTP/2 and HTTP/1.1 through ALPN.
+func (r *TLSReloader) Config() *tls.Config {
+	config := r.base.Clone()
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		certs := r.current.Load()
+		config := r.base.Clone()
+		config.Certificates = []tls.Certificate{*certs.cert}
+		config.ClientCAs = certs.clientCAs
+		return config, nil
+	}
+	return config
+}
+
+// Watch reloads the certificates whenever the files change, until ctx is
+// done. A failed reload is logged and the previous certificates stay in use.
+func (r *TLSReloader) Watch(ctx context.Context) {
+	ticker := time.NewTicker(r.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		stamp, err := r.stamp()
+		if err != nil {
+			r.logger.Error("Failed to check TLS certificate files", err)
+			continue
+		}
+		if stamp == r.current.Load().stamp {
+			continue
+		}
+		certs, err := r.load()
+		if err != nil {
+			r.logger.Error("Failed to reload TLS certificates", err)
+			continue
+		}
+		r.current.Store(certs)
+		r.logger.Info("Reloaded TLS certificates", zap.String("cert_file", r.config.CertFile))
+	}
+}
+
+func (r *TLSReloader) load() (*certificates, error) {
+	stamp, err := r.stamp()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	certs := &certificates{cert: &cert, stamp: stamp}
+
+	if r.config.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		certs.clientCAs = x509.NewCertPool()
+		if !certs.clientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in client CA file %s", r.config.ClientCAFile)
+		}
+	}
+	return certs, nil
+}
+
+// stamp summarizes the size and modification time of the files.
+func (r *TLSReloader) stamp() (string, error) {
+	var stamp string
+	for _, path := range []string{r.config.CertFile, r.config.KeyFile, r.config.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		stamp += fmt.Sprintf("%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+	}
+	return stamp, nil
+}
+
+func parseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS min version %q", version)
+	}
+}
+
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func parseClientAuth(mode string, hasCA bool) (tls.ClientAuthType, error) {
+	if mode == "" {
+		mode = ClientAuthNone
+		if hasCA {
+			mode = ClientAuthVerifyIfGiven
+		}
+	}
+
+	switch mode {
+	case ClientAuthNone:
+		return tls.NoClientCert, nil
+	case ClientAuthVerifyIfGiven, ClientAuthRequire:
+		if !hasCA {
+			return 0, fmt.Errorf("client auth %q needs a client CA file", mode)
+		}
+		if mode == ClientAuthRequire {
+			return tls.RequireAndVerifyClientCert, nil
+		}
+		return tls.VerifyClientCertIfGiven, nil
+	default:
+		return 0, fmt.Errorf("unknown client auth %q", mode)
+	}
+}
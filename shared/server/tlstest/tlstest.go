@@ -0,0 +1,144 @@
+// Package tlstest issues short-lived certificates for tests of mutual TLS.
+package tlstest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// CA is a test certificate authority with its certificate written to
+// File.
+type CA struct {
+	File string
+	Pool *x509.CertPool
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+}
+
+// NewCA creates a CA whose files are removed when the test ends.
+func NewCA(t testing.TB) *CA {
+	t.Helper()
+	dir := t.TempDir()
+	key, der := issue(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, nil)
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca := &CA{
+		File: filepath.Join(dir, "ca.pem"),
+		Pool: x509.NewCertPool(),
+		cert: cert,
+		key:  key,
+		dir:  dir,
+	}
+	ca.Pool.AddCert(cert)
+	writePEM(t, ca.File, "CERTIFICATE", der)
+	return ca
+}
+
+// ServerFiles issues a server certificate for localhost and 127.0.0.1 and
+// writes it and its key to files named after name.
+func (ca *CA) ServerFiles(t testing.TB, name string) (certFile, keyFile string) {
+	t.Helper()
+	cert := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "localhost"},
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	return ca.write(t, name, cert)
+}
+
+// ClientCert issues a client certificate with the given common name and,
+// when uri is not empty, URI SAN (such as a SPIFFE ID).
+func (ca *CA) ClientCert(t testing.TB, commonName, uri string) tls.Certificate {
+	t.Helper()
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if uri != "" {
+		parsed, err := url.Parse(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template.URIs = []*url.URL{parsed}
+	}
+	return ca.issue(t, template)
+}
+
+// ClientFiles is ClientCert written to files named after commonName.
+func (ca *CA) ClientFiles(t testing.TB, commonName, uri string) (certFile, keyFile string) {
+	t.Helper()
+	return ca.write(t, commonName, ca.ClientCert(t, commonName, uri))
+}
+
+func (ca *CA) issue(t testing.TB, template *x509.Certificate) tls.Certificate {
+	t.Helper()
+	key, der := issue(t, template, ca.cert, ca.key)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func (ca *CA) write(t testing.TB, name string, cert tls.Certificate) (certFile, keyFile string) {
+	t.Helper()
+	keyDER, err := x509.MarshalECPrivateKey(cert.PrivateKey.(*ecdsa.PrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile = filepath.Join(ca.dir, name+".pem")
+	keyFile = filepath.Join(ca.dir, name+"-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", cert.Certificate[0])
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+// issue signs template with parent's key, or self-signs it when parent is
+// nil, and returns the new key and the DER certificate.
+func issue(t testing.TB, template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, der
+}
+
+func writePEM(t testing.TB, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,128 @@
+// Package tracing configures OpenTelemetry tracing for a service: the OTLP
+// exporter, sampling and W3C trace-context propagation.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// Exporter protocols accepted in Config.Provider.
+const (
+	ProviderOTLP     = "otlp"
+	ProviderOTLPHTTP = "otlp-http"
+)
+
+type Config struct {
+	Enabled bool
+	// Provider is the exporter protocol: "otlp" (gRPC, usually port 4317)
+	// or "otlp-http" (usually port 4318).
+	Provider string
+	// Endpoint is host:port, or a full URL whose scheme decides whether
+	// TLS is used.
+	Endpoint string
+	// Insecure disables TLS for a host:port endpoint.
+	Insecure       bool
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	// SampleRate is the fraction of new traces recorded, from 0 to 1.
+	// Traces continued from a caller follow the caller's decision.
+	SampleRate float64
+}
+
+// ShutdownFunc flushes buffered spans and stops the exporter.
+type ShutdownFunc func(ctx context.Context) error
+
+// Init installs the W3C trace-context and baggage propagators and, when
+// tracing is enabled, a tracer provider exporting over OTLP. Propagators
+// are installed even when tracing is disabled so incoming trace IDs still
+// reach logs and outgoing messages.
+func Init(ctx context.Context, config Config) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !config.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+	if config.SampleRate < 0 || config.SampleRate > 1 {
+		return nil, fmt.Errorf("tracing sample rate must be between 0 and 1")
+	}
+
+	exporter, err := newExporter(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := []attribute.KeyValue{semconv.ServiceName(config.ServiceName)}
+	if config.ServiceVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersion(config.ServiceVersion))
+	}
+	if config.Environment != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironment(config.Environment))
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, attrs...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRate))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, config Config) (sdktrace.SpanExporter, error) {
+	isURL := strings.Contains(config.Endpoint, "://")
+
+	switch config.Provider {
+	case ProviderOTLP:
+		var opts []otlptracegrpc.Option
+		if isURL {
+			opts = append(opts, otlptracegrpc.WithEndpointURL(config.Endpoint))
+		} else if config.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure && !isURL {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err := otlptracegrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP gRPC exporter: %w", err)
+		}
+		return exporter, nil
+	case ProviderOTLPHTTP:
+		var opts []otlptracehttp.Option
+		if isURL {
+			opts = append(opts, otlptracehttp.WithEndpointURL(config.Endpoint))
+		} else if config.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure && !isURL {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP HTTP exporter: %w", err)
+		}
+		return exporter, nil
+	default:
+		return nil, fmt.Errorf("unknown tracing provider %q", config.Provider)
+	}
+}
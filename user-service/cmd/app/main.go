@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"http_server/shared/database"
+	"http_server/shared/jwtkeys"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+	"http_server/shared/secrets"
+	sharedserver "http_server/shared/server"
+	"http_server/user-service/internal/client"
+	"http_server/user-service/internal/config"
+	"http_server/user-service/internal/domain/models"
+	"http_server/user-service/internal/domain/repository"
+	"http_server/user-service/internal/handler"
+	"http_server/user-service/internal/search"
+	"http_server/user-service/internal/server"
+	"http_server/user-service/internal/service"
+)
+
+func main() {
+	// Load configuration
+	configPath := os.Getenv("CONFIG_PATH")
+	if configPath == "" {
+		configPath = "config.yaml"
+	}
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Initialize logger
+	logger, err := logging.NewLogger(&logging.Config{
+		ServiceName: "user-service",
+		Environment: os.Getenv("APP_ENV"),
+		LogLevel:    cfg.Logging.Level,
+		FilePath:    cfg.Logging.FilePath,
+		MaxSize:     10, // 10MB
+		MaxBackups:  5,
+		MaxAge:      30, // 30 days
+		Compress:    true,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer logger.Sync()
+
+	// Read secrets, refusing placeholder values outside development
+	secretProvider, err := secrets.NewProvider(cfg.Secrets)
+	if err != nil {
+		logger.Fatal("Failed to initialize secrets provider", err)
+	}
+	if err := config.ResolveSecrets(context.Background(), cfg, secretProvider); err != nil {
+		logger.Fatal("Failed to read secrets", err)
+	}
+	if !secrets.IsDevelopment(os.Getenv("APP_ENV")) {
+		if err := config.CheckSecrets(cfg); err != nil {
+			logger.Fatal("Refusing to start with default secrets", err)
+		}
+	}
+
+	// Initialize database connection
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		logger.Fatal("Failed to connect to database", err)
+	}
+
+	if err := db.AutoMigrate(&models.Profile{}, &models.Follow{}, &models.Block{}, &models.Mute{}); err != nil {
+		logger.Fatal("Failed to auto-migrate database", err)
+	}
+
+	searchIndex, err := search.New(cfg.Search, db)
+	if err != nil {
+		logger.Fatal("Failed to initialize search index", err)
+	}
+
+	// Initialize clients, repositories and services. auth-service's
+	// internal API needs a client certificate.
+	authTLS, err := sharedserver.NewClientTLSReloader(sharedserver.ClientTLSConfig{
+		CertFile:       cfg.Services.Auth.TLS.CertFile,
+		KeyFile:        cfg.Services.Auth.TLS.KeyFile,
+		CAFile:         cfg.Services.Auth.TLS.CAFile,
+		ServerName:     cfg.Services.Auth.TLS.ServerName,
+		ReloadInterval: cfg.Services.Auth.TLS.ReloadInterval,
+	}, logger)
+	if err != nil {
+		logger.Fatal("Failed to load auth service client certificate", err)
+	}
+	users := client.NewAuthServiceClient(cfg.Services.Auth, sharedserver.NewClientTransport(authTLS))
+	profileRepo := repository.NewProfileRepository(db)
+	followRepo := repository.NewFollowRepository(db)
+	blockRepo := repository.NewBlockRepository(db)
+	muteRepo := repository.NewMuteRepository(db)
+	profiles := service.NewProfileLoader(profileRepo, users, searchIndex, cfg.Profiles, logger)
+	profileService := service.NewProfileService(profileRepo, followRepo, blockRepo, muteRepo, profiles, searchIndex, cfg.Profiles, logger)
+	relationshipService := service.NewRelationshipService(profileRepo, followRepo, blockRepo, muteRepo, profiles, cfg.Relationships, logger)
+	searchService := service.NewSearchService(searchIndex, profileRepo, blockRepo, cfg.Search, logger)
+
+	if cfg.Search.Driver == "memory" {
+		if err := searchService.Reindex(context.Background()); err != nil {
+			logger.Fatal("Failed to build search index", err)
+		}
+	}
+
+	// Initialize handlers and middleware
+	handlers := server.Handlers{
+		Profile:      handler.NewProfileHandler(profileService, logger),
+		Relationship: handler.NewRelationshipHandler(relationshipService, logger),
+		Search:       handler.NewSearchHandler(searchService, logger),
+	}
+	jwtKeys := jwtkeys.New([]byte(cfg.JWT.SecretKey))
+	authMiddleware := middleware.NewJWTAuth(jwtKeys, logger)
+
+	// Initialize health checks and server
+	sqlDB, err := db.DB()
+	if err != nil {
+		logger.Fatal("Failed to get database instance", err)
+	}
+	healthRegistry := server.NewHealth(cfg, sqlDB, jwtKeys)
+	srv := server.NewServer(cfg, handlers, healthRegistry, authMiddleware, logger)
+	internalSrv := server.NewInternalServer(cfg, handler.NewInternalHandler(relationshipService, logger), logger)
+
+	// Start background workers
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go authTLS.Watch(workerCtx)
+	// Serve the internal API to other services over mutual TLS
+	go func() {
+		if err := internalSrv.Run(workerCtx); err != nil {
+			logger.Fatal("Internal server stopped with error", err)
+		}
+	}()
+	// Pick up rotations of the JWT key
+	if cfg.Secrets.Provider != "env" {
+		go secrets.Watch(workerCtx, secretProvider, config.SecretJWTKey, cfg.JWT.SecretKey, cfg.Secrets.RefreshInterval,
+			jwtKeys.Rotator(os.Getenv("APP_ENV"), logger), logger)
+	}
+
+	// Serve until SIGINT or SIGTERM, then drain in-flight requests
+	if err := srv.Run(context.Background()); err != nil {
+		logger.Fatal("Server stopped with error", err)
+	}
+
+	logger.Info("Server shutdown completed")
+}
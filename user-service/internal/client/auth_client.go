@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"http_server/user-service/internal/config"
+
+	"github.com/google/uuid"
+)
+
+// ErrUserNotFound is returned when auth-service has no such user.
+var ErrUserNotFound = errors.New("user not found")
+
+// userNotFoundCode is the problem code auth-service answers unknown users
+// with. Other 404s, such as for an internal API turned off because mutual
+// TLS is not configured, are failures rather than missing users.
+const userNotFoundCode = "user_not_found"
+
+// UserRecord holds the profile fields of a user as stored by auth-service.
+type UserRecord struct {
+	ID             uuid.UUID `json:"id"`
+	Name           string    `json:"name"`
+	Bio            string    `json:"bio"`
+	ProfilePicture string    `json:"profile_picture"`
+	Location       string    `json:"location"`
+	Active         bool      `json:"active"`
+	Verified       bool      `json:"verified"`
+	Roles          []string  `json:"roles"`
+}
+
+// UserDirectory looks up accounts owned by auth-service.
+type UserDirectory interface {
+	GetUser(ctx context.Context, userID uuid.UUID) (*UserRecord, error)
+}
+
+type authServiceClient struct {
+	baseURL    string
+	httpClient *http.Client
+	retry      config.RetryConfig
+}
+
+// NewAuthServiceClient calls auth-service through transport, which
+// presents the client certificate its internal API requires. A nil
+// transport uses http.DefaultTransport.
+func NewAuthServiceClient(cfg config.ServiceEndpointConfig, transport http.RoundTripper) UserDirectory {
+	return &authServiceClient{
+		baseURL:    strings.TrimRight(cfg.URL, "/"),
+		httpClient: &http.Client{Timeout: cfg.Timeout, Transport: transport},
+		retry:      cfg.Retry,
+	}
+}
+
+// retryableError marks failures worth another attempt: transport errors and
+// 5xx responses.
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+func (c *authServiceClient) GetUser(ctx context.Context, userID uuid.UUID) (*UserRecord, error) {
+	url := fmt.Sprintf("%s/internal/v1/users/%s", c.baseURL, userID)
+
+	var user *UserRecord
+	err := c.withRetry(ctx, func() error {
+		var err error
+		user, err = c.getUser(ctx, url)
+		return err
+	})
+	return user, err
+}
+
+func (c *authServiceClient) getUser(ctx context.Context, url string) (*UserRecord, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build user request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &retryableError{fmt.Errorf("failed to call auth service: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		if code := problemCode(resp); code != userNotFoundCode {
+			return nil, fmt.Errorf("auth service returned status %d (%s)", resp.StatusCode, code)
+		}
+		return nil, ErrUserNotFound
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("auth service rejected the client certificate: status %d (%s)", resp.StatusCode, problemCode(resp))
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return nil, &retryableError{fmt.Errorf("auth service returned status %d", resp.StatusCode)}
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("auth service returned status %d", resp.StatusCode)
+	}
+
+	var user UserRecord
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return nil, fmt.Errorf("failed to decode user response: %w", err)
+	}
+	return &user, nil
+}
+
+// problemCode reads the code of a problem response, or returns "" when the
+// body is not one.
+func problemCode(resp *http.Response) string {
+	var problem struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+		return ""
+	}
+	return problem.Code
+}
+
+// withRetry runs call until it succeeds, fails with a non-retryable error or
+// the configured attempts are used up, backing off exponentially in between.
+func (c *authServiceClient) withRetry(ctx context.Context, call func() error) error {
+	interval := c.retry.InitialInterval
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = call()
+		var retryable *retryableError
+		if err == nil || !errors.As(err, &retryable) || attempt >= c.retry.MaxAttempts {
+			return err
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		interval *= 2
+		if interval > c.retry.MaxInterval {
+			interval = c.retry.MaxInterval
+		}
+	}
+}
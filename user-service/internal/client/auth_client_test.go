@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apperrors "http_server/shared/errors"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+	"http_server/shared/problem"
+	sharedserver "http_server/shared/server"
+	"http_server/shared/server/tlstest"
+	"http_server/user-service/internal/config"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const userServiceURI = "spiffe://cluster.local/ns/default/sa/user-service"
+
+func newTestLogger(t *testing.T) *logging.Logger {
+	t.Helper()
+	logger, err := logging.NewLogger(&logging.Config{ServiceName: "test", LogLevel: "error", FilePath: "stderr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+// authServer stands in for auth-service: the internal user lookup behind
+// mutual TLS and machine auth, or, when internal is false, no internal API
+// at all, as when auth-service runs without a client CA.
+type authServer struct {
+	ca    *tlstest.CA
+	url   string
+	users map[uuid.UUID]UserRecord
+}
+
+func newAuthServer(t *testing.T, internal bool) *authServer {
+	t.Helper()
+	logger := newTestLogger(t)
+	s := &authServer{ca: tlstest.NewCA(t), users: make(map[uuid.UUID]UserRecord)}
+
+	r := mux.NewRouter()
+	r.NotFoundHandler = problem.NotFoundHandler()
+	if internal {
+		machineAuth := middleware.NewMachineAuth(map[string]string{userServiceURI: "user-service"}, logger)
+		r.Handle("/internal/v1/users/{id}", machineAuth.RequireClientCert(http.HandlerFunc(s.getUser))).Methods("GET")
+	}
+
+	certFile, keyFile := s.ca.ServerFiles(t, "auth-service")
+	reloader, err := sharedserver.NewTLSReloader(sharedserver.TLSConfig{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCAFile: s.ca.File,
+	}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(r)
+	srv.TLS = reloader.Config()
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	s.url = srv.URL
+	return s
+}
+
+func (s *authServer) getUser(w http.ResponseWriter, r *http.Request) {
+	id, _ := uuid.Parse(mux.Vars(r)["id"])
+	user, ok := s.users[id]
+	if !ok {
+		problem.Write(w, r, apperrors.NewNotFoundError("User not found", nil).WithCode("user_not_found"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// client returns a user-service client presenting a certificate with the
+// given URI SAN, or no certificate when uri is empty.
+func (s *authServer) client(t *testing.T, uri string) UserDirectory {
+	t.Helper()
+	cfg := config.ServiceEndpointConfig{
+		URL:     s.url,
+		Timeout: 5 * time.Second,
+		Retry:   config.RetryConfig{MaxAttempts: 1},
+	}
+	if uri == "" {
+		transport := &http.Transport{TLSClientConfig: &tls.Config{RootCAs: s.ca.Pool}}
+		return NewAuthServiceClient(cfg, transport)
+	}
+
+	certFile, keyFile := s.ca.ClientFiles(t, "user-service", uri)
+	reloader, err := sharedserver.NewClientTLSReloader(sharedserver.ClientTLSConfig{
+		CertFile: certFile,
+		KeyFile:  keyFile,
+		CAFile:   s.ca.File,
+	}, newTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewAuthServiceClient(cfg, sharedserver.NewClientTransport(reloader))
+}
+
+func TestAuthServiceClientGetUser(t *testing.T) {
+	server := newAuthServer(t, true)
+	user := UserRecord{ID: uuid.New(), Name: "Ada", Active: true, Roles: []string{"user"}}
+	server.users[user.ID] = user
+	users := server.client(t, userServiceURI)
+
+	got, err := users.GetUser(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if got.ID != user.ID || got.Name != user.Name || !got.Active {
+		t.Errorf("GetUser = %+v, want %+v", got, user)
+	}
+
+	if _, err := users.GetUser(context.Background(), uuid.New()); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("GetUser for an unknown user = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestAuthServiceClientFailuresAreNotMissingUsers(t *testing.T) {
+	tests := []struct {
+		name     string
+		internal bool
+		uri      string
+	}{
+		{"no client certificate", true, ""},
+		{"unmapped client certificate", true, "spiffe://cluster.local/ns/default/sa/someone-else"},
+		{"internal API turned off", false, userServiceURI},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newAuthServer(t, tt.internal)
+			user := UserRecord{ID: uuid.New(), Name: "Ada", Active: true}
+			server.users[user.ID] = user
+
+			_, err := server.client(t, tt.uri).GetUser(context.Background(), user.ID)
+			if err == nil || errors.Is(err, ErrUserNotFound) {
+				t.Errorf("GetUser = %v, want an error other than ErrUserNotFound", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	sharedconfig "http_server/shared/config"
+	"http_server/shared/database"
+)
+
+type Config struct {
+	Server        ServerConfig        `mapstructure:"server"`
+	Health        HealthConfig        `mapstructure:"health"`
+	Database      DatabaseConfig      `mapstructure:"database"`
+	Services      ServicesConfig      `mapstructure:"services"`
+	JWT           JWTConfig           `mapstructure:"jwt"`
+	Secrets       SecretsConfig       `mapstructure:"secrets"`
+	Profiles      ProfilesConfig      `mapstructure:"profiles"`
+	Relationships RelationshipsConfig `mapstructure:"relationships"`
+	Search        SearchConfig        `mapstructure:"search"`
+	Logging       LoggingConfig       `mapstructure:"logging"`
+}
+
+type ServerConfig struct {
+	Port            int           `mapstructure:"port"`
+	Timeout         time.Duration `mapstructure:"timeout"`
+	ReadTimeout     time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout    time.Duration `mapstructure:"write_timeout"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// DrainDelay is how long readiness fails before the listener closes on
+	// shutdown, giving load balancers time to stop routing here.
+	DrainDelay time.Duration        `mapstructure:"drain_delay"`
+	Internal   InternalServerConfig `mapstructure:"internal"`
+}
+
+// InternalServerConfig is the listener of the internal API, apart from the
+// public port. It only serves mutual TLS: callers must present a client
+// certificate signed by ClientCAFile whose identity is listed in
+// Principals. The files are reloaded when they change, checked every
+// ReloadInterval.
+type InternalServerConfig struct {
+	Port           int                     `mapstructure:"port"`
+	CertFile       string                  `mapstructure:"cert_file"`
+	KeyFile        string                  `mapstructure:"key_file"`
+	ClientCAFile   string                  `mapstructure:"client_ca_file"`
+	MinVersion     string                  `mapstructure:"min_version"`
+	ReloadInterval time.Duration           `mapstructure:"reload_interval"`
+	Principals     []ClientPrincipalConfig `mapstructure:"principals"`
+}
+
+// ClientPrincipalConfig maps a client certificate identity to the machine
+// principal it authenticates as. Identity is matched against the
+// certificate's URI SANs (e.g. a SPIFFE ID), DNS SANs and common name.
+type ClientPrincipalConfig struct {
+	Identity  string `mapstructure:"identity"`
+	Principal string `mapstructure:"principal"`
+}
+
+// HealthConfig controls the dependency checks behind the readiness probe.
+// Results are cached for CacheTTL; each check is bounded by Timeout.
+type HealthConfig struct {
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+// DatabaseConfig holds the connection and pool settings.
+type DatabaseConfig = database.Config
+
+type ServicesConfig struct {
+	Auth ServiceEndpointConfig `mapstructure:"auth"`
+}
+
+// ServiceEndpointConfig describes how to reach another service.
+type ServiceEndpointConfig struct {
+	URL     string          `mapstructure:"url"`
+	Timeout time.Duration   `mapstructure:"timeout"`
+	Retry   RetryConfig     `mapstructure:"retry"`
+	TLS     ClientTLSConfig `mapstructure:"tls"`
+}
+
+// ClientTLSConfig is the client certificate presented to another service
+// over mutual TLS and the CAs its certificate must chain to; an empty
+// CAFile uses the system roots. ServerName overrides the host in the URL
+// when checking the server certificate. The files are reloaded when they
+// change, checked every ReloadInterval.
+type ClientTLSConfig struct {
+	CertFile       string        `mapstructure:"cert_file"`
+	KeyFile        string        `mapstructure:"key_file"`
+	CAFile         string        `mapstructure:"ca_file"`
+	ServerName     string        `mapstructure:"server_name"`
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+}
+
+// RetryConfig controls exponential backoff for idempotent calls. The wait
+// doubles after every failed attempt, capped at MaxInterval.
+type RetryConfig struct {
+	MaxAttempts     int           `mapstructure:"max_attempts"`
+	InitialInterval time.Duration `mapstructure:"initial_interval"`
+	MaxInterval     time.Duration `mapstructure:"max_interval"`
+}
+
+type JWTConfig struct {
+	SecretKey string `mapstructure:"secret_key"`
+}
+
+// ProfilesConfig limits profile fields. RevalidateAfter is how long the
+// account state copied from auth-service (active, verified, roles) is
+// trusted before it is read again.
+type ProfilesConfig struct {
+	MaxNameLength     int           `mapstructure:"max_name_length"`
+	MaxBioLength      int           `mapstructure:"max_bio_length"`
+	MaxLocationLength int           `mapstructure:"max_location_length"`
+	RevalidateAfter   time.Duration `mapstructure:"revalidate_after"`
+}
+
+type RelationshipsConfig struct {
+	DefaultPageSize int `mapstructure:"default_page_size"`
+	MaxPageSize     int `mapstructure:"max_page_size"`
+}
+
+// SearchConfig selects the user search backend. The postgres driver queries
+// the profiles table through pg_trgm indexes; the memory driver keeps a
+// trigram index in process and is meant for tests and local runs.
+type SearchConfig struct {
+	Driver         string  `mapstructure:"driver"`
+	MinSimilarity  float64 `mapstructure:"min_similarity"`
+	MaxQueryLength int     `mapstructure:"max_query_length"`
+	DefaultLimit   int     `mapstructure:"default_limit"`
+	MaxLimit       int     `mapstructure:"max_limit"`
+}
+
+type LoggingConfig struct {
+	Level    string `mapstructure:"level"`
+	FilePath string `mapstructure:"file_path"`
+}
+
+func LoadConfig(path string) (*Config, error) {
+	var config Config
+	if err := sharedconfig.Load(path, &config); err != nil {
+		return nil, err
+	}
+
+	applyDefaults(&config)
+
+	if err := validateConfig(&config); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return &config, nil
+}
+
+func applyDefaults(config *Config) {
+	config.Secrets.ApplyDefaults()
+	if config.Server.ShutdownTimeout == 0 {
+		config.Server.ShutdownTimeout = 30 * time.Second
+	}
+	if config.Health.CacheTTL == 0 {
+		config.Health.CacheTTL = 2 * time.Second
+	}
+	if config.Health.Timeout == 0 {
+		config.Health.Timeout = 2 * time.Second
+	}
+	if config.Services.Auth.Timeout == 0 {
+		config.Services.Auth.Timeout = 5 * time.Second
+	}
+	if config.Services.Auth.Retry.MaxAttempts == 0 {
+		config.Services.Auth.Retry.MaxAttempts = 1
+	}
+	if config.Services.Auth.Retry.InitialInterval == 0 {
+		config.Services.Auth.Retry.InitialInterval = 100 * time.Millisecond
+	}
+	if config.Services.Auth.Retry.MaxInterval == 0 {
+		config.Services.Auth.Retry.MaxInterval = 2 * time.Second
+	}
+	if config.Profiles.MaxNameLength == 0 {
+		config.Profiles.MaxNameLength = 100
+	}
+	if config.Profiles.MaxBioLength == 0 {
+		config.Profiles.MaxBioLength = 500
+	}
+	if config.Profiles.MaxLocationLength == 0 {
+		config.Profiles.MaxLocationLength = 100
+	}
+	if config.Profiles.RevalidateAfter == 0 {
+		config.Profiles.RevalidateAfter = 10 * time.Minute
+	}
+	if config.Relationships.DefaultPageSize == 0 {
+		config.Relationships.DefaultPageSize = 20
+	}
+	if config.Relationships.MaxPageSize == 0 {
+		config.Relationships.MaxPageSize = 100
+	}
+	if config.Search.Driver == "" {
+		config.Search.Driver = "postgres"
+	}
+	if config.Search.MinSimilarity == 0 {
+		config.Search.MinSimilarity = 0.3
+	}
+	if config.Search.MaxQueryLength == 0 {
+		config.Search.MaxQueryLength = 100
+	}
+	if config.Search.DefaultLimit == 0 {
+		config.Search.DefaultLimit = 20
+	}
+	if config.Search.MaxLimit == 0 {
+		config.Search.MaxLimit = 50
+	}
+}
+
+func validateConfig(config *Config) error {
+	if config.Server.Port == 0 {
+		return fmt.Errorf("server port is required")
+	}
+	if err := validateInternalServer(config.Server); err != nil {
+		return err
+	}
+	if config.JWT.SecretKey == "" {
+		return fmt.Errorf("JWT secret key is required")
+	}
+	if err := config.Secrets.Validate(); err != nil {
+		return err
+	}
+	if config.Database.Host == "" || config.Database.DBName == "" {
+		return fmt.Errorf("database host and name are required")
+	}
+	if config.Services.Auth.URL == "" {
+		return fmt.Errorf("auth service URL is required")
+	}
+	if config.Services.Auth.Retry.MaxAttempts < 1 {
+		return fmt.Errorf("auth service retry max_attempts must be at least 1")
+	}
+	// auth-service only serves its internal API over mutual TLS
+	if config.Services.Auth.TLS.CertFile == "" || config.Services.Auth.TLS.KeyFile == "" {
+		return fmt.Errorf("auth service tls cert_file and key_file are required")
+	}
+	if config.Relationships.DefaultPageSize > config.Relationships.MaxPageSize {
+		return fmt.Errorf("relationships default page size exceeds max page size")
+	}
+	if config.Search.Driver != "postgres" && config.Search.Driver != "memory" {
+		return fmt.Errorf("unsupported search driver %q", config.Search.Driver)
+	}
+	if config.Search.MinSimilarity < 0 || config.Search.MinSimilarity > 1 {
+		return fmt.Errorf("search min_similarity must be between 0 and 1")
+	}
+	if config.Search.DefaultLimit > config.Search.MaxLimit {
+		return fmt.Errorf("search default limit exceeds max limit")
+	}
+	return nil
+}
+
+func validateInternalServer(server ServerConfig) error {
+	internal := server.Internal
+	if internal.Port == 0 || internal.Port == server.Port {
+		return fmt.Errorf("internal server port is required and must differ from the server port")
+	}
+	if internal.CertFile == "" || internal.KeyFile == "" || internal.ClientCAFile == "" {
+		return fmt.Errorf("internal server cert_file, key_file and client_ca_file are required")
+	}
+	if len(internal.Principals) == 0 {
+		return fmt.Errorf("internal server needs at least one principal")
+	}
+	for _, principal := range internal.Principals {
+		if principal.Identity == "" || principal.Principal == "" {
+			return fmt.Errorf("internal server principals need an identity and a principal")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Profile is the public view of an account. It is seeded from the user record
+// in auth-service the first time the account is seen and owned by this service
+// from then on. Active, Verified and Important are taken from auth-service and
+// checked again once ValidatedAt is older than profiles.revalidate_after;
+// profiles of inactive accounts are hidden. Verified and Important are badges
+// that rank the account higher in search.
+type Profile struct {
+	UserID         uuid.UUID `json:"user_id" gorm:"primaryKey;type:uuid"`
+	Handle         *string   `json:"handle,omitempty" gorm:"size:30;uniqueIndex"`
+	Name           string    `json:"name" gorm:"size:255;not null"`
+	Bio            string    `json:"bio,omitempty" gorm:"type:text"`
+	ProfilePicture string    `json:"profile_picture,omitempty"`
+	Location       string    `json:"location,omitempty"`
+	IsPrivate      bool      `json:"is_private" gorm:"not null;default:false"`
+	Verified       bool      `json:"verified" gorm:"not null;default:false"`
+	Important      bool      `json:"important" gorm:"not null;default:false"`
+	Active         bool      `json:"-" gorm:"not null;default:true"`
+	// ValidatedAt is when the account state was last read from
+	// auth-service; nil forces a check on the next load.
+	ValidatedAt    *time.Time `json:"-"`
+	FollowersCount int64      `json:"followers_count" gorm:"not null;default:0"`
+	FollowingCount int64      `json:"following_count" gorm:"not null;default:0"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Follow statuses. Follows of private accounts start out pending until the
+// followee accepts them; only accepted follows count and grant access.
+const (
+	FollowPending  = "pending"
+	FollowAccepted = "accepted"
+)
+
+type Follow struct {
+	FollowerID uuid.UUID `json:"follower_id" gorm:"primaryKey;type:uuid"`
+	FolloweeID uuid.UUID `json:"followee_id" gorm:"primaryKey;type:uuid;index:idx_follows_followee,priority:1"`
+	Status     string    `json:"status" gorm:"size:16;not null;index:idx_follows_followee,priority:2"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type Block struct {
+	BlockerID uuid.UUID `json:"blocker_id" gorm:"primaryKey;type:uuid"`
+	BlockedID uuid.UUID `json:"blocked_id" gorm:"primaryKey;type:uuid;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Mute struct {
+	MuterID   uuid.UUID `json:"muter_id" gorm:"primaryKey;type:uuid"`
+	MutedID   uuid.UUID `json:"muted_id" gorm:"primaryKey;type:uuid"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Relationship describes how one account relates to another, from the point of
+// view of the first.
+type Relationship struct {
+	Following       bool `json:"following"`
+	FollowRequested bool `json:"follow_requested"`
+	FollowedBy      bool `json:"followed_by"`
+	Blocking        bool `json:"blocking"`
+	BlockedBy       bool `json:"blocked_by"`
+	Muting          bool `json:"muting"`
+}
+
+// ListPosition identifies an entry in a relationship list ordered by
+// (CreatedAt DESC, UserID DESC), newest first.
+type ListPosition struct {
+	At     time.Time `json:"t"`
+	UserID uuid.UUID `json:"id"`
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"http_server/user-service/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type BlockRepository interface {
+	// Create inserts a block and removes any follow between the two accounts
+	// in either direction. It returns ErrDuplicateKey if the block exists.
+	Create(ctx context.Context, block *models.Block) error
+	Delete(ctx context.Context, blockerID, blockedID uuid.UUID) error
+	Exists(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error)
+	// List returns blocks made by blockerID, newest first.
+	List(ctx context.Context, blockerID uuid.UUID, after *models.ListPosition, limit int) ([]models.Block, error)
+	// BlockedIDs returns every account blockerID has blocked.
+	BlockedIDs(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error)
+	// BlockerIDs returns every account that has blocked blockedID.
+	BlockerIDs(ctx context.Context, blockedID uuid.UUID) ([]uuid.UUID, error)
+}
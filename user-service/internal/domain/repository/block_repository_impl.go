@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+
+	"http_server/user-service/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type blockRepository struct {
+	db *gorm.DB
+}
+
+func NewBlockRepository(db *gorm.DB) BlockRepository {
+	return &blockRepository{db: db}
+}
+
+func (r *blockRepository) Create(ctx context.Context, block *models.Block) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(block)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrDuplicateKey
+		}
+		if _, err := deleteFollow(tx, block.BlockerID, block.BlockedID); err != nil {
+			return err
+		}
+		_, err := deleteFollow(tx, block.BlockedID, block.BlockerID)
+		return err
+	})
+}
+
+func (r *blockRepository) Delete(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		Delete(&models.Block{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *blockRepository) Exists(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Block{}).
+		Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *blockRepository) List(ctx context.Context, blockerID uuid.UUID, after *models.ListPosition, limit int) ([]models.Block, error) {
+	query := r.db.WithContext(ctx).Where("blocker_id = ?", blockerID)
+	if after != nil {
+		query = query.Where("(created_at, blocked_id) < (?, ?)", after.At, after.UserID)
+	}
+
+	var blocks []models.Block
+	err := query.Order("created_at DESC, blocked_id DESC").Limit(limit).Find(&blocks).Error
+	return blocks, err
+}
+
+func (r *blockRepository) BlockedIDs(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	err := r.db.WithContext(ctx).Model(&models.Block{}).
+		Where("blocker_id = ?", blockerID).
+		Pluck("blocked_id", &ids).Error
+	return ids, err
+}
+
+func (r *blockRepository) BlockerIDs(ctx context.Context, blockedID uuid.UUID) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	err := r.db.WithContext(ctx).Model(&models.Block{}).
+		Where("blocked_id = ?", blockedID).
+		Pluck("blocker_id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"http_server/user-service/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type FollowRepository interface {
+	Find(ctx context.Context, followerID, followeeID uuid.UUID) (*models.Follow, error)
+	// Create inserts a follow, returning ErrDuplicateKey if the pair already
+	// exists. Accepted follows update both profiles' counters.
+	Create(ctx context.Context, follow *models.Follow) error
+	// Accept turns a pending follow into an accepted one.
+	Accept(ctx context.Context, followerID, followeeID uuid.UUID) error
+	// AcceptAll accepts every pending follow of followeeID and returns how
+	// many were accepted.
+	AcceptAll(ctx context.Context, followeeID uuid.UUID) (int64, error)
+	Delete(ctx context.Context, followerID, followeeID uuid.UUID) error
+
+	// ListFollowers returns follows of followeeID with the given status,
+	// newest first, starting strictly after the given position.
+	ListFollowers(ctx context.Context, followeeID uuid.UUID, status string, after *models.ListPosition, limit int) ([]models.Follow, error)
+	// ListFollowing returns accepted follows made by followerID, newest first.
+	ListFollowing(ctx context.Context, followerID uuid.UUID, after *models.ListPosition, limit int) ([]models.Follow, error)
+	// FollowingIDs returns every account followerID follows (accepted only).
+	FollowingIDs(ctx context.Context, followerID uuid.UUID) ([]uuid.UUID, error)
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"http_server/user-service/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type followRepository struct {
+	db *gorm.DB
+}
+
+func NewFollowRepository(db *gorm.DB) FollowRepository {
+	return &followRepository{db: db}
+}
+
+func (r *followRepository) Find(ctx context.Context, followerID, followeeID uuid.UUID) (*models.Follow, error) {
+	var follow models.Follow
+	result := r.db.WithContext(ctx).
+		First(&follow, "follower_id = ? AND followee_id = ?", followerID, followeeID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, result.Error
+	}
+	return &follow, nil
+}
+
+func (r *followRepository) Create(ctx context.Context, follow *models.Follow) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(follow)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrDuplicateKey
+		}
+		if follow.Status != models.FollowAccepted {
+			return nil
+		}
+		return adjustFollowCounts(tx, follow.FollowerID, follow.FolloweeID, 1)
+	})
+}
+
+func (r *followRepository) Accept(ctx context.Context, followerID, followeeID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Follow{}).
+			Where("follower_id = ? AND followee_id = ? AND status = ?", followerID, followeeID, models.FollowPending).
+			Update("status", models.FollowAccepted)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return adjustFollowCounts(tx, followerID, followeeID, 1)
+	})
+}
+
+func (r *followRepository) AcceptAll(ctx context.Context, followeeID uuid.UUID) (int64, error) {
+	var accepted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var follows []models.Follow
+		result := tx.Model(&follows).
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "follower_id"}}}).
+			Where("followee_id = ? AND status = ?", followeeID, models.FollowPending).
+			Update("status", models.FollowAccepted)
+		if result.Error != nil {
+			return result.Error
+		}
+		accepted = result.RowsAffected
+		if accepted == 0 {
+			return nil
+		}
+
+		followerIDs := make([]uuid.UUID, len(follows))
+		for i, follow := range follows {
+			followerIDs[i] = follow.FollowerID
+		}
+		if err := tx.Model(&models.Profile{}).
+			Where("user_id IN ?", followerIDs).
+			UpdateColumn("following_count", gorm.Expr("following_count + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Profile{}).
+			Where("user_id = ?", followeeID).
+			UpdateColumn("followers_count", gorm.Expr("followers_count + ?", accepted)).Error
+	})
+	return accepted, err
+}
+
+func (r *followRepository) Delete(ctx context.Context, followerID, followeeID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		removed, err := deleteFollow(tx, followerID, followeeID)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// deleteFollow removes the follow between the two accounts, if any, and
+// releases the counters it held. It reports whether a row was removed.
+func deleteFollow(tx *gorm.DB, followerID, followeeID uuid.UUID) (bool, error) {
+	var removed []models.Follow
+	result := tx.Clauses(clause.Returning{}).
+		Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+		Delete(&removed)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if len(removed) == 0 {
+		return false, nil
+	}
+	if removed[0].Status == models.FollowAccepted {
+		if err := adjustFollowCounts(tx, followerID, followeeID, -1); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (r *followRepository) ListFollowers(ctx context.Context, followeeID uuid.UUID, status string, after *models.ListPosition, limit int) ([]models.Follow, error) {
+	query := r.db.WithContext(ctx).Where("followee_id = ? AND status = ?", followeeID, status)
+	if after != nil {
+		query = query.Where("(created_at, follower_id) < (?, ?)", after.At, after.UserID)
+	}
+
+	var follows []models.Follow
+	err := query.Order("created_at DESC, follower_id DESC").Limit(limit).Find(&follows).Error
+	return follows, err
+}
+
+func (r *followRepository) ListFollowing(ctx context.Context, followerID uuid.UUID, after *models.ListPosition, limit int) ([]models.Follow, error) {
+	query := r.db.WithContext(ctx).Where("follower_id = ? AND status = ?", followerID, models.FollowAccepted)
+	if after != nil {
+		query = query.Where("(created_at, followee_id) < (?, ?)", after.At, after.UserID)
+	}
+
+	var follows []models.Follow
+	err := query.Order("created_at DESC, followee_id DESC").Limit(limit).Find(&follows).Error
+	return follows, err
+}
+
+func (r *followRepository) FollowingIDs(ctx context.Context, followerID uuid.UUID) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	err := r.db.WithContext(ctx).Model(&models.Follow{}).
+		Where("follower_id = ? AND status = ?", followerID, models.FollowAccepted).
+		Pluck("followee_id", &ids).Error
+	return ids, err
+}
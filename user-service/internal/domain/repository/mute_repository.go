@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"http_server/user-service/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type MuteRepository interface {
+	// Create inserts a mute and returns ErrDuplicateKey if it exists.
+	Create(ctx context.Context, mute *models.Mute) error
+	Delete(ctx context.Context, muterID, mutedID uuid.UUID) error
+	Exists(ctx context.Context, muterID, mutedID uuid.UUID) (bool, error)
+	// List returns mutes made by muterID, newest first.
+	List(ctx context.Context, muterID uuid.UUID, after *models.ListPosition, limit int) ([]models.Mute, error)
+	// MutedIDs returns every account muterID has muted.
+	MutedIDs(ctx context.Context, muterID uuid.UUID) ([]uuid.UUID, error)
+}
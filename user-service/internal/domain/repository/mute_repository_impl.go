@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"http_server/user-service/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type muteRepository struct {
+	db *gorm.DB
+}
+
+func NewMuteRepository(db *gorm.DB) MuteRepository {
+	return &muteRepository{db: db}
+}
+
+func (r *muteRepository) Create(ctx context.Context, mute *models.Mute) error {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(mute)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDuplicateKey
+	}
+	return nil
+}
+
+func (r *muteRepository) Delete(ctx context.Context, muterID, mutedID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("muter_id = ? AND muted_id = ?", muterID, mutedID).
+		Delete(&models.Mute{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *muteRepository) Exists(ctx context.Context, muterID, mutedID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Mute{}).
+		Where("muter_id = ? AND muted_id = ?", muterID, mutedID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *muteRepository) List(ctx context.Context, muterID uuid.UUID, after *models.ListPosition, limit int) ([]models.Mute, error) {
+	query := r.db.WithContext(ctx).Where("muter_id = ?", muterID)
+	if after != nil {
+		query = query.Where("(created_at, muted_id) < (?, ?)", after.At, after.UserID)
+	}
+
+	var mutes []models.Mute
+	err := query.Order("created_at DESC, muted_id DESC").Limit(limit).Find(&mutes).Error
+	return mutes, err
+}
+
+func (r *muteRepository) MutedIDs(ctx context.Context, muterID uuid.UUID) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	err := r.db.WithContext(ctx).Model(&models.Mute{}).
+		Where("muter_id = ?", muterID).
+		Pluck("muted_id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"http_server/user-service/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type ProfileRepository interface {
+	// Create inserts a profile and returns ErrDuplicateKey if one already
+	// exists for the user.
+	Create(ctx context.Context, profile *models.Profile) error
+	FindByID(ctx context.Context, userID uuid.UUID) (*models.Profile, error)
+	// FindByIDs returns the profiles of active accounts among userIDs, in
+	// no particular order.
+	FindByIDs(ctx context.Context, userIDs []uuid.UUID) ([]models.Profile, error)
+	// ListAfter returns profiles ordered by user ID, starting strictly after
+	// the given ID (uuid.Nil for the first batch).
+	ListAfter(ctx context.Context, after uuid.UUID, limit int) ([]models.Profile, error)
+	// Update saves the editable fields of a profile and returns
+	// ErrDuplicateKey if the handle is taken. Counters are maintained by the
+	// relationship repositories and are never written here.
+	Update(ctx context.Context, profile *models.Profile) error
+	// UpdateAccountState saves the fields taken from auth-service: Active,
+	// Verified, Important and ValidatedAt.
+	UpdateAccountState(ctx context.Context, profile *models.Profile) error
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"http_server/user-service/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type profileRepository struct {
+	db *gorm.DB
+}
+
+func NewProfileRepository(db *gorm.DB) ProfileRepository {
+	return &profileRepository{db: db}
+}
+
+func (r *profileRepository) Create(ctx context.Context, profile *models.Profile) error {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(profile)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDuplicateKey
+	}
+	return nil
+}
+
+func (r *profileRepository) FindByID(ctx context.Context, userID uuid.UUID) (*models.Profile, error) {
+	var profile models.Profile
+	result := r.db.WithContext(ctx).First(&profile, "user_id = ?", userID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, result.Error
+	}
+	return &profile, nil
+}
+
+func (r *profileRepository) FindByIDs(ctx context.Context, userIDs []uuid.UUID) ([]models.Profile, error) {
+	var profiles []models.Profile
+	if len(userIDs) == 0 {
+		return profiles, nil
+	}
+	err := r.db.WithContext(ctx).Where("user_id IN ? AND active", userIDs).Find(&profiles).Error
+	return profiles, err
+}
+
+func (r *profileRepository) ListAfter(ctx context.Context, after uuid.UUID, limit int) ([]models.Profile, error) {
+	var profiles []models.Profile
+	err := r.db.WithContext(ctx).
+		Where("user_id > ?", after).
+		Order("user_id").
+		Limit(limit).
+		Find(&profiles).Error
+	return profiles, err
+}
+
+func (r *profileRepository) Update(ctx context.Context, profile *models.Profile) error {
+	result := r.db.WithContext(ctx).Model(&models.Profile{}).
+		Where("user_id = ?", profile.UserID).
+		Updates(map[string]interface{}{
+			"handle":          profile.Handle,
+			"name":            profile.Name,
+			"bio":             profile.Bio,
+			"profile_picture": profile.ProfilePicture,
+			"location":        profile.Location,
+			"is_private":      profile.IsPrivate,
+			"updated_at":      profile.UpdatedAt,
+		})
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateKey
+		}
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *profileRepository) UpdateAccountState(ctx context.Context, profile *models.Profile) error {
+	result := r.db.WithContext(ctx).Model(&models.Profile{}).
+		Where("user_id = ?", profile.UserID).
+		Updates(map[string]interface{}{
+			"active":       profile.Active,
+			"verified":     profile.Verified,
+			"important":    profile.Important,
+			"validated_at": profile.ValidatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// adjustFollowCounts moves the follower's following_count and the followee's
+// followers_count by delta inside tx.
+func adjustFollowCounts(tx *gorm.DB, followerID, followeeID uuid.UUID, delta int64) error {
+	if err := tx.Model(&models.Profile{}).
+		Where("user_id = ?", followerID).
+		UpdateColumn("following_count", gorm.Expr("following_count + ?", delta)).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Profile{}).
+		Where("user_id = ?", followeeID).
+		UpdateColumn("followers_count", gorm.Expr("followers_count + ?", delta)).Error
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	apperrors "http_server/shared/errors"
+	"http_server/shared/problem"
+	"http_server/user-service/internal/service"
+)
+
+// Stable error codes reported in problem responses. Clients may rely on
+// these, so existing values must not change.
+const (
+	CodeInvalidPayload        = "invalid_payload"
+	CodeUnauthorized          = "unauthorized"
+	CodeInvalidUserID         = "invalid_user_id"
+	CodeInvalidCursor         = "invalid_cursor"
+	CodeInvalidQuery          = "invalid_query"
+	CodeInvalidProfile        = "invalid_profile"
+	CodeUserNotFound          = "user_not_found"
+	CodeHandleTaken           = "handle_taken"
+	CodeSelfRelationship      = "self_relationship"
+	CodeNotFollowing          = "not_following"
+	CodeFollowRequestNotFound = "follow_request_not_found"
+	CodeNotBlocked            = "not_blocked"
+	CodeNotMuted              = "not_muted"
+	CodeAlreadyFollowing      = "already_following"
+	CodeAlreadyBlocked        = "already_blocked"
+	CodeAlreadyMuted          = "already_muted"
+	CodeBlocked               = "blocked"
+	CodePrivateAccount        = "private_account"
+)
+
+var (
+	errInvalidPayload = apperrors.NewBadRequestError("Invalid request payload", nil).WithCode(CodeInvalidPayload)
+	errUnauthorized   = apperrors.NewAuthenticationError("Authentication required", nil).WithCode(CodeUnauthorized)
+	errInvalidUserID  = apperrors.NewBadRequestError("Invalid user ID", nil).WithCode(CodeInvalidUserID)
+)
+
+// serviceErrors maps service sentinel errors onto client-facing errors.
+// Anything not listed is reported as an internal error.
+var serviceErrors = []struct {
+	err    error
+	appErr *apperrors.AppError
+}{
+	{service.ErrUserNotFound, apperrors.NewNotFoundError("User not found", nil).WithCode(CodeUserNotFound)},
+	{service.ErrHandleTaken, apperrors.NewConflictError("Handle already taken", nil).WithCode(CodeHandleTaken)},
+	{service.ErrInvalidCursor, apperrors.NewBadRequestError("Invalid cursor", nil).WithCode(CodeInvalidCursor)},
+	{service.ErrInvalidQuery, apperrors.NewValidationError("Invalid search query", nil).WithCode(CodeInvalidQuery)},
+	{service.ErrSelfRelationship, apperrors.NewValidationError("Cannot target your own account", nil).WithCode(CodeSelfRelationship)},
+	{service.ErrNotFollowing, apperrors.NewNotFoundError("Not following this user", nil).WithCode(CodeNotFollowing)},
+	{service.ErrFollowRequestNotFound, apperrors.NewNotFoundError("Follow request not found", nil).WithCode(CodeFollowRequestNotFound)},
+	{service.ErrNotBlocked, apperrors.NewNotFoundError("User is not blocked", nil).WithCode(CodeNotBlocked)},
+	{service.ErrNotMuted, apperrors.NewNotFoundError("User is not muted", nil).WithCode(CodeNotMuted)},
+	{service.ErrAlreadyFollowing, apperrors.NewConflictError("Already following or requested", nil).WithCode(CodeAlreadyFollowing)},
+	{service.ErrAlreadyBlocked, apperrors.NewConflictError("User is already blocked", nil).WithCode(CodeAlreadyBlocked)},
+	{service.ErrAlreadyMuted, apperrors.NewConflictError("User is already muted", nil).WithCode(CodeAlreadyMuted)},
+	{service.ErrBlocked, apperrors.NewAuthorizationError("Unblock this user first", nil).WithCode(CodeBlocked)},
+	{service.ErrPrivateAccount, apperrors.NewAuthorizationError("This account is private", nil).WithCode(CodePrivateAccount)},
+}
+
+// toAppError translates a service error for the client, keeping the original
+// as the cause for logging.
+func toAppError(err error) *apperrors.AppError {
+	if appErr, ok := apperrors.As(err); ok {
+		return appErr
+	}
+	if errors.Is(err, service.ErrInvalidProfile) {
+		// The service names the offending field in the message.
+		return apperrors.NewValidationError(err.Error(), err).WithCode(CodeInvalidProfile)
+	}
+	for _, mapping := range serviceErrors {
+		if errors.Is(err, mapping.err) {
+			appErr := *mapping.appErr
+			appErr.Err = err
+			return &appErr
+		}
+	}
+	return apperrors.NewInternalError("Internal server error", err)
+}
+
+func respondWithProblem(w http.ResponseWriter, r *http.Request, err error) {
+	problem.Write(w, r, toAppError(err))
+}
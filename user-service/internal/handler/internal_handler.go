@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"http_server/shared/logging"
+	"http_server/user-service/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// InternalHandler serves the relationship queries other services make. These
+// routes carry no user token; the internal server authenticates callers by
+// their client certificate instead.
+type InternalHandler struct {
+	relationshipService service.RelationshipService
+	logger              *logging.Logger
+}
+
+func NewInternalHandler(relationshipService service.RelationshipService, logger *logging.Logger) *InternalHandler {
+	return &InternalHandler{
+		relationshipService: relationshipService,
+		logger:              logger,
+	}
+}
+
+type userIDsResponse struct {
+	UserIDs []uuid.UUID `json:"user_ids"`
+}
+
+// Following serves GET /internal/v1/users/{id}/following
+func (h *InternalHandler) Following(w http.ResponseWriter, r *http.Request) {
+	h.listIDs(w, r, h.relationshipService.FollowingIDs)
+}
+
+// Blocked serves GET /internal/v1/users/{id}/blocked
+func (h *InternalHandler) Blocked(w http.ResponseWriter, r *http.Request) {
+	h.listIDs(w, r, h.relationshipService.BlockedIDs)
+}
+
+// BlockedBy serves GET /internal/v1/users/{id}/blocked-by
+func (h *InternalHandler) BlockedBy(w http.ResponseWriter, r *http.Request) {
+	h.listIDs(w, r, h.relationshipService.BlockedByIDs)
+}
+
+// Muted serves GET /internal/v1/users/{id}/muted
+func (h *InternalHandler) Muted(w http.ResponseWriter, r *http.Request) {
+	h.listIDs(w, r, h.relationshipService.MutedIDs)
+}
+
+// Relationship serves GET /internal/v1/users/{id}/relationships/{other}
+func (h *InternalHandler) Relationship(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidUserID)
+		return
+	}
+	otherID, err := uuid.Parse(mux.Vars(r)["other"])
+	if err != nil {
+		respondWithProblem(w, r, errInvalidUserID)
+		return
+	}
+
+	rel, err := h.relationshipService.Relationship(r.Context(), userID, otherID)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rel)
+}
+
+func (h *InternalHandler) listIDs(w http.ResponseWriter, r *http.Request, list func(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)) {
+	userID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidUserID)
+		return
+	}
+
+	ids, err := list(r.Context(), userID)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, userIDsResponse{UserIDs: ids})
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+	"http_server/user-service/internal/service"
+
+	"go.uber.org/zap"
+)
+
+type ProfileHandler struct {
+	profileService service.ProfileService
+	logger         *logging.Logger
+}
+
+func NewProfileHandler(profileService service.ProfileService, logger *logging.Logger) *ProfileHandler {
+	return &ProfileHandler{
+		profileService: profileService,
+		logger:         logger,
+	}
+}
+
+// Get serves GET /users/{id}
+func (h *ProfileHandler) Get(w http.ResponseWriter, r *http.Request) {
+	viewerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	userID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidUserID)
+		return
+	}
+
+	profile, err := h.profileService.GetProfile(r.Context(), viewerID, userID)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, profile)
+}
+
+// Me serves GET /users/me
+func (h *ProfileHandler) Me(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	profile, err := h.profileService.GetProfile(r.Context(), userID, userID)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, profile)
+}
+
+// UpdateMe serves PATCH /users/me
+func (h *ProfileHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithContext(r.Context())
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	var req service.ProfileUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("Failed to decode request payload", zap.Error(err))
+		respondWithProblem(w, r, errInvalidPayload)
+		return
+	}
+
+	profile, err := h.profileService.UpdateProfile(r.Context(), userID, req)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, profile)
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+	"http_server/user-service/internal/service"
+
+	"github.com/google/uuid"
+)
+
+type RelationshipHandler struct {
+	relationshipService service.RelationshipService
+	logger              *logging.Logger
+}
+
+func NewRelationshipHandler(relationshipService service.RelationshipService, logger *logging.Logger) *RelationshipHandler {
+	return &RelationshipHandler{
+		relationshipService: relationshipService,
+		logger:              logger,
+	}
+}
+
+// Follow serves POST /users/{id}/follow
+func (h *RelationshipHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := userAndTarget(w, r)
+	if !ok {
+		return
+	}
+
+	follow, err := h.relationshipService.Follow(r.Context(), userID, targetID)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, follow)
+}
+
+// Unfollow serves DELETE /users/{id}/follow
+func (h *RelationshipHandler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	h.applyToTarget(w, r, h.relationshipService.Unfollow)
+}
+
+// Followers serves GET /users/{id}/followers?cursor=&limit=
+func (h *RelationshipHandler) Followers(w http.ResponseWriter, r *http.Request) {
+	viewerID, userID, ok := userAndTarget(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, limit := pageParams(r)
+	page, err := h.relationshipService.Followers(r.Context(), viewerID, userID, cursor, limit)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// Following serves GET /users/{id}/following?cursor=&limit=
+func (h *RelationshipHandler) Following(w http.ResponseWriter, r *http.Request) {
+	viewerID, userID, ok := userAndTarget(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, limit := pageParams(r)
+	page, err := h.relationshipService.Following(r.Context(), viewerID, userID, cursor, limit)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// Relationship serves GET /users/{id}/relationship
+func (h *RelationshipHandler) Relationship(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := userAndTarget(w, r)
+	if !ok {
+		return
+	}
+
+	rel, err := h.relationshipService.Relationship(r.Context(), userID, targetID)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rel)
+}
+
+// FollowRequests serves GET /users/me/follow-requests?cursor=&limit=
+func (h *RelationshipHandler) FollowRequests(w http.ResponseWriter, r *http.Request) {
+	h.listOwn(w, r, h.relationshipService.FollowRequests)
+}
+
+// AcceptFollowRequest serves POST /users/me/follow-requests/{id}/accept
+func (h *RelationshipHandler) AcceptFollowRequest(w http.ResponseWriter, r *http.Request) {
+	h.applyToTarget(w, r, h.relationshipService.AcceptFollowRequest)
+}
+
+// RejectFollowRequest serves DELETE /users/me/follow-requests/{id}
+func (h *RelationshipHandler) RejectFollowRequest(w http.ResponseWriter, r *http.Request) {
+	h.applyToTarget(w, r, h.relationshipService.RejectFollowRequest)
+}
+
+// Block serves PUT /users/{id}/block
+func (h *RelationshipHandler) Block(w http.ResponseWriter, r *http.Request) {
+	h.applyToTarget(w, r, h.relationshipService.Block)
+}
+
+// Unblock serves DELETE /users/{id}/block
+func (h *RelationshipHandler) Unblock(w http.ResponseWriter, r *http.Request) {
+	h.applyToTarget(w, r, h.relationshipService.Unblock)
+}
+
+// Blocks serves GET /users/me/blocks?cursor=&limit=
+func (h *RelationshipHandler) Blocks(w http.ResponseWriter, r *http.Request) {
+	h.listOwn(w, r, h.relationshipService.Blocks)
+}
+
+// Mute serves PUT /users/{id}/mute
+func (h *RelationshipHandler) Mute(w http.ResponseWriter, r *http.Request) {
+	h.applyToTarget(w, r, h.relationshipService.Mute)
+}
+
+// Unmute serves DELETE /users/{id}/mute
+func (h *RelationshipHandler) Unmute(w http.ResponseWriter, r *http.Request) {
+	h.applyToTarget(w, r, h.relationshipService.Unmute)
+}
+
+// Mutes serves GET /users/me/mutes?cursor=&limit=
+func (h *RelationshipHandler) Mutes(w http.ResponseWriter, r *http.Request) {
+	h.listOwn(w, r, h.relationshipService.Mutes)
+}
+
+// applyToTarget runs an action of the caller on the account named by the
+// {id} route variable and answers 204 on success.
+func (h *RelationshipHandler) applyToTarget(w http.ResponseWriter, r *http.Request, action func(ctx context.Context, userID, targetID uuid.UUID) error) {
+	userID, targetID, ok := userAndTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if err := action(r.Context(), userID, targetID); err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listOwn serves one of the caller's own relationship lists.
+func (h *RelationshipHandler) listOwn(w http.ResponseWriter, r *http.Request, list func(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*service.UserPage, error)) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	cursor, limit := pageParams(r)
+	page, err := list(r.Context(), userID, cursor, limit)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// userAndTarget reads the caller from the token and the target account from
+// the {id} route variable, answering the request itself if either is missing.
+func userAndTarget(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	targetID, err := pathUUID(r, "id")
+	if err != nil {
+		respondWithProblem(w, r, errInvalidUserID)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, targetID, true
+}
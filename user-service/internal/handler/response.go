@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
+
+// pathUUID parses the named mux route variable as a UUID.
+func pathUUID(r *http.Request, name string) (uuid.UUID, error) {
+	return uuid.Parse(mux.Vars(r)[name])
+}
+
+// pageParams reads the cursor and limit query parameters. A missing or
+// malformed limit is reported as zero so the service applies its default.
+func pageParams(r *http.Request) (string, int) {
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	return query.Get("cursor"), limit
+}
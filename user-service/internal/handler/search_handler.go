@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+	"http_server/user-service/internal/service"
+)
+
+type SearchHandler struct {
+	searchService service.SearchService
+	logger        *logging.Logger
+}
+
+func NewSearchHandler(searchService service.SearchService, logger *logging.Logger) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+		logger:        logger,
+	}
+}
+
+// Search serves GET /users/search?q=&limit=
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	viewerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	result, err := h.searchService.Search(r.Context(), viewerID, query.Get("q"), limit)
+	if err != nil {
+		respondWithProblem(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
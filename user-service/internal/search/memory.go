@@ -0,0 +1,190 @@
+package search
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type memoryEntry struct {
+	doc      Document
+	trigrams []string
+	tokens   []string
+}
+
+type tokenRef struct {
+	token  string
+	userID uuid.UUID
+}
+
+// memoryIndex is an in-process index for tests and local runs. Fuzzy
+// candidates come from a trigram posting list and prefix candidates from a
+// sorted list of the handle, the name and every name suffix that starts a
+// word. Candidates are then scored and ranked like the postgres index.
+type memoryIndex struct {
+	mu       sync.RWMutex
+	entries  map[uuid.UUID]*memoryEntry
+	postings map[string]map[uuid.UUID]struct{}
+	tokens   []tokenRef
+}
+
+func NewMemoryIndex() Index {
+	return &memoryIndex{
+		entries:  make(map[uuid.UUID]*memoryEntry),
+		postings: make(map[string]map[uuid.UUID]struct{}),
+	}
+}
+
+func (m *memoryIndex) Upsert(ctx context.Context, doc Document) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(doc.UserID)
+
+	name := strings.ToLower(doc.Name)
+	entry := &memoryEntry{doc: doc}
+	set := trigrams(name)
+	for t := range trigrams(doc.Handle) {
+		set[t] = struct{}{}
+	}
+	for t := range set {
+		entry.trigrams = append(entry.trigrams, t)
+		if m.postings[t] == nil {
+			m.postings[t] = make(map[uuid.UUID]struct{})
+		}
+		m.postings[t][doc.UserID] = struct{}{}
+	}
+
+	if doc.Handle != "" {
+		entry.tokens = append(entry.tokens, doc.Handle)
+	}
+	entry.tokens = append(entry.tokens, name)
+	for i, r := range name {
+		if r == ' ' {
+			entry.tokens = append(entry.tokens, name[i+1:])
+		}
+	}
+	for _, token := range entry.tokens {
+		m.insertToken(tokenRef{token: token, userID: doc.UserID})
+	}
+
+	m.entries[doc.UserID] = entry
+	return nil
+}
+
+func (m *memoryIndex) Remove(ctx context.Context, userID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(userID)
+	return nil
+}
+
+func (m *memoryIndex) remove(userID uuid.UUID) {
+	entry, ok := m.entries[userID]
+	if !ok {
+		return
+	}
+	for _, t := range entry.trigrams {
+		delete(m.postings[t], userID)
+		if len(m.postings[t]) == 0 {
+			delete(m.postings, t)
+		}
+	}
+	for _, token := range entry.tokens {
+		m.deleteToken(tokenRef{token: token, userID: userID})
+	}
+	delete(m.entries, userID)
+}
+
+func (m *memoryIndex) Search(ctx context.Context, query Query) ([]Hit, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	candidates := make(map[uuid.UUID]struct{})
+	for i := m.searchToken(tokenRef{token: query.Text}); i < len(m.tokens) && strings.HasPrefix(m.tokens[i].token, query.Text); i++ {
+		candidates[m.tokens[i].userID] = struct{}{}
+	}
+	for t := range trigrams(query.Text) {
+		for userID := range m.postings[t] {
+			candidates[userID] = struct{}{}
+		}
+	}
+	for _, userID := range query.Exclude {
+		delete(candidates, userID)
+	}
+
+	type ranked struct {
+		hit   Hit
+		doc   Document
+		exact bool
+	}
+	matches := make([]ranked, 0, len(candidates))
+	for userID := range candidates {
+		doc := m.entries[userID].doc
+		s := score(doc, query.Text)
+		if s < query.MinSimilarity {
+			continue
+		}
+		matches = append(matches, ranked{
+			hit:   Hit{UserID: userID, Score: s},
+			doc:   doc,
+			exact: doc.Handle != "" && doc.Handle == query.Text,
+		})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		switch {
+		case a.exact != b.exact:
+			return a.exact
+		case a.doc.Important != b.doc.Important:
+			return a.doc.Important
+		case a.doc.Verified != b.doc.Verified:
+			return a.doc.Verified
+		case a.hit.Score != b.hit.Score:
+			return a.hit.Score > b.hit.Score
+		default:
+			return a.hit.UserID.String() < b.hit.UserID.String()
+		}
+	})
+
+	if len(matches) > query.Limit {
+		matches = matches[:query.Limit]
+	}
+	hits := make([]Hit, len(matches))
+	for i, match := range matches {
+		hits[i] = match.hit
+	}
+	return hits, nil
+}
+
+// searchToken returns the position of the first token not ordered before ref.
+func (m *memoryIndex) searchToken(ref tokenRef) int {
+	return sort.Search(len(m.tokens), func(i int) bool {
+		t := m.tokens[i]
+		if t.token != ref.token {
+			return t.token > ref.token
+		}
+		return t.userID.String() >= ref.userID.String()
+	})
+}
+
+func (m *memoryIndex) insertToken(ref tokenRef) {
+	i := m.searchToken(ref)
+	if i < len(m.tokens) && m.tokens[i] == ref {
+		return
+	}
+	m.tokens = append(m.tokens, tokenRef{})
+	copy(m.tokens[i+1:], m.tokens[i:])
+	m.tokens[i] = ref
+}
+
+func (m *memoryIndex) deleteToken(ref tokenRef) {
+	i := m.searchToken(ref)
+	if i < len(m.tokens) && m.tokens[i] == ref {
+		m.tokens = append(m.tokens[:i], m.tokens[i+1:]...)
+	}
+}
@@ -0,0 +1,153 @@
+package search
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNormalize(t *testing.T) {
+	for input, want := range map[string]string{
+		"  @Ada   Love ": "ada love",
+		"ADA":            "ada",
+		"@":              "",
+		"ada\tlovelace":  "ada lovelace",
+	} {
+		if got := Normalize(input); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestSimilarityMatchesPgTrgm(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		// Values from SELECT similarity(a, b) with pg_trgm.
+		{"word", "words", 4.0 / 7},
+		{"word", "word", 1},
+		{"Word", "wORD", 1},
+		{"abc", "xyz", 0},
+		{"", "abc", 0},
+	}
+	for _, tt := range tests {
+		if got := similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+type indexFixture struct {
+	index Index
+	ids   map[string]uuid.UUID
+}
+
+func newIndexFixture(t *testing.T, docs ...Document) *indexFixture {
+	t.Helper()
+	f := &indexFixture{index: NewMemoryIndex(), ids: make(map[string]uuid.UUID)}
+	for _, doc := range docs {
+		doc.UserID = uuid.New()
+		f.ids[doc.Handle] = doc.UserID
+		if err := f.index.Upsert(context.Background(), doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return f
+}
+
+// search returns the handles of the hits for text.
+func (f *indexFixture) search(t *testing.T, text string, exclude ...string) []string {
+	t.Helper()
+	query := Query{Text: Normalize(text), MinSimilarity: 0.3, Limit: 10}
+	for _, handle := range exclude {
+		query.Exclude = append(query.Exclude, f.ids[handle])
+	}
+	hits, err := f.index.Search(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handles := make([]string, len(hits))
+	for i, hit := range hits {
+		for handle, id := range f.ids {
+			if id == hit.UserID {
+				handles[i] = handle
+			}
+		}
+		if hit.Score < query.MinSimilarity || hit.Score > 1 {
+			t.Errorf("hit %s score = %v, out of range", handles[i], hit.Score)
+		}
+	}
+	return handles
+}
+
+func TestMemoryIndexRanking(t *testing.T) {
+	f := newIndexFixture(t,
+		Document{Name: "Ada Lovelace", Handle: "ada"},
+		Document{Name: "Ada Byron", Handle: "adabyron", Verified: true},
+		Document{Name: "Adam Smith", Handle: "adam", Important: true},
+		Document{Name: "Grace Hopper", Handle: "grace"},
+	)
+
+	// The exact handle wins, then important, then verified accounts.
+	if got, want := f.search(t, "@Ada"), []string{"ada", "adam", "adabyron"}; !slices.Equal(got, want) {
+		t.Errorf("search ada = %v, want %v", got, want)
+	}
+	// A prefix of a later word in the name matches.
+	if got, want := f.search(t, "lovel"), []string{"ada"}; !slices.Equal(got, want) {
+		t.Errorf("search lovel = %v, want %v", got, want)
+	}
+	// A typo still finds the name through trigram similarity.
+	if got, want := f.search(t, "grace hoper"), []string{"grace"}; !slices.Equal(got, want) {
+		t.Errorf("search grace hoper = %v, want %v", got, want)
+	}
+	if got := f.search(t, "zzz"); len(got) != 0 {
+		t.Errorf("search zzz = %v, want no hits", got)
+	}
+}
+
+func TestMemoryIndexExcludeAndLimit(t *testing.T) {
+	f := newIndexFixture(t,
+		Document{Name: "Ada One", Handle: "ada1"},
+		Document{Name: "Ada Two", Handle: "ada2"},
+		Document{Name: "Ada Three", Handle: "ada3"},
+	)
+
+	if got := f.search(t, "ada", "ada2"); len(got) != 2 || got[0] == "ada2" || got[1] == "ada2" {
+		t.Errorf("search excluding ada2 = %v", got)
+	}
+	hits, err := f.index.Search(context.Background(), Query{Text: "ada", Limit: 1})
+	if err != nil || len(hits) != 1 {
+		t.Errorf("search with limit 1 = %v, %v", hits, err)
+	}
+}
+
+func TestMemoryIndexUpsertReplacesAndRemoves(t *testing.T) {
+	f := newIndexFixture(t, Document{Name: "Ada Lovelace", Handle: "ada"})
+	ctx := context.Background()
+
+	renamed := Document{UserID: f.ids["ada"], Name: "Grace Hopper", Handle: "ada"}
+	if err := f.index.Upsert(ctx, renamed); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.search(t, "lovelace"); len(got) != 0 {
+		t.Errorf("old name still found: %v", got)
+	}
+	if got, want := f.search(t, "hopper"), []string{"ada"}; !slices.Equal(got, want) {
+		t.Errorf("search hopper = %v, want %v", got, want)
+	}
+
+	if err := f.index.Remove(ctx, f.ids["ada"]); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.search(t, "ada"); len(got) != 0 {
+		t.Errorf("removed profile still found: %v", got)
+	}
+	// Removing an unknown user is a no-op.
+	if err := f.index.Remove(ctx, uuid.New()); err != nil {
+		t.Errorf("Remove of an unknown user = %v", err)
+	}
+}
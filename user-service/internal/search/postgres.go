@@ -0,0 +1,101 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// postgresIndex searches the profiles table directly, so Upsert and Remove
+// have nothing to do. Fuzzy and prefix matches are served by pg_trgm GIN
+// indexes on the handle and the lowercased name.
+type postgresIndex struct {
+	db *gorm.DB
+}
+
+func newPostgresIndex(db *gorm.DB) (Index, error) {
+	statements := []string{
+		"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+		"CREATE INDEX IF NOT EXISTS idx_profiles_name_trgm ON profiles USING gin (lower(name) gin_trgm_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_profiles_handle_trgm ON profiles USING gin (handle gin_trgm_ops)",
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return nil, fmt.Errorf("failed to prepare search indexes: %w", err)
+		}
+	}
+	return &postgresIndex{db: db}, nil
+}
+
+func (p *postgresIndex) Upsert(ctx context.Context, doc Document) error {
+	return nil
+}
+
+func (p *postgresIndex) Remove(ctx context.Context, userID uuid.UUID) error {
+	return nil
+}
+
+// searchQuery mirrors score(). The % operator uses the similarity threshold
+// set for the transaction, which lets the GIN indexes prune candidates.
+const searchQuery = `
+SELECT user_id, score FROM (
+	SELECT user_id, important, verified,
+		coalesce(handle, '') = @q AS exact,
+		GREATEST(
+			CASE WHEN handle LIKE @prefix
+				THEN 0.5 + 0.5 * @q_length / length(handle)::float8 ELSE 0 END,
+			CASE WHEN lower(name) LIKE @prefix OR lower(name) LIKE @word_prefix
+				THEN 0.5 + 0.5 * @q_length / length(name)::float8 ELSE 0 END,
+			similarity(coalesce(handle, ''), @q),
+			similarity(lower(name), @q)
+		) AS score
+	FROM profiles
+	WHERE active
+		AND (handle LIKE @prefix
+			OR lower(name) LIKE @prefix
+			OR lower(name) LIKE @word_prefix
+			OR handle % @q
+			OR lower(name) % @q)
+	%s
+) matches
+WHERE score >= @min_similarity
+ORDER BY exact DESC, important DESC, verified DESC, score DESC, user_id
+LIMIT @limit`
+
+func (p *postgresIndex) Search(ctx context.Context, query Query) ([]Hit, error) {
+	args := map[string]interface{}{
+		"q":              query.Text,
+		"q_length":       utf8.RuneCountInString(query.Text),
+		"prefix":         escapeLike(query.Text) + "%",
+		"word_prefix":    "% " + escapeLike(query.Text) + "%",
+		"min_similarity": query.MinSimilarity,
+		"limit":          query.Limit,
+	}
+	exclude := ""
+	if len(query.Exclude) > 0 {
+		exclude = "AND user_id NOT IN @exclude"
+		args["exclude"] = query.Exclude
+	}
+	sql := strings.Replace(searchQuery, "%s", exclude, 1)
+
+	var hits []Hit
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		threshold := strconv.FormatFloat(query.MinSimilarity, 'f', -1, 64)
+		if err := tx.Exec("SELECT set_config('pg_trgm.similarity_threshold', ?, true)", threshold).Error; err != nil {
+			return err
+		}
+		return tx.Raw(sql, args).Scan(&hits).Error
+	})
+	return hits, err
+}
+
+// escapeLike escapes the LIKE wildcards in s, so handles such as "ada_l"
+// match literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,87 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"http_server/user-service/internal/config"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Document is the searchable part of a profile.
+type Document struct {
+	UserID    uuid.UUID
+	Name      string
+	Handle    string
+	Verified  bool
+	Important bool
+}
+
+// Query is a normalized search request.
+type Query struct {
+	Text          string
+	Exclude       []uuid.UUID
+	MinSimilarity float64
+	Limit         int
+}
+
+// Hit is a matching account and its match score in [0, 1].
+type Hit struct {
+	UserID uuid.UUID
+	Score  float64
+}
+
+// Index finds profiles by name or handle. Hits are ordered by exact handle
+// match first, then important accounts, then verified accounts, then score.
+type Index interface {
+	// Upsert adds or replaces the document for doc.UserID.
+	Upsert(ctx context.Context, doc Document) error
+	Remove(ctx context.Context, userID uuid.UUID) error
+	Search(ctx context.Context, query Query) ([]Hit, error)
+}
+
+// New returns the index selected by cfg.Driver. The postgres driver prepares
+// its trigram indexes on the profiles table, which must already exist.
+func New(cfg config.SearchConfig, db *gorm.DB) (Index, error) {
+	switch cfg.Driver {
+	case "postgres":
+		return newPostgresIndex(db)
+	case "memory":
+		return NewMemoryIndex(), nil
+	default:
+		return nil, fmt.Errorf("unsupported search driver %q", cfg.Driver)
+	}
+}
+
+// Normalize lowercases text, drops a leading @ and collapses whitespace so
+// that "  @Ada   Love" and "ada love" search alike.
+func Normalize(text string) string {
+	text = strings.TrimPrefix(strings.TrimSpace(text), "@")
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// score rates how well doc matches the normalized query q. An exact or prefix
+// match on the handle, the name or any word of the name scores at least 0.5,
+// growing with how much of the field the query covers. Anything else scores
+// its trigram similarity. postgresIndex computes the same score in SQL.
+func score(doc Document, q string) float64 {
+	name := strings.ToLower(doc.Name)
+	best := 0.0
+
+	if doc.Handle != "" && strings.HasPrefix(doc.Handle, q) {
+		best = prefixScore(q, doc.Handle)
+	}
+	if strings.HasPrefix(name, q) || strings.Contains(name, " "+q) {
+		best = max(best, prefixScore(q, name))
+	}
+	best = max(best, similarity(doc.Handle, q), similarity(name, q))
+	return best
+}
+
+func prefixScore(q, field string) float64 {
+	return 0.5 + 0.5*float64(utf8.RuneCountInString(q))/float64(utf8.RuneCountInString(field))
+}
@@ -0,0 +1,41 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// trigrams returns the set of trigrams of s the way pg_trgm builds them:
+// s is split into words of letters and digits, each word is lowercased and
+// padded with two spaces in front and one behind, and every run of three
+// characters is collected.
+func trigrams(s string) map[string]struct{} {
+	set := make(map[string]struct{})
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+// similarity matches pg_trgm's similarity(): the number of shared trigrams
+// divided by the number of distinct trigrams in either string.
+func similarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
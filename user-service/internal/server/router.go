@@ -0,0 +1,86 @@
+package server
+
+import (
+	"http_server/shared/health"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+	"http_server/shared/problem"
+	"http_server/user-service/internal/handler"
+
+	"github.com/gorilla/mux"
+)
+
+type Handlers struct {
+	Profile      *handler.ProfileHandler
+	Relationship *handler.RelationshipHandler
+	Search       *handler.SearchHandler
+}
+
+func NewRouter(h Handlers, healthRegistry *health.Registry, authMiddleware *middleware.JWTAuth, logger *logging.Logger) *mux.Router {
+	r := mux.NewRouter()
+	r.NotFoundHandler = problem.NotFoundHandler()
+	r.MethodNotAllowedHandler = problem.MethodNotAllowedHandler()
+
+	// Request ID, access logging and panic recovery
+	r.Use(middleware.RequestID, middleware.Logging(logger), middleware.Recovery(logger))
+
+	// Health probes; /health is kept as an alias of /readyz for existing
+	// callers.
+	r.Handle("/livez", healthRegistry.LiveHandler()).Methods("GET")
+	r.Handle("/readyz", healthRegistry.ReadyHandler()).Methods("GET")
+	r.Handle("/health", healthRegistry.ReadyHandler()).Methods("GET")
+
+	// API routes
+	api := r.PathPrefix("/api/v1").Subrouter()
+	api.Use(authMiddleware.ValidateJWT)
+
+	// Own account and search; registered before /users/{id} so "me" and
+	// "search" are not taken as IDs
+	api.HandleFunc("/users/me", h.Profile.Me).Methods("GET")
+	api.HandleFunc("/users/me", h.Profile.UpdateMe).Methods("PATCH")
+	api.HandleFunc("/users/me/follow-requests", h.Relationship.FollowRequests).Methods("GET")
+	api.HandleFunc("/users/me/follow-requests/{id}/accept", h.Relationship.AcceptFollowRequest).Methods("POST")
+	api.HandleFunc("/users/me/follow-requests/{id}", h.Relationship.RejectFollowRequest).Methods("DELETE")
+	api.HandleFunc("/users/me/blocks", h.Relationship.Blocks).Methods("GET")
+	api.HandleFunc("/users/me/mutes", h.Relationship.Mutes).Methods("GET")
+	api.HandleFunc("/users/search", h.Search.Search).Methods("GET")
+
+	// Profiles
+	api.HandleFunc("/users/{id}", h.Profile.Get).Methods("GET")
+	api.HandleFunc("/users/{id}/relationship", h.Relationship.Relationship).Methods("GET")
+
+	// Follow graph
+	api.HandleFunc("/users/{id}/follow", h.Relationship.Follow).Methods("POST")
+	api.HandleFunc("/users/{id}/follow", h.Relationship.Unfollow).Methods("DELETE")
+	api.HandleFunc("/users/{id}/followers", h.Relationship.Followers).Methods("GET")
+	api.HandleFunc("/users/{id}/following", h.Relationship.Following).Methods("GET")
+
+	// Blocks and mutes
+	api.HandleFunc("/users/{id}/block", h.Relationship.Block).Methods("PUT")
+	api.HandleFunc("/users/{id}/block", h.Relationship.Unblock).Methods("DELETE")
+	api.HandleFunc("/users/{id}/mute", h.Relationship.Mute).Methods("PUT")
+	api.HandleFunc("/users/{id}/mute", h.Relationship.Unmute).Methods("DELETE")
+
+	return r
+}
+
+// NewInternalRouter serves the internal API to other services. It runs on
+// its own mutual TLS listener, and every route needs a client certificate
+// mapped to a principal.
+func NewInternalRouter(h *handler.InternalHandler, machineAuth *middleware.MachineAuth, logger *logging.Logger) *mux.Router {
+	r := mux.NewRouter()
+	r.NotFoundHandler = problem.NotFoundHandler()
+	r.MethodNotAllowedHandler = problem.MethodNotAllowedHandler()
+
+	r.Use(middleware.RequestID, middleware.Logging(logger), middleware.Recovery(logger))
+
+	internal := r.PathPrefix("/internal/v1").Subrouter()
+	internal.Use(machineAuth.RequireClientCert)
+	internal.HandleFunc("/users/{id}/following", h.Following).Methods("GET")
+	internal.HandleFunc("/users/{id}/blocked", h.Blocked).Methods("GET")
+	internal.HandleFunc("/users/{id}/blocked-by", h.BlockedBy).Methods("GET")
+	internal.HandleFunc("/users/{id}/muted", h.Muted).Methods("GET")
+	internal.HandleFunc("/users/{id}/relationships/{other}", h.Relationship).Methods("GET")
+
+	return r
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"http_server/shared/health"
+	"http_server/shared/jwtkeys"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+	sharedserver "http_server/shared/server"
+	"http_server/shared/server/tlstest"
+	"http_server/user-service/internal/handler"
+	"http_server/user-service/internal/service"
+
+	"github.com/google/uuid"
+)
+
+const postServiceURI = "spiffe://cluster.local/ns/default/sa/post-service"
+
+func newTestLogger(t *testing.T) *logging.Logger {
+	t.Helper()
+	logger, err := logging.NewLogger(&logging.Config{ServiceName: "test", LogLevel: "error", FilePath: "stderr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+type fakeRelationships struct {
+	service.RelationshipService
+	following map[uuid.UUID][]uuid.UUID
+}
+
+func (f fakeRelationships) FollowingIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	return f.following[userID], nil
+}
+
+// startInternalServer serves the internal router over mutual TLS, as
+// NewInternalServer does, with the post-service identity mapped.
+func startInternalServer(t *testing.T, ca *tlstest.CA, relationships service.RelationshipService) string {
+	t.Helper()
+	logger := newTestLogger(t)
+	machineAuth := middleware.NewMachineAuth(map[string]string{postServiceURI: "post-service"}, logger)
+	router := NewInternalRouter(handler.NewInternalHandler(relationships, logger), machineAuth, logger)
+
+	certFile, keyFile := ca.ServerFiles(t, "user-service")
+	reloader, err := sharedserver.NewTLSReloader(sharedserver.TLSConfig{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCAFile: ca.File,
+		ClientAuth:   sharedserver.ClientAuthRequire,
+	}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(router)
+	srv.TLS = reloader.Config()
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestInternalRouterRequiresMappedClientCert(t *testing.T) {
+	ca := tlstest.NewCA(t)
+	userID, followed := uuid.New(), uuid.New()
+	url := startInternalServer(t, ca, fakeRelationships{following: map[uuid.UUID][]uuid.UUID{userID: {followed}}})
+	path := url + "/internal/v1/users/" + userID.String() + "/following"
+
+	clientFor := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: ca.Pool, Certificates: certs}}}
+	}
+
+	resp, err := clientFor(ca.ClientCert(t, "post-service", postServiceURI)).Get(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body struct {
+		UserIDs []uuid.UUID `json:"user_ids"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || len(body.UserIDs) != 1 || body.UserIDs[0] != followed {
+		t.Errorf("mapped certificate: status %d, user_ids %v, want 200 and [%s]", resp.StatusCode, body.UserIDs, followed)
+	}
+
+	resp, err = clientFor(ca.ClientCert(t, "someone-else", "spiffe://cluster.local/ns/default/sa/someone-else")).Get(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("unmapped certificate: status %d, want 403", resp.StatusCode)
+	}
+
+	if resp, err := clientFor().Get(path); err == nil {
+		resp.Body.Close()
+		t.Errorf("no certificate: status %d, want the handshake refused", resp.StatusCode)
+	}
+}
+
+func TestPublicRouterHasNoInternalRoutes(t *testing.T) {
+	logger := newTestLogger(t)
+	router := NewRouter(Handlers{}, health.New(health.Config{}), middleware.NewJWTAuth(jwtkeys.New([]byte("test-key")), logger), logger)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/internal/v1/users/"+uuid.NewString()+"/following", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
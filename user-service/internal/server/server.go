@@ -0,0 +1,48 @@
+package server
+
+import (
+	"http_server/shared/health"
+	"http_server/shared/logging"
+	"http_server/shared/middleware"
+	sharedserver "http_server/shared/server"
+	"http_server/user-service/internal/config"
+	"http_server/user-service/internal/handler"
+)
+
+func NewServer(cfg *config.Config, handlers Handlers, healthRegistry *health.Registry, authMiddleware *middleware.JWTAuth, logger *logging.Logger) *sharedserver.Server {
+	router := NewRouter(handlers, healthRegistry, authMiddleware, logger)
+
+	return sharedserver.New(sharedserver.Config{
+		Port:            cfg.Server.Port,
+		ReadTimeout:     cfg.Server.ReadTimeout,
+		WriteTimeout:    cfg.Server.WriteTimeout,
+		ShutdownTimeout: cfg.Server.ShutdownTimeout,
+		DrainDelay:      cfg.Server.DrainDelay,
+		OnDrain:         healthRegistry.SetDraining,
+	}, router, logger)
+}
+
+// NewInternalServer serves the internal API over mutual TLS, refusing
+// connections without a client certificate from the configured CA.
+func NewInternalServer(cfg *config.Config, internal *handler.InternalHandler, logger *logging.Logger) *sharedserver.Server {
+	principals := make(map[string]string, len(cfg.Server.Internal.Principals))
+	for _, principal := range cfg.Server.Internal.Principals {
+		principals[principal.Identity] = principal.Principal
+	}
+	router := NewInternalRouter(internal, middleware.NewMachineAuth(principals, logger), logger)
+
+	return sharedserver.New(sharedserver.Config{
+		Port:            cfg.Server.Internal.Port,
+		ReadTimeout:     cfg.Server.ReadTimeout,
+		WriteTimeout:    cfg.Server.WriteTimeout,
+		ShutdownTimeout: cfg.Server.ShutdownTimeout,
+		TLS: &sharedserver.TLSConfig{
+			CertFile:       cfg.Server.Internal.CertFile,
+			KeyFile:        cfg.Server.Internal.KeyFile,
+			MinVersion:     cfg.Server.Internal.MinVersion,
+			ClientCAFile:   cfg.Server.Internal.ClientCAFile,
+			ClientAuth:     sharedserver.ClientAuthRequire,
+			ReloadInterval: cfg.Server.Internal.ReloadInterval,
+		},
+	}, router, logger)
+}
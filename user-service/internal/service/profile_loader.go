@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"http_server/shared/logging"
+	"http_server/user-service/internal/client"
+	"http_server/user-service/internal/config"
+	"http_server/user-service/internal/domain/models"
+	"http_server/user-service/internal/domain/repository"
+	"http_server/user-service/internal/search"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ProfileLoader returns stored profiles, seeding missing ones from the user
+// record in auth-service on first access. Seeded profiles are added to the
+// search index.
+//
+// The account state taken from auth-service (whether the account is active,
+// and its badges) is checked again once it is older than revalidateAfter,
+// so deactivated and deleted accounts disappear and badge changes show up
+// within that time. Profiles of inactive accounts are kept, since
+// deactivation can be undone, but are reported as not found.
+type ProfileLoader struct {
+	profileRepo     repository.ProfileRepository
+	users           client.UserDirectory
+	index           search.Index
+	revalidateAfter time.Duration
+	logger          *logging.Logger
+}
+
+func NewProfileLoader(profileRepo repository.ProfileRepository, users client.UserDirectory, index search.Index, cfg config.ProfilesConfig, logger *logging.Logger) *ProfileLoader {
+	return &ProfileLoader{
+		profileRepo:     profileRepo,
+		users:           users,
+		index:           index,
+		revalidateAfter: cfg.RevalidateAfter,
+		logger:          logger,
+	}
+}
+
+func (l *ProfileLoader) load(ctx context.Context, userID uuid.UUID) (*models.Profile, error) {
+	logger := l.logger.WithContext(ctx)
+
+	profile, err := l.profileRepo.FindByID(ctx, userID)
+	if err == nil {
+		if profile.ValidatedAt == nil || time.Since(*profile.ValidatedAt) >= l.revalidateAfter {
+			return l.revalidate(ctx, profile)
+		}
+		return visible(profile)
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		logger.Error("Failed to find profile", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to find profile: %w", err)
+	}
+
+	user, err := l.users.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, client.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		logger.Error("Failed to fetch user from auth service", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if !user.Active {
+		return nil, ErrUserNotFound
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	profile = &models.Profile{
+		UserID:         userID,
+		Name:           user.Name,
+		Bio:            user.Bio,
+		ProfilePicture: user.ProfilePicture,
+		Location:       user.Location,
+		Active:         true,
+		Verified:       user.Verified,
+		Important:      hasImportantRole(user.Roles),
+		ValidatedAt:    &now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := l.profileRepo.Create(ctx, profile); err != nil {
+		if errors.Is(err, repository.ErrDuplicateKey) {
+			// Seeded concurrently by another request.
+			return l.profileRepo.FindByID(ctx, userID)
+		}
+		logger.Error("Failed to create profile", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to create profile: %w", err)
+	}
+
+	if err := l.index.Upsert(ctx, searchDocument(profile)); err != nil {
+		logger.Warn("Failed to index profile", zap.String("user_id", userID.String()), zap.Error(err))
+	}
+
+	logger.Info("Seeded profile from auth service", zap.String("user_id", userID.String()))
+	return profile, nil
+}
+
+// revalidate refreshes the account state of a stored profile from
+// auth-service. While auth-service cannot be reached the stored state is
+// served, and checked again on the next load.
+func (l *ProfileLoader) revalidate(ctx context.Context, profile *models.Profile) (*models.Profile, error) {
+	logger := l.logger.WithContext(ctx)
+	userField := zap.String("user_id", profile.UserID.String())
+
+	user, err := l.users.GetUser(ctx, profile.UserID)
+	if errors.Is(err, client.ErrUserNotFound) {
+		// Deleted from auth-service
+		user, err = &client.UserRecord{ID: profile.UserID}, nil
+	}
+	if err != nil {
+		logger.Warn("Failed to revalidate profile, serving stored state", userField, zap.Error(err))
+		return visible(profile)
+	}
+
+	wasActive := profile.Active
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	profile.Active = user.Active
+	profile.Verified = user.Verified
+	profile.Important = hasImportantRole(user.Roles)
+	profile.ValidatedAt = &now
+	if err := l.profileRepo.UpdateAccountState(ctx, profile); err != nil {
+		logger.Error("Failed to save revalidated profile", err, userField)
+		return nil, fmt.Errorf("failed to save profile: %w", err)
+	}
+
+	if profile.Active {
+		err = l.index.Upsert(ctx, searchDocument(profile))
+	} else {
+		err = l.index.Remove(ctx, profile.UserID)
+	}
+	if err != nil {
+		logger.Warn("Failed to update profile in search index", userField, zap.Error(err))
+	}
+	if wasActive != profile.Active {
+		logger.Info("Account state changed in auth service", userField, zap.Bool("active", profile.Active))
+	}
+	return visible(profile)
+}
+
+// visible hides the profiles of inactive accounts.
+func visible(profile *models.Profile) (*models.Profile, error) {
+	if !profile.Active {
+		return nil, ErrUserNotFound
+	}
+	return profile, nil
+}
+
+// hasImportantRole reports whether roles mark an account to be ranked first.
+func hasImportantRole(roles []string) bool {
+	for _, role := range roles {
+		if role == "important_person" || role == "official_news" {
+			return true
+		}
+	}
+	return false
+}
+
+func searchDocument(profile *models.Profile) search.Document {
+	doc := search.Document{
+		UserID:    profile.UserID,
+		Name:      profile.Name,
+		Verified:  profile.Verified,
+		Important: profile.Important,
+	}
+	if profile.Handle != nil {
+		doc.Handle = *profile.Handle
+	}
+	return doc
+}
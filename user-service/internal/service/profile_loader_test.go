@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"http_server/shared/logging"
+	"http_server/user-service/internal/client"
+	"http_server/user-service/internal/config"
+	"http_server/user-service/internal/domain/models"
+	"http_server/user-service/internal/domain/repository"
+	"http_server/user-service/internal/search"
+
+	"github.com/google/uuid"
+)
+
+func newTestLogger(t *testing.T) *logging.Logger {
+	t.Helper()
+	logger, err := logging.NewLogger(&logging.Config{ServiceName: "test", LogLevel: "error", FilePath: "stderr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+// fakeProfiles keeps profiles in memory.
+type fakeProfiles struct {
+	repository.ProfileRepository
+	mu       sync.Mutex
+	profiles map[uuid.UUID]*models.Profile
+}
+
+func newFakeProfiles(profiles ...*models.Profile) *fakeProfiles {
+	f := &fakeProfiles{profiles: make(map[uuid.UUID]*models.Profile)}
+	for _, profile := range profiles {
+		f.profiles[profile.UserID] = profile
+	}
+	return f
+}
+
+func (f *fakeProfiles) Create(ctx context.Context, profile *models.Profile) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.profiles[profile.UserID]; ok {
+		return repository.ErrDuplicateKey
+	}
+	stored := *profile
+	f.profiles[profile.UserID] = &stored
+	return nil
+}
+
+func (f *fakeProfiles) FindByID(ctx context.Context, userID uuid.UUID) (*models.Profile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	profile, ok := f.profiles[userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	found := *profile
+	return &found, nil
+}
+
+func (f *fakeProfiles) FindByIDs(ctx context.Context, userIDs []uuid.UUID) ([]models.Profile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var found []models.Profile
+	for _, id := range userIDs {
+		if profile, ok := f.profiles[id]; ok && profile.Active {
+			found = append(found, *profile)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeProfiles) UpdateAccountState(ctx context.Context, profile *models.Profile) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored, ok := f.profiles[profile.UserID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	stored.Active = profile.Active
+	stored.Verified = profile.Verified
+	stored.Important = profile.Important
+	stored.ValidatedAt = profile.ValidatedAt
+	return nil
+}
+
+// stored returns the saved copy of a profile.
+func (f *fakeProfiles) stored(userID uuid.UUID) *models.Profile {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.profiles[userID]
+}
+
+// fakeDirectory answers for auth-service. Users missing from the map are
+// not found; err, when set, fails every lookup.
+type fakeDirectory struct {
+	mu    sync.Mutex
+	users map[uuid.UUID]client.UserRecord
+	err   error
+	calls int
+}
+
+func (f *fakeDirectory) GetUser(ctx context.Context, userID uuid.UUID) (*client.UserRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	user, ok := f.users[userID]
+	if !ok {
+		return nil, client.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+// fakeIndex records which users are indexed.
+type fakeIndex struct {
+	search.Index
+	mu      sync.Mutex
+	indexed map[uuid.UUID]bool
+}
+
+func (f *fakeIndex) Upsert(ctx context.Context, doc search.Document) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.indexed[doc.UserID] = true
+	return nil
+}
+
+func (f *fakeIndex) Remove(ctx context.Context, userID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.indexed, userID)
+	return nil
+}
+
+const testRevalidateAfter = 10 * time.Minute
+
+type loaderFixture struct {
+	loader   *ProfileLoader
+	profiles *fakeProfiles
+	users    *fakeDirectory
+	index    *fakeIndex
+}
+
+func newLoaderFixture(t *testing.T, profiles ...*models.Profile) *loaderFixture {
+	t.Helper()
+	f := &loaderFixture{
+		profiles: newFakeProfiles(profiles...),
+		users:    &fakeDirectory{users: make(map[uuid.UUID]client.UserRecord)},
+		index:    &fakeIndex{indexed: make(map[uuid.UUID]bool)},
+	}
+	cfg := config.ProfilesConfig{RevalidateAfter: testRevalidateAfter}
+	f.loader = NewProfileLoader(f.profiles, f.users, f.index, cfg, newTestLogger(t))
+	return f
+}
+
+// storedProfile is an active profile last validated age ago.
+func storedProfile(age time.Duration) *models.Profile {
+	validatedAt := time.Now().Add(-age)
+	return &models.Profile{UserID: uuid.New(), Name: "Ada", Active: true, ValidatedAt: &validatedAt}
+}
+
+func TestProfileLoaderSeedsMissingProfiles(t *testing.T) {
+	f := newLoaderFixture(t)
+	user := client.UserRecord{ID: uuid.New(), Name: "Ada", Active: true, Verified: true, Roles: []string{"official_news"}}
+	f.users.users[user.ID] = user
+
+	profile, err := f.loader.load(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Name != "Ada" || !profile.Verified || !profile.Important || !profile.Active || profile.ValidatedAt == nil {
+		t.Errorf("seeded profile = %+v", profile)
+	}
+	if f.profiles.stored(user.ID) == nil || !f.index.indexed[user.ID] {
+		t.Error("seeded profile not stored and indexed")
+	}
+
+	inactive := uuid.New()
+	f.users.users[inactive] = client.UserRecord{ID: inactive, Active: false}
+	for _, id := range []uuid.UUID{inactive, uuid.New()} {
+		if _, err := f.loader.load(context.Background(), id); !errors.Is(err, ErrUserNotFound) {
+			t.Errorf("load(%s) = %v, want ErrUserNotFound", id, err)
+		}
+	}
+}
+
+func TestProfileLoaderTrustsRecentlyValidatedProfiles(t *testing.T) {
+	profile := storedProfile(time.Minute)
+	f := newLoaderFixture(t, profile)
+
+	if _, err := f.loader.load(context.Background(), profile.UserID); err != nil {
+		t.Fatal(err)
+	}
+	if f.users.calls != 0 {
+		t.Errorf("auth service called %d times for a profile validated a minute ago", f.users.calls)
+	}
+}
+
+func TestProfileLoaderRevalidatesStaleProfiles(t *testing.T) {
+	tests := []struct {
+		name        string
+		stored      func() *models.Profile
+		user        *client.UserRecord // nil: deleted from auth-service
+		wantErr     error
+		wantActive  bool
+		wantIndexed bool
+	}{
+		{
+			name:        "still active, badges changed",
+			stored:      func() *models.Profile { return storedProfile(time.Hour) },
+			user:        &client.UserRecord{Active: true, Verified: true, Roles: []string{"important_person"}},
+			wantActive:  true,
+			wantIndexed: true,
+		},
+		{
+			name:    "deactivated",
+			stored:  func() *models.Profile { return storedProfile(time.Hour) },
+			user:    &client.UserRecord{Active: false},
+			wantErr: ErrUserNotFound,
+		},
+		{
+			name:    "deleted",
+			stored:  func() *models.Profile { return storedProfile(time.Hour) },
+			wantErr: ErrUserNotFound,
+		},
+		{
+			name: "reactivated",
+			stored: func() *models.Profile {
+				profile := storedProfile(time.Hour)
+				profile.Active = false
+				return profile
+			},
+			user:        &client.UserRecord{Active: true},
+			wantActive:  true,
+			wantIndexed: true,
+		},
+		{
+			name: "never validated",
+			stored: func() *models.Profile {
+				profile := storedProfile(0)
+				profile.ValidatedAt = nil
+				return profile
+			},
+			user:    &client.UserRecord{Active: false},
+			wantErr: ErrUserNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := tt.stored()
+			f := newLoaderFixture(t, stored)
+			f.index.indexed[stored.UserID] = stored.Active
+			if tt.user != nil {
+				tt.user.ID = stored.UserID
+				f.users.users[stored.UserID] = *tt.user
+			}
+
+			profile, err := f.loader.load(context.Background(), stored.UserID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("load = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (profile.Verified != tt.user.Verified || profile.Important != hasImportantRole(tt.user.Roles)) {
+				t.Errorf("profile badges = verified %v, important %v, want them from %+v", profile.Verified, profile.Important, tt.user)
+			}
+
+			saved := f.profiles.stored(stored.UserID)
+			if saved.Active != tt.wantActive {
+				t.Errorf("saved active = %v, want %v", saved.Active, tt.wantActive)
+			}
+			if saved.ValidatedAt == nil || time.Since(*saved.ValidatedAt) > time.Minute {
+				t.Errorf("validated_at = %v, want now", saved.ValidatedAt)
+			}
+			if f.index.indexed[stored.UserID] != tt.wantIndexed {
+				t.Errorf("indexed = %v, want %v", f.index.indexed[stored.UserID], tt.wantIndexed)
+			}
+		})
+	}
+}
+
+func TestProfileLoaderServesStoredStateWhenAuthServiceFails(t *testing.T) {
+	tests := []struct {
+		name    string
+		active  bool
+		wantErr error
+	}{
+		{"active", true, nil},
+		{"inactive", false, ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := storedProfile(time.Hour)
+			stored.Active = tt.active
+			validatedAt := *stored.ValidatedAt
+			f := newLoaderFixture(t, stored)
+			f.users.err = errors.New("auth service unavailable")
+
+			if _, err := f.loader.load(context.Background(), stored.UserID); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("load = %v, want %v", err, tt.wantErr)
+			}
+			// Still stale, so the next load tries again
+			if saved := f.profiles.stored(stored.UserID); !saved.ValidatedAt.Equal(validatedAt) {
+				t.Errorf("validated_at moved to %v after a failed check", saved.ValidatedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"http_server/shared/logging"
+	"http_server/user-service/internal/config"
+	"http_server/user-service/internal/domain/models"
+	"http_server/user-service/internal/domain/repository"
+	"http_server/user-service/internal/search"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrUserNotFound   = errors.New("user not found")
+	ErrInvalidProfile = errors.New("invalid profile")
+	ErrHandleTaken    = errors.New("handle already taken")
+)
+
+// handlePattern is the shape of a handle once lowercased and stripped of @.
+var handlePattern = regexp.MustCompile(`^[a-z0-9_]{3,30}$`)
+
+// ProfileView is a profile as seen by a particular viewer. Relationship is
+// omitted when users view their own profile.
+type ProfileView struct {
+	models.Profile
+	Relationship *models.Relationship `json:"relationship,omitempty"`
+}
+
+// ProfileUpdate lists the profile fields to change; nil fields are kept. An
+// empty handle removes it.
+type ProfileUpdate struct {
+	Handle         *string `json:"handle"`
+	Name           *string `json:"name"`
+	Bio            *string `json:"bio"`
+	ProfilePicture *string `json:"profile_picture"`
+	Location       *string `json:"location"`
+	IsPrivate      *bool   `json:"is_private"`
+}
+
+type ProfileService interface {
+	GetProfile(ctx context.Context, viewerID, userID uuid.UUID) (*ProfileView, error)
+	UpdateProfile(ctx context.Context, userID uuid.UUID, update ProfileUpdate) (*models.Profile, error)
+}
+
+type profileService struct {
+	profiles      *ProfileLoader
+	relationships *relationshipReader
+	profileRepo   repository.ProfileRepository
+	followRepo    repository.FollowRepository
+	index         search.Index
+	config        config.ProfilesConfig
+	logger        *logging.Logger
+}
+
+func NewProfileService(profileRepo repository.ProfileRepository, followRepo repository.FollowRepository, blockRepo repository.BlockRepository, muteRepo repository.MuteRepository, profiles *ProfileLoader, index search.Index, cfg config.ProfilesConfig, logger *logging.Logger) ProfileService {
+	return &profileService{
+		profiles:      profiles,
+		relationships: &relationshipReader{followRepo: followRepo, blockRepo: blockRepo, muteRepo: muteRepo},
+		profileRepo:   profileRepo,
+		followRepo:    followRepo,
+		index:         index,
+		config:        cfg,
+		logger:        logger,
+	}
+}
+
+func (s *profileService) GetProfile(ctx context.Context, viewerID, userID uuid.UUID) (*ProfileView, error) {
+	profile, err := s.profiles.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &ProfileView{Profile: *profile}
+	if viewerID == userID {
+		return view, nil
+	}
+
+	rel, err := s.relationships.read(ctx, viewerID, userID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to read relationship", err,
+			zap.String("viewer_id", viewerID.String()),
+			zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to read relationship: %w", err)
+	}
+	// Accounts that blocked the viewer are indistinguishable from missing ones.
+	if rel.BlockedBy {
+		return nil, ErrUserNotFound
+	}
+	view.Relationship = rel
+	return view, nil
+}
+
+func (s *profileService) UpdateProfile(ctx context.Context, userID uuid.UUID, update ProfileUpdate) (*models.Profile, error) {
+	logger := s.logger.WithContext(ctx)
+
+	profile, err := s.profiles.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	wasPrivate := profile.IsPrivate
+
+	if update.Handle != nil {
+		handle := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(*update.Handle), "@"))
+		switch {
+		case handle == "":
+			profile.Handle = nil
+		case !handlePattern.MatchString(handle):
+			return nil, fmt.Errorf("%w: handle must be 3 to 30 letters, digits or underscores", ErrInvalidProfile)
+		default:
+			profile.Handle = &handle
+		}
+	}
+	if update.Name != nil {
+		profile.Name = strings.TrimSpace(*update.Name)
+		if profile.Name == "" {
+			return nil, fmt.Errorf("%w: name is required", ErrInvalidProfile)
+		}
+		if utf8.RuneCountInString(profile.Name) > s.config.MaxNameLength {
+			return nil, fmt.Errorf("%w: name exceeds %d characters", ErrInvalidProfile, s.config.MaxNameLength)
+		}
+	}
+	if update.Bio != nil {
+		profile.Bio = strings.TrimSpace(*update.Bio)
+		if utf8.RuneCountInString(profile.Bio) > s.config.MaxBioLength {
+			return nil, fmt.Errorf("%w: bio exceeds %d characters", ErrInvalidProfile, s.config.MaxBioLength)
+		}
+	}
+	if update.ProfilePicture != nil {
+		profile.ProfilePicture = strings.TrimSpace(*update.ProfilePicture)
+	}
+	if update.Location != nil {
+		profile.Location = strings.TrimSpace(*update.Location)
+		if utf8.RuneCountInString(profile.Location) > s.config.MaxLocationLength {
+			return nil, fmt.Errorf("%w: location exceeds %d characters", ErrInvalidProfile, s.config.MaxLocationLength)
+		}
+	}
+	if update.IsPrivate != nil {
+		profile.IsPrivate = *update.IsPrivate
+	}
+
+	profile.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	if err := s.profileRepo.Update(ctx, profile); err != nil {
+		if errors.Is(err, repository.ErrDuplicateKey) {
+			return nil, ErrHandleTaken
+		}
+		logger.Error("Failed to update profile", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	if err := s.index.Upsert(ctx, searchDocument(profile)); err != nil {
+		logger.Warn("Failed to index profile", zap.String("user_id", userID.String()), zap.Error(err))
+	}
+
+	// Going public approves everyone who was waiting.
+	if wasPrivate && !profile.IsPrivate {
+		accepted, err := s.followRepo.AcceptAll(ctx, userID)
+		if err != nil {
+			logger.Error("Failed to accept pending follow requests", err, zap.String("user_id", userID.String()))
+			return nil, fmt.Errorf("failed to accept pending follow requests: %w", err)
+		}
+		profile.FollowersCount += accepted
+		logger.Info("Accepted pending follow requests",
+			zap.String("user_id", userID.String()),
+			zap.Int64("accepted", accepted))
+	}
+
+	return profile, nil
+}
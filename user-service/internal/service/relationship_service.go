@@ -0,0 +1,533 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"http_server/shared/logging"
+	"http_server/shared/pagination"
+	"http_server/user-service/internal/config"
+	"http_server/user-service/internal/domain/models"
+	"http_server/user-service/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidCursor         = pagination.ErrInvalidCursor
+	ErrSelfRelationship      = errors.New("cannot target your own account")
+	ErrAlreadyFollowing      = errors.New("already following or requested")
+	ErrNotFollowing          = errors.New("not following")
+	ErrFollowRequestNotFound = errors.New("follow request not found")
+	ErrBlocked               = errors.New("blocked")
+	ErrPrivateAccount        = errors.New("account is private")
+	ErrAlreadyBlocked        = errors.New("already blocked")
+	ErrNotBlocked            = errors.New("not blocked")
+	ErrAlreadyMuted          = errors.New("already muted")
+	ErrNotMuted              = errors.New("not muted")
+)
+
+// UserSummary is an entry in a follower, following, request, block or mute
+// list. Since is when the relationship was created.
+type UserSummary struct {
+	UserID         uuid.UUID `json:"user_id"`
+	Name           string    `json:"name"`
+	ProfilePicture string    `json:"profile_picture,omitempty"`
+	IsPrivate      bool      `json:"is_private"`
+	Since          time.Time `json:"since"`
+}
+
+type UserPage struct {
+	Users      []UserSummary `json:"users"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+type RelationshipService interface {
+	// Follow follows followeeID, or requests to if the account is private.
+	Follow(ctx context.Context, followerID, followeeID uuid.UUID) (*models.Follow, error)
+	// Unfollow removes a follow or withdraws a pending request.
+	Unfollow(ctx context.Context, followerID, followeeID uuid.UUID) error
+	FollowRequests(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*UserPage, error)
+	AcceptFollowRequest(ctx context.Context, userID, requesterID uuid.UUID) error
+	RejectFollowRequest(ctx context.Context, userID, requesterID uuid.UUID) error
+	Followers(ctx context.Context, viewerID, userID uuid.UUID, cursor string, limit int) (*UserPage, error)
+	Following(ctx context.Context, viewerID, userID uuid.UUID, cursor string, limit int) (*UserPage, error)
+
+	Block(ctx context.Context, blockerID, blockedID uuid.UUID) error
+	Unblock(ctx context.Context, blockerID, blockedID uuid.UUID) error
+	Blocks(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*UserPage, error)
+	Mute(ctx context.Context, muterID, mutedID uuid.UUID) error
+	Unmute(ctx context.Context, muterID, mutedID uuid.UUID) error
+	Mutes(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*UserPage, error)
+
+	// The methods below back the internal API queried by other services.
+	Relationship(ctx context.Context, userID, otherID uuid.UUID) (*models.Relationship, error)
+	FollowingIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	BlockedIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	BlockedByIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	MutedIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type relationshipService struct {
+	profiles      *ProfileLoader
+	relationships *relationshipReader
+	profileRepo   repository.ProfileRepository
+	followRepo    repository.FollowRepository
+	blockRepo     repository.BlockRepository
+	muteRepo      repository.MuteRepository
+	config        config.RelationshipsConfig
+	logger        *logging.Logger
+}
+
+func NewRelationshipService(profileRepo repository.ProfileRepository, followRepo repository.FollowRepository, blockRepo repository.BlockRepository, muteRepo repository.MuteRepository, profiles *ProfileLoader, cfg config.RelationshipsConfig, logger *logging.Logger) RelationshipService {
+	return &relationshipService{
+		profiles:      profiles,
+		relationships: &relationshipReader{followRepo: followRepo, blockRepo: blockRepo, muteRepo: muteRepo},
+		profileRepo:   profileRepo,
+		followRepo:    followRepo,
+		blockRepo:     blockRepo,
+		muteRepo:      muteRepo,
+		config:        cfg,
+		logger:        logger,
+	}
+}
+
+func (s *relationshipService) Follow(ctx context.Context, followerID, followeeID uuid.UUID) (*models.Follow, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if followerID == followeeID {
+		return nil, ErrSelfRelationship
+	}
+	if _, err := s.profiles.load(ctx, followerID); err != nil {
+		return nil, err
+	}
+	followee, err := s.profiles.load(ctx, followeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	rel, err := s.relationships.read(ctx, followerID, followeeID)
+	if err != nil {
+		logger.Error("Failed to read relationship", err, zap.String("followee_id", followeeID.String()))
+		return nil, fmt.Errorf("failed to read relationship: %w", err)
+	}
+	if rel.BlockedBy {
+		return nil, ErrUserNotFound
+	}
+	if rel.Blocking {
+		return nil, ErrBlocked
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	follow := &models.Follow{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+		Status:     models.FollowAccepted,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if followee.IsPrivate {
+		follow.Status = models.FollowPending
+	}
+
+	if err := s.followRepo.Create(ctx, follow); err != nil {
+		if errors.Is(err, repository.ErrDuplicateKey) {
+			return nil, ErrAlreadyFollowing
+		}
+		logger.Error("Failed to create follow", err, zap.String("followee_id", followeeID.String()))
+		return nil, fmt.Errorf("failed to create follow: %w", err)
+	}
+
+	logger.Info("Follow created",
+		zap.String("follower_id", followerID.String()),
+		zap.String("followee_id", followeeID.String()),
+		zap.String("status", follow.Status))
+	return follow, nil
+}
+
+func (s *relationshipService) Unfollow(ctx context.Context, followerID, followeeID uuid.UUID) error {
+	if err := s.followRepo.Delete(ctx, followerID, followeeID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFollowing
+		}
+		s.logger.WithContext(ctx).Error("Failed to delete follow", err, zap.String("followee_id", followeeID.String()))
+		return fmt.Errorf("failed to delete follow: %w", err)
+	}
+	return nil
+}
+
+func (s *relationshipService) FollowRequests(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*UserPage, error) {
+	limit = pagination.ClampLimit(limit, s.config.DefaultPageSize, s.config.MaxPageSize)
+
+	after, err := pagination.DecodeCursor[models.ListPosition](cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	follows, err := s.followRepo.ListFollowers(ctx, userID, models.FollowPending, after, limit)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to list follow requests", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to list follow requests: %w", err)
+	}
+	return s.buildPage(ctx, followerEntries(follows), limit)
+}
+
+func (s *relationshipService) AcceptFollowRequest(ctx context.Context, userID, requesterID uuid.UUID) error {
+	if err := s.followRepo.Accept(ctx, requesterID, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrFollowRequestNotFound
+		}
+		s.logger.WithContext(ctx).Error("Failed to accept follow request", err, zap.String("requester_id", requesterID.String()))
+		return fmt.Errorf("failed to accept follow request: %w", err)
+	}
+	return nil
+}
+
+func (s *relationshipService) RejectFollowRequest(ctx context.Context, userID, requesterID uuid.UUID) error {
+	logger := s.logger.WithContext(ctx)
+
+	follow, err := s.followRepo.Find(ctx, requesterID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrFollowRequestNotFound
+		}
+		logger.Error("Failed to find follow request", err, zap.String("requester_id", requesterID.String()))
+		return fmt.Errorf("failed to find follow request: %w", err)
+	}
+	if follow.Status != models.FollowPending {
+		return ErrFollowRequestNotFound
+	}
+
+	if err := s.followRepo.Delete(ctx, requesterID, userID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		logger.Error("Failed to reject follow request", err, zap.String("requester_id", requesterID.String()))
+		return fmt.Errorf("failed to reject follow request: %w", err)
+	}
+	return nil
+}
+
+func (s *relationshipService) Followers(ctx context.Context, viewerID, userID uuid.UUID, cursor string, limit int) (*UserPage, error) {
+	limit = pagination.ClampLimit(limit, s.config.DefaultPageSize, s.config.MaxPageSize)
+
+	after, err := pagination.DecodeCursor[models.ListPosition](cursor)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureVisible(ctx, viewerID, userID); err != nil {
+		return nil, err
+	}
+
+	follows, err := s.followRepo.ListFollowers(ctx, userID, models.FollowAccepted, after, limit)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to list followers", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to list followers: %w", err)
+	}
+	return s.buildPage(ctx, followerEntries(follows), limit)
+}
+
+func (s *relationshipService) Following(ctx context.Context, viewerID, userID uuid.UUID, cursor string, limit int) (*UserPage, error) {
+	limit = pagination.ClampLimit(limit, s.config.DefaultPageSize, s.config.MaxPageSize)
+
+	after, err := pagination.DecodeCursor[models.ListPosition](cursor)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureVisible(ctx, viewerID, userID); err != nil {
+		return nil, err
+	}
+
+	follows, err := s.followRepo.ListFollowing(ctx, userID, after, limit)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to list following", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to list following: %w", err)
+	}
+
+	entries := make([]listEntry, len(follows))
+	for i, follow := range follows {
+		entries[i] = listEntry{UserID: follow.FolloweeID, At: follow.CreatedAt}
+	}
+	return s.buildPage(ctx, entries, limit)
+}
+
+// ensureVisible checks that viewerID may see who userID follows and is
+// followed by: the account must exist, must not have blocked the viewer and,
+// if private, must be followed by the viewer.
+func (s *relationshipService) ensureVisible(ctx context.Context, viewerID, userID uuid.UUID) error {
+	profile, err := s.profiles.load(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if viewerID == userID {
+		return nil
+	}
+
+	rel, err := s.relationships.read(ctx, viewerID, userID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to read relationship", err, zap.String("user_id", userID.String()))
+		return fmt.Errorf("failed to read relationship: %w", err)
+	}
+	if rel.BlockedBy {
+		return ErrUserNotFound
+	}
+	if profile.IsPrivate && !rel.Following {
+		return ErrPrivateAccount
+	}
+	return nil
+}
+
+func (s *relationshipService) Block(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	logger := s.logger.WithContext(ctx)
+
+	if blockerID == blockedID {
+		return ErrSelfRelationship
+	}
+	if _, err := s.profiles.load(ctx, blockedID); err != nil {
+		return err
+	}
+
+	block := &models.Block{
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	if err := s.blockRepo.Create(ctx, block); err != nil {
+		if errors.Is(err, repository.ErrDuplicateKey) {
+			return ErrAlreadyBlocked
+		}
+		logger.Error("Failed to create block", err, zap.String("blocked_id", blockedID.String()))
+		return fmt.Errorf("failed to create block: %w", err)
+	}
+
+	logger.Info("Block created",
+		zap.String("blocker_id", blockerID.String()),
+		zap.String("blocked_id", blockedID.String()))
+	return nil
+}
+
+func (s *relationshipService) Unblock(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	if err := s.blockRepo.Delete(ctx, blockerID, blockedID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotBlocked
+		}
+		s.logger.WithContext(ctx).Error("Failed to delete block", err, zap.String("blocked_id", blockedID.String()))
+		return fmt.Errorf("failed to delete block: %w", err)
+	}
+	return nil
+}
+
+func (s *relationshipService) Blocks(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*UserPage, error) {
+	limit = pagination.ClampLimit(limit, s.config.DefaultPageSize, s.config.MaxPageSize)
+
+	after, err := pagination.DecodeCursor[models.ListPosition](cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := s.blockRepo.List(ctx, userID, after, limit)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to list blocks", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to list blocks: %w", err)
+	}
+
+	entries := make([]listEntry, len(blocks))
+	for i, block := range blocks {
+		entries[i] = listEntry{UserID: block.BlockedID, At: block.CreatedAt}
+	}
+	return s.buildPage(ctx, entries, limit)
+}
+
+func (s *relationshipService) Mute(ctx context.Context, muterID, mutedID uuid.UUID) error {
+	if muterID == mutedID {
+		return ErrSelfRelationship
+	}
+	if _, err := s.profiles.load(ctx, mutedID); err != nil {
+		return err
+	}
+
+	mute := &models.Mute{
+		MuterID:   muterID,
+		MutedID:   mutedID,
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	if err := s.muteRepo.Create(ctx, mute); err != nil {
+		if errors.Is(err, repository.ErrDuplicateKey) {
+			return ErrAlreadyMuted
+		}
+		s.logger.WithContext(ctx).Error("Failed to create mute", err, zap.String("muted_id", mutedID.String()))
+		return fmt.Errorf("failed to create mute: %w", err)
+	}
+	return nil
+}
+
+func (s *relationshipService) Unmute(ctx context.Context, muterID, mutedID uuid.UUID) error {
+	if err := s.muteRepo.Delete(ctx, muterID, mutedID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotMuted
+		}
+		s.logger.WithContext(ctx).Error("Failed to delete mute", err, zap.String("muted_id", mutedID.String()))
+		return fmt.Errorf("failed to delete mute: %w", err)
+	}
+	return nil
+}
+
+func (s *relationshipService) Mutes(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*UserPage, error) {
+	limit = pagination.ClampLimit(limit, s.config.DefaultPageSize, s.config.MaxPageSize)
+
+	after, err := pagination.DecodeCursor[models.ListPosition](cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	mutes, err := s.muteRepo.List(ctx, userID, after, limit)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to list mutes", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to list mutes: %w", err)
+	}
+
+	entries := make([]listEntry, len(mutes))
+	for i, mute := range mutes {
+		entries[i] = listEntry{UserID: mute.MutedID, At: mute.CreatedAt}
+	}
+	return s.buildPage(ctx, entries, limit)
+}
+
+func (s *relationshipService) Relationship(ctx context.Context, userID, otherID uuid.UUID) (*models.Relationship, error) {
+	rel, err := s.relationships.read(ctx, userID, otherID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to read relationship", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to read relationship: %w", err)
+	}
+	return rel, nil
+}
+
+func (s *relationshipService) FollowingIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	ids, err := s.followRepo.FollowingIDs(ctx, userID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to list following IDs", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to list following: %w", err)
+	}
+	return ids, nil
+}
+
+func (s *relationshipService) BlockedIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	ids, err := s.blockRepo.BlockedIDs(ctx, userID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to list blocked IDs", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to list blocked accounts: %w", err)
+	}
+	return ids, nil
+}
+
+func (s *relationshipService) BlockedByIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	ids, err := s.blockRepo.BlockerIDs(ctx, userID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to list blocker IDs", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to list blocking accounts: %w", err)
+	}
+	return ids, nil
+}
+
+func (s *relationshipService) MutedIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	ids, err := s.muteRepo.MutedIDs(ctx, userID)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to list muted IDs", err, zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to list muted accounts: %w", err)
+	}
+	return ids, nil
+}
+
+// listEntry is one row of a relationship list before it is joined with the
+// other account's profile.
+type listEntry struct {
+	UserID uuid.UUID
+	At     time.Time
+}
+
+func followerEntries(follows []models.Follow) []listEntry {
+	entries := make([]listEntry, len(follows))
+	for i, follow := range follows {
+		entries[i] = listEntry{UserID: follow.FollowerID, At: follow.CreatedAt}
+	}
+	return entries
+}
+
+// buildPage joins entries with their profiles, keeping the list order. The
+// cursor is taken from the raw entries so that a missing profile never ends
+// pagination early.
+func (s *relationshipService) buildPage(ctx context.Context, entries []listEntry, limit int) (*UserPage, error) {
+	ids := make([]uuid.UUID, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.UserID
+	}
+
+	profiles, err := s.profileRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Failed to load profiles", err)
+		return nil, fmt.Errorf("failed to load profiles: %w", err)
+	}
+	byID := make(map[uuid.UUID]models.Profile, len(profiles))
+	for _, profile := range profiles {
+		byID[profile.UserID] = profile
+	}
+
+	page := &UserPage{Users: make([]UserSummary, 0, len(entries))}
+	for _, entry := range entries {
+		profile, ok := byID[entry.UserID]
+		if !ok {
+			continue
+		}
+		page.Users = append(page.Users, UserSummary{
+			UserID:         entry.UserID,
+			Name:           profile.Name,
+			ProfilePicture: profile.ProfilePicture,
+			IsPrivate:      profile.IsPrivate,
+			Since:          entry.At,
+		})
+	}
+	if len(entries) == limit {
+		last := entries[len(entries)-1]
+		page.NextCursor = pagination.EncodeCursor(models.ListPosition{At: last.At, UserID: last.UserID})
+	}
+	return page, nil
+}
+
+// relationshipReader computes how one account relates to another.
+type relationshipReader struct {
+	followRepo repository.FollowRepository
+	blockRepo  repository.BlockRepository
+	muteRepo   repository.MuteRepository
+}
+
+func (r *relationshipReader) read(ctx context.Context, userID, otherID uuid.UUID) (*models.Relationship, error) {
+	rel := &models.Relationship{}
+
+	follow, err := r.followRepo.Find(ctx, userID, otherID)
+	switch {
+	case err == nil:
+		rel.Following = follow.Status == models.FollowAccepted
+		rel.FollowRequested = follow.Status == models.FollowPending
+	case !errors.Is(err, repository.ErrNotFound):
+		return nil, err
+	}
+
+	reverse, err := r.followRepo.Find(ctx, otherID, userID)
+	switch {
+	case err == nil:
+		rel.FollowedBy = reverse.Status == models.FollowAccepted
+	case !errors.Is(err, repository.ErrNotFound):
+		return nil, err
+	}
+
+	if rel.Blocking, err = r.blockRepo.Exists(ctx, userID, otherID); err != nil {
+		return nil, err
+	}
+	if rel.BlockedBy, err = r.blockRepo.Exists(ctx, otherID, userID); err != nil {
+		return nil, err
+	}
+	if rel.Muting, err = r.muteRepo.Exists(ctx, userID, otherID); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
@@ -0,0 +1,472 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"http_server/user-service/internal/config"
+	"http_server/user-service/internal/domain/models"
+	"http_server/user-service/internal/domain/repository"
+
+	"github.com/google/uuid"
+)
+
+// pair is a directed edge between two accounts.
+type pair struct{ from, to uuid.UUID }
+
+// fakeGraph keeps follows, blocks and mutes in memory. Its follow, block and
+// mute views implement the three repositories over the same state, so a
+// block can remove follows as the Postgres repository does.
+type fakeGraph struct {
+	mu      sync.Mutex
+	follows map[pair]models.Follow
+	blocks  map[pair]models.Block
+	mutes   map[pair]models.Mute
+}
+
+func newFakeGraph() *fakeGraph {
+	return &fakeGraph{
+		follows: make(map[pair]models.Follow),
+		blocks:  make(map[pair]models.Block),
+		mutes:   make(map[pair]models.Mute),
+	}
+}
+
+type fakeFollows struct {
+	repository.FollowRepository
+	g *fakeGraph
+}
+
+func (f fakeFollows) Find(ctx context.Context, followerID, followeeID uuid.UUID) (*models.Follow, error) {
+	f.g.mu.Lock()
+	defer f.g.mu.Unlock()
+	follow, ok := f.g.follows[pair{followerID, followeeID}]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &follow, nil
+}
+
+func (f fakeFollows) Create(ctx context.Context, follow *models.Follow) error {
+	f.g.mu.Lock()
+	defer f.g.mu.Unlock()
+	key := pair{follow.FollowerID, follow.FolloweeID}
+	if _, ok := f.g.follows[key]; ok {
+		return repository.ErrDuplicateKey
+	}
+	f.g.follows[key] = *follow
+	return nil
+}
+
+func (f fakeFollows) Accept(ctx context.Context, followerID, followeeID uuid.UUID) error {
+	f.g.mu.Lock()
+	defer f.g.mu.Unlock()
+	key := pair{followerID, followeeID}
+	follow, ok := f.g.follows[key]
+	if !ok || follow.Status != models.FollowPending {
+		return repository.ErrNotFound
+	}
+	follow.Status = models.FollowAccepted
+	f.g.follows[key] = follow
+	return nil
+}
+
+func (f fakeFollows) Delete(ctx context.Context, followerID, followeeID uuid.UUID) error {
+	f.g.mu.Lock()
+	defer f.g.mu.Unlock()
+	key := pair{followerID, followeeID}
+	if _, ok := f.g.follows[key]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(f.g.follows, key)
+	return nil
+}
+
+func (f fakeFollows) ListFollowers(ctx context.Context, followeeID uuid.UUID, status string, after *models.ListPosition, limit int) ([]models.Follow, error) {
+	f.g.mu.Lock()
+	defer f.g.mu.Unlock()
+	var follows []models.Follow
+	for key, follow := range f.g.follows {
+		if key.to == followeeID && follow.Status == status && len(follows) < limit {
+			follows = append(follows, follow)
+		}
+	}
+	return follows, nil
+}
+
+type fakeBlocks struct {
+	repository.BlockRepository
+	g *fakeGraph
+}
+
+func (f fakeBlocks) Create(ctx context.Context, block *models.Block) error {
+	f.g.mu.Lock()
+	defer f.g.mu.Unlock()
+	key := pair{block.BlockerID, block.BlockedID}
+	if _, ok := f.g.blocks[key]; ok {
+		return repository.ErrDuplicateKey
+	}
+	f.g.blocks[key] = *block
+	delete(f.g.follows, key)
+	delete(f.g.follows, pair{block.BlockedID, block.BlockerID})
+	return nil
+}
+
+func (f fakeBlocks) Delete(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	f.g.mu.Lock()
+	defer f.g.mu.Unlock()
+	key := pair{blockerID, blockedID}
+	if _, ok := f.g.blocks[key]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(f.g.blocks, key)
+	return nil
+}
+
+func (f fakeBlocks) Exists(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error) {
+	f.g.mu.Lock()
+	defer f.g.mu.Unlock()
+	_, ok := f.g.blocks[pair{blockerID, blockedID}]
+	return ok, nil
+}
+
+type fakeMutes struct {
+	repository.MuteRepository
+	g *fakeGraph
+}
+
+func (f fakeMutes) Create(ctx context.Context, mute *models.Mute) error {
+	f.g.mu.Lock()
+	defer f.g.mu.Unlock()
+	key := pair{mute.MuterID, mute.MutedID}
+	if _, ok := f.g.mutes[key]; ok {
+		return repository.ErrDuplicateKey
+	}
+	f.g.mutes[key] = *mute
+	return nil
+}
+
+func (f fakeMutes) Delete(ctx context.Context, muterID, mutedID uuid.UUID) error {
+	f.g.mu.Lock()
+	defer f.g.mu.Unlock()
+	key := pair{muterID, mutedID}
+	if _, ok := f.g.mutes[key]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(f.g.mutes, key)
+	return nil
+}
+
+func (f fakeMutes) Exists(ctx context.Context, muterID, mutedID uuid.UUID) (bool, error) {
+	f.g.mu.Lock()
+	defer f.g.mu.Unlock()
+	_, ok := f.g.mutes[pair{muterID, mutedID}]
+	return ok, nil
+}
+
+func (f fakeMutes) MutedIDs(ctx context.Context, muterID uuid.UUID) ([]uuid.UUID, error) {
+	f.g.mu.Lock()
+	defer f.g.mu.Unlock()
+	var ids []uuid.UUID
+	for key := range f.g.mutes {
+		if key.from == muterID {
+			ids = append(ids, key.to)
+		}
+	}
+	return ids, nil
+}
+
+type relationshipFixture struct {
+	service RelationshipService
+	graph   *fakeGraph
+}
+
+// newRelationshipFixture builds the service over the given profiles, all
+// recently validated so that auth-service is never asked.
+func newRelationshipFixture(t *testing.T, profiles ...*models.Profile) *relationshipFixture {
+	t.Helper()
+	loader := newLoaderFixture(t, profiles...)
+	graph := newFakeGraph()
+	cfg := config.RelationshipsConfig{DefaultPageSize: 20, MaxPageSize: 100}
+	return &relationshipFixture{
+		service: NewRelationshipService(loader.profiles, fakeFollows{g: graph}, fakeBlocks{g: graph}, fakeMutes{g: graph}, loader.loader, cfg, newTestLogger(t)),
+		graph:   graph,
+	}
+}
+
+func (f *relationshipFixture) follow(from, to uuid.UUID, status string) {
+	f.graph.follows[pair{from, to}] = models.Follow{FollowerID: from, FolloweeID: to, Status: status, CreatedAt: time.Now()}
+}
+
+func (f *relationshipFixture) block(from, to uuid.UUID) {
+	f.graph.blocks[pair{from, to}] = models.Block{BlockerID: from, BlockedID: to, CreatedAt: time.Now()}
+}
+
+func TestRelationshipServiceFollow(t *testing.T) {
+	tests := []struct {
+		name       string
+		private    bool
+		setup      func(f *relationshipFixture, follower, followee uuid.UUID)
+		target     func(follower, followee uuid.UUID) uuid.UUID // default: followee
+		wantErr    error
+		wantStatus string
+	}{
+		{name: "public account", wantStatus: models.FollowAccepted},
+		{name: "private account", private: true, wantStatus: models.FollowPending},
+		{
+			name: "already following",
+			setup: func(f *relationshipFixture, follower, followee uuid.UUID) {
+				f.follow(follower, followee, models.FollowAccepted)
+			},
+			wantErr: ErrAlreadyFollowing,
+		},
+		{
+			name: "blocked by followee",
+			setup: func(f *relationshipFixture, follower, followee uuid.UUID) {
+				f.block(followee, follower)
+			},
+			wantErr: ErrUserNotFound,
+		},
+		{
+			name: "blocking followee",
+			setup: func(f *relationshipFixture, follower, followee uuid.UUID) {
+				f.block(follower, followee)
+			},
+			wantErr: ErrBlocked,
+		},
+		{
+			name:    "self",
+			target:  func(follower, followee uuid.UUID) uuid.UUID { return follower },
+			wantErr: ErrSelfRelationship,
+		},
+		{
+			name:    "unknown account",
+			target:  func(follower, followee uuid.UUID) uuid.UUID { return uuid.New() },
+			wantErr: ErrUserNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			follower, followee := storedProfile(time.Minute), storedProfile(time.Minute)
+			followee.IsPrivate = tt.private
+			f := newRelationshipFixture(t, follower, followee)
+			if tt.setup != nil {
+				tt.setup(f, follower.UserID, followee.UserID)
+			}
+			target := followee.UserID
+			if tt.target != nil {
+				target = tt.target(follower.UserID, followee.UserID)
+			}
+
+			follow, err := f.service.Follow(context.Background(), follower.UserID, target)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Follow = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && follow.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", follow.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRelationshipServiceFollowRequests(t *testing.T) {
+	owner, requester, follower := storedProfile(time.Minute), storedProfile(time.Minute), storedProfile(time.Minute)
+	owner.IsPrivate = true
+	f := newRelationshipFixture(t, owner, requester, follower)
+	f.follow(requester.UserID, owner.UserID, models.FollowPending)
+	f.follow(follower.UserID, owner.UserID, models.FollowAccepted)
+	ctx := context.Background()
+
+	page, err := f.service.FollowRequests(ctx, owner.UserID, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Users) != 1 || page.Users[0].UserID != requester.UserID {
+		t.Errorf("FollowRequests = %+v, want only the requester", page.Users)
+	}
+
+	// An accepted follow is not a request and is left alone
+	if err := f.service.RejectFollowRequest(ctx, owner.UserID, follower.UserID); !errors.Is(err, ErrFollowRequestNotFound) {
+		t.Errorf("RejectFollowRequest(accepted) = %v, want ErrFollowRequestNotFound", err)
+	}
+	if _, ok := f.graph.follows[pair{follower.UserID, owner.UserID}]; !ok {
+		t.Error("rejecting an accepted follow removed it")
+	}
+
+	if err := f.service.AcceptFollowRequest(ctx, owner.UserID, requester.UserID); err != nil {
+		t.Fatalf("AcceptFollowRequest: %v", err)
+	}
+	rel, err := f.service.Relationship(ctx, requester.UserID, owner.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rel.Following || rel.FollowRequested {
+		t.Errorf("relationship after accept = %+v, want following", rel)
+	}
+	if err := f.service.AcceptFollowRequest(ctx, owner.UserID, requester.UserID); !errors.Is(err, ErrFollowRequestNotFound) {
+		t.Errorf("second AcceptFollowRequest = %v, want ErrFollowRequestNotFound", err)
+	}
+
+	f.follow(requester.UserID, owner.UserID, models.FollowPending)
+	if err := f.service.RejectFollowRequest(ctx, owner.UserID, requester.UserID); err != nil {
+		t.Fatalf("RejectFollowRequest: %v", err)
+	}
+	if _, ok := f.graph.follows[pair{requester.UserID, owner.UserID}]; ok {
+		t.Error("rejected follow request still stored")
+	}
+}
+
+func TestRelationshipServiceFollowersVisibility(t *testing.T) {
+	tests := []struct {
+		name    string
+		private bool
+		setup   func(f *relationshipFixture, viewer, owner uuid.UUID)
+		wantErr error
+	}{
+		{name: "public account"},
+		{name: "private account, not following", private: true, wantErr: ErrPrivateAccount},
+		{
+			name:    "private account, request pending",
+			private: true,
+			setup: func(f *relationshipFixture, viewer, owner uuid.UUID) {
+				f.follow(viewer, owner, models.FollowPending)
+			},
+			wantErr: ErrPrivateAccount,
+		},
+		{
+			name:    "private account, following",
+			private: true,
+			setup: func(f *relationshipFixture, viewer, owner uuid.UUID) {
+				f.follow(viewer, owner, models.FollowAccepted)
+			},
+		},
+		{
+			name: "blocked by owner",
+			setup: func(f *relationshipFixture, viewer, owner uuid.UUID) {
+				f.block(owner, viewer)
+			},
+			wantErr: ErrUserNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer, owner := storedProfile(time.Minute), storedProfile(time.Minute)
+			owner.IsPrivate = tt.private
+			f := newRelationshipFixture(t, viewer, owner)
+			if tt.setup != nil {
+				tt.setup(f, viewer.UserID, owner.UserID)
+			}
+
+			if _, err := f.service.Followers(context.Background(), viewer.UserID, owner.UserID, "", 0); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Followers = %v, want %v", err, tt.wantErr)
+			}
+			// Owners always see their own lists
+			if _, err := f.service.Followers(context.Background(), owner.UserID, owner.UserID, "", 0); err != nil {
+				t.Errorf("Followers of self = %v", err)
+			}
+		})
+	}
+}
+
+func TestRelationshipServiceBlock(t *testing.T) {
+	blocker, blocked := storedProfile(time.Minute), storedProfile(time.Minute)
+	f := newRelationshipFixture(t, blocker, blocked)
+	f.follow(blocker.UserID, blocked.UserID, models.FollowAccepted)
+	f.follow(blocked.UserID, blocker.UserID, models.FollowPending)
+	ctx := context.Background()
+
+	if err := f.service.Block(ctx, blocker.UserID, blocker.UserID); !errors.Is(err, ErrSelfRelationship) {
+		t.Errorf("Block(self) = %v, want ErrSelfRelationship", err)
+	}
+	if err := f.service.Block(ctx, blocker.UserID, uuid.New()); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Block(unknown) = %v, want ErrUserNotFound", err)
+	}
+
+	if err := f.service.Block(ctx, blocker.UserID, blocked.UserID); err != nil {
+		t.Fatalf("Block: %v", err)
+	}
+	if err := f.service.Block(ctx, blocker.UserID, blocked.UserID); !errors.Is(err, ErrAlreadyBlocked) {
+		t.Errorf("second Block = %v, want ErrAlreadyBlocked", err)
+	}
+
+	rel, err := f.service.Relationship(ctx, blocker.UserID, blocked.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (models.Relationship{Blocking: true}); *rel != want {
+		t.Errorf("blocker's relationship = %+v, want %+v", *rel, want)
+	}
+	rel, err = f.service.Relationship(ctx, blocked.UserID, blocker.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (models.Relationship{BlockedBy: true}); *rel != want {
+		t.Errorf("blocked account's relationship = %+v, want %+v", *rel, want)
+	}
+
+	if err := f.service.Unblock(ctx, blocker.UserID, blocked.UserID); err != nil {
+		t.Fatalf("Unblock: %v", err)
+	}
+	if err := f.service.Unblock(ctx, blocker.UserID, blocked.UserID); !errors.Is(err, ErrNotBlocked) {
+		t.Errorf("second Unblock = %v, want ErrNotBlocked", err)
+	}
+	// Unblocking does not bring the removed follows back
+	if _, err := f.service.Follow(ctx, blocker.UserID, blocked.UserID); err != nil {
+		t.Errorf("Follow after unblock = %v", err)
+	}
+}
+
+func TestRelationshipServiceMute(t *testing.T) {
+	muter, muted := storedProfile(time.Minute), storedProfile(time.Minute)
+	f := newRelationshipFixture(t, muter, muted)
+	f.follow(muter.UserID, muted.UserID, models.FollowAccepted)
+	ctx := context.Background()
+
+	if err := f.service.Mute(ctx, muter.UserID, muter.UserID); !errors.Is(err, ErrSelfRelationship) {
+		t.Errorf("Mute(self) = %v, want ErrSelfRelationship", err)
+	}
+	if err := f.service.Mute(ctx, muter.UserID, uuid.New()); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Mute(unknown) = %v, want ErrUserNotFound", err)
+	}
+
+	if err := f.service.Mute(ctx, muter.UserID, muted.UserID); err != nil {
+		t.Fatalf("Mute: %v", err)
+	}
+	if err := f.service.Mute(ctx, muter.UserID, muted.UserID); !errors.Is(err, ErrAlreadyMuted) {
+		t.Errorf("second Mute = %v, want ErrAlreadyMuted", err)
+	}
+
+	// Muting is private to the muter and keeps the follow
+	rel, err := f.service.Relationship(ctx, muter.UserID, muted.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rel.Muting || !rel.Following {
+		t.Errorf("muter's relationship = %+v, want muting and following", rel)
+	}
+	rel, err = f.service.Relationship(ctx, muted.UserID, muter.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rel.Muting || !rel.FollowedBy {
+		t.Errorf("muted account's relationship = %+v, want followed by and not muting", rel)
+	}
+
+	ids, err := f.service.MutedIDs(ctx, muter.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != muted.UserID {
+		t.Errorf("MutedIDs = %v, want [%s]", ids, muted.UserID)
+	}
+
+	if err := f.service.Unmute(ctx, muter.UserID, muted.UserID); err != nil {
+		t.Fatalf("Unmute: %v", err)
+	}
+	if err := f.service.Unmute(ctx, muter.UserID, muted.UserID); !errors.Is(err, ErrNotMuted) {
+		t.Errorf("second Unmute = %v, want ErrNotMuted", err)
+	}
+}
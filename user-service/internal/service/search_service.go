@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"http_server/shared/logging"
+	"http_server/shared/pagination"
+	"http_server/user-service/internal/config"
+	"http_server/user-service/internal/domain/models"
+	"http_server/user-service/internal/domain/repository"
+	"http_server/user-service/internal/search"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidQuery = errors.New("invalid search query")
+
+// reindexBatchSize is how many profiles Reindex loads per query.
+const reindexBatchSize = 500
+
+// SearchResultUser is one account in a search result.
+type SearchResultUser struct {
+	UserID         uuid.UUID `json:"user_id"`
+	Handle         string    `json:"handle,omitempty"`
+	Name           string    `json:"name"`
+	ProfilePicture string    `json:"profile_picture,omitempty"`
+	IsPrivate      bool      `json:"is_private"`
+	Verified       bool      `json:"verified"`
+	Important      bool      `json:"important"`
+	Score          float64   `json:"score"`
+}
+
+type SearchResult struct {
+	Users []SearchResultUser `json:"users"`
+}
+
+type SearchService interface {
+	// Search finds accounts by name or handle, hiding accounts the viewer has
+	// blocked or been blocked by.
+	Search(ctx context.Context, viewerID uuid.UUID, query string, limit int) (*SearchResult, error)
+	// Reindex loads every active profile into the index. Only in-process
+	// indexes need it.
+	Reindex(ctx context.Context) error
+}
+
+type searchService struct {
+	index       search.Index
+	profileRepo repository.ProfileRepository
+	blockRepo   repository.BlockRepository
+	config      config.SearchConfig
+	logger      *logging.Logger
+}
+
+func NewSearchService(index search.Index, profileRepo repository.ProfileRepository, blockRepo repository.BlockRepository, cfg config.SearchConfig, logger *logging.Logger) SearchService {
+	return &searchService{
+		index:       index,
+		profileRepo: profileRepo,
+		blockRepo:   blockRepo,
+		config:      cfg,
+		logger:      logger,
+	}
+}
+
+func (s *searchService) Search(ctx context.Context, viewerID uuid.UUID, query string, limit int) (*SearchResult, error) {
+	logger := s.logger.WithContext(ctx)
+
+	text := search.Normalize(query)
+	if text == "" || utf8.RuneCountInString(text) > s.config.MaxQueryLength {
+		return nil, ErrInvalidQuery
+	}
+	limit = pagination.ClampLimit(limit, s.config.DefaultLimit, s.config.MaxLimit)
+
+	blocked, err := s.blockRepo.BlockedIDs(ctx, viewerID)
+	if err != nil {
+		logger.Error("Failed to list blocked accounts", err, zap.String("user_id", viewerID.String()))
+		return nil, fmt.Errorf("failed to list blocked accounts: %w", err)
+	}
+	blockers, err := s.blockRepo.BlockerIDs(ctx, viewerID)
+	if err != nil {
+		logger.Error("Failed to list blocking accounts", err, zap.String("user_id", viewerID.String()))
+		return nil, fmt.Errorf("failed to list blocking accounts: %w", err)
+	}
+
+	hits, err := s.index.Search(ctx, search.Query{
+		Text:          text,
+		Exclude:       append(blocked, blockers...),
+		MinSimilarity: s.config.MinSimilarity,
+		Limit:         limit,
+	})
+	if err != nil {
+		logger.Error("Failed to search profiles", err)
+		return nil, fmt.Errorf("failed to search profiles: %w", err)
+	}
+
+	ids := make([]uuid.UUID, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.UserID
+	}
+	profiles, err := s.profileRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		logger.Error("Failed to load profiles", err)
+		return nil, fmt.Errorf("failed to load profiles: %w", err)
+	}
+	byID := make(map[uuid.UUID]models.Profile, len(profiles))
+	for _, profile := range profiles {
+		byID[profile.UserID] = profile
+	}
+
+	result := &SearchResult{Users: make([]SearchResultUser, 0, len(hits))}
+	for _, hit := range hits {
+		profile, ok := byID[hit.UserID]
+		if !ok {
+			continue
+		}
+		user := SearchResultUser{
+			UserID:         profile.UserID,
+			Name:           profile.Name,
+			ProfilePicture: profile.ProfilePicture,
+			IsPrivate:      profile.IsPrivate,
+			Verified:       profile.Verified,
+			Important:      profile.Important,
+			Score:          hit.Score,
+		}
+		if profile.Handle != nil {
+			user.Handle = *profile.Handle
+		}
+		result.Users = append(result.Users, user)
+	}
+	return result, nil
+}
+
+func (s *searchService) Reindex(ctx context.Context) error {
+	logger := s.logger.WithContext(ctx)
+
+	after := uuid.Nil
+	indexed := 0
+	for {
+		profiles, err := s.profileRepo.ListAfter(ctx, after, reindexBatchSize)
+		if err != nil {
+			logger.Error("Failed to load profiles for reindex", err)
+			return fmt.Errorf("failed to load profiles: %w", err)
+		}
+		for i := range profiles {
+			if !profiles[i].Active {
+				continue
+			}
+			if err := s.index.Upsert(ctx, searchDocument(&profiles[i])); err != nil {
+				return fmt.Errorf("failed to index profile: %w", err)
+			}
+		}
+		indexed += len(profiles)
+		if len(profiles) < reindexBatchSize {
+			break
+		}
+		after = profiles[len(profiles)-1].UserID
+	}
+
+	logger.Info("Search index rebuilt", zap.Int("profiles", indexed))
+	return nil
+}